@@ -0,0 +1,253 @@
+package alkira
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+)
+
+// cidrRange is a prefix matching the prefixes within it of a length
+// from ge to le, like the prefix_range of a prefix list, or only itself
+// when both are zero.
+type cidrRange struct {
+	prefix netip.Prefix
+	ge     int
+	le     int
+}
+
+// bounds returns the shortest and longest length of the prefixes the
+// range matches.
+func (r cidrRange) bounds() (int, int) {
+	switch {
+	case r.ge == 0 && r.le == 0:
+		return r.prefix.Bits(), r.prefix.Bits()
+	case r.le == 0:
+		return r.ge, r.prefix.Addr().BitLen()
+	case r.ge == 0:
+		return r.prefix.Bits(), r.le
+	default:
+		return r.ge, r.le
+	}
+}
+
+// key returns the range with its bounds as ge and le, the same for
+// ranges matching the same prefixes.
+func (r cidrRange) key() cidrRange {
+	lo, hi := r.bounds()
+	return cidrRange{prefix: r.prefix, ge: lo, le: hi}
+}
+
+func (r cidrRange) validate() error {
+	lo, hi := r.bounds()
+
+	if lo < r.prefix.Bits() || hi > r.prefix.Addr().BitLen() || lo > hi {
+		return fmt.Errorf("%s: ge and le must be within %d and %d with ge not greater than le",
+			r, r.prefix.Bits(), r.prefix.Addr().BitLen())
+	}
+
+	return nil
+}
+
+func (r cidrRange) String() string {
+	s := r.prefix.String()
+	if r.ge != 0 {
+		s += fmt.Sprintf(" ge %d", r.ge)
+	}
+	if r.le != 0 {
+		s += fmt.Sprintf(" le %d", r.le)
+	}
+	return s
+}
+
+// contains returns whether r matches everything o matches. With
+// addresses, ranges are the addresses of their prefix and ge and le
+// are ignored.
+func (r cidrRange) contains(o cidrRange, addresses bool) bool {
+	if r.prefix.Bits() > o.prefix.Bits() || !r.prefix.Contains(o.prefix.Addr()) {
+		return false
+	}
+
+	if addresses {
+		return true
+	}
+
+	lo, hi := r.bounds()
+	olo, ohi := o.bounds()
+
+	return lo <= olo && ohi <= hi
+}
+
+// cidrAggregate is a range of a summary with the inputs it covers.
+type cidrAggregate struct {
+	cidrRange
+	inputs []cidrRange
+}
+
+// absorbed returns the inputs covered by the aggregate other than the
+// aggregate itself.
+func (a cidrAggregate) absorbed() []cidrRange {
+	var absorbed []cidrRange
+	for _, in := range a.inputs {
+		if in != a.cidrRange {
+			absorbed = append(absorbed, in)
+		}
+	}
+	return absorbed
+}
+
+// summarizeCidrs returns the fewest ranges matching what the inputs
+// match, dropping the ranges contained in others and merging sibling
+// ranges into their parent. With addresses, the ranges are taken as
+// the addresses of their prefix, e.g. for lists matching traffic,
+// otherwise as the prefixes they match, e.g. for lists matching
+// routes, so siblings merge into a parent range matching the same
+// lengths. The ranges are sorted by address.
+func summarizeCidrs(in []cidrRange, addresses bool) ([]cidrAggregate, error) {
+	var aggregates []cidrAggregate
+	index := make(map[cidrRange]int)
+
+	for _, r := range in {
+		if addresses {
+			r.ge, r.le = 0, 0
+		} else if err := r.validate(); err != nil {
+			return nil, err
+		}
+
+		r.prefix = r.prefix.Masked()
+
+		if i, ok := index[r.key()]; ok {
+			aggregates[i].inputs = append(aggregates[i].inputs, r)
+			continue
+		}
+
+		index[r.key()] = len(aggregates)
+		aggregates = append(aggregates, cidrAggregate{cidrRange: r, inputs: []cidrRange{r}})
+	}
+
+	for changed := true; changed; {
+		aggregates = absorbContainedCidrs(aggregates, addresses)
+		aggregates, changed = mergeSiblingCidrs(aggregates, addresses)
+	}
+
+	sortCidrAggregates(aggregates)
+
+	return aggregates, nil
+}
+
+// sortCidrAggregates orders IPv4 before IPv6, then by address, from
+// the shortest to the longest prefix and from the widest range.
+func sortCidrAggregates(aggregates []cidrAggregate) {
+	sort.SliceStable(aggregates, func(i, j int) bool {
+		a, b := aggregates[i].cidrRange, aggregates[j].cidrRange
+		if a.prefix != b.prefix {
+			return prefixLess(a.prefix, b.prefix)
+		}
+
+		alo, ahi := a.bounds()
+		blo, bhi := b.bounds()
+		if alo != blo {
+			return alo < blo
+		}
+		return ahi > bhi
+	})
+}
+
+// absorbContainedCidrs moves the ranges contained in others into them.
+func absorbContainedCidrs(aggregates []cidrAggregate, addresses bool) []cidrAggregate {
+	// Containers come before the ranges they contain.
+	sortCidrAggregates(aggregates)
+
+	var kept []cidrAggregate
+	byPrefix := make(map[netip.Prefix][]int)
+
+	for _, a := range aggregates {
+		container := -1
+
+		for bits := a.prefix.Bits(); bits >= 0 && container < 0; bits-- {
+			parent, _ := a.prefix.Addr().Prefix(bits)
+			for _, i := range byPrefix[parent] {
+				if kept[i].contains(a.cidrRange, addresses) {
+					container = i
+					break
+				}
+			}
+		}
+
+		if container >= 0 {
+			kept[container].inputs = append(kept[container].inputs, a.inputs...)
+			continue
+		}
+
+		byPrefix[a.prefix] = append(byPrefix[a.prefix], len(kept))
+		kept = append(kept, a)
+	}
+
+	return kept
+}
+
+// mergeSiblingCidrs merges the pairs of sibling ranges matching the
+// same lengths into their parent.
+func mergeSiblingCidrs(aggregates []cidrAggregate, addresses bool) ([]cidrAggregate, bool) {
+	index := make(map[cidrRange]int, len(aggregates))
+	for i, a := range aggregates {
+		index[a.key()] = i
+	}
+
+	merged := make([]bool, len(aggregates))
+	var result []cidrAggregate
+	changed := false
+
+	for i, a := range aggregates {
+		if merged[i] {
+			continue
+		}
+
+		bits := a.prefix.Bits()
+		if bits == 0 {
+			continue
+		}
+
+		parent, _ := a.prefix.Addr().Prefix(bits - 1)
+
+		sibling := a.key()
+		sibling.prefix = netip.PrefixFrom(siblingAddr(a.prefix), bits)
+
+		j, ok := index[sibling]
+		if !ok || merged[j] {
+			continue
+		}
+
+		// Ranges match no prefix shorter than their own, so the parent
+		// matches the same prefixes when it starts at the siblings'
+		// length.
+		r := cidrRange{prefix: parent}
+		if !addresses {
+			r.ge, r.le = a.bounds()
+		}
+
+		merged[i], merged[j] = true, true
+		changed = true
+
+		inputs := append(append([]cidrRange{}, a.inputs...), aggregates[j].inputs...)
+		result = append(result, cidrAggregate{cidrRange: r, inputs: inputs})
+	}
+
+	for i, a := range aggregates {
+		if !merged[i] {
+			result = append(result, a)
+		}
+	}
+
+	return result, changed
+}
+
+// siblingAddr returns the address of the other half of the parent of a
+// prefix.
+func siblingAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	bit := p.Bits() - 1
+	b[bit/8] ^= 0x80 >> (bit % 8)
+
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
@@ -0,0 +1,135 @@
+package alkira
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCidrRange(prefix string, ge, le int) cidrRange {
+	return cidrRange{prefix: netip.MustParsePrefix(prefix), ge: ge, le: le}
+}
+
+func cidrAggregateStrings(aggregates []cidrAggregate) []string {
+	var result []string
+	for _, a := range aggregates {
+		s := a.String()
+		for _, r := range a.absorbed() {
+			s += " <" + r.String()
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func TestSummarizeCidrsAddresses(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []string
+		want   []string
+	}{
+		{
+			name:   "adjacent",
+			inputs: []string{"10.0.1.0/24", "10.0.0.0/24"},
+			want:   []string{"10.0.0.0/23 <10.0.0.0/24 <10.0.1.0/24"},
+		},
+		{
+			name:   "contained",
+			inputs: []string{"10.1.2.0/24", "10.0.0.0/8", "10.1.0.0/16"},
+			want:   []string{"10.0.0.0/8 <10.1.0.0/16 <10.1.2.0/24"},
+		},
+		{
+			name:   "duplicates",
+			inputs: []string{"192.168.0.0/16", "192.168.0.0/16"},
+			want:   []string{"192.168.0.0/16"},
+		},
+		{
+			name:   "merged then contained",
+			inputs: []string{"10.0.0.0/25", "10.0.0.128/25", "10.0.0.64/26", "10.0.1.0/24"},
+			want:   []string{"10.0.0.0/23 <10.0.0.0/25 <10.0.0.64/26 <10.0.0.128/25 <10.0.1.0/24"},
+		},
+		{
+			name:   "apart",
+			inputs: []string{"10.0.1.0/24", "10.0.2.0/24", "2001:db8::/32"},
+			want:   []string{"10.0.1.0/24", "10.0.2.0/24", "2001:db8::/32"},
+		},
+		{
+			name:   "halves of the address space",
+			inputs: []string{"128.0.0.0/1", "0.0.0.0/1"},
+			want:   []string{"0.0.0.0/0 <0.0.0.0/1 <128.0.0.0/1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in []cidrRange
+			for _, p := range tt.inputs {
+				in = append(in, testCidrRange(p, 0, 0))
+			}
+
+			aggregates, err := summarizeCidrs(in, true)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cidrAggregateStrings(aggregates))
+		})
+	}
+}
+
+func TestSummarizeCidrsRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		inputs  []cidrRange
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "exact siblings merge into a range",
+			inputs: []cidrRange{testCidrRange("10.0.0.0/24", 0, 0), testCidrRange("10.0.1.0/24", 0, 0)},
+			want:   []string{"10.0.0.0/23 ge 24 le 24 <10.0.0.0/24 <10.0.1.0/24"},
+		},
+		{
+			name:   "sibling ranges merge",
+			inputs: []cidrRange{testCidrRange("10.0.0.0/24", 0, 28), testCidrRange("10.0.1.0/24", 24, 28)},
+			want:   []string{"10.0.0.0/23 ge 24 le 28 <10.0.0.0/24 le 28 <10.0.1.0/24 ge 24 le 28"},
+		},
+		{
+			name:   "siblings with other lengths don't merge",
+			inputs: []cidrRange{testCidrRange("10.0.0.0/24", 25, 28), testCidrRange("10.0.1.0/24", 25, 30)},
+			want:   []string{"10.0.0.0/24 ge 25 le 28", "10.0.1.0/24 ge 25 le 30"},
+		},
+		{
+			name:   "contained lengths",
+			inputs: []cidrRange{testCidrRange("10.0.0.0/8", 16, 0), testCidrRange("10.1.0.0/16", 20, 24), testCidrRange("10.2.0.0/16", 0, 0)},
+			want:   []string{"10.0.0.0/8 ge 16 <10.1.0.0/16 ge 20 le 24 <10.2.0.0/16"},
+		},
+		{
+			name:   "not contained outside the lengths",
+			inputs: []cidrRange{testCidrRange("10.0.0.0/8", 16, 24), testCidrRange("10.1.0.0/16", 20, 28)},
+			want:   []string{"10.0.0.0/8 ge 16 le 24", "10.1.0.0/16 ge 20 le 28"},
+		},
+		{
+			name:   "same prefix wider range",
+			inputs: []cidrRange{testCidrRange("10.0.0.0/16", 24, 26), testCidrRange("10.0.0.0/16", 20, 28)},
+			want:   []string{"10.0.0.0/16 ge 20 le 28 <10.0.0.0/16 ge 24 le 26"},
+		},
+		{
+			name:    "invalid range",
+			inputs:  []cidrRange{testCidrRange("10.0.0.0/16", 24, 20)},
+			wantErr: "10.0.0.0/16 ge 24 le 20: ge and le must be within 16 and 32 with ge not greater than le",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregates, err := summarizeCidrs(tt.inputs, false)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cidrAggregateStrings(aggregates))
+		})
+	}
+}
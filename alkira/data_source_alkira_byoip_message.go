@@ -0,0 +1,103 @@
+package alkira
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAlkiraByoipMessage() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to build the message to sign " +
+			"for the `message` of `alkira_byoip_prefix`. It is computed " +
+			"locally without calling the API.",
+
+		Read: dataSourceAlkiraByoipMessageRead,
+
+		Schema: map[string]*schema.Schema{
+			"cloud_provider": {
+				Description:  "Cloud provider for the BYOIP, `AWS` or `AZURE`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"AWS", "AZURE"}, false),
+			},
+			"prefix": {
+				Description: "Prefix for BYOIP.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"account_id": {
+				Description: "The 12-digit AWS account ID for AWS, or the " +
+					"subscription ID for AZURE.",
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"expiry_date": {
+				Description: "The expiry date of the message for AWS, or " +
+					"the validity date on the ROA for AZURE, in the " +
+					"format YYYY-MM-DD.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateExpiryDate,
+			},
+			"message": {
+				Description: "The message to sign, for the `message` of " +
+					"`alkira_byoip_prefix`.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"openssl_command": {
+				Description: "The openssl command signing the message with " +
+					"the private key in `private.key` and printing the " +
+					"`signature` in the format the cloud provider expects.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraByoipMessageRead(d *schema.ResourceData, m interface{}) error {
+	cloudProvider := d.Get("cloud_provider").(string)
+
+	prefix, err := parseNetworkPrefix(d.Get("prefix").(string))
+	if err != nil {
+		return fmt.Errorf("prefix: %w", err)
+	}
+
+	expiry, err := time.Parse(expiryDateLayout, d.Get("expiry_date").(string))
+	if err != nil {
+		return fmt.Errorf("expiry_date must be a date in the format YYYY-MM-DD")
+	}
+
+	message, err := byoipMessage(cloudProvider, d.Get("account_id").(string), prefix, expiry)
+	if err != nil {
+		return fmt.Errorf("account_id: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(message))
+
+	d.SetId(hex.EncodeToString(sum[:8]))
+	d.Set("message", message)
+	d.Set("openssl_command", byoipOpensslCommand(cloudProvider, message))
+
+	return nil
+}
+
+// byoipOpensslCommand returns the openssl command signing a message the
+// way the cloud provider verifies it, with the private key in
+// private.key.
+func byoipOpensslCommand(cloudProvider, message string) string {
+	command := fmt.Sprintf("printf '%%s' '%s' | openssl dgst -sha256", message)
+
+	if cloudProvider == "AZURE" {
+		return command + " -sign private.key -keyform PEM | openssl base64 -A"
+	}
+
+	return command + " -sigopt rsa_padding_mode:pss -sigopt rsa_pss_saltlen:-1" +
+		" -sign private.key -keyform PEM | openssl base64 | tr -- '+=/' '-_~' | tr -d '\\n'"
+}
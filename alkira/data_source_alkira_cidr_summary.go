@@ -0,0 +1,180 @@
+package alkira
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAlkiraCidrSummary() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to summarize CIDRs, e.g. the " +
+			"`cidrs` of segments or the prefixes of a prefix list, " +
+			"dropping the ones contained in others and merging adjacent " +
+			"ones. It is computed locally without calling the API.",
+
+		Read: dataSourceAlkiraCidrSummaryRead,
+
+		Schema: map[string]*schema.Schema{
+			"cidrs": {
+				Description: "The CIDRs to summarize as addresses, e.g. " +
+					"`10.0.0.0/24` and `10.0.1.0/24` into `10.0.0.0/23`.",
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"prefix_range": {
+				Description: "The prefix ranges to summarize as the " +
+					"prefixes they match, so ranges only merge when the " +
+					"result matches the same prefixes.",
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"prefix": {
+							Description: "A CIDR in `x.x.x.x/m` format.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"ge": {
+							Description: "The shortest length of the " +
+								"prefixes matched, `m` when `0`.",
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+						"le": {
+							Description: "The longest length of the " +
+								"prefixes matched, `m` when `0` and `ge` " +
+								"is `0` too, otherwise `32` or `128`.",
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"summary": {
+				Description: "The summarized `cidrs`, sorted by address.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"prefix_range_summary": {
+				Description: "The summarized `prefix_range`, sorted by " +
+					"address.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"prefix": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ge": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"le": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"absorbed": {
+				Description: "The CIDRs and ranges of the summaries " +
+					"replacing inputs, with the inputs they replace.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"prefix": {
+							Description: "The CIDR of the summary.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"ge": {
+							Description: "The `ge` of the range, `0` for a CIDR.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"le": {
+							Description: "The `le` of the range, `0` for a CIDR.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"absorbed": {
+							Description: "The inputs it replaces, e.g. " +
+								"`10.1.0.0/24` or `10.2.0.0/16 ge 24 le 28`.",
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraCidrSummaryRead(d *schema.ResourceData, m interface{}) error {
+	var cidrs, ranges []cidrRange
+	var inputs []string
+
+	for i, v := range d.Get("cidrs").([]interface{}) {
+		s, _ := v.(string)
+
+		prefix, err := parseNetworkPrefix(s)
+		if err != nil {
+			return fmt.Errorf("cidrs.%d: %w", i, err)
+		}
+
+		cidrs = append(cidrs, cidrRange{prefix: prefix})
+		inputs = append(inputs, prefix.String())
+	}
+
+	for i, v := range d.Get("prefix_range").([]interface{}) {
+		r := v.(map[string]interface{})
+
+		prefix, err := parseNetworkPrefix(r["prefix"].(string))
+		if err != nil {
+			return fmt.Errorf("prefix_range.%d: %w", i, err)
+		}
+
+		cr := cidrRange{prefix: prefix, ge: r["ge"].(int), le: r["le"].(int)}
+		ranges = append(ranges, cr)
+		inputs = append(inputs, cr.String())
+	}
+
+	cidrAggregates, _ := summarizeCidrs(cidrs, true)
+
+	rangeAggregates, err := summarizeCidrs(ranges, false)
+	if err != nil {
+		return fmt.Errorf("prefix_range: %w", err)
+	}
+
+	summary := make([]string, len(cidrAggregates))
+	for i, a := range cidrAggregates {
+		summary[i] = a.prefix.String()
+	}
+
+	rangeSummary := make([]map[string]interface{}, len(rangeAggregates))
+	for i, a := range rangeAggregates {
+		rangeSummary[i] = map[string]interface{}{
+			"prefix": a.prefix.String(),
+			"ge":     a.ge,
+			"le":     a.le,
+		}
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(inputs, ",")))
+
+	d.SetId(hex.EncodeToString(sum[:8]))
+	d.Set("summary", summary)
+	d.Set("prefix_range_summary", rangeSummary)
+	d.Set("absorbed", flattenCidrAggregation(append(cidrAggregates, rangeAggregates...)))
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package alkira
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceAlkiraCidrSummaryRead(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceAlkiraCidrSummary().Schema, map[string]interface{}{
+		"cidrs": []interface{}{"10.0.1.0/24", "10.0.0.0/24", "10.0.0.0/25", "172.16.0.0/16"},
+		"prefix_range": []interface{}{
+			map[string]interface{}{"prefix": "192.168.0.0/24", "ge": 0, "le": 0},
+			map[string]interface{}{"prefix": "192.168.1.0/24", "ge": 0, "le": 0},
+		},
+	})
+
+	require.NoError(t, dataSourceAlkiraCidrSummaryRead(d, nil))
+
+	assert.Equal(t, []interface{}{"10.0.0.0/23", "172.16.0.0/16"}, d.Get("summary"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"prefix": "192.168.0.0/23", "ge": 24, "le": 24},
+	}, d.Get("prefix_range_summary"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"prefix": "10.0.0.0/23", "ge": 0, "le": 0,
+			"absorbed": []interface{}{"10.0.0.0/24", "10.0.0.0/25", "10.0.1.0/24"}},
+		map[string]interface{}{"prefix": "192.168.0.0/23", "ge": 24, "le": 24,
+			"absorbed": []interface{}{"192.168.0.0/24", "192.168.1.0/24"}},
+	}, d.Get("absorbed"))
+	assert.NotEmpty(t, d.Id())
+}
+
+func TestDataSourceAlkiraCidrSummaryReadInvalid(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceAlkiraCidrSummary().Schema, map[string]interface{}{
+		"cidrs": []interface{}{"10.0.0.0/24", "10.0.0.300/24"},
+	})
+
+	assert.EqualError(t, dataSourceAlkiraCidrSummaryRead(d, nil), `cidrs.1: invalid prefix "10.0.0.300/24"`)
+
+	d = schema.TestResourceDataRaw(t, dataSourceAlkiraCidrSummary().Schema, map[string]interface{}{
+		"prefix_range": []interface{}{
+			map[string]interface{}{"prefix": "10.0.0.0/16", "ge": 8, "le": 0},
+		},
+	})
+
+	assert.EqualError(t, dataSourceAlkiraCidrSummaryRead(d, nil),
+		"prefix_range: 10.0.0.0/16 ge 8: ge and le must be within 16 and 32 with ge not greater than le")
+}
@@ -0,0 +1,207 @@
+package alkira
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAlkiraConnectorIpsecCustomerConfig() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to render the customer side " +
+			"configuration of an IPSec connector (`alkira_connector_ipsec` " +
+			"or `alkira_connector_ipsec_adv`) for a network device.\n\n" +
+			"The API doesn't return every CXP side tunnel address, values " +
+			"which are unknown are rendered as placeholders like " +
+			"`<ALKIRA_PUBLIC_IP_1>` unless they are set in `tunnel`.",
+
+		Read: dataSourceAlkiraConnectorIpsecCustomerConfigRead,
+
+		Schema: map[string]*schema.Schema{
+			"connector_ipsec_id": {
+				Description:  "The ID of the `alkira_connector_ipsec`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"connector_ipsec_id", "connector_ipsec_adv_id"},
+			},
+			"connector_ipsec_adv_id": {
+				Description: "The ID of the `alkira_connector_ipsec_adv`. " +
+					"Algorithms of `alkira_connector_ipsec_tunnel_profile` " +
+					"and the reserved CXP addresses of the tunnels are " +
+					"resolved automatically.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"vendor": {
+				Description: "The vendor of the customer device. The value " +
+					"could be `cisco_iosxe`, `juniper_srx`, `paloalto`, " +
+					"`fortigate` or `strongswan`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(ipsecCustomerConfigVendors, false),
+			},
+			"endpoint_name": {
+				Description: "Only render the tunnels of the endpoint " +
+					"(or gateway of `alkira_connector_ipsec_adv`) with " +
+					"this name. By default all endpoints are rendered.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"wan_interface": {
+				Description: "The WAN interface of the customer device " +
+					"terminating the tunnels.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"alkira_asn": {
+				Description: "The ASN of the CXP used as BGP peer AS.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"remote_prefixes": {
+				Description: "Prefixes routed statically over the tunnels " +
+					"when the connector doesn't use dynamic routing.",
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsCIDR,
+				},
+			},
+			"tunnel": {
+				Description: "CXP side addresses of a tunnel which are not " +
+					"returned by the API.",
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"endpoint_name": {
+							Description: "The name of the endpoint or gateway.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"tunnel_no": {
+							Description: "The number of the tunnel, which is " +
+								"the position of the pre-shared key for " +
+								"`alkira_connector_ipsec`, starting from 1.",
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+						"alkira_public_ip": {
+							Description:  "The public IP of the CXP.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsIPv4Address,
+						},
+						"alkira_overlay_ip": {
+							Description: "The overlay IP of the CXP, " +
+								"e.g. `169.254.1.1/30`.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsCIDR,
+						},
+						"customer_overlay_ip": {
+							Description: "The overlay IP of the customer " +
+								"device, e.g. `169.254.1.2/30`.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsCIDR,
+						},
+					},
+				},
+			},
+			"config": {
+				Description: "The rendered configuration.",
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraConnectorIpsecCustomerConfigRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*alkira.AlkiraClient)
+	vendor := d.Get("vendor").(string)
+	endpoint := d.Get("endpoint_name").(string)
+
+	var cfg *ipsecCustomerConfig
+	var connectorId string
+
+	if id, ok := d.GetOk("connector_ipsec_id"); ok {
+		connectorId = id.(string)
+
+		connector, _, err := alkira.NewConnectorIPSec(client).GetById(connectorId)
+		if err != nil {
+			return err
+		}
+
+		cfg, err = ipsecCustomerConfigFromConnector(connector, endpoint)
+		if err != nil {
+			return err
+		}
+	} else {
+		connectorId = d.Get("connector_ipsec_adv_id").(string)
+
+		connector, _, err := alkira.NewConnectorAdvIPSec(client).GetById(connectorId)
+		if err != nil {
+			return err
+		}
+
+		profileApi := alkira.NewConnectorIPSecTunnelProfile(client)
+		reservationApi := alkira.NewIPReservation(client)
+
+		cfg, err = ipsecCustomerConfigFromAdvConnector(connector, endpoint,
+			func(id int) (*alkira.ConnectorIPSecTunnelProfile, error) {
+				profile, _, err := profileApi.GetById(strconv.Itoa(id))
+				return profile, err
+			},
+			func(id string) (*alkira.IPReservation, error) {
+				reservation, _, err := reservationApi.GetById(id)
+				return reservation, err
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg.WanInterface = d.Get("wan_interface").(string)
+	cfg.RemotePrefixes = convertTypeListToStringList(d.Get("remote_prefixes").([]interface{}))
+
+	if cfg.Bgp != nil {
+		cfg.Bgp.AlkiraAsn = d.Get("alkira_asn").(string)
+	}
+
+	cfg.applyOverrides(expandIpsecTunnelOverrides(d.Get("tunnel").([]interface{})))
+
+	config, err := renderIpsecCustomerConfig(vendor, cfg)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s-%s", connectorId, vendor))
+	d.Set("config", config)
+
+	return nil
+}
+
+func expandIpsecTunnelOverrides(in []interface{}) []ipsecTunnelOverride {
+	var overrides []ipsecTunnelOverride
+
+	for _, v := range in {
+		t := v.(map[string]interface{})
+
+		overrides = append(overrides, ipsecTunnelOverride{
+			Endpoint:          t["endpoint_name"].(string),
+			TunnelNo:          t["tunnel_no"].(int),
+			AlkiraPublicIp:    t["alkira_public_ip"].(string),
+			AlkiraOverlayIp:   t["alkira_overlay_ip"].(string),
+			CustomerOverlayIp: t["customer_overlay_ip"].(string),
+		})
+	}
+
+	return overrides
+}
@@ -0,0 +1,641 @@
+package alkira
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+)
+
+//go:embed ipsec_templates/*.tmpl
+var ipsecCustomerConfigTemplates embed.FS
+
+// Vendors supported by alkira_connector_ipsec_customer_config. The
+// value is also the name of the template in ipsec_templates.
+const (
+	ipsecVendorCiscoIosXe = "cisco_iosxe"
+	ipsecVendorJuniperSrx = "juniper_srx"
+	ipsecVendorPaloAlto   = "paloalto"
+	ipsecVendorFortiGate  = "fortigate"
+	ipsecVendorStrongSwan = "strongswan"
+)
+
+var ipsecCustomerConfigVendors = []string{
+	ipsecVendorCiscoIosXe,
+	ipsecVendorJuniperSrx,
+	ipsecVendorPaloAlto,
+	ipsecVendorFortiGate,
+	ipsecVendorStrongSwan,
+}
+
+// Defaults used when an endpoint, gateway or tunnel has no advanced
+// options. They match the default proposal of the CXP.
+var (
+	ipsecDefaultIkeVersion    = "IKEv2"
+	ipsecDefaultEncryption    = []string{"AES256CBC"}
+	ipsecDefaultIntegrity     = []string{"SHA256"}
+	ipsecDefaultDHGroups      = []string{"MODP2048"}
+	ipsecDefaultIkeLifetime   = 28800
+	ipsecDefaultEspLifetime   = 3600
+	ipsecDefaultDPDDelay      = 10
+	ipsecDefaultDPDTimeout    = 30
+	ipsecDefaultOverlayLength = 30
+)
+
+// ipsecCustomerConfig is the vendor independent model rendered by the
+// templates.
+type ipsecCustomerConfig struct {
+	ConnectorName  string
+	VpnMode        string
+	WanInterface   string
+	Tunnels        []*ipsecCustomerTunnel
+	Bgp            *ipsecCustomerBgp
+	RemotePrefixes []string
+}
+
+type ipsecCustomerTunnel struct {
+	Index             int
+	Endpoint          string
+	TunnelNo          int
+	CustomerGatewayIp string
+	AlkiraPublicIp    string
+	CustomerOverlayIp string
+	AlkiraOverlayIp   string
+	OverlayPrefixLen  int
+	PresharedKey      string
+	CustomerAsn       string
+	IkeVersion        string
+	IkeEncryption     []string
+	IkeIntegrity      []string
+	IkeDHGroups       []string
+	EspEncryption     []string
+	EspIntegrity      []string
+	EspDHGroups       []string
+	IkeLifetime       int
+	EspLifetime       int
+	DPDDelay          int
+	DPDTimeout        int
+}
+
+type ipsecCustomerBgp struct {
+	AlkiraAsn string
+	AuthKey   string
+}
+
+// ipsecCustomerBgpGroup is a BGP process of the customer, the tunnels
+// of the endpoints sharing the same customer ASN.
+type ipsecCustomerBgpGroup struct {
+	CustomerAsn string
+	Endpoints   []string
+	Tunnels     []*ipsecCustomerTunnel
+}
+
+// ipsecTunnelOverride holds the tunnel addresses supplied in the data
+// source for values the API doesn't return.
+type ipsecTunnelOverride struct {
+	Endpoint          string
+	TunnelNo          int
+	AlkiraPublicIp    string
+	AlkiraOverlayIp   string
+	CustomerOverlayIp string
+}
+
+// ipsecCustomerConfigFromConnector builds the model of an
+// alkira_connector_ipsec. Every endpoint has one tunnel per pre-shared
+// key. When endpoint is set, only that endpoint is rendered.
+func ipsecCustomerConfigFromConnector(connector *alkira.ConnectorIPSec, endpoint string) (*ipsecCustomerConfig, error) {
+	if connector.VpnMode == "POLICY_BASED" {
+		return nil, fmt.Errorf("connector %s uses POLICY_BASED vpn_mode, only ROUTE_BASED connectors are supported", connector.Name)
+	}
+
+	cfg := &ipsecCustomerConfig{
+		ConnectorName: connector.Name,
+		VpnMode:       connector.VpnMode,
+	}
+
+	var customerAsn string
+	if connector.RoutingOptions != nil && connector.RoutingOptions.DynamicRouting != nil {
+		customerAsn = connector.RoutingOptions.DynamicRouting.CustomerGwAsn
+		cfg.Bgp = &ipsecCustomerBgp{
+			AuthKey: connector.RoutingOptions.DynamicRouting.BgpAuthKeyAlkira,
+		}
+	}
+
+	for _, site := range connector.Sites {
+		if endpoint != "" && site.Name != endpoint {
+			continue
+		}
+
+		for i, key := range site.PresharedKeys {
+			tunnel := newIpsecCustomerTunnel(site.Name, i+1, site.CustomerGwIp, key)
+
+			if adv := site.Advanced; adv != nil {
+				tunnel.applyAdvanced(adv.IkeVersion,
+					adv.IkeEncryptionAlgorithms, adv.IkeIntegrityAlgorithms, adv.IkeDHGroupNumbers,
+					adv.EspEncryptionAlgorithms, adv.EspIntegrityAlgorithms, adv.EspDHGroupNumbers,
+					adv.IkeRekeyTime, adv.EspRekeyTime, adv.DPDDelay, adv.DPDTimeout)
+			}
+
+			// An endpoint may peer with its own ASN
+			if cfg.Bgp != nil {
+				tunnel.CustomerAsn = customerAsn
+				if site.CustomerGwAsn != "" {
+					tunnel.CustomerAsn = site.CustomerGwAsn
+				}
+			}
+
+			cfg.addTunnel(tunnel)
+		}
+	}
+
+	if len(cfg.Tunnels) == 0 {
+		return nil, fmt.Errorf("no tunnel found for endpoint %q of connector %s", endpoint, connector.Name)
+	}
+
+	return cfg, nil
+}
+
+// ipsecCustomerConfigFromAdvConnector builds the model of an
+// alkira_connector_ipsec_adv. Algorithms of tunnels using an
+// alkira_connector_ipsec_tunnel_profile are resolved with getProfile
+// and the CXP addresses with getReservation.
+func ipsecCustomerConfigFromAdvConnector(
+	connector *alkira.ConnectorAdvIPSec,
+	gateway string,
+	getProfile func(id int) (*alkira.ConnectorIPSecTunnelProfile, error),
+	getReservation func(id string) (*alkira.IPReservation, error),
+) (*ipsecCustomerConfig, error) {
+	if connector.VpnMode == "POLICY_BASED" {
+		return nil, fmt.Errorf("connector %s uses POLICY_BASED vpn_mode, only ROUTE_BASED connectors are supported", connector.Name)
+	}
+
+	cfg := &ipsecCustomerConfig{
+		ConnectorName: connector.Name,
+		VpnMode:       connector.VpnMode,
+	}
+
+	var customerAsn string
+	if connector.RoutingOptions != nil && connector.RoutingOptions.DynamicRouting != nil {
+		customerAsn = connector.RoutingOptions.DynamicRouting.CustomerGwAsn
+		cfg.Bgp = &ipsecCustomerBgp{
+			AuthKey: connector.RoutingOptions.DynamicRouting.BgpAuthKeyAlkira,
+		}
+	}
+
+	for _, gw := range connector.Gateways {
+		if gateway != "" && gw.Name != gateway {
+			continue
+		}
+
+		for _, t := range gw.Tunnels {
+			tunnel := newIpsecCustomerTunnel(gw.Name, t.TunnelNo, gw.CustomerGwIp, t.PresharedKey)
+			tunnel.CustomerAsn = customerAsn
+
+			if t.ProfileId != 0 {
+				profile, err := getProfile(t.ProfileId)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get tunnel profile %d: %w", t.ProfileId, err)
+				}
+				tunnel.applyAdvanced("",
+					[]string{profile.IkeConfiguration.EncryptionAlgorithm},
+					[]string{profile.IkeConfiguration.IntegrityAlgorithm},
+					[]string{profile.IkeConfiguration.DhGroup},
+					[]string{profile.IpSecConfiguration.EncryptionAlgorithm},
+					[]string{profile.IpSecConfiguration.IntegrityAlgorithm},
+					[]string{profile.IpSecConfiguration.DhGroup},
+					0, 0, 0, 0)
+			}
+
+			if adv := t.Advanced; adv != nil {
+				tunnel.applyAdvanced(adv.IkeVersion,
+					adv.IkeEncryptionAlgorithms, adv.IkeIntegrityAlgorithms, adv.IkeDHGroupNumbers,
+					adv.EspEncryptionAlgorithms, adv.EspIntegrityAlgorithms, adv.EspDHGroupNumbers,
+					adv.IkeRekeyTime, adv.EspRekeyTime, adv.DPDDelay, adv.DPDTimeout)
+			}
+
+			if id := t.CxpEnd.PublicIpReservationId; id != "" {
+				reservation, err := getReservation(id)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get public IP reservation %s: %w", id, err)
+				}
+				tunnel.AlkiraPublicIp = reservation.Prefix
+			}
+
+			if id := t.CxpEnd.OverlayIpReservationId; id != "" {
+				reservation, err := getReservation(id)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get overlay IP reservation %s: %w", id, err)
+				}
+				if err := tunnel.applyOverlayReservation(reservation); err != nil {
+					return nil, err
+				}
+			}
+
+			if t.CustomerEnd.OverlayIp != "" {
+				tunnel.CustomerOverlayIp = t.CustomerEnd.OverlayIp
+			}
+
+			cfg.addTunnel(tunnel)
+		}
+	}
+
+	if len(cfg.Tunnels) == 0 {
+		return nil, fmt.Errorf("no tunnel found for gateway %q of connector %s", gateway, connector.Name)
+	}
+
+	return cfg, nil
+}
+
+func newIpsecCustomerTunnel(endpoint string, tunnelNo int, customerGatewayIp string, presharedKey string) *ipsecCustomerTunnel {
+	return &ipsecCustomerTunnel{
+		Endpoint:          endpoint,
+		TunnelNo:          tunnelNo,
+		CustomerGatewayIp: customerGatewayIp,
+		PresharedKey:      presharedKey,
+		OverlayPrefixLen:  ipsecDefaultOverlayLength,
+		IkeVersion:        ipsecDefaultIkeVersion,
+		IkeEncryption:     ipsecDefaultEncryption,
+		IkeIntegrity:      ipsecDefaultIntegrity,
+		IkeDHGroups:       ipsecDefaultDHGroups,
+		EspEncryption:     ipsecDefaultEncryption,
+		EspIntegrity:      ipsecDefaultIntegrity,
+		EspDHGroups:       ipsecDefaultDHGroups,
+		IkeLifetime:       ipsecDefaultIkeLifetime,
+		EspLifetime:       ipsecDefaultEspLifetime,
+		DPDDelay:          ipsecDefaultDPDDelay,
+		DPDTimeout:        ipsecDefaultDPDTimeout,
+	}
+}
+
+// applyAdvanced overrides the defaults of the tunnel with every
+// non-empty value.
+func (t *ipsecCustomerTunnel) applyAdvanced(ikeVersion string, ikeEnc, ikeInt, ikeDH, espEnc, espInt, espDH []string, ikeLifetime, espLifetime, dpdDelay, dpdTimeout int) {
+	if ikeVersion != "" {
+		t.IkeVersion = ikeVersion
+	}
+
+	setList := func(dst *[]string, v []string) {
+		var values []string
+		for _, s := range v {
+			if s != "" {
+				values = append(values, s)
+			}
+		}
+		if len(values) > 0 {
+			*dst = values
+		}
+	}
+
+	setList(&t.IkeEncryption, ikeEnc)
+	setList(&t.IkeIntegrity, ikeInt)
+	setList(&t.IkeDHGroups, ikeDH)
+	setList(&t.EspEncryption, espEnc)
+	setList(&t.EspIntegrity, espInt)
+	setList(&t.EspDHGroups, espDH)
+
+	setInt := func(dst *int, v int) {
+		if v > 0 {
+			*dst = v
+		}
+	}
+
+	setInt(&t.IkeLifetime, ikeLifetime)
+	setInt(&t.EspLifetime, espLifetime)
+	setInt(&t.DPDDelay, dpdDelay)
+	setInt(&t.DPDTimeout, dpdTimeout)
+}
+
+// applyOverlayReservation sets both overlay addresses from the overlay
+// reservation of the tunnel. The first host address goes to the side
+// in FirstIpAssignedTo (the CXP unless it says CUSTOMER), the second
+// one to the other side.
+func (t *ipsecCustomerTunnel) applyOverlayReservation(r *alkira.IPReservation) error {
+	ip := net.ParseIP(r.Prefix).To4()
+	if ip == nil {
+		return fmt.Errorf("invalid overlay IP reservation prefix %q", r.Prefix)
+	}
+
+	first := make(net.IP, len(ip))
+	copy(first, ip)
+	first[3]++
+
+	second := make(net.IP, len(ip))
+	copy(second, first)
+	second[3]++
+
+	t.AlkiraOverlayIp, t.CustomerOverlayIp = first.String(), second.String()
+	if strings.EqualFold(r.FirstIpAssignedTo, "CUSTOMER") {
+		t.AlkiraOverlayIp, t.CustomerOverlayIp = second.String(), first.String()
+	}
+
+	if r.PrefixLen > 0 {
+		t.OverlayPrefixLen = r.PrefixLen
+	}
+
+	return nil
+}
+
+func (c *ipsecCustomerConfig) addTunnel(t *ipsecCustomerTunnel) {
+	c.Tunnels = append(c.Tunnels, t)
+	t.Index = len(c.Tunnels)
+}
+
+// BgpGroups groups the tunnels by customer ASN in the order of the
+// tunnels. The templates render a BGP process per group.
+func (c *ipsecCustomerConfig) BgpGroups() []*ipsecCustomerBgpGroup {
+	var groups []*ipsecCustomerBgpGroup
+	byAsn := make(map[string]*ipsecCustomerBgpGroup)
+
+	for _, t := range c.Tunnels {
+		group, ok := byAsn[t.CustomerAsn]
+		if !ok {
+			group = &ipsecCustomerBgpGroup{CustomerAsn: t.CustomerAsn}
+			byAsn[t.CustomerAsn] = group
+			groups = append(groups, group)
+		}
+
+		if n := len(group.Endpoints); n == 0 || group.Endpoints[n-1] != t.Endpoint {
+			group.Endpoints = append(group.Endpoints, t.Endpoint)
+		}
+		group.Tunnels = append(group.Tunnels, t)
+	}
+
+	return groups
+}
+
+// applyOverrides applies the addresses configured in the data source
+// and fills whatever is still unknown with placeholders, so that the
+// output makes it obvious what has to be completed by hand.
+func (c *ipsecCustomerConfig) applyOverrides(overrides []ipsecTunnelOverride) {
+	for _, t := range c.Tunnels {
+		for _, o := range overrides {
+			if o.Endpoint != t.Endpoint || o.TunnelNo != t.TunnelNo {
+				continue
+			}
+			if o.AlkiraPublicIp != "" {
+				t.AlkiraPublicIp = o.AlkiraPublicIp
+			}
+			if o.AlkiraOverlayIp != "" {
+				t.AlkiraOverlayIp, t.OverlayPrefixLen = splitOverlayCidr(o.AlkiraOverlayIp, t.OverlayPrefixLen)
+			}
+			if o.CustomerOverlayIp != "" {
+				t.CustomerOverlayIp, t.OverlayPrefixLen = splitOverlayCidr(o.CustomerOverlayIp, t.OverlayPrefixLen)
+			}
+		}
+
+		if t.AlkiraPublicIp == "" {
+			t.AlkiraPublicIp = fmt.Sprintf("<ALKIRA_PUBLIC_IP_%d>", t.Index)
+		}
+		if t.AlkiraOverlayIp == "" {
+			t.AlkiraOverlayIp = fmt.Sprintf("<ALKIRA_OVERLAY_IP_%d>", t.Index)
+		}
+		if t.CustomerOverlayIp == "" {
+			t.CustomerOverlayIp = fmt.Sprintf("<CUSTOMER_OVERLAY_IP_%d>", t.Index)
+		}
+		if t.CustomerGatewayIp == "" || t.CustomerGatewayIp == "0.0.0.0" {
+			t.CustomerGatewayIp = "<CUSTOMER_GATEWAY_IP>"
+		}
+	}
+
+	if c.WanInterface == "" {
+		c.WanInterface = "<WAN_INTERFACE>"
+	}
+
+	if c.Bgp != nil && c.Bgp.AlkiraAsn == "" {
+		c.Bgp.AlkiraAsn = "<ALKIRA_ASN>"
+	}
+}
+
+// splitOverlayCidr splits "169.254.0.1/30" into address and prefix
+// length. Without a prefix length the given default is kept.
+func splitOverlayCidr(v string, defaultLen int) (string, int) {
+	addr, length, found := strings.Cut(v, "/")
+	if !found {
+		return v, defaultLen
+	}
+
+	n, err := strconv.Atoi(length)
+	if err != nil {
+		return addr, defaultLen
+	}
+
+	return addr, n
+}
+
+// ipsecAlgorithmNames maps the algorithm names of the API to the names
+// used by each vendor, per kind of algorithm.
+var ipsecAlgorithmNames = map[string]map[string]map[string]string{
+	"ike_encryption": {
+		"AES256CBC":   {ipsecVendorCiscoIosXe: "aes-cbc-256", ipsecVendorJuniperSrx: "aes-256-cbc", ipsecVendorPaloAlto: "aes-256-cbc", ipsecVendorFortiGate: "aes256", ipsecVendorStrongSwan: "aes256"},
+		"AES192CBC":   {ipsecVendorCiscoIosXe: "aes-cbc-192", ipsecVendorJuniperSrx: "aes-192-cbc", ipsecVendorPaloAlto: "aes-192-cbc", ipsecVendorFortiGate: "aes192", ipsecVendorStrongSwan: "aes192"},
+		"AES128CBC":   {ipsecVendorCiscoIosXe: "aes-cbc-128", ipsecVendorJuniperSrx: "aes-128-cbc", ipsecVendorPaloAlto: "aes-128-cbc", ipsecVendorFortiGate: "aes128", ipsecVendorStrongSwan: "aes128"},
+		"AES256GCM16": {ipsecVendorCiscoIosXe: "aes-gcm-256", ipsecVendorJuniperSrx: "aes-256-gcm", ipsecVendorPaloAlto: "aes-256-gcm", ipsecVendorFortiGate: "aes256gcm", ipsecVendorStrongSwan: "aes256gcm16"},
+		"3DESCBC":     {ipsecVendorCiscoIosXe: "3des", ipsecVendorJuniperSrx: "3des-cbc", ipsecVendorPaloAlto: "3des", ipsecVendorFortiGate: "3des", ipsecVendorStrongSwan: "3des"},
+	},
+	"esp_encryption": {
+		"AES256CBC":   {ipsecVendorCiscoIosXe: "esp-aes 256", ipsecVendorJuniperSrx: "aes-256-cbc", ipsecVendorPaloAlto: "aes-256-cbc", ipsecVendorFortiGate: "aes256", ipsecVendorStrongSwan: "aes256"},
+		"AES192CBC":   {ipsecVendorCiscoIosXe: "esp-aes 192", ipsecVendorJuniperSrx: "aes-192-cbc", ipsecVendorPaloAlto: "aes-192-cbc", ipsecVendorFortiGate: "aes192", ipsecVendorStrongSwan: "aes192"},
+		"AES128CBC":   {ipsecVendorCiscoIosXe: "esp-aes 128", ipsecVendorJuniperSrx: "aes-128-cbc", ipsecVendorPaloAlto: "aes-128-cbc", ipsecVendorFortiGate: "aes128", ipsecVendorStrongSwan: "aes128"},
+		"AES256GCM16": {ipsecVendorCiscoIosXe: "esp-gcm 256", ipsecVendorJuniperSrx: "aes-256-gcm", ipsecVendorPaloAlto: "aes-256-gcm", ipsecVendorFortiGate: "aes256gcm", ipsecVendorStrongSwan: "aes256gcm16"},
+		"3DESCBC":     {ipsecVendorCiscoIosXe: "esp-3des", ipsecVendorJuniperSrx: "3des-cbc", ipsecVendorPaloAlto: "3des", ipsecVendorFortiGate: "3des", ipsecVendorStrongSwan: "3des"},
+	},
+	"ike_integrity": {
+		"SHA1":   {ipsecVendorCiscoIosXe: "sha1", ipsecVendorJuniperSrx: "sha1", ipsecVendorPaloAlto: "sha1", ipsecVendorFortiGate: "sha1", ipsecVendorStrongSwan: "sha1"},
+		"SHA256": {ipsecVendorCiscoIosXe: "sha256", ipsecVendorJuniperSrx: "sha-256", ipsecVendorPaloAlto: "sha256", ipsecVendorFortiGate: "sha256", ipsecVendorStrongSwan: "sha256"},
+		"SHA384": {ipsecVendorCiscoIosXe: "sha384", ipsecVendorJuniperSrx: "sha-384", ipsecVendorPaloAlto: "sha384", ipsecVendorFortiGate: "sha384", ipsecVendorStrongSwan: "sha384"},
+		"SHA512": {ipsecVendorCiscoIosXe: "sha512", ipsecVendorJuniperSrx: "sha-512", ipsecVendorPaloAlto: "sha512", ipsecVendorFortiGate: "sha512", ipsecVendorStrongSwan: "sha512"},
+		"MD5":    {ipsecVendorCiscoIosXe: "md5", ipsecVendorJuniperSrx: "md5", ipsecVendorPaloAlto: "md5", ipsecVendorFortiGate: "md5", ipsecVendorStrongSwan: "md5"},
+	},
+	"esp_integrity": {
+		"SHA1":   {ipsecVendorCiscoIosXe: "esp-sha-hmac", ipsecVendorJuniperSrx: "hmac-sha1-96", ipsecVendorPaloAlto: "sha1", ipsecVendorFortiGate: "sha1", ipsecVendorStrongSwan: "sha1"},
+		"SHA256": {ipsecVendorCiscoIosXe: "esp-sha256-hmac", ipsecVendorJuniperSrx: "hmac-sha-256-128", ipsecVendorPaloAlto: "sha256", ipsecVendorFortiGate: "sha256", ipsecVendorStrongSwan: "sha256"},
+		"SHA384": {ipsecVendorCiscoIosXe: "esp-sha384-hmac", ipsecVendorJuniperSrx: "hmac-sha-384", ipsecVendorPaloAlto: "sha384", ipsecVendorFortiGate: "sha384", ipsecVendorStrongSwan: "sha384"},
+		"SHA512": {ipsecVendorCiscoIosXe: "esp-sha512-hmac", ipsecVendorJuniperSrx: "hmac-sha-512", ipsecVendorPaloAlto: "sha512", ipsecVendorFortiGate: "sha512", ipsecVendorStrongSwan: "sha512"},
+		"MD5":    {ipsecVendorCiscoIosXe: "esp-md5-hmac", ipsecVendorJuniperSrx: "hmac-md5-96", ipsecVendorPaloAlto: "md5", ipsecVendorFortiGate: "md5", ipsecVendorStrongSwan: "md5"},
+	},
+	"dh_group": {
+		"MODP1024":   {ipsecVendorCiscoIosXe: "2", ipsecVendorJuniperSrx: "group2", ipsecVendorPaloAlto: "group2", ipsecVendorFortiGate: "2", ipsecVendorStrongSwan: "modp1024"},
+		"MODP2048":   {ipsecVendorCiscoIosXe: "14", ipsecVendorJuniperSrx: "group14", ipsecVendorPaloAlto: "group14", ipsecVendorFortiGate: "14", ipsecVendorStrongSwan: "modp2048"},
+		"MODP3072":   {ipsecVendorCiscoIosXe: "15", ipsecVendorJuniperSrx: "group15", ipsecVendorPaloAlto: "group15", ipsecVendorFortiGate: "15", ipsecVendorStrongSwan: "modp3072"},
+		"MODP4096":   {ipsecVendorCiscoIosXe: "16", ipsecVendorJuniperSrx: "group16", ipsecVendorPaloAlto: "group16", ipsecVendorFortiGate: "16", ipsecVendorStrongSwan: "modp4096"},
+		"MODP6144":   {ipsecVendorCiscoIosXe: "17", ipsecVendorJuniperSrx: "group17", ipsecVendorPaloAlto: "group17", ipsecVendorFortiGate: "17", ipsecVendorStrongSwan: "modp6144"},
+		"MODP8192":   {ipsecVendorCiscoIosXe: "18", ipsecVendorJuniperSrx: "group18", ipsecVendorPaloAlto: "group18", ipsecVendorFortiGate: "18", ipsecVendorStrongSwan: "modp8192"},
+		"ECP256":     {ipsecVendorCiscoIosXe: "19", ipsecVendorJuniperSrx: "group19", ipsecVendorPaloAlto: "group19", ipsecVendorFortiGate: "19", ipsecVendorStrongSwan: "ecp256"},
+		"ECP384":     {ipsecVendorCiscoIosXe: "20", ipsecVendorJuniperSrx: "group20", ipsecVendorPaloAlto: "group20", ipsecVendorFortiGate: "20", ipsecVendorStrongSwan: "ecp384"},
+		"ECP521":     {ipsecVendorCiscoIosXe: "21", ipsecVendorJuniperSrx: "group21", ipsecVendorPaloAlto: "group21", ipsecVendorFortiGate: "21", ipsecVendorStrongSwan: "ecp521"},
+		"CURVE25519": {ipsecVendorCiscoIosXe: "31", ipsecVendorJuniperSrx: "group31", ipsecVendorPaloAlto: "group31", ipsecVendorFortiGate: "31", ipsecVendorStrongSwan: "curve25519"},
+	},
+}
+
+// ipsecAlgorithms translates algorithm names of the API into vendor
+// names. "NONE" DH groups (no PFS) are dropped.
+func ipsecAlgorithms(vendor string, kind string, values []string) ([]string, error) {
+	var names []string
+
+	for _, v := range values {
+		if kind == "dh_group" && strings.EqualFold(v, "NONE") {
+			continue
+		}
+
+		name, ok := ipsecAlgorithmNames[kind][strings.ToUpper(v)][vendor]
+		if !ok {
+			return nil, fmt.Errorf("%s algorithm %q is not supported by the %s template", strings.ReplaceAll(kind, "_", " "), v, vendor)
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// ipsecCombinedProposals returns the proposals of vendors that combine
+// all algorithms in one string: FortiGate ("aes256-sha256 aes128-sha1")
+// and strongSwan ("aes256-sha256-modp2048"). phase is "ike" or "esp".
+// AEAD ciphers (GCM) carry no integrity algorithm; for IKE they use
+// the integrity algorithm as PRF instead.
+func ipsecCombinedProposals(vendor string, phase string, enc, integ, dh []string) (string, error) {
+	encNames, err := ipsecAlgorithms(vendor, phase+"_encryption", enc)
+	if err != nil {
+		return "", err
+	}
+	integNames, err := ipsecAlgorithms(vendor, phase+"_integrity", integ)
+	if err != nil {
+		return "", err
+	}
+	dhNames, err := ipsecAlgorithms(vendor, "dh_group", dh)
+	if err != nil {
+		return "", err
+	}
+
+	var aead, normal []string
+	for i, e := range enc {
+		if strings.Contains(strings.ToUpper(e), "GCM") {
+			aead = append(aead, encNames[i])
+		} else {
+			normal = append(normal, encNames[i])
+		}
+	}
+
+	prf := func(names []string) []string {
+		var out []string
+		for _, n := range names {
+			out = append(out, "prf"+n)
+		}
+		return out
+	}
+
+	switch vendor {
+	case ipsecVendorFortiGate:
+		var proposals []string
+		for _, e := range normal {
+			for _, i := range integNames {
+				proposals = append(proposals, e+"-"+i)
+			}
+		}
+		for _, e := range aead {
+			if phase == "ike" {
+				for _, p := range prf(integNames) {
+					proposals = append(proposals, e+"-"+p)
+				}
+			} else {
+				proposals = append(proposals, e)
+			}
+		}
+		return strings.Join(proposals, " "), nil
+
+	case ipsecVendorStrongSwan:
+		var proposals []string
+		if len(normal) > 0 {
+			proposals = append(proposals, strings.Join(append(append(normal, integNames...), dhNames...), "-"))
+		}
+		if len(aead) > 0 {
+			parts := aead
+			if phase == "ike" {
+				parts = append(parts, prf(integNames)...)
+			}
+			proposals = append(proposals, strings.Join(append(parts, dhNames...), "-"))
+		}
+		return strings.Join(proposals, ","), nil
+	}
+
+	return "", fmt.Errorf("combined proposals are not used by %s", vendor)
+}
+
+// prefixLenToNetmask returns the dotted netmask of an IPv4 prefix
+// length, e.g. 255.255.255.252 for 30.
+func prefixLenToNetmask(length int) string {
+	return net.IP(net.CIDRMask(length, 32)).String()
+}
+
+// renderIpsecCustomerConfig renders the configuration of the vendor.
+func renderIpsecCustomerConfig(vendor string, cfg *ipsecCustomerConfig) (string, error) {
+	funcs := template.FuncMap{
+		"alg": func(kind string, values []string) ([]string, error) {
+			return ipsecAlgorithms(vendor, kind, values)
+		},
+		"join":    strings.Join,
+		"netmask": prefixLenToNetmask,
+		"lower":   strings.ToLower,
+		"add": func(a, b int) int {
+			return a + b
+		},
+		"div": func(a, b int) int {
+			if b == 0 {
+				return 0
+			}
+			return a / b
+		},
+		"first": func(values []string) string {
+			if len(values) == 0 {
+				return ""
+			}
+			return values[0]
+		},
+		"gcm": func(values []string) bool {
+			for _, v := range values {
+				if strings.Contains(strings.ToUpper(v), "GCM") {
+					return true
+				}
+			}
+			return false
+		},
+		"ikev1": func(version string) bool {
+			return strings.EqualFold(version, "IKEv1")
+		},
+		"cidrAddr": func(cidr string) (string, error) {
+			_, n, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return "", err
+			}
+			return n.IP.String(), nil
+		},
+		"cidrMask": func(cidr string) (string, error) {
+			_, n, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return "", err
+			}
+			return net.IP(n.Mask).String(), nil
+		},
+		"proposals": func(phase string, enc, integ, dh []string) (string, error) {
+			return ipsecCombinedProposals(vendor, phase, enc, integ, dh)
+		},
+	}
+
+	name := vendor + ".tmpl"
+
+	tmpl, err := template.New(name).Funcs(funcs).ParseFS(ipsecCustomerConfigTemplates, "ipsec_templates/"+name)
+	if err != nil {
+		return "", fmt.Errorf("unsupported vendor %q: %w", vendor, err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, cfg); err != nil {
+		return "", fmt.Errorf("failed to render %s configuration: %w", vendor, err)
+	}
+
+	return out.String(), nil
+}
@@ -0,0 +1,307 @@
+package alkira
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// assertGolden compares got with testdata/<name>.golden, or rewrites
+// the file when the tests run with -update.
+func assertGolden(t *testing.T, name string, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run the tests with -update to create the golden file")
+	assert.Equal(t, string(want), got)
+}
+
+func testIpsecConnectorBgp() *alkira.ConnectorIPSec {
+	return &alkira.ConnectorIPSec{
+		Name:    "branch-ipsec",
+		VpnMode: "ROUTE_BASED",
+		RoutingOptions: &alkira.ConnectorIPSecRoutingOptions{
+			DynamicRouting: &alkira.ConnectorIPSecDynamicRouting{
+				CustomerGwAsn:    "65310",
+				BgpAuthKeyAlkira: "bgp-secret",
+			},
+		},
+		Sites: []*alkira.ConnectorIPSecSite{
+			{
+				Name:          "branch-1",
+				CustomerGwIp:  "203.0.113.10",
+				PresharedKeys: []string{"psk-one", "psk-two"},
+			},
+			{
+				Name:          "branch-2",
+				CustomerGwIp:  "203.0.113.20",
+				PresharedKeys: []string{"psk-three"},
+			},
+		},
+	}
+}
+
+func testIpsecAdvConnectorStatic() *alkira.ConnectorAdvIPSec {
+	return &alkira.ConnectorAdvIPSec{
+		Name:    "dc-ipsec-adv",
+		VpnMode: "ROUTE_BASED",
+		RoutingOptions: &alkira.ConnectorAdvIPSecRoutingOptions{
+			StaticRouting: &alkira.ConnectorAdvIPSecStaticRouting{PrefixListId: 7},
+		},
+		Gateways: []*alkira.ConnectorAdvIPSecGateway{
+			{
+				Name:         "dc-gw",
+				CustomerGwIp: "198.51.100.5",
+				Tunnels: []*alkira.ConnectorAdvIPSecTunnel{
+					{
+						TunnelNo:     1,
+						PresharedKey: "adv-psk-1",
+						ProfileId:    3,
+						CxpEnd: alkira.ConnectorAdvIPSecTunnelCxpEnd{
+							PublicIpReservationId:  "pub-1",
+							OverlayIpReservationId: "ovl-1",
+						},
+					},
+					{
+						TunnelNo:     2,
+						PresharedKey: "adv-psk-2",
+						Advanced: &alkira.ConnectorAdvIPSecAdvanced{
+							IkeVersion:              "IKEv1",
+							IkeEncryptionAlgorithms: []string{"AES128CBC"},
+							IkeIntegrityAlgorithms:  []string{"SHA1"},
+							IkeDHGroupNumbers:       []string{"MODP2048"},
+							EspEncryptionAlgorithms: []string{"AES128CBC"},
+							EspIntegrityAlgorithms:  []string{"SHA1"},
+							EspDHGroupNumbers:       []string{"NONE"},
+							IkeRekeyTime:            86400,
+							EspRekeyTime:            1800,
+							DPDDelay:                5,
+							DPDTimeout:              20,
+						},
+						CustomerEnd: alkira.ConnectorAdvIPSecTunnelCustomerEnd{OverlayIp: "169.254.20.2"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testIpsecProfile(id int) (*alkira.ConnectorIPSecTunnelProfile, error) {
+	if id != 3 {
+		return nil, fmt.Errorf("profile %d not found", id)
+	}
+
+	return &alkira.ConnectorIPSecTunnelProfile{
+		Name: "gcm",
+		IkeConfiguration: alkira.ConnectorIPSecTunnelProfileIkeConfiguration{
+			EncryptionAlgorithm: "AES256GCM16",
+			IntegrityAlgorithm:  "SHA384",
+			DhGroup:             "ECP384",
+		},
+		IpSecConfiguration: alkira.ConnectorIPSecTunnelProfileIpSecConfiguration{
+			EncryptionAlgorithm: "AES256GCM16",
+			IntegrityAlgorithm:  "SHA384",
+			DhGroup:             "ECP384",
+		},
+	}, nil
+}
+
+func testIpReservation(id string) (*alkira.IPReservation, error) {
+	switch id {
+	case "pub-1":
+		return &alkira.IPReservation{Id: id, Prefix: "192.0.2.44", PrefixLen: 32}, nil
+	case "ovl-1":
+		return &alkira.IPReservation{Id: id, Prefix: "169.254.10.0", PrefixLen: 30, FirstIpAssignedTo: "CXP"}, nil
+	}
+
+	return nil, fmt.Errorf("reservation %s not found", id)
+}
+
+func TestRenderIpsecCustomerConfigGolden(t *testing.T) {
+	for _, vendor := range ipsecCustomerConfigVendors {
+		t.Run(vendor+"/bgp", func(t *testing.T) {
+			cfg, err := ipsecCustomerConfigFromConnector(testIpsecConnectorBgp(), "")
+			require.NoError(t, err)
+
+			cfg.WanInterface = "GigabitEthernet1"
+			cfg.Bgp.AlkiraAsn = "65001"
+			cfg.applyOverrides([]ipsecTunnelOverride{
+				{
+					Endpoint:          "branch-1",
+					TunnelNo:          1,
+					AlkiraPublicIp:    "192.0.2.10",
+					AlkiraOverlayIp:   "169.254.0.1/30",
+					CustomerOverlayIp: "169.254.0.2/30",
+				},
+			})
+
+			got, err := renderIpsecCustomerConfig(vendor, cfg)
+			require.NoError(t, err)
+			assertGolden(t, filepath.Join("ipsec_customer_config", vendor+"_bgp"), got)
+		})
+
+		t.Run(vendor+"/bgp_two_asns", func(t *testing.T) {
+			connector := testIpsecConnectorBgp()
+			connector.Sites[1].CustomerGwAsn = "65320"
+
+			cfg, err := ipsecCustomerConfigFromConnector(connector, "")
+			require.NoError(t, err)
+
+			cfg.WanInterface = "GigabitEthernet1"
+			cfg.Bgp.AlkiraAsn = "65001"
+			cfg.applyOverrides(nil)
+
+			got, err := renderIpsecCustomerConfig(vendor, cfg)
+			require.NoError(t, err)
+			assertGolden(t, filepath.Join("ipsec_customer_config", vendor+"_bgp_two_asns"), got)
+		})
+
+		t.Run(vendor+"/adv_static", func(t *testing.T) {
+			cfg, err := ipsecCustomerConfigFromAdvConnector(testIpsecAdvConnectorStatic(), "", testIpsecProfile, testIpReservation)
+			require.NoError(t, err)
+
+			cfg.WanInterface = "ethernet1/1"
+			cfg.RemotePrefixes = []string{"10.10.0.0/16", "10.20.0.0/16"}
+			cfg.applyOverrides(nil)
+
+			got, err := renderIpsecCustomerConfig(vendor, cfg)
+			require.NoError(t, err)
+			assertGolden(t, filepath.Join("ipsec_customer_config", vendor+"_adv_static"), got)
+		})
+	}
+}
+
+func TestIpsecCustomerConfigFromConnector(t *testing.T) {
+	t.Run("endpoint filter", func(t *testing.T) {
+		cfg, err := ipsecCustomerConfigFromConnector(testIpsecConnectorBgp(), "branch-2")
+		require.NoError(t, err)
+		require.Len(t, cfg.Tunnels, 1)
+		assert.Equal(t, 1, cfg.Tunnels[0].Index)
+		assert.Equal(t, "psk-three", cfg.Tunnels[0].PresharedKey)
+	})
+
+	t.Run("customer ASN of the endpoint", func(t *testing.T) {
+		connector := testIpsecConnectorBgp()
+		connector.Sites[1].CustomerGwAsn = "65320"
+
+		cfg, err := ipsecCustomerConfigFromConnector(connector, "")
+		require.NoError(t, err)
+		require.Len(t, cfg.Tunnels, 3)
+		assert.Equal(t, "65310", cfg.Tunnels[0].CustomerAsn)
+		assert.Equal(t, "65310", cfg.Tunnels[1].CustomerAsn)
+		assert.Equal(t, "65320", cfg.Tunnels[2].CustomerAsn)
+
+		groups := cfg.BgpGroups()
+		require.Len(t, groups, 2)
+		assert.Equal(t, []string{"branch-1"}, groups[0].Endpoints)
+		assert.Len(t, groups[0].Tunnels, 2)
+		assert.Equal(t, []string{"branch-2"}, groups[1].Endpoints)
+	})
+
+	t.Run("unknown endpoint", func(t *testing.T) {
+		_, err := ipsecCustomerConfigFromConnector(testIpsecConnectorBgp(), "nope")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no tunnel found")
+	})
+
+	t.Run("policy based", func(t *testing.T) {
+		connector := testIpsecConnectorBgp()
+		connector.VpnMode = "POLICY_BASED"
+
+		_, err := ipsecCustomerConfigFromConnector(connector, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "POLICY_BASED")
+	})
+}
+
+func TestIpsecCustomerConfigFromAdvConnector(t *testing.T) {
+	cfg, err := ipsecCustomerConfigFromAdvConnector(testIpsecAdvConnectorStatic(), "", testIpsecProfile, testIpReservation)
+	require.NoError(t, err)
+	require.Len(t, cfg.Tunnels, 2)
+
+	first := cfg.Tunnels[0]
+	assert.Equal(t, "192.0.2.44", first.AlkiraPublicIp)
+	assert.Equal(t, "169.254.10.1", first.AlkiraOverlayIp)
+	assert.Equal(t, "169.254.10.2", first.CustomerOverlayIp)
+	assert.Equal(t, []string{"AES256GCM16"}, first.IkeEncryption)
+
+	second := cfg.Tunnels[1]
+	assert.Equal(t, "IKEv1", second.IkeVersion)
+	assert.Equal(t, "169.254.20.2", second.CustomerOverlayIp)
+	assert.Equal(t, 1800, second.EspLifetime)
+
+	_, err = ipsecCustomerConfigFromAdvConnector(testIpsecAdvConnectorStatic(), "", testIpsecProfile,
+		func(id string) (*alkira.IPReservation, error) { return nil, fmt.Errorf("boom") })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pub-1")
+}
+
+func TestIpsecCustomerConfigPlaceholders(t *testing.T) {
+	cfg, err := ipsecCustomerConfigFromConnector(testIpsecConnectorBgp(), "branch-2")
+	require.NoError(t, err)
+	cfg.applyOverrides(nil)
+
+	tunnel := cfg.Tunnels[0]
+	assert.Equal(t, "<ALKIRA_PUBLIC_IP_1>", tunnel.AlkiraPublicIp)
+	assert.Equal(t, "<ALKIRA_OVERLAY_IP_1>", tunnel.AlkiraOverlayIp)
+	assert.Equal(t, "<CUSTOMER_OVERLAY_IP_1>", tunnel.CustomerOverlayIp)
+	assert.Equal(t, "<WAN_INTERFACE>", cfg.WanInterface)
+	assert.Equal(t, "<ALKIRA_ASN>", cfg.Bgp.AlkiraAsn)
+}
+
+func TestRenderIpsecCustomerConfigUnsupportedAlgorithm(t *testing.T) {
+	connector := testIpsecConnectorBgp()
+	connector.Sites[0].Advanced = &alkira.ConnectorIPSecSiteAdvanced{
+		IkeEncryptionAlgorithms: []string{"CHACHA20"},
+	}
+
+	cfg, err := ipsecCustomerConfigFromConnector(connector, "branch-1")
+	require.NoError(t, err)
+	cfg.applyOverrides(nil)
+
+	for _, vendor := range ipsecCustomerConfigVendors {
+		_, err := renderIpsecCustomerConfig(vendor, cfg)
+		require.Error(t, err, vendor)
+		assert.Contains(t, err.Error(), "CHACHA20")
+	}
+}
+
+func TestIpsecCombinedProposals(t *testing.T) {
+	tests := []struct {
+		vendor string
+		phase  string
+		enc    []string
+		integ  []string
+		dh     []string
+		want   string
+	}{
+		{ipsecVendorFortiGate, "ike", []string{"AES256CBC", "AES128CBC"}, []string{"SHA256"}, []string{"MODP2048"}, "aes256-sha256 aes128-sha256"},
+		{ipsecVendorFortiGate, "ike", []string{"AES256GCM16"}, []string{"SHA384"}, []string{"ECP384"}, "aes256gcm-prfsha384"},
+		{ipsecVendorFortiGate, "esp", []string{"AES256GCM16"}, []string{"SHA384"}, nil, "aes256gcm"},
+		{ipsecVendorStrongSwan, "ike", []string{"AES256CBC"}, []string{"SHA256", "SHA1"}, []string{"MODP2048"}, "aes256-sha256-sha1-modp2048"},
+		{ipsecVendorStrongSwan, "esp", []string{"AES256GCM16"}, []string{"SHA256"}, []string{"NONE"}, "aes256gcm16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := ipsecCombinedProposals(tt.vendor, tt.phase, tt.enc, tt.integ, tt.dh)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
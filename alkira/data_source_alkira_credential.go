@@ -1,43 +1,117 @@
 package alkira
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/alkiranet/alkira-client-go/alkira"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func dataSourceAlkiraCredential() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Description: "The name of the credentials. When more than one " +
+				"credential has the name, the first one is used unless " +
+				"`type` narrows the match.",
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			AtLeastOneOf: []string{"name", "name_regex", "credential_id"},
+		},
+		"name_regex": {
+			Description: "A regular expression the name of the credentials " +
+				"must match. Exactly one credential must match.",
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringIsValidRegExp,
+		},
+		"type": {
+			Description: "The type of the credentials, e.g. `awsvpc` or " +
+				"`paninstance`.",
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validation.StringInSlice(credentialTypeNames(), false),
+		},
+		"credential_id": {
+			Description: "The ID of the credentials.",
+			Type:        schema.TypeString,
+			Computed:    true,
+			Optional:    true,
+		},
+	}
+
+	for k, v := range credentialMetadataSchema() {
+		s[k] = v
+	}
+
 	return &schema.Resource{
 		Description: "Use this data source to get information on an existing credential.",
 
 		Read: dataSourceAlkiraCredentialRead,
 
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Description: "The name of the credentials.",
-				Type:        schema.TypeString,
-				Required:    true,
-			},
-			"credential_id": {
-				Description: "The ID of the credentials.",
-				Type:        schema.TypeString,
-				Computed:    true,
-				Optional:    true,
-			},
-		},
+		Schema: s,
 	}
 }
 
 func dataSourceAlkiraCredentialRead(d *schema.ResourceData, m interface{}) error {
 	client := m.(*alkira.AlkiraClient)
 
-	credential, err := client.GetCredentialByName(d.Get("name").(string))
+	filter, err := expandCredentialFilter(d)
+	if err != nil {
+		return err
+	}
 
+	credentials, err := getAllCredentialDetails(client)
 	if err != nil {
 		return err
 	}
 
-	d.SetId(credential.Id)
-	d.Set("credential_id", credential.Id)
+	// Keep the order of the API for credentials sharing a name.
+	var matches []credentialDetail
+	for _, c := range credentials {
+		if filter.match(c) {
+			matches = append(matches, c)
+		}
+	}
+
+	if len(matches) == 0 {
+		return fmt.Errorf("failed to find a credential matching %s", describeCredentialFilter(filter))
+	}
+
+	if len(matches) > 1 && filter.NameRegex != nil {
+		return fmt.Errorf("%d credentials match %s, narrow the match or use alkira_credentials",
+			len(matches), describeCredentialFilter(filter))
+	}
+
+	credential := flattenCredential(matches[0], time.Now())
+
+	d.SetId(matches[0].Id)
+	for k, v := range credential {
+		d.Set(k, v)
+	}
 
 	return nil
 }
+
+func describeCredentialFilter(f credentialFilter) string {
+	var parts []string
+
+	if f.Id != "" {
+		parts = append(parts, fmt.Sprintf("credential_id %q", f.Id))
+	}
+	if f.Name != "" {
+		parts = append(parts, fmt.Sprintf("name %q", f.Name))
+	}
+	if f.NameRegex != nil {
+		parts = append(parts, fmt.Sprintf("name_regex %q", f.NameRegex.String()))
+	}
+	if f.Type != "" {
+		parts = append(parts, fmt.Sprintf("type %q", f.Type))
+	}
+
+	return strings.Join(parts, ", ")
+}
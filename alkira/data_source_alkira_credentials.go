@@ -0,0 +1,105 @@
+package alkira
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAlkiraCredentials() *schema.Resource {
+	credential := map[string]*schema.Schema{
+		"credential_id": {
+			Description: "The ID of the credential.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"name": {
+			Description: "The name of the credential.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"type": {
+			Description: "The type of the credential.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+	}
+
+	for k, v := range credentialMetadataSchema() {
+		credential[k] = v
+	}
+
+	return &schema.Resource{
+		Description: "Use this data source to list the credentials of the " +
+			"tenant network, e.g. all credentials of a type to find the " +
+			"ones no longer used by any connector or service.",
+
+		Read: dataSourceAlkiraCredentialsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Description: "Only list the credentials whose name " +
+					"matches this regular expression.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"type": {
+				Description: "Only list the credentials of this type, " +
+					"e.g. `awsvpc` or `paninstance`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(credentialTypeNames(), false),
+			},
+			"ids": {
+				Description: "The IDs of the credentials.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"credentials": {
+				Description: "The credentials, sorted by name.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: credential},
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraCredentialsRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*alkira.AlkiraClient)
+
+	filter, err := expandCredentialFilter(d)
+	if err != nil {
+		return err
+	}
+
+	credentials, err := getAllCredentialDetails(client)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	ids := []string{}
+	list := []map[string]interface{}{}
+
+	for _, c := range filterCredentials(credentials, filter) {
+		ids = append(ids, c.Id)
+		list = append(list, flattenCredential(c, now))
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s", d.Get("name_regex"), d.Get("type"))))
+
+	d.SetId(fmt.Sprintf("%s-credentials-%s", client.TenantNetworkId, hex.EncodeToString(sum[:8])))
+	d.Set("ids", ids)
+	d.Set("credentials", list)
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package alkira
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// credentialTypes are the types of credentials the data sources filter
+// by.
+var credentialTypes = []alkira.CredentialType{
+	alkira.CredentialTypeAkamaiProlexic,
+	alkira.CredentialTypeArubaEdgeConnectInstance,
+	alkira.CredentialTypeAwsVpc,
+	alkira.CredentialTypeAzureVnet,
+	alkira.CredentialTypeChkpFw,
+	alkira.CredentialTypeChkpFwInstance,
+	alkira.CredentialTypeChkpFwManagement,
+	alkira.CredentialTypeCiscoFtdv,
+	alkira.CredentialTypeCiscoFtdvInstance,
+	alkira.CredentialTypeCiscoSdwan,
+	alkira.CredentialTypeFortinet,
+	alkira.CredentialTypeFortinetInstance,
+	alkira.CredentialTypeFortinetSdwanInstance,
+	alkira.CredentialTypeGcpVpc,
+	alkira.CredentialTypeInfoblox,
+	alkira.CredentialTypeInfobloxGridMaster,
+	alkira.CredentialTypeInfobloxInstance,
+	alkira.CredentialTypeKeyPair,
+	alkira.CredentialTypeLdap,
+	alkira.CredentialTypeOciVcn,
+	alkira.CredentialTypePan,
+	alkira.CredentialTypePanInstance,
+	alkira.CredentialTypePanMasterKey,
+	alkira.CredentialTypePanRegistration,
+	alkira.CredentialTypeVmwareSdwanInstance,
+	alkira.CredentialTypeF5Instance,
+	alkira.CredentialTypeF5InstanceRegistration,
+	alkira.CredentialTypeApiKey,
+	alkira.CredentialTypeBluecatBDDSInstanceLicense,
+	alkira.CredentialTypeBluecatEdgeInstance,
+}
+
+func credentialTypeNames() []string {
+	names := make([]string, len(credentialTypes))
+	for i, t := range credentialTypes {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// credentialDetail is a credential returned by the API with its
+// metadata.
+type credentialDetail struct {
+	alkira.CredentialResponseDetail
+	Expires   json.Number `json:"expires"`
+	CreatedAt json.Number `json:"createdAt"`
+}
+
+func getAllCredentialDetails(client *alkira.AlkiraClient) ([]credentialDetail, error) {
+	data, err := client.GetCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials []credentialDetail
+	if err := json.Unmarshal([]byte(data), &credentials); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// credentialFilter selects credentials by ID, name, name regex and
+// type. Empty fields match any credential.
+type credentialFilter struct {
+	Id        string
+	Name      string
+	NameRegex *regexp.Regexp
+	Type      string
+}
+
+func expandCredentialFilter(d *schema.ResourceData) (credentialFilter, error) {
+	filter := credentialFilter{
+		Type: d.Get("type").(string),
+	}
+
+	if v, ok := d.GetOk("credential_id"); ok {
+		filter.Id = v.(string)
+	}
+	if v, ok := d.GetOk("name"); ok {
+		filter.Name = v.(string)
+	}
+	if v, ok := d.GetOk("name_regex"); ok {
+		re, err := regexp.Compile(v.(string))
+		if err != nil {
+			return filter, fmt.Errorf("invalid name_regex: %w", err)
+		}
+		filter.NameRegex = re
+	}
+
+	return filter, nil
+}
+
+func (f credentialFilter) match(c credentialDetail) bool {
+	return (f.Id == "" || c.Id == f.Id) &&
+		(f.Name == "" || c.Name == f.Name) &&
+		(f.NameRegex == nil || f.NameRegex.MatchString(c.Name)) &&
+		(f.Type == "" || c.Type == f.Type)
+}
+
+// filterCredentials returns the matching credentials sorted by name.
+func filterCredentials(credentials []credentialDetail, filter credentialFilter) []credentialDetail {
+	var matches []credentialDetail
+
+	for _, c := range credentials {
+		if filter.match(c) {
+			matches = append(matches, c)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Name < matches[j].Name
+	})
+
+	return matches
+}
+
+// flattenCredential returns the metadata of a credential. The dates
+// are empty when the API doesn't return them.
+func flattenCredential(c credentialDetail, now time.Time) map[string]interface{} {
+	credential := map[string]interface{}{
+		"credential_id":  c.Id,
+		"name":           c.Name,
+		"type":           c.Type,
+		"sub_type":       c.SubType,
+		"expiry_date":    "",
+		"days_remaining": 0,
+		"created_at":     "",
+	}
+
+	if expiry, ok := epochTime(c.Expires); ok {
+		date := expiry.Format(expiryDateLayout)
+		credential["expiry_date"] = date
+		credential["days_remaining"], _ = daysRemaining(date, now)
+	}
+
+	if created, ok := epochTime(c.CreatedAt); ok {
+		credential["created_at"] = created.Format(time.RFC3339)
+	}
+
+	return credential
+}
+
+// credentialMetadataSchema is the metadata of a credential in the data
+// sources.
+func credentialMetadataSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"sub_type": {
+			Description: "The sub type of the credential.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"expiry_date": {
+			Description: "The expiry date of the credential in the " +
+				"format `YYYY-MM-DD`, empty when it doesn't expire.",
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"days_remaining": {
+			Description: "The number of days until `expiry_date`, " +
+				"negative once it has passed.",
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"created_at": {
+			Description: "The creation time of the credential in RFC 3339 " +
+				"format, when known.",
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+}
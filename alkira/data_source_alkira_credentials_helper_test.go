@@ -0,0 +1,173 @@
+package alkira
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCredentialDetails() []credentialDetail {
+	detail := func(id, name string, t alkira.CredentialType, expires, createdAt string) credentialDetail {
+		return credentialDetail{
+			CredentialResponseDetail: alkira.CredentialResponseDetail{Id: id, Name: name, Type: string(t)},
+			Expires:                  json.Number(expires),
+			CreatedAt:                json.Number(createdAt),
+		}
+	}
+
+	return []credentialDetail{
+		detail("3", "prod-aws", alkira.CredentialTypeAwsVpc, "", "1760000000"),
+		detail("1", "dev-aws", alkira.CredentialTypeAwsVpc, "", ""),
+		detail("2", "prod-pan", alkira.CredentialTypePanInstance, "1792800000000", ""),
+		detail("4", "dev-aws", alkira.CredentialTypeGcpVpc, "", ""),
+	}
+}
+
+func TestFilterCredentials(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter credentialFilter
+		want   []string
+	}{
+		{
+			name: "all sorted by name",
+			want: []string{"1", "4", "3", "2"},
+		},
+		{
+			name:   "type",
+			filter: credentialFilter{Type: string(alkira.CredentialTypeAwsVpc)},
+			want:   []string{"1", "3"},
+		},
+		{
+			name:   "name regex",
+			filter: credentialFilter{NameRegex: regexp.MustCompile("^prod-")},
+			want:   []string{"3", "2"},
+		},
+		{
+			name:   "name and type",
+			filter: credentialFilter{Name: "dev-aws", Type: string(alkira.CredentialTypeGcpVpc)},
+			want:   []string{"4"},
+		},
+		{
+			name:   "id",
+			filter: credentialFilter{Id: "2"},
+			want:   []string{"2"},
+		},
+		{
+			name:   "no match",
+			filter: credentialFilter{Name: "missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range filterCredentials(testCredentialDetails(), tt.filter) {
+				got = append(got, c.Id)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFlattenCredential(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	credentials := testCredentialDetails()
+
+	aws := flattenCredential(credentials[0], now)
+	assert.Equal(t, "prod-aws", aws["name"])
+	assert.Equal(t, "awsvpc", aws["type"])
+	assert.Equal(t, "", aws["expiry_date"])
+	assert.Equal(t, 0, aws["days_remaining"])
+	assert.Equal(t, "2025-10-09T08:53:20Z", aws["created_at"])
+
+	pan := flattenCredential(credentials[2], now)
+	assert.Equal(t, "2026-10-24", pan["expiry_date"])
+	assert.Equal(t, 5, pan["days_remaining"])
+	assert.Equal(t, "", pan["created_at"])
+}
+
+func TestDataSourceAlkiraCredentialRead(t *testing.T) {
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/credentials/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(testCredentialDetails())
+	})
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantId  string
+		wantErr string
+	}{
+		{
+			name:   "first credential with the name",
+			config: map[string]interface{}{"name": "dev-aws"},
+			wantId: "1",
+		},
+		{
+			name:   "name narrowed by type",
+			config: map[string]interface{}{"name": "dev-aws", "type": "gcpvpc"},
+			wantId: "4",
+		},
+		{
+			name:   "single regex match",
+			config: map[string]interface{}{"name_regex": "pan$"},
+			wantId: "2",
+		},
+		{
+			name:    "ambiguous regex",
+			config:  map[string]interface{}{"name_regex": "^prod-"},
+			wantErr: `2 credentials match name_regex "^prod-", narrow the match or use alkira_credentials`,
+		},
+		{
+			name:    "no match",
+			config:  map[string]interface{}{"name": "prod-aws", "type": "gcpvpc"},
+			wantErr: `failed to find a credential matching name "prod-aws", type "gcpvpc"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceAlkiraCredential().Schema, tt.config)
+
+			err := dataSourceAlkiraCredentialRead(d, client)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantId, d.Id())
+			assert.Equal(t, tt.wantId, d.Get("credential_id"))
+		})
+	}
+}
+
+func TestDataSourceAlkiraCredentialsRead(t *testing.T) {
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(testCredentialDetails())
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAlkiraCredentials().Schema, map[string]interface{}{
+		"type": "awsvpc",
+	})
+
+	require.NoError(t, dataSourceAlkiraCredentialsRead(d, client))
+	assert.Equal(t, []interface{}{"1", "3"}, d.Get("ids"))
+	assert.Equal(t, "dev-aws", d.Get("credentials.0.name"))
+	assert.Equal(t, "prod-aws", d.Get("credentials.1.name"))
+	assert.NotEmpty(t, d.Id())
+}
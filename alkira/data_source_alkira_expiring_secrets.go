@@ -0,0 +1,139 @@
+package alkira
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAlkiraExpiringSecrets() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to list the credentials of the " +
+			"tenant network, e.g. PAN auth codes, registration PINs and " +
+			"master keys, that expire within a number of days or have " +
+			"expired.",
+
+		Read: dataSourceAlkiraExpiringSecretsRead,
+
+		Schema: map[string]*schema.Schema{
+			"within_days": {
+				Description: "List the credentials expiring within this " +
+					"number of days. The default value is the " +
+					"`expiry_warning_days` of the provider.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"include_expired": {
+				Description: "Whether to list the credentials that have " +
+					"already expired. The default value is `true`.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"secrets": {
+				Description: "The expiring credentials, the earliest " +
+					"expiry first.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"credential_id": {
+							Description: "The ID of the credential.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"name": {
+							Description: "The name of the credential.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"type": {
+							Description: "The type of the credential.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"sub_type": {
+							Description: "The sub type of the credential.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"expiry_date": {
+							Description: "The expiry date in the format " +
+								"`YYYY-MM-DD`.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"days_remaining": {
+							Description: "The number of days until " +
+								"`expiry_date`, negative once it has passed.",
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraExpiringSecretsRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*alkira.AlkiraClient)
+
+	// GetOk can't tell an explicit 0 from an unset within_days.
+	withinDays := expiryWarningDaysFor(m)
+	if config := d.GetRawConfig(); !config.IsNull() && !config.GetAttr("within_days").IsNull() {
+		withinDays = d.Get("within_days").(int)
+	}
+
+	credentials, err := getAllCredentialDetails(client)
+	if err != nil {
+		return err
+	}
+
+	secrets := expiringSecrets(credentials, time.Now(), withinDays, d.Get("include_expired").(bool))
+
+	d.SetId(fmt.Sprintf("%s-%d", client.TenantNetworkId, withinDays))
+	d.Set("secrets", secrets)
+
+	return nil
+}
+
+// expiringSecrets returns the credentials expiring within the given
+// number of days, sorted by expiry date.
+func expiringSecrets(credentials []credentialDetail, now time.Time, withinDays int, includeExpired bool) []map[string]interface{} {
+	var secrets []map[string]interface{}
+
+	for _, c := range credentials {
+		expiry, ok := epochTime(c.Expires)
+		if !ok {
+			continue
+		}
+
+		date := expiry.Format(expiryDateLayout)
+
+		days, err := daysRemaining(date, now)
+		if err != nil || days > withinDays || (days < 0 && !includeExpired) {
+			continue
+		}
+
+		secrets = append(secrets, map[string]interface{}{
+			"credential_id":  c.Id,
+			"name":           c.Name,
+			"type":           c.Type,
+			"sub_type":       c.SubType,
+			"expiry_date":    date,
+			"days_remaining": days,
+		})
+	}
+
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return secrets[i]["expiry_date"].(string) < secrets[j]["expiry_date"].(string)
+	})
+
+	return secrets
+}
@@ -0,0 +1,116 @@
+package alkira
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAlkiraIpPoolAllocation() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to split a block allocated by " +
+			"`alkira_ip_pool` into the addresses of both ends of a tunnel " +
+			"or link.\n\n" +
+			"The ends use the first two host addresses of a `/29` or " +
+			"`/30` and both addresses of a `/31`.",
+
+		Read: dataSourceAlkiraIpPoolAllocationRead,
+
+		Schema: map[string]*schema.Schema{
+			"prefix": {
+				Description: "The allocated block, e.g. " +
+					"`alkira_ip_pool.tunnels.allocated_prefixes[\"branch-1\"]`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsCIDR,
+			},
+			"first_ip_assignment": {
+				Description: "Which end gets the first address, either " +
+					"`CXP` or `CUSTOMER`. The default value is `CXP`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "CXP",
+				ValidateFunc: validation.StringInSlice([]string{"CXP", "CUSTOMER"}, false),
+			},
+			"prefix_length": {
+				Description: "The prefix length of the block.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"netmask": {
+				Description: "The netmask of the block, e.g. `255.255.255.252`.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"cxp_ip": {
+				Description: "The address of the CXP end.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"customer_ip": {
+				Description: "The address of the customer end.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"host_ips": {
+				Description: "All usable host addresses of the block.",
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraIpPoolAllocationRead(d *schema.ResourceData, m interface{}) error {
+	prefix, err := netip.ParsePrefix(d.Get("prefix").(string))
+	if err != nil {
+		return err
+	}
+
+	hosts, err := ipPoolBlockHosts(prefix)
+	if err != nil {
+		return err
+	}
+
+	cxp, customer := hosts[0], hosts[1]
+	if d.Get("first_ip_assignment").(string) == "CUSTOMER" {
+		cxp, customer = customer, cxp
+	}
+
+	mask := ^uint32(0) << (32 - prefix.Bits())
+
+	d.SetId(prefix.Masked().String())
+	d.Set("prefix_length", prefix.Bits())
+	d.Set("netmask", uint32ToIpv4(mask).String())
+	d.Set("cxp_ip", cxp)
+	d.Set("customer_ip", customer)
+	d.Set("host_ips", hosts)
+
+	return nil
+}
+
+// ipPoolBlockHosts returns the usable host addresses of a block, both
+// addresses for a /31.
+func ipPoolBlockHosts(prefix netip.Prefix) ([]string, error) {
+	if !prefix.Addr().Is4() || prefix.Bits() < 29 || prefix.Bits() > 31 {
+		return nil, fmt.Errorf("prefix %s is not an IPv4 /29, /30 or /31", prefix)
+	}
+
+	base := ipv4ToUint32(prefix.Masked().Addr())
+	size := uint32(1) << (32 - prefix.Bits())
+
+	first, last := base+1, base+size-2
+	if prefix.Bits() == 31 {
+		first, last = base, base+1
+	}
+
+	var hosts []string
+	for a := first; a <= last; a++ {
+		hosts = append(hosts, uint32ToIpv4(a).String())
+	}
+
+	return hosts, nil
+}
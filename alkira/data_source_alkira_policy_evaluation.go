@@ -0,0 +1,358 @@
+package alkira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAlkiraPolicyEvaluation() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to evaluate a flow against " +
+			"traffic policies without provisioning anything.\n\n" +
+			"Policies are evaluated in the order of `policy_ids` or, " +
+			"by default, in the order returned by the API. A policy " +
+			"applies when it is enabled and the segment, source group " +
+			"and destination group of the flow are part of it. The rules " +
+			"of its rule list are evaluated by ascending priority and the " +
+			"first matching rule wins.\n\n" +
+			"The policies are either passed in with `policy`, e.g. " +
+			"`policy = [alkira_policy.a, alkira_policy.b]`, which evaluates " +
+			"the planned policies before they are applied, or read from " +
+			"the API. Rule lists and rules passed in with `rule_list` and " +
+			"`rule` are used instead of the applied ones with the same ID.",
+
+		Read: dataSourceAlkiraPolicyEvaluationRead,
+
+		Schema: map[string]*schema.Schema{
+			"policy_ids": {
+				Description: "The IDs of the policies to evaluate in order. " +
+					"By default all policies are evaluated.",
+				Type:          schema.TypeList,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Optional:      true,
+				ConflictsWith: []string{"policy"},
+			},
+			"policy": {
+				Description: "The policies to evaluate in order, in the " +
+					"format of `alkira_policy`.",
+				Type:       schema.TypeList,
+				Optional:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
+				Elem:       policyEvaluationElem(resourceAlkiraPolicy(), false),
+			},
+			"rule_list": {
+				Description: "The rule lists of the policies in the format " +
+					"of `alkira_policy_rule_list`.",
+				Type:       schema.TypeList,
+				Optional:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
+				Elem:       policyEvaluationElem(resourceAlkiraPolicyRuleList(), true),
+			},
+			"rule": {
+				Description: "The rules of the rule lists in the format of " +
+					"`alkira_policy_rule`.",
+				Type:       schema.TypeList,
+				Optional:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
+				Elem:       policyEvaluationElem(resourceAlkiraPolicyRule(), true),
+			},
+			"segment_id": {
+				Description: "The ID of the segment of the flow.",
+				Type:        schema.TypeInt,
+				Required:    true,
+			},
+			"src_group_id": {
+				Description: "The ID of the group the source of the flow " +
+					"belongs to. Without it the source groups of the " +
+					"policies aren't checked.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				AtLeastOneOf: []string{"src_group_id", "src_ip"},
+			},
+			"dst_group_id": {
+				Description: "The ID of the group the destination of the " +
+					"flow belongs to. Without it the destination groups of " +
+					"the policies aren't checked.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				AtLeastOneOf: []string{"dst_group_id", "dst_ip"},
+			},
+			"src_ip": {
+				Description: "The source IP of the flow. Rules with a " +
+					"source IP or prefix list never match a flow without it.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPAddress,
+				AtLeastOneOf: []string{"src_group_id", "src_ip"},
+			},
+			"dst_ip": {
+				Description: "The destination IP of the flow. Rules with a " +
+					"destination IP or prefix list never match a flow " +
+					"without it.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPAddress,
+				AtLeastOneOf: []string{"dst_group_id", "dst_ip"},
+			},
+			"protocol": {
+				Description:  "The protocol of the flow, `icmp`, `tcp` or `udp`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"icmp", "tcp", "udp"}, false),
+			},
+			"src_port": {
+				Description:  "The source port of the flow.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 65535),
+			},
+			"dst_port": {
+				Description:  "The destination port of the flow.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 65535),
+			},
+			"dscp": {
+				Description:  "The DSCP value of the flow. The default value is `0`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntBetween(0, 63),
+			},
+			"application_id": {
+				Description: "The ID of the application of the flow.",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			"internet_application_id": {
+				Description: "The ID of the `internet_application` the flow " +
+					"is destined to.",
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"default_action": {
+				Description: "The action reported when no rule matches, " +
+					"either `ALLOW` or `DROP`. The default value is `DROP`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "DROP",
+				ValidateFunc: validation.StringInSlice([]string{"ALLOW", "DROP"}, false),
+			},
+			"matched": {
+				Description: "Whether a rule matched the flow.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"policy_id": {
+				Description: "The ID of the matching policy.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"policy_name": {
+				Description: "The name of the matching policy.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"rule_list_id": {
+				Description: "The ID of the rule list of the matching policy.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"rule_id": {
+				Description: "The ID of the matching rule.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"rule_name": {
+				Description: "The name of the matching rule.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"rule_priority": {
+				Description: "The priority of the matching rule in the rule list.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"action": {
+				Description: "The resulting action, `ALLOW` or `DROP`.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"service_ids": {
+				Description: "The IDs of the services the flow is steered " +
+					"to, in order.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+				Computed: true,
+			},
+			"service_types": {
+				Description: "The service types the flow is steered to, in order.",
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
+			"trace": {
+				Description: "The evaluation steps, explaining why policies " +
+					"and rules were skipped.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraPolicyEvaluationRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*alkira.AlkiraClient)
+
+	flow := policyEvaluationFlow{
+		SegmentId:             d.Get("segment_id").(int),
+		SrcGroupId:            d.Get("src_group_id").(int),
+		DstGroupId:            d.Get("dst_group_id").(int),
+		SrcIp:                 net.ParseIP(d.Get("src_ip").(string)),
+		DstIp:                 net.ParseIP(d.Get("dst_ip").(string)),
+		Protocol:              d.Get("protocol").(string),
+		SrcPort:               d.Get("src_port").(int),
+		DstPort:               d.Get("dst_port").(int),
+		Dscp:                  d.Get("dscp").(int),
+		ApplicationId:         d.Get("application_id").(int),
+		InternetApplicationId: d.Get("internet_application_id").(int),
+	}
+
+	policies := expandPolicyEvaluationPolicies(d.Get("policy").([]interface{}))
+	if len(policies) == 0 {
+		var err error
+		policies, err = getPoliciesForEvaluation(client, convertTypeListToStringList(d.Get("policy_ids").([]interface{})))
+		if err != nil {
+			return err
+		}
+	}
+
+	ruleLists, err := expandPolicyEvaluationRuleLists(d.Get("rule_list").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	rules, err := expandPolicyEvaluationRules(d.Get("rule").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	ruleListApi := alkira.NewPolicyRuleList(client)
+	ruleApi := alkira.NewTrafficPolicyRule(client)
+	prefixListApi := alkira.NewPolicyPrefixList(client)
+
+	evaluator := newPolicyEvaluator(
+		func(id int) (*alkira.PolicyRuleList, error) {
+			if ruleList, ok := ruleLists[id]; ok {
+				return ruleList, nil
+			}
+			ruleList, _, err := ruleListApi.GetById(strconv.Itoa(id))
+			return ruleList, err
+		},
+		func(id int) (*alkira.TrafficPolicyRule, error) {
+			if rule, ok := rules[id]; ok {
+				return rule, nil
+			}
+			rule, _, err := ruleApi.GetById(strconv.Itoa(id))
+			return rule, err
+		},
+		func(id int) (*alkira.PolicyPrefixList, error) {
+			prefixList, _, err := prefixListApi.GetById(strconv.Itoa(id))
+			return prefixList, err
+		},
+	)
+
+	result, err := evaluator.evaluate(policies, flow, d.Get("default_action").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(policyEvaluationId(flow))
+	d.Set("matched", result.Matched)
+	d.Set("action", result.Action)
+	d.Set("trace", result.Trace)
+
+	if result.Matched {
+		d.Set("policy_id", string(result.Policy.Id))
+		d.Set("policy_name", result.Policy.Name)
+		d.Set("rule_list_id", result.Policy.RuleListId)
+		d.Set("rule_id", result.RuleId)
+		d.Set("rule_name", result.Rule.Name)
+		d.Set("rule_priority", result.RulePriority)
+		d.Set("service_ids", result.ServiceIds)
+		d.Set("service_types", result.ServiceTypes)
+	} else {
+		d.Set("policy_id", "")
+		d.Set("policy_name", "")
+		d.Set("rule_list_id", 0)
+		d.Set("rule_id", 0)
+		d.Set("rule_name", "")
+		d.Set("rule_priority", 0)
+		d.Set("service_ids", nil)
+		d.Set("service_types", nil)
+	}
+
+	return nil
+}
+
+// getPoliciesForEvaluation returns the policies with the given IDs in
+// that order, or all policies when no ID is given.
+func getPoliciesForEvaluation(client *alkira.AlkiraClient, ids []string) ([]alkira.TrafficPolicy, error) {
+	api := alkira.NewTrafficPolicy(client)
+
+	if len(ids) == 0 {
+		data, err := api.GetAll()
+		if err != nil {
+			return nil, err
+		}
+
+		var policies []alkira.TrafficPolicy
+		if err := json.Unmarshal([]byte(data), &policies); err != nil {
+			return nil, fmt.Errorf("failed to parse policies: %w", err)
+		}
+
+		return policies, nil
+	}
+
+	policies := make([]alkira.TrafficPolicy, 0, len(ids))
+
+	for _, id := range ids {
+		policy, _, err := api.GetById(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get policy %s: %w", id, err)
+		}
+		policies = append(policies, *policy)
+	}
+
+	return policies, nil
+}
+
+// policyEvaluationId identifies the evaluated flow.
+func policyEvaluationId(flow policyEvaluationFlow) string {
+	ip := func(v net.IP) string {
+		if v == nil {
+			return "any"
+		}
+		return v.String()
+	}
+
+	return strings.Join([]string{
+		strconv.Itoa(flow.SegmentId),
+		strconv.Itoa(flow.SrcGroupId),
+		strconv.Itoa(flow.DstGroupId),
+		ip(flow.SrcIp),
+		ip(flow.DstIp),
+		flow.Protocol,
+		strconv.Itoa(flow.SrcPort),
+		strconv.Itoa(flow.DstPort),
+		strconv.Itoa(flow.Dscp),
+	}, "-")
+}
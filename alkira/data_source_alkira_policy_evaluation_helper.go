@@ -0,0 +1,497 @@
+package alkira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// policyEvaluationFlow is the flow evaluated by alkira_policy_evaluation.
+// Zero values of optional fields mean "not specified" and only match
+// wildcard conditions of a rule. Groups that aren't specified aren't
+// checked against the groups of a policy.
+type policyEvaluationFlow struct {
+	SegmentId             int
+	SrcGroupId            int
+	DstGroupId            int
+	SrcIp                 net.IP
+	DstIp                 net.IP
+	Protocol              string
+	SrcPort               int
+	DstPort               int
+	Dscp                  int
+	ApplicationId         int
+	InternetApplicationId int
+}
+
+// policyEvaluationResult is the outcome of an evaluation. Trace explains
+// why every policy and rule before the match was skipped.
+type policyEvaluationResult struct {
+	Matched      bool
+	Policy       *alkira.TrafficPolicy
+	Rule         *alkira.TrafficPolicyRule
+	RuleId       int
+	RulePriority int
+	Action       string
+	ServiceIds   []int
+	ServiceTypes []string
+	Trace        []string
+}
+
+// policyEvaluationElem returns the schema of the objects passed in to
+// alkira_policy_evaluation, the schema of the resource with its ID. Rule
+// lists and rules are referenced by ID, so it's required for them.
+func policyEvaluationElem(r *schema.Resource, idRequired bool) *schema.Resource {
+	elem := evaluationRuleElem(r.Schema)
+
+	elem.Schema["id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: !idRequired,
+		Required: idRequired,
+	}
+
+	return elem
+}
+
+// expandPolicyEvaluationPolicies expands the policies passed in to
+// alkira_policy_evaluation.
+func expandPolicyEvaluationPolicies(in []interface{}) []alkira.TrafficPolicy {
+	policies := make([]alkira.TrafficPolicy, 0, len(in))
+
+	for i, p := range in {
+		input := p.(map[string]interface{})
+
+		policy := alkira.TrafficPolicy{
+			Id:         json.Number(input["id"].(string)),
+			Name:       input["name"].(string),
+			Enabled:    input["enabled"].(bool),
+			RuleListId: input["rule_list_id"].(int),
+			FromGroups: convertTypeSetToIntList(input["from_groups"].(*schema.Set)),
+			ToGroups:   convertTypeSetToIntList(input["to_groups"].(*schema.Set)),
+			SegmentIds: convertTypeListToIntList(input["segment_ids"].([]interface{})),
+		}
+		if policy.Name == "" {
+			policy.Name = fmt.Sprintf("#%d", i+1)
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies
+}
+
+// expandPolicyEvaluationRuleLists expands the rule lists passed in to
+// alkira_policy_evaluation by ID.
+func expandPolicyEvaluationRuleLists(in []interface{}) (map[int]*alkira.PolicyRuleList, error) {
+	ruleLists := make(map[int]*alkira.PolicyRuleList, len(in))
+
+	for _, l := range in {
+		input := l.(map[string]interface{})
+
+		id, err := strconv.Atoi(input["id"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q of rule list %s", input["id"], input["name"])
+		}
+
+		ruleList := &alkira.PolicyRuleList{
+			Id:   json.Number(input["id"].(string)),
+			Name: input["name"].(string),
+		}
+		for _, r := range input["rules"].(*schema.Set).List() {
+			rule := r.(map[string]interface{})
+			ruleList.Rules = append(ruleList.Rules, alkira.PolicyRuleListRule{
+				Priority: rule["priority"].(int),
+				RuleId:   rule["rule_id"].(int),
+			})
+		}
+
+		ruleLists[id] = ruleList
+	}
+
+	return ruleLists, nil
+}
+
+// expandPolicyEvaluationRules expands the rules passed in to
+// alkira_policy_evaluation by ID.
+func expandPolicyEvaluationRules(in []interface{}) (map[int]*alkira.TrafficPolicyRule, error) {
+	rules := make(map[int]*alkira.TrafficPolicyRule, len(in))
+
+	for _, r := range in {
+		input := r.(map[string]interface{})
+
+		id, err := strconv.Atoi(input["id"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q of rule %s", input["id"], input["name"])
+		}
+
+		rules[id] = &alkira.TrafficPolicyRule{
+			Id:   json.Number(input["id"].(string)),
+			Name: input["name"].(string),
+			MatchCondition: alkira.PolicyRuleMatchCondition{
+				SrcIp:                 input["src_ip"].(string),
+				DstIp:                 input["dst_ip"].(string),
+				Dscp:                  input["dscp"].(string),
+				Protocol:              input["protocol"].(string),
+				SrcPortList:           convertTypeListToStringList(input["src_ports"].([]interface{})),
+				DstPortList:           convertTypeListToStringList(input["dst_ports"].([]interface{})),
+				SrcPrefixListId:       input["src_prefix_list_id"].(int),
+				DstPrefixListId:       input["dst_prefix_list_id"].(int),
+				InternetApplicationId: input["internet_application_id"].(int),
+				ApplicationList:       convertTypeSetToIntList(input["application_ids"].(*schema.Set)),
+			},
+			RuleAction: alkira.PolicyRuleAction{
+				Action:          input["rule_action"].(string),
+				ServiceTypeList: convertTypeListToStringList(input["rule_action_service_types"].([]interface{})),
+				ServiceList:     convertTypeListToIntList(input["rule_action_service_ids"].([]interface{})),
+			},
+		}
+	}
+
+	return rules, nil
+}
+
+// policyEvaluator evaluates a flow against traffic policies. Rule
+// lists, rules and prefix lists are loaded on demand and cached, so
+// only the objects on the evaluation path are fetched.
+type policyEvaluator struct {
+	getRuleList   func(id int) (*alkira.PolicyRuleList, error)
+	getRule       func(id int) (*alkira.TrafficPolicyRule, error)
+	getPrefixList func(id int) (*alkira.PolicyPrefixList, error)
+
+	ruleLists   map[int]*alkira.PolicyRuleList
+	rules       map[int]*alkira.TrafficPolicyRule
+	prefixLists map[int][]*net.IPNet
+}
+
+func newPolicyEvaluator(
+	getRuleList func(id int) (*alkira.PolicyRuleList, error),
+	getRule func(id int) (*alkira.TrafficPolicyRule, error),
+	getPrefixList func(id int) (*alkira.PolicyPrefixList, error),
+) *policyEvaluator {
+	return &policyEvaluator{
+		getRuleList:   getRuleList,
+		getRule:       getRule,
+		getPrefixList: getPrefixList,
+		ruleLists:     make(map[int]*alkira.PolicyRuleList),
+		rules:         make(map[int]*alkira.TrafficPolicyRule),
+		prefixLists:   make(map[int][]*net.IPNet),
+	}
+}
+
+// evaluate walks the policies in the given order. Within a policy the
+// rules of its rule list are evaluated by ascending priority and the
+// first matching rule wins. When nothing matches, defaultAction is
+// returned.
+func (e *policyEvaluator) evaluate(policies []alkira.TrafficPolicy, flow policyEvaluationFlow, defaultAction string) (*policyEvaluationResult, error) {
+	result := &policyEvaluationResult{Action: defaultAction}
+
+	for i := range policies {
+		policy := &policies[i]
+
+		if reason := policyMismatch(policy, flow); reason != "" {
+			result.tracef("policy %s (%s): skipped, %s", policy.Name, policy.Id, reason)
+			continue
+		}
+
+		ruleList, err := e.ruleList(policy.RuleListId)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]alkira.PolicyRuleListRule, len(ruleList.Rules))
+		copy(entries, ruleList.Rules)
+		sort.SliceStable(entries, func(a, b int) bool {
+			return entries[a].Priority < entries[b].Priority
+		})
+
+		for _, entry := range entries {
+			rule, err := e.rule(entry.RuleId)
+			if err != nil {
+				return nil, err
+			}
+
+			reason, err := e.ruleMismatch(rule, flow)
+			if err != nil {
+				return nil, err
+			}
+
+			if reason != "" {
+				result.tracef("policy %s (%s): rule %s (%d) at priority %d skipped, %s",
+					policy.Name, policy.Id, rule.Name, entry.RuleId, entry.Priority, reason)
+				continue
+			}
+
+			result.tracef("policy %s (%s): rule %s (%d) at priority %d matched",
+				policy.Name, policy.Id, rule.Name, entry.RuleId, entry.Priority)
+
+			result.Matched = true
+			result.Policy = policy
+			result.Rule = rule
+			result.RuleId = entry.RuleId
+			result.RulePriority = entry.Priority
+			result.Action = rule.RuleAction.Action
+			result.ServiceIds = rule.RuleAction.ServiceList
+			result.ServiceTypes = rule.RuleAction.ServiceTypeList
+
+			return result, nil
+		}
+
+		result.tracef("policy %s (%s): no rule matched", policy.Name, policy.Id)
+	}
+
+	result.tracef("no policy matched, default action %s", defaultAction)
+
+	return result, nil
+}
+
+func (r *policyEvaluationResult) tracef(format string, args ...interface{}) {
+	r.Trace = append(r.Trace, fmt.Sprintf(format, args...))
+}
+
+// policyMismatch returns why the policy doesn't apply to the flow or an
+// empty string if it does.
+func policyMismatch(policy *alkira.TrafficPolicy, flow policyEvaluationFlow) string {
+	switch {
+	case !policy.Enabled:
+		return "policy is disabled"
+	case !intInSlice(flow.SegmentId, policy.SegmentIds):
+		return fmt.Sprintf("segment %d is not in %v", flow.SegmentId, policy.SegmentIds)
+	case flow.SrcGroupId != 0 && !intInSlice(flow.SrcGroupId, policy.FromGroups):
+		return fmt.Sprintf("source group %d is not in %v", flow.SrcGroupId, policy.FromGroups)
+	case flow.DstGroupId != 0 && !intInSlice(flow.DstGroupId, policy.ToGroups):
+		return fmt.Sprintf("destination group %d is not in %v", flow.DstGroupId, policy.ToGroups)
+	}
+
+	return ""
+}
+
+// ruleMismatch returns why the match condition of the rule doesn't
+// match the flow or an empty string if it does.
+func (e *policyEvaluator) ruleMismatch(rule *alkira.TrafficPolicyRule, flow policyEvaluationFlow) (string, error) {
+	c := rule.MatchCondition
+
+	if p := strings.ToLower(c.Protocol); p != "" && p != "any" && p != strings.ToLower(flow.Protocol) {
+		return fmt.Sprintf("protocol %s does not match %s", flow.Protocol, c.Protocol), nil
+	}
+
+	if d := strings.ToLower(c.Dscp); d != "" && d != "any" && d != strconv.Itoa(flow.Dscp) {
+		return fmt.Sprintf("dscp %d does not match %s", flow.Dscp, c.Dscp), nil
+	}
+
+	if reason := ipConditionMismatch("source", c.SrcIp, flow.SrcIp); reason != "" {
+		return reason, nil
+	}
+
+	if reason := ipConditionMismatch("destination", c.DstIp, flow.DstIp); reason != "" {
+		return reason, nil
+	}
+
+	if c.SrcPrefixListId != 0 {
+		reason, err := e.prefixListMismatch("source", c.SrcPrefixListId, flow.SrcIp)
+		if err != nil || reason != "" {
+			return reason, err
+		}
+	}
+
+	if c.DstPrefixListId != 0 {
+		reason, err := e.prefixListMismatch("destination", c.DstPrefixListId, flow.DstIp)
+		if err != nil || reason != "" {
+			return reason, err
+		}
+	}
+
+	if !strings.EqualFold(flow.Protocol, "icmp") {
+		if reason := portConditionMismatch("source", c.SrcPortList, flow.SrcPort); reason != "" {
+			return reason, nil
+		}
+
+		if reason := portConditionMismatch("destination", c.DstPortList, flow.DstPort); reason != "" {
+			return reason, nil
+		}
+	}
+
+	if len(c.ApplicationList) > 0 && !intInSlice(flow.ApplicationId, c.ApplicationList) {
+		return fmt.Sprintf("application %d is not in %v", flow.ApplicationId, c.ApplicationList), nil
+	}
+
+	if c.InternetApplicationId != 0 && c.InternetApplicationId != flow.InternetApplicationId {
+		return fmt.Sprintf("internet application %d does not match %d", flow.InternetApplicationId, c.InternetApplicationId), nil
+	}
+
+	return "", nil
+}
+
+// ipConditionMismatch matches a single IP or CIDR condition of a rule.
+func ipConditionMismatch(side string, condition string, ip net.IP) string {
+	if condition == "" || strings.EqualFold(condition, "any") {
+		return ""
+	}
+
+	if ip == nil {
+		return fmt.Sprintf("rule requires %s IP %s but the flow has none", side, condition)
+	}
+
+	network, err := parseIpOrCidr(condition)
+	if err != nil || !network.Contains(ip) {
+		return fmt.Sprintf("%s IP %s does not match %s", side, ip, condition)
+	}
+
+	return ""
+}
+
+// portConditionMismatch matches a port list of a rule. Entries are
+// "any", a port or a range like "8000-8080".
+func portConditionMismatch(side string, ports []string, port int) string {
+	if len(ports) == 0 {
+		return ""
+	}
+
+	for _, p := range ports {
+		if strings.EqualFold(p, "any") {
+			return ""
+		}
+
+		if port == 0 {
+			continue
+		}
+
+		low, high, isRange := strings.Cut(p, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(low))
+		if err != nil {
+			continue
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(strings.TrimSpace(high)); err != nil {
+				continue
+			}
+		}
+
+		if port >= from && port <= to {
+			return ""
+		}
+	}
+
+	if port == 0 {
+		return fmt.Sprintf("rule requires %s ports %v but the flow has none", side, ports)
+	}
+
+	return fmt.Sprintf("%s port %d is not in %v", side, port, ports)
+}
+
+func (e *policyEvaluator) prefixListMismatch(side string, id int, ip net.IP) (string, error) {
+	if ip == nil {
+		return fmt.Sprintf("rule requires %s prefix list %d but the flow has no %s IP", side, id, side), nil
+	}
+
+	networks, err := e.prefixList(id)
+	if err != nil {
+		return "", err
+	}
+
+	for _, n := range networks {
+		if n.Contains(ip) {
+			return "", nil
+		}
+	}
+
+	return fmt.Sprintf("%s IP %s is not in prefix list %d", side, ip, id), nil
+}
+
+func (e *policyEvaluator) ruleList(id int) (*alkira.PolicyRuleList, error) {
+	if ruleList, ok := e.ruleLists[id]; ok {
+		return ruleList, nil
+	}
+
+	ruleList, err := e.getRuleList(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy rule list %d: %w", id, err)
+	}
+	e.ruleLists[id] = ruleList
+
+	return ruleList, nil
+}
+
+func (e *policyEvaluator) rule(id int) (*alkira.TrafficPolicyRule, error) {
+	if rule, ok := e.rules[id]; ok {
+		return rule, nil
+	}
+
+	rule, err := e.getRule(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy rule %d: %w", id, err)
+	}
+	e.rules[id] = rule
+
+	return rule, nil
+}
+
+// prefixList returns the networks of a prefix list, including the base
+// prefixes of its prefix ranges.
+func (e *policyEvaluator) prefixList(id int) ([]*net.IPNet, error) {
+	if networks, ok := e.prefixLists[id]; ok {
+		return networks, nil
+	}
+
+	list, err := e.getPrefixList(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy prefix list %d: %w", id, err)
+	}
+
+	var networks []*net.IPNet
+
+	prefixes := list.Prefixes
+	for _, r := range list.PrefixRanges {
+		prefixes = append(prefixes, r.Prefix)
+	}
+
+	for _, p := range prefixes {
+		n, err := parseIpOrCidr(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix %q in policy prefix list %d: %w", p, id, err)
+		}
+		networks = append(networks, n)
+	}
+
+	e.prefixLists[id] = networks
+
+	return networks, nil
+}
+
+// parseIpOrCidr parses a CIDR or a single IP, which is treated as a
+// host prefix.
+func parseIpOrCidr(v string) (*net.IPNet, error) {
+	if strings.Contains(v, "/") {
+		_, n, err := net.ParseCIDR(v)
+		return n, err
+	}
+
+	ip := net.ParseIP(v)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP %q", v)
+	}
+
+	bits := 128
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 32
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+func intInSlice(v int, list []int) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,392 @@
+package alkira
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPolicyEvaluator returns an evaluator over a small policy set:
+//
+//   - rule list 10: 1 (priority 10) allows web to 10.1.0.0/16 via the
+//     firewall service 55, 2 (priority 20) drops everything else;
+//   - rule list 20: 3 allows DNS to any destination.
+func testPolicyEvaluator() (*policyEvaluator, map[string]int) {
+	calls := map[string]int{}
+
+	ruleLists := map[int]*alkira.PolicyRuleList{
+		10: {Name: "web", Rules: []alkira.PolicyRuleListRule{
+			{Priority: 20, RuleId: 2},
+			{Priority: 10, RuleId: 1},
+		}},
+		20: {Name: "dns", Rules: []alkira.PolicyRuleListRule{
+			{Priority: 1, RuleId: 3},
+		}},
+	}
+
+	rules := map[int]*alkira.TrafficPolicyRule{
+		1: {Name: "allow-web", MatchCondition: alkira.PolicyRuleMatchCondition{
+			Protocol:        "tcp",
+			Dscp:            "any",
+			SrcIp:           "any",
+			DstPrefixListId: 100,
+			DstPortList:     []string{"80", "8000-8080"},
+		}, RuleAction: alkira.PolicyRuleAction{
+			Action:          "ALLOW",
+			ServiceTypeList: []string{"PAN"},
+			ServiceList:     []int{55},
+		}},
+		2: {Name: "drop-all", MatchCondition: alkira.PolicyRuleMatchCondition{
+			Protocol: "any",
+			Dscp:     "any",
+		}, RuleAction: alkira.PolicyRuleAction{Action: "DROP"}},
+		3: {Name: "allow-dns", MatchCondition: alkira.PolicyRuleMatchCondition{
+			Protocol:    "udp",
+			Dscp:        "any",
+			DstPortList: []string{"53"},
+		}, RuleAction: alkira.PolicyRuleAction{Action: "ALLOW"}},
+	}
+
+	prefixLists := map[int]*alkira.PolicyPrefixList{
+		100: {
+			Prefixes:     []string{"10.1.0.0/16"},
+			PrefixRanges: []alkira.PolicyPrefixListRange{{Prefix: "192.168.10.0/24", Le: 32}},
+		},
+	}
+
+	evaluator := newPolicyEvaluator(
+		func(id int) (*alkira.PolicyRuleList, error) {
+			calls[fmt.Sprintf("rulelist/%d", id)]++
+			if l, ok := ruleLists[id]; ok {
+				return l, nil
+			}
+			return nil, fmt.Errorf("not found")
+		},
+		func(id int) (*alkira.TrafficPolicyRule, error) {
+			calls[fmt.Sprintf("rule/%d", id)]++
+			if r, ok := rules[id]; ok {
+				return r, nil
+			}
+			return nil, fmt.Errorf("not found")
+		},
+		func(id int) (*alkira.PolicyPrefixList, error) {
+			calls[fmt.Sprintf("prefixlist/%d", id)]++
+			if l, ok := prefixLists[id]; ok {
+				return l, nil
+			}
+			return nil, fmt.Errorf("not found")
+		},
+	)
+
+	return evaluator, calls
+}
+
+func testPolicies() []alkira.TrafficPolicy {
+	return []alkira.TrafficPolicy{
+		{Id: "1", Name: "disabled", Enabled: false, SegmentIds: []int{1}, FromGroups: []int{7}, ToGroups: []int{8}, RuleListId: 20},
+		{Id: "2", Name: "dns", Enabled: true, SegmentIds: []int{1}, FromGroups: []int{7}, ToGroups: []int{9}, RuleListId: 20},
+		{Id: "3", Name: "web", Enabled: true, SegmentIds: []int{1}, FromGroups: []int{7}, ToGroups: []int{8}, RuleListId: 10},
+	}
+}
+
+func TestPolicyEvaluatorEvaluate(t *testing.T) {
+	tests := []struct {
+		name         string
+		flow         policyEvaluationFlow
+		wantMatched  bool
+		wantPolicy   string
+		wantRuleId   int
+		wantAction   string
+		wantServices []int
+	}{
+		{
+			name: "web traffic is steered to the firewall",
+			flow: policyEvaluationFlow{
+				SegmentId: 1, SrcGroupId: 7, DstGroupId: 8,
+				SrcIp: net.ParseIP("172.16.0.1"), DstIp: net.ParseIP("10.1.2.3"),
+				Protocol: "tcp", DstPort: 8080,
+			},
+			wantMatched:  true,
+			wantPolicy:   "web",
+			wantRuleId:   1,
+			wantAction:   "ALLOW",
+			wantServices: []int{55},
+		},
+		{
+			name: "prefix range matches",
+			flow: policyEvaluationFlow{
+				SegmentId: 1, SrcGroupId: 7, DstGroupId: 8,
+				DstIp: net.ParseIP("192.168.10.20"), Protocol: "tcp", DstPort: 80,
+			},
+			wantMatched:  true,
+			wantPolicy:   "web",
+			wantRuleId:   1,
+			wantAction:   "ALLOW",
+			wantServices: []int{55},
+		},
+		{
+			name: "other port falls through to drop",
+			flow: policyEvaluationFlow{
+				SegmentId: 1, SrcGroupId: 7, DstGroupId: 8,
+				DstIp: net.ParseIP("10.1.2.3"), Protocol: "tcp", DstPort: 22,
+			},
+			wantMatched: true,
+			wantPolicy:  "web",
+			wantRuleId:  2,
+			wantAction:  "DROP",
+		},
+		{
+			name: "flow without destination IP skips prefix list rule",
+			flow: policyEvaluationFlow{
+				SegmentId: 1, SrcGroupId: 7, DstGroupId: 8,
+				Protocol: "tcp", DstPort: 80,
+			},
+			wantMatched: true,
+			wantPolicy:  "web",
+			wantRuleId:  2,
+			wantAction:  "DROP",
+		},
+		{
+			name: "dns policy",
+			flow: policyEvaluationFlow{
+				SegmentId: 1, SrcGroupId: 7, DstGroupId: 9,
+				Protocol: "udp", DstPort: 53,
+			},
+			wantMatched: true,
+			wantPolicy:  "dns",
+			wantRuleId:  3,
+			wantAction:  "ALLOW",
+		},
+		{
+			name: "flow without groups is matched by IP",
+			flow: policyEvaluationFlow{
+				SegmentId: 1, DstIp: net.ParseIP("10.1.2.3"),
+				Protocol: "tcp", DstPort: 8080,
+			},
+			wantMatched:  true,
+			wantPolicy:   "web",
+			wantRuleId:   1,
+			wantAction:   "ALLOW",
+			wantServices: []int{55},
+		},
+		{
+			name: "no policy for the segment",
+			flow: policyEvaluationFlow{
+				SegmentId: 2, SrcGroupId: 7, DstGroupId: 8,
+				Protocol: "tcp", DstPort: 80,
+			},
+			wantAction: "DROP",
+		},
+		{
+			name: "no rule in the policy matches",
+			flow: policyEvaluationFlow{
+				SegmentId: 1, SrcGroupId: 7, DstGroupId: 9,
+				Protocol: "tcp", DstPort: 53,
+			},
+			wantAction: "DROP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator, _ := testPolicyEvaluator()
+
+			result, err := evaluator.evaluate(testPolicies(), tt.flow, "DROP")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantMatched, result.Matched)
+			assert.Equal(t, tt.wantAction, result.Action)
+			assert.NotEmpty(t, result.Trace)
+
+			if !tt.wantMatched {
+				assert.Nil(t, result.Policy)
+				return
+			}
+
+			assert.Equal(t, tt.wantPolicy, result.Policy.Name)
+			assert.Equal(t, tt.wantRuleId, result.RuleId)
+			assert.Equal(t, tt.wantServices, result.ServiceIds)
+		})
+	}
+}
+
+func TestPolicyEvaluatorTraceAndCache(t *testing.T) {
+	evaluator, calls := testPolicyEvaluator()
+
+	flow := policyEvaluationFlow{
+		SegmentId: 1, SrcGroupId: 7, DstGroupId: 8,
+		DstIp: net.ParseIP("10.9.9.9"), Protocol: "tcp", DstPort: 80,
+	}
+
+	result, err := evaluator.evaluate(testPolicies(), flow, "ALLOW")
+	require.NoError(t, err)
+	require.Equal(t, 2, result.RuleId)
+
+	assert.Equal(t, []string{
+		"policy disabled (1): skipped, policy is disabled",
+		"policy dns (2): skipped, destination group 8 is not in [9]",
+		"policy web (3): rule allow-web (1) at priority 10 skipped, destination IP 10.9.9.9 is not in prefix list 100",
+		"policy web (3): rule drop-all (2) at priority 20 matched",
+	}, result.Trace)
+
+	_, err = evaluator.evaluate(testPolicies(), flow, "ALLOW")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls["rulelist/10"])
+	assert.Equal(t, 1, calls["rule/1"])
+	assert.Equal(t, 1, calls["prefixlist/100"])
+}
+
+func TestPolicyEvaluatorMissingRule(t *testing.T) {
+	evaluator, _ := testPolicyEvaluator()
+
+	policies := []alkira.TrafficPolicy{
+		{Id: "4", Name: "broken", Enabled: true, SegmentIds: []int{1}, FromGroups: []int{1}, ToGroups: []int{1}, RuleListId: 30},
+	}
+
+	_, err := evaluator.evaluate(policies, policyEvaluationFlow{SegmentId: 1, SrcGroupId: 1, DstGroupId: 1, Protocol: "tcp"}, "DROP")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "policy rule list 30")
+}
+
+func TestPolicyEvaluationPassedPolicies(t *testing.T) {
+	// The policies, rule lists and rules passed in are evaluated without
+	// reading anything.
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	policies := []interface{}{
+		map[string]interface{}{
+			"name":         "web",
+			"enabled":      true,
+			"segment_ids":  []interface{}{1},
+			"from_groups":  []interface{}{7},
+			"to_groups":    []interface{}{8},
+			"rule_list_id": 10,
+		},
+	}
+	ruleLists := []interface{}{
+		map[string]interface{}{
+			"id":   "10",
+			"name": "web",
+			"rules": []interface{}{
+				map[string]interface{}{"priority": 2, "rule_id": 2},
+				map[string]interface{}{"priority": 1, "rule_id": 1},
+			},
+		},
+	}
+	rules := []interface{}{
+		map[string]interface{}{
+			"id":                        "1",
+			"name":                      "allow-web",
+			"dscp":                      "any",
+			"protocol":                  "tcp",
+			"dst_ip":                    "10.1.0.0/16",
+			"dst_ports":                 []interface{}{"443"},
+			"rule_action":               "ALLOW",
+			"rule_action_service_types": []interface{}{"PAN"},
+			"rule_action_service_ids":   []interface{}{55},
+		},
+		map[string]interface{}{
+			"id":          "2",
+			"name":        "drop-all",
+			"dscp":        "any",
+			"protocol":    "any",
+			"rule_action": "DROP",
+		},
+	}
+
+	tests := []struct {
+		name         string
+		config       map[string]interface{}
+		wantRule     string
+		wantAction   string
+		wantServices []interface{}
+	}{
+		{
+			name: "groups",
+			config: map[string]interface{}{
+				"segment_id": 1, "src_group_id": 7, "dst_group_id": 8,
+				"dst_ip": "10.1.2.3", "protocol": "tcp", "dst_port": 443,
+			},
+			wantRule:     "allow-web",
+			wantAction:   "ALLOW",
+			wantServices: []interface{}{55},
+		},
+		{
+			name: "IPs",
+			config: map[string]interface{}{
+				"segment_id": 1, "src_ip": "172.16.0.1",
+				"dst_ip": "10.1.2.3", "protocol": "tcp", "dst_port": 22,
+			},
+			wantRule:     "drop-all",
+			wantAction:   "DROP",
+			wantServices: []interface{}{},
+		},
+		{
+			name: "other group",
+			config: map[string]interface{}{
+				"segment_id": 1, "src_group_id": 9, "dst_ip": "10.1.2.3",
+				"protocol": "tcp", "dst_port": 443, "default_action": "ALLOW",
+			},
+			wantAction:   "ALLOW",
+			wantServices: []interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["policy"] = policies
+			tt.config["rule_list"] = ruleLists
+			tt.config["rule"] = rules
+
+			r := dataSourceAlkiraPolicyEvaluation()
+			d := schema.TestResourceDataRaw(t, r.Schema, tt.config)
+
+			require.NoError(t, r.Read(d, client))
+
+			assert.Equal(t, tt.wantRule != "", d.Get("matched"))
+			assert.Equal(t, tt.wantRule, d.Get("rule_name"))
+			assert.Equal(t, tt.wantAction, d.Get("action"))
+			assert.Equal(t, tt.wantServices, d.Get("service_ids"))
+		})
+	}
+}
+
+func TestPortConditionMismatch(t *testing.T) {
+	tests := []struct {
+		ports []string
+		port  int
+		match bool
+	}{
+		{nil, 0, true},
+		{[]string{"any"}, 0, true},
+		{[]string{"443"}, 443, true},
+		{[]string{"443"}, 444, false},
+		{[]string{"443"}, 0, false},
+		{[]string{"1000-2000"}, 1500, true},
+		{[]string{"1000-2000"}, 2001, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v/%d", tt.ports, tt.port), func(t *testing.T) {
+			assert.Equal(t, tt.match, portConditionMismatch("destination", tt.ports, tt.port) == "")
+		})
+	}
+}
+
+func TestIpConditionMismatch(t *testing.T) {
+	assert.Empty(t, ipConditionMismatch("source", "", nil))
+	assert.Empty(t, ipConditionMismatch("source", "any", nil))
+	assert.Empty(t, ipConditionMismatch("source", "10.0.0.1", net.ParseIP("10.0.0.1")))
+	assert.Empty(t, ipConditionMismatch("source", "10.0.0.0/8", net.ParseIP("10.2.3.4")))
+	assert.NotEmpty(t, ipConditionMismatch("source", "10.0.0.1", net.ParseIP("10.0.0.2")))
+	assert.NotEmpty(t, ipConditionMismatch("source", "10.0.0.1", nil))
+}
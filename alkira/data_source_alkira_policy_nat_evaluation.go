@@ -0,0 +1,333 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAlkiraPolicyNatEvaluation() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to preview how an " +
+			"`alkira_policy_nat` translates a packet without provisioning " +
+			"anything.\n\n" +
+			"Rules are walked in the order of `rule_ids` of the policy and " +
+			"the first enabled matching rule is applied. Static " +
+			"translations map addresses 1:1 between the matched and the " +
+			"translated prefix at the same position. Dynamic source " +
+			"translation reports the first address of the translated " +
+			"prefixes, the actual address and port are allocated at " +
+			"runtime.\n\n" +
+			"The rules are either passed in with `rule`, e.g. " +
+			"`rule = [alkira_policy_nat_rule.a, alkira_policy_nat_rule.b]`, " +
+			"which evaluates the planned rules before they are applied, or " +
+			"read from the policy of `policy_nat_id`.",
+
+		ReadContext: dataSourceAlkiraPolicyNatEvaluationRead,
+
+		Schema: map[string]*schema.Schema{
+			"rule": {
+				Description: "The rules to evaluate in the format of " +
+					"`alkira_policy_nat_rule`, in the order the policy " +
+					"applies them.",
+				Type:         schema.TypeList,
+				Optional:     true,
+				ConfigMode:   schema.SchemaConfigModeAttr,
+				Elem:         natEvaluationRuleElem(),
+				ExactlyOneOf: []string{"rule", "policy_nat_id"},
+			},
+			"included_group_ids": {
+				Description: "The included groups of the policy of `rule`, " +
+					"checked against `group_id`.",
+				Type:          schema.TypeSet,
+				Elem:          &schema.Schema{Type: schema.TypeInt},
+				Optional:      true,
+				ConflictsWith: []string{"policy_nat_id"},
+			},
+			"excluded_group_ids": {
+				Description: "The excluded groups of the policy of `rule`, " +
+					"checked against `group_id`.",
+				Type:          schema.TypeSet,
+				Elem:          &schema.Schema{Type: schema.TypeInt},
+				Optional:      true,
+				ConflictsWith: []string{"policy_nat_id"},
+			},
+			"policy_nat_id": {
+				Description: "The ID of the `alkira_policy_nat` whose rules " +
+					"to evaluate.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"segment_id": {
+				Description: "The ID of the segment of the packet. The " +
+					"policy doesn't apply when it's on another segment.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"group_id": {
+				Description: "The ID of the group of the connector the " +
+					"packet comes from, checked against the included and " +
+					"excluded groups of the policy.",
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"src_ip": {
+				Description:  "The source IP of the packet.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPv4Address,
+			},
+			"dst_ip": {
+				Description:  "The destination IP of the packet.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPv4Address,
+			},
+			"protocol": {
+				Description: "The protocol of the packet, `tcp`, `udp` " +
+					"or `icmp`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"tcp", "udp", "icmp"}, false),
+			},
+			"src_port": {
+				Description:  "The source port of the packet.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 65535),
+			},
+			"dst_port": {
+				Description:  "The destination port of the packet.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 65535),
+			},
+			"matched": {
+				Description: "Whether a rule matched the packet.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"rule_id": {
+				Description: "The ID of the matching `alkira_policy_nat_rule`.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"rule_name": {
+				Description: "The name of the matching rule.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"src_translation_type": {
+				Description: "The source translation type of the matching rule.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"dst_translation_type": {
+				Description: "The destination translation type of the " +
+					"matching rule.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"translated_src_ip": {
+				Description: "The source IP after translation.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"translated_src_port": {
+				Description: "The source port after translation, `0` when " +
+					"it's allocated dynamically.",
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"translated_dst_ip": {
+				Description: "The destination IP after translation.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"translated_dst_port": {
+				Description: "The destination port after translation.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"translated_dst_policy_fqdn_list_id": {
+				Description: "The ID of the policy FQDN list the " +
+					"destination is translated to.",
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"bidirectional": {
+				Description: "Whether the translation is bidirectional.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"egress_type": {
+				Description: "The egress IP type of the matching rule.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"advertised_prefixes": {
+				Description: "The prefixes the rule advertises: the " +
+					"translated source prefixes and, with " +
+					"`dst_addr_translation_advertise_to_connector`, the " +
+					"matched destination prefixes.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"invalidated_prefixes": {
+				Description: "The matched source prefixes invalidated by " +
+					"`src_addr_translation_match_and_invalidate`.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"tracked_prefixes": {
+				Description: "The prefixes tracked by the routing options " +
+					"of the rule. Prefix lists are reported as " +
+					"`prefix_list:<id>`.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"invalidate_tracked_prefixes": {
+				Description: "Whether the translation is invalidated when " +
+					"the tracked prefixes are withdrawn.",
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"trace": {
+				Description: "The evaluation steps, explaining why rules " +
+					"were skipped.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraPolicyNatEvaluationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*alkira.AlkiraClient)
+
+	srcIp, err := netip.ParseAddr(d.Get("src_ip").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	dstIp, err := netip.ParseAddr(d.Get("dst_ip").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	packet := natEvaluationPacket{
+		SrcIp:    srcIp,
+		DstIp:    dstIp,
+		Protocol: d.Get("protocol").(string),
+		SrcPort:  d.Get("src_port").(int),
+		DstPort:  d.Get("dst_port").(int),
+		GroupId:  d.Get("group_id").(int),
+	}
+
+	if segmentId, ok := d.GetOk("segment_id"); ok {
+		packet.Segment, err = getSegmentNameById(segmentId.(string), m)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	getPrefixList := func(id int) (*alkira.PolicyPrefixList, error) {
+		list, _, err := alkira.NewPolicyPrefixList(client).GetById(strconv.Itoa(id))
+		return list, err
+	}
+
+	var policyId string
+	var policy *alkira.NatPolicy
+	var evaluator *natEvaluator
+
+	// The rules passed in get the IDs of their position, starting at 1.
+	var ruleIds []string
+
+	if in, ok := d.GetOk("rule"); ok {
+		var rules []alkira.NatPolicyRule
+		rules, ruleIds, err = expandNatEvaluationRules(ctx, in.([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		policyId = "rules"
+		policy = &alkira.NatPolicy{
+			Name:           "rules",
+			IncludedGroups: convertTypeSetToIntList(d.Get("included_group_ids").(*schema.Set)),
+			ExcludedGroups: convertTypeSetToIntList(d.Get("excluded_group_ids").(*schema.Set)),
+		}
+		for i := range rules {
+			policy.NatRuleIds = append(policy.NatRuleIds, i+1)
+		}
+
+		evaluator = newNatEvaluator(
+			func(id int) (*alkira.NatPolicyRule, error) {
+				return &rules[id-1], nil
+			},
+			getPrefixList,
+		)
+	} else {
+		policyId = d.Get("policy_nat_id").(string)
+
+		policy, _, err = alkira.NewNatPolicy(client).GetById(policyId)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		evaluator = newNatEvaluator(
+			func(id int) (*alkira.NatPolicyRule, error) {
+				rule, _, err := alkira.NewNatRule(client).GetById(strconv.Itoa(id))
+				return rule, err
+			},
+			getPrefixList,
+		)
+	}
+
+	result, err := evaluator.evaluate(policy, packet)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s-%s-%s-%s", policyId, srcIp, dstIp, packet.Protocol))
+	d.Set("matched", result.Matched)
+	d.Set("translated_src_ip", result.Packet.SrcIp.String())
+	d.Set("translated_src_port", result.Packet.SrcPort)
+	d.Set("translated_dst_ip", result.Packet.DstIp.String())
+	d.Set("translated_dst_port", result.Packet.DstPort)
+	d.Set("translated_dst_policy_fqdn_list_id", result.TranslatedDstFqdnId)
+	d.Set("bidirectional", result.Bidirectional)
+	d.Set("advertised_prefixes", result.AdvertisedPrefixes)
+	d.Set("invalidated_prefixes", result.InvalidatedPrefixes)
+	d.Set("tracked_prefixes", result.TrackedPrefixes)
+	d.Set("invalidate_tracked_prefixes", result.InvalidateTracked)
+	d.Set("trace", result.Trace)
+
+	d.Set("rule_id", "")
+	d.Set("rule_name", "")
+	d.Set("src_translation_type", "")
+	d.Set("dst_translation_type", "")
+	d.Set("egress_type", "")
+	if rule := result.Rule; rule != nil {
+		if ruleIds != nil {
+			d.Set("rule_id", ruleIds[result.RuleId-1])
+		} else {
+			d.Set("rule_id", strconv.Itoa(result.RuleId))
+		}
+		d.Set("rule_name", rule.Name)
+		d.Set("src_translation_type", rule.Action.SourceAddressTranslation.TranslationType)
+		d.Set("dst_translation_type", rule.Action.DestinationAddressTranslation.TranslationType)
+		d.Set("egress_type", rule.Action.Egress.IpType)
+	}
+
+	return nil
+}
@@ -0,0 +1,516 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// natEvaluationPacket is the packet evaluated by
+// alkira_policy_nat_evaluation. Zero ports mean "not specified".
+type natEvaluationPacket struct {
+	SrcIp    netip.Addr
+	DstIp    netip.Addr
+	Protocol string
+	SrcPort  int
+	DstPort  int
+	GroupId  int
+	Segment  string
+}
+
+// natEvaluationResult is the outcome of an evaluation: the translated
+// packet and the routing side effects of the matching rule.
+type natEvaluationResult struct {
+	Matched             bool
+	Rule                *alkira.NatPolicyRule
+	RuleId              int
+	Packet              natEvaluationPacket
+	TranslatedDstFqdnId int
+	AdvertisedPrefixes  []string
+	InvalidatedPrefixes []string
+	TrackedPrefixes     []string
+	InvalidateTracked   bool
+	Bidirectional       bool
+	Trace               []string
+}
+
+func (r *natEvaluationResult) tracef(format string, args ...interface{}) {
+	r.Trace = append(r.Trace, fmt.Sprintf(format, args...))
+}
+
+// natEvaluationRuleElem returns the schema of the rules passed in to
+// alkira_policy_nat_evaluation, the schema of alkira_policy_nat_rule
+// with the ID of the rule.
+func natEvaluationRuleElem() *schema.Resource {
+	rule := evaluationRuleElem(resourceAlkiraPolicyNatRule().Schema)
+
+	rule.Schema["id"] = &schema.Schema{
+		Description: "The ID of the rule, reported as `rule_id`.",
+		Type:        schema.TypeString,
+		Optional:    true,
+	}
+
+	return rule
+}
+
+// expandNatEvaluationRules expands the rules passed in to
+// alkira_policy_nat_evaluation and returns them with their IDs.
+func expandNatEvaluationRules(ctx context.Context, in []interface{}) ([]alkira.NatPolicyRule, []string, error) {
+	rules := make([]alkira.NatPolicyRule, 0, len(in))
+	ids := make([]string, 0, len(in))
+
+	for i, r := range in {
+		input := r.(map[string]interface{})
+
+		name, _ := input["name"].(string)
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		match := expandPolicyNatRuleMatch(ctx, input["match"].(*schema.Set))
+		if match == nil {
+			return nil, nil, fmt.Errorf("rule %s needs exactly one match", name)
+		}
+
+		action := expandPolicyNatRuleAction(ctx, input["action"].(*schema.Set))
+		if action == nil {
+			return nil, nil, fmt.Errorf("rule %s needs exactly one action", name)
+		}
+
+		rule := alkira.NatPolicyRule{
+			Name:   name,
+			Match:  *match,
+			Action: *action,
+		}
+		rule.Enabled, _ = input["enabled"].(bool)
+		rule.Category, _ = input["category"].(string)
+		rule.Direction, _ = input["direction"].(string)
+
+		id, _ := input["id"].(string)
+
+		rules = append(rules, rule)
+		ids = append(ids, id)
+	}
+
+	return rules, ids, nil
+}
+
+// natEvaluator walks the rules of a NAT policy. Rules and prefix lists
+// are loaded on demand and cached.
+type natEvaluator struct {
+	getRule       func(id int) (*alkira.NatPolicyRule, error)
+	getPrefixList func(id int) (*alkira.PolicyPrefixList, error)
+	prefixLists   map[int][]netip.Prefix
+}
+
+func newNatEvaluator(
+	getRule func(id int) (*alkira.NatPolicyRule, error),
+	getPrefixList func(id int) (*alkira.PolicyPrefixList, error),
+) *natEvaluator {
+	return &natEvaluator{
+		getRule:       getRule,
+		getPrefixList: getPrefixList,
+		prefixLists:   make(map[int][]netip.Prefix),
+	}
+}
+
+// evaluate walks the rules of the policy in the order of its rule IDs.
+// The first enabled rule matching the packet is applied and the packet
+// is returned unchanged when nothing matches.
+func (e *natEvaluator) evaluate(policy *alkira.NatPolicy, packet natEvaluationPacket) (*natEvaluationResult, error) {
+	result := &natEvaluationResult{Packet: packet}
+
+	if reason := natPolicyMismatch(policy, packet); reason != "" {
+		result.tracef("policy %s: skipped, %s", policy.Name, reason)
+		return result, nil
+	}
+
+	for _, id := range policy.NatRuleIds {
+		rule, err := e.getRule(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get NAT rule %d: %w", id, err)
+		}
+
+		if !rule.Enabled {
+			result.tracef("rule %s (%d): skipped, rule is disabled", rule.Name, id)
+			continue
+		}
+
+		match, err := e.ruleMatch(rule, packet)
+		if err != nil {
+			return nil, err
+		}
+
+		if match.reason != "" {
+			result.tracef("rule %s (%d): skipped, %s", rule.Name, id, match.reason)
+			continue
+		}
+
+		result.tracef("rule %s (%d): matched", rule.Name, id)
+
+		result.Matched = true
+		result.Rule = rule
+		result.RuleId = id
+
+		if err := e.apply(rule, match, result); err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	}
+
+	result.tracef("no rule matched, packet is not translated")
+
+	return result, nil
+}
+
+// natPolicyMismatch returns why the policy doesn't apply to the packet
+// or an empty string if it does.
+func natPolicyMismatch(policy *alkira.NatPolicy, packet natEvaluationPacket) string {
+	if packet.Segment != "" && policy.Segment != "" && packet.Segment != policy.Segment {
+		return fmt.Sprintf("segment %s is not the segment %s of the policy", packet.Segment, policy.Segment)
+	}
+
+	if packet.GroupId != 0 {
+		if intInSlice(packet.GroupId, policy.ExcludedGroups) {
+			return fmt.Sprintf("group %d is excluded", packet.GroupId)
+		}
+		if len(policy.IncludedGroups) > 0 && !intInSlice(packet.GroupId, policy.IncludedGroups) {
+			return fmt.Sprintf("group %d is not in the included groups %v", packet.GroupId, policy.IncludedGroups)
+		}
+	}
+
+	return ""
+}
+
+// natRuleMatch records which prefixes and ports of a rule matched, as
+// static translations map them by position.
+type natRuleMatch struct {
+	reason      string
+	srcPrefixes []netip.Prefix
+	srcIndex    int
+	dstPrefixes []netip.Prefix
+	dstIndex    int
+	dstPortFrom int
+	dstPortIdx  int
+}
+
+func (e *natEvaluator) ruleMatch(rule *alkira.NatPolicyRule, packet natEvaluationPacket) (*natRuleMatch, error) {
+	m := rule.Match
+	match := &natRuleMatch{srcIndex: -1, dstIndex: -1, dstPortIdx: -1}
+
+	if p := strings.ToLower(m.Protocol); p != "" && p != "any" && p != strings.ToLower(packet.Protocol) {
+		match.reason = fmt.Sprintf("protocol %s does not match %s", packet.Protocol, m.Protocol)
+		return match, nil
+	}
+
+	var err error
+
+	if match.srcPrefixes, err = e.prefixes(m.SourcePrefixes, m.SourcePrefixListIds); err != nil {
+		return nil, err
+	}
+	if len(match.srcPrefixes) > 0 {
+		if match.srcIndex = prefixIndex(match.srcPrefixes, packet.SrcIp); match.srcIndex < 0 {
+			match.reason = fmt.Sprintf("source IP %s is not in %v", packet.SrcIp, match.srcPrefixes)
+			return match, nil
+		}
+	}
+
+	if match.dstPrefixes, err = e.prefixes(m.DestPrefixes, m.DestPrefixListIds); err != nil {
+		return nil, err
+	}
+	if len(match.dstPrefixes) > 0 {
+		if match.dstIndex = prefixIndex(match.dstPrefixes, packet.DstIp); match.dstIndex < 0 {
+			match.reason = fmt.Sprintf("destination IP %s is not in %v", packet.DstIp, match.dstPrefixes)
+			return match, nil
+		}
+	}
+
+	if strings.EqualFold(packet.Protocol, "icmp") {
+		return match, nil
+	}
+
+	if reason := portConditionMismatch("source", m.SourcePortList, packet.SrcPort); reason != "" {
+		match.reason = reason
+		return match, nil
+	}
+
+	if reason := portConditionMismatch("destination", m.DestPortList, packet.DstPort); reason != "" {
+		match.reason = reason
+		return match, nil
+	}
+
+	for i, p := range m.DestPortList {
+		if from, to, ok := parsePortRange(p); ok && packet.DstPort >= from && packet.DstPort <= to {
+			match.dstPortIdx, match.dstPortFrom = i, from
+			break
+		}
+	}
+
+	return match, nil
+}
+
+// apply translates the packet and records the routing side effects.
+func (e *natEvaluator) apply(rule *alkira.NatPolicyRule, match *natRuleMatch, result *natEvaluationResult) error {
+	src := rule.Action.SourceAddressTranslation
+	dst := rule.Action.DestinationAddressTranslation
+
+	switch src.TranslationType {
+	case "STATIC_IP":
+		translated, err := e.prefixes(src.TranslatedPrefixes, src.TranslatedPrefixListIds)
+		if err != nil {
+			return err
+		}
+		addr, err := staticTranslate(match.srcPrefixes, match.srcIndex, translated, result.Packet.SrcIp)
+		if err != nil {
+			return fmt.Errorf("rule %s: source translation: %w", rule.Name, err)
+		}
+		result.tracef("source %s translated to %s", result.Packet.SrcIp, addr)
+		result.Packet.SrcIp = addr
+		result.addSourceRoutes(src, match, translated)
+
+	case "DYNAMIC_IP_AND_PORT":
+		translated, err := e.prefixes(src.TranslatedPrefixes, src.TranslatedPrefixListIds)
+		if err != nil {
+			return err
+		}
+		if len(translated) == 0 {
+			return fmt.Errorf("rule %s: source translation has no translated prefixes", rule.Name)
+		}
+		result.tracef("source %s:%d translated to an address and port allocated from %v, showing the first address",
+			result.Packet.SrcIp, result.Packet.SrcPort, translated)
+		result.Packet.SrcIp = translated[0].Addr()
+		result.Packet.SrcPort = 0
+		result.addSourceRoutes(src, match, translated)
+	}
+
+	if src.TranslationType != "" && src.TranslationType != "NONE" {
+		result.Bidirectional = src.Bidirectional != nil && *src.Bidirectional
+		result.addTracked(src.RoutingOptions)
+	}
+
+	if dst.TranslatedPolicyFqdnListId != 0 {
+		result.TranslatedDstFqdnId = dst.TranslatedPolicyFqdnListId
+		result.tracef("destination %s translated to the FQDNs of policy FQDN list %d", result.Packet.DstIp, dst.TranslatedPolicyFqdnListId)
+	}
+
+	switch dst.TranslationType {
+	case "STATIC_IP", "STATIC_IP_AND_PORT":
+		if dst.TranslatedPolicyFqdnListId == 0 {
+			translated, err := e.prefixes(dst.TranslatedPrefixes, dst.TranslatedPrefixListIds)
+			if err != nil {
+				return err
+			}
+			addr, err := staticTranslate(match.dstPrefixes, match.dstIndex, translated, result.Packet.DstIp)
+			if err != nil {
+				return fmt.Errorf("rule %s: destination translation: %w", rule.Name, err)
+			}
+			result.tracef("destination %s translated to %s", result.Packet.DstIp, addr)
+			result.Packet.DstIp = addr
+		}
+		if dst.TranslationType == "STATIC_IP_AND_PORT" {
+			if err := result.translateDstPort(rule, match, dst.TranslatedPortList); err != nil {
+				return err
+			}
+		}
+
+	case "STATIC_PORT":
+		if err := result.translateDstPort(rule, match, dst.TranslatedPortList); err != nil {
+			return err
+		}
+	}
+
+	if dst.TranslationType != "" && dst.TranslationType != "NONE" {
+		if dst.AdvertiseToConnector != nil && *dst.AdvertiseToConnector {
+			for _, p := range match.dstPrefixes {
+				result.AdvertisedPrefixes = appendMissing(result.AdvertisedPrefixes, []string{p.String()})
+			}
+		}
+		result.Bidirectional = result.Bidirectional || (dst.Bidirectional != nil && *dst.Bidirectional)
+		result.addTracked(dst.RoutingOptions)
+	}
+
+	return nil
+}
+
+// addSourceRoutes advertises the translated source prefixes, so return
+// traffic reaches them, and with match_and_invalidate (the default)
+// invalidates the original source prefixes.
+func (r *natEvaluationResult) addSourceRoutes(src alkira.NatRuleActionSrcTranslation, match *natRuleMatch, translated []netip.Prefix) {
+	for _, p := range translated {
+		r.AdvertisedPrefixes = appendMissing(r.AdvertisedPrefixes, []string{p.String()})
+	}
+
+	if src.MatchAndInvalidate == nil || *src.MatchAndInvalidate {
+		for _, p := range match.srcPrefixes {
+			r.InvalidatedPrefixes = appendMissing(r.InvalidatedPrefixes, []string{p.String()})
+		}
+	}
+}
+
+func (r *natEvaluationResult) addTracked(o alkira.NatRuleRoutingOptions) {
+	r.TrackedPrefixes = appendMissing(r.TrackedPrefixes, o.TrackPrefixes)
+	for _, id := range o.TrackPrefixListIds {
+		r.TrackedPrefixes = appendMissing(r.TrackedPrefixes, []string{fmt.Sprintf("prefix_list:%d", id)})
+	}
+
+	if o.InvalidateRoutingTrackPrefixes != nil && *o.InvalidateRoutingTrackPrefixes {
+		r.InvalidateTracked = true
+	}
+}
+
+// translateDstPort maps the destination port by position from the
+// destination ports of the match to the translated ports, keeping the
+// offset within port ranges.
+func (r *natEvaluationResult) translateDstPort(rule *alkira.NatPolicyRule, match *natRuleMatch, ports []string) error {
+	if len(ports) == 0 || r.Packet.DstPort == 0 {
+		return nil
+	}
+
+	idx, offset := 0, 0
+	if match.dstPortIdx >= 0 {
+		idx, offset = match.dstPortIdx, r.Packet.DstPort-match.dstPortFrom
+	}
+	if idx >= len(ports) {
+		idx = len(ports) - 1
+	}
+
+	from, to, ok := parsePortRange(ports[idx])
+	if !ok {
+		return fmt.Errorf("rule %s: invalid translated port %q", rule.Name, ports[idx])
+	}
+
+	port := from + offset
+	if port > to {
+		port = from
+	}
+
+	r.tracef("destination port %d translated to %d", r.Packet.DstPort, port)
+	r.Packet.DstPort = port
+
+	return nil
+}
+
+// staticTranslate maps addr 1:1 from the matched prefix to the
+// translated prefix at the same position (or the first one), keeping
+// the host offset.
+func staticTranslate(matched []netip.Prefix, idx int, translated []netip.Prefix, addr netip.Addr) (netip.Addr, error) {
+	if len(translated) == 0 {
+		return netip.Addr{}, fmt.Errorf("no translated prefixes")
+	}
+
+	target := translated[0]
+	if idx >= 0 && idx < len(translated) {
+		target = translated[idx]
+	}
+
+	if idx < 0 || !addr.Is4() || !target.Addr().Is4() {
+		return target.Addr(), nil
+	}
+
+	from := matched[idx]
+	offset := ipv4ToUint32(addr) - ipv4ToUint32(from.Masked().Addr())
+	size := uint32(1) << (32 - target.Bits())
+	if target.Bits() == 0 {
+		size = 0
+	}
+	if size != 0 {
+		offset %= size
+	}
+
+	return uint32ToIpv4(ipv4ToUint32(target.Masked().Addr()) + offset), nil
+}
+
+func ipv4ToUint32(a netip.Addr) uint32 {
+	b := a.As4()
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
+
+func uint32ToIpv4(v uint32) netip.Addr {
+	return netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+}
+
+func prefixIndex(prefixes []netip.Prefix, addr netip.Addr) int {
+	for i, p := range prefixes {
+		if p.Contains(addr) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// prefixes returns the given prefixes followed by the prefixes of the
+// prefix lists. A single IP is treated as a host prefix.
+func (e *natEvaluator) prefixes(values []string, listIds []int) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+
+	for _, v := range values {
+		if strings.EqualFold(v, "any") {
+			v = "0.0.0.0/0"
+		}
+		p, err := parsePrefixOrAddr(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+
+	for _, id := range listIds {
+		list, ok := e.prefixLists[id]
+		if !ok {
+			l, err := e.getPrefixList(id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get policy prefix list %d: %w", id, err)
+			}
+			for _, v := range l.Prefixes {
+				p, err := parsePrefixOrAddr(v)
+				if err != nil {
+					return nil, fmt.Errorf("policy prefix list %d: %w", id, err)
+				}
+				list = append(list, p)
+			}
+			e.prefixLists[id] = list
+		}
+		out = append(out, list...)
+	}
+
+	return out, nil
+}
+
+func parsePrefixOrAddr(v string) (netip.Prefix, error) {
+	if strings.Contains(v, "/") {
+		return netip.ParsePrefix(v)
+	}
+
+	addr, err := netip.ParseAddr(v)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parsePortRange parses "443" or "8000-8080".
+func parsePortRange(v string) (int, int, bool) {
+	low, high, isRange := strings.Cut(strings.TrimSpace(v), "-")
+
+	from, err := strconv.Atoi(strings.TrimSpace(low))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	to := from
+	if isRange {
+		if to, err = strconv.Atoi(strings.TrimSpace(high)); err != nil {
+			return 0, 0, false
+		}
+	}
+
+	return from, to, true
+}
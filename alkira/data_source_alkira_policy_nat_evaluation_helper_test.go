@@ -0,0 +1,394 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"testing"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNatEvaluator returns an evaluator over the rules:
+//
+//   - 1 is disabled;
+//   - 2 translates 10.0.0.0/24 and 10.0.1.0/24 1:1 to the prefixes of
+//     prefix list 100 and advertises them;
+//   - 3 forwards tcp/8443 on 203.0.113.10 to 172.16.0.10:443 and tcp
+//     ports 9000-9010 to 10000-10010;
+//   - 4 hides 192.168.0.0/16 behind 198.51.100.0/30.
+func testNatEvaluator() (*natEvaluator, map[string]int) {
+	calls := map[string]int{}
+	yes, no := true, false
+
+	rules := map[int]*alkira.NatPolicyRule{
+		1: {Name: "disabled", Enabled: false, Match: alkira.NatRuleMatch{Protocol: "any"}},
+		2: {Name: "static-src", Enabled: true,
+			Match: alkira.NatRuleMatch{
+				SourcePrefixes: []string{"10.0.0.0/24", "10.0.1.0/24"},
+				DestPrefixes:   []string{"any"},
+				Protocol:       "any",
+			},
+			Action: alkira.NatRuleAction{
+				SourceAddressTranslation: alkira.NatRuleActionSrcTranslation{
+					TranslationType:         "STATIC_IP",
+					TranslatedPrefixListIds: []int{100},
+					Bidirectional:           &yes,
+					RoutingOptions: alkira.NatRuleRoutingOptions{
+						TrackPrefixes:                  []string{"10.0.0.0/16"},
+						InvalidateRoutingTrackPrefixes: &yes,
+					},
+				},
+				Egress: alkira.EgressAction{IpType: "OVERLAY"},
+			},
+		},
+		3: {Name: "port-forward", Enabled: true,
+			Match: alkira.NatRuleMatch{
+				DestPrefixes: []string{"203.0.113.10/32"},
+				DestPortList: []string{"8443", "9000-9010"},
+				Protocol:     "tcp",
+			},
+			Action: alkira.NatRuleAction{
+				DestinationAddressTranslation: alkira.NatRuleActionDstTranslation{
+					TranslationType:      "STATIC_IP_AND_PORT",
+					TranslatedPrefixes:   []string{"172.16.0.10/32"},
+					TranslatedPortList:   []string{"443", "10000-10010"},
+					AdvertiseToConnector: &yes,
+				},
+			},
+		},
+		4: {Name: "hide", Enabled: true,
+			Match: alkira.NatRuleMatch{
+				SourcePrefixes: []string{"192.168.0.0/16"},
+				Protocol:       "any",
+			},
+			Action: alkira.NatRuleAction{
+				SourceAddressTranslation: alkira.NatRuleActionSrcTranslation{
+					TranslationType:    "DYNAMIC_IP_AND_PORT",
+					TranslatedPrefixes: []string{"198.51.100.0/30"},
+					MatchAndInvalidate: &no,
+				},
+			},
+		},
+	}
+
+	prefixLists := map[int]*alkira.PolicyPrefixList{
+		100: {Prefixes: []string{"100.64.0.0/24", "100.64.1.0/24"}},
+	}
+
+	evaluator := newNatEvaluator(
+		func(id int) (*alkira.NatPolicyRule, error) {
+			calls[fmt.Sprintf("rule/%d", id)]++
+			if r, ok := rules[id]; ok {
+				return r, nil
+			}
+			return nil, fmt.Errorf("not found")
+		},
+		func(id int) (*alkira.PolicyPrefixList, error) {
+			calls[fmt.Sprintf("prefixlist/%d", id)]++
+			if l, ok := prefixLists[id]; ok {
+				return l, nil
+			}
+			return nil, fmt.Errorf("not found")
+		},
+	)
+
+	return evaluator, calls
+}
+
+func testNatPolicy() *alkira.NatPolicy {
+	return &alkira.NatPolicy{
+		Name:           "nat",
+		Segment:        "corp",
+		IncludedGroups: []int{7, 8},
+		ExcludedGroups: []int{9},
+		NatRuleIds:     []int{1, 2, 3, 4},
+	}
+}
+
+func testNatPacket(src, dst, protocol string, srcPort, dstPort int) natEvaluationPacket {
+	return natEvaluationPacket{
+		SrcIp:    netip.MustParseAddr(src),
+		DstIp:    netip.MustParseAddr(dst),
+		Protocol: protocol,
+		SrcPort:  srcPort,
+		DstPort:  dstPort,
+	}
+}
+
+func TestNatEvaluatorEvaluate(t *testing.T) {
+	tests := []struct {
+		name            string
+		packet          natEvaluationPacket
+		wantRule        string
+		wantSrc         string
+		wantSrcPort     int
+		wantDst         string
+		wantDstPort     int
+		wantAdvertised  []string
+		wantInvalidated []string
+	}{
+		{
+			name:            "static source keeps the host offset",
+			packet:          testNatPacket("10.0.1.25", "8.8.8.8", "udp", 5353, 53),
+			wantRule:        "static-src",
+			wantSrc:         "100.64.1.25",
+			wantSrcPort:     5353,
+			wantDst:         "8.8.8.8",
+			wantDstPort:     53,
+			wantAdvertised:  []string{"100.64.0.0/24", "100.64.1.0/24"},
+			wantInvalidated: []string{"10.0.0.0/24", "10.0.1.0/24"},
+		},
+		{
+			name:           "single port forward",
+			packet:         testNatPacket("172.31.0.1", "203.0.113.10", "tcp", 40000, 8443),
+			wantRule:       "port-forward",
+			wantSrc:        "172.31.0.1",
+			wantSrcPort:    40000,
+			wantDst:        "172.16.0.10",
+			wantDstPort:    443,
+			wantAdvertised: []string{"203.0.113.10/32"},
+		},
+		{
+			name:           "port range forward keeps the offset",
+			packet:         testNatPacket("172.31.0.1", "203.0.113.10", "tcp", 40000, 9005),
+			wantRule:       "port-forward",
+			wantSrc:        "172.31.0.1",
+			wantSrcPort:    40000,
+			wantDst:        "172.16.0.10",
+			wantDstPort:    10005,
+			wantAdvertised: []string{"203.0.113.10/32"},
+		},
+		{
+			name:        "udp is not forwarded",
+			packet:      testNatPacket("172.31.0.1", "203.0.113.10", "udp", 40000, 8443),
+			wantSrc:     "172.31.0.1",
+			wantSrcPort: 40000,
+			wantDst:     "203.0.113.10",
+			wantDstPort: 8443,
+		},
+		{
+			name:           "dynamic source port is allocated at runtime",
+			packet:         testNatPacket("192.168.5.5", "1.1.1.1", "tcp", 51000, 443),
+			wantRule:       "hide",
+			wantSrc:        "198.51.100.0",
+			wantDst:        "1.1.1.1",
+			wantDstPort:    443,
+			wantAdvertised: []string{"198.51.100.0/30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator, _ := testNatEvaluator()
+
+			result, err := evaluator.evaluate(testNatPolicy(), tt.packet)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantRule != "", result.Matched)
+			if tt.wantRule != "" {
+				assert.Equal(t, tt.wantRule, result.Rule.Name)
+			}
+			assert.Equal(t, tt.wantSrc, result.Packet.SrcIp.String())
+			assert.Equal(t, tt.wantSrcPort, result.Packet.SrcPort)
+			assert.Equal(t, tt.wantDst, result.Packet.DstIp.String())
+			assert.Equal(t, tt.wantDstPort, result.Packet.DstPort)
+			assert.Equal(t, tt.wantAdvertised, result.AdvertisedPrefixes)
+			assert.Equal(t, tt.wantInvalidated, result.InvalidatedPrefixes)
+		})
+	}
+}
+
+func TestNatEvaluatorRoutingOptions(t *testing.T) {
+	evaluator, calls := testNatEvaluator()
+
+	result, err := evaluator.evaluate(testNatPolicy(), testNatPacket("10.0.0.1", "8.8.8.8", "icmp", 0, 0))
+	require.NoError(t, err)
+	require.True(t, result.Matched)
+
+	assert.True(t, result.Bidirectional)
+	assert.True(t, result.InvalidateTracked)
+	assert.Equal(t, []string{"10.0.0.0/16"}, result.TrackedPrefixes)
+	assert.Equal(t, []string{
+		"rule disabled (1): skipped, rule is disabled",
+		"rule static-src (2): matched",
+		"source 10.0.0.1 translated to 100.64.0.1",
+	}, result.Trace)
+
+	_, err = evaluator.evaluate(testNatPolicy(), testNatPacket("10.0.0.2", "8.8.8.8", "icmp", 0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls["prefixlist/100"])
+}
+
+func TestNatEvaluatorPolicyScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		segment string
+		group   int
+		matched bool
+	}{
+		{"no context", "", 0, true},
+		{"included group", "corp", 7, true},
+		{"other segment", "guest", 7, false},
+		{"excluded group", "corp", 9, false},
+		{"group not included", "corp", 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator, _ := testNatEvaluator()
+
+			packet := testNatPacket("10.0.0.1", "8.8.8.8", "tcp", 1024, 443)
+			packet.Segment, packet.GroupId = tt.segment, tt.group
+
+			result, err := evaluator.evaluate(testNatPolicy(), packet)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matched, result.Matched)
+			assert.NotEmpty(t, result.Trace)
+		})
+	}
+}
+
+func TestNatEvaluatorMissingRule(t *testing.T) {
+	evaluator, _ := testNatEvaluator()
+
+	policy := testNatPolicy()
+	policy.NatRuleIds = []int{5}
+
+	_, err := evaluator.evaluate(policy, testNatPacket("10.0.0.1", "8.8.8.8", "tcp", 1024, 443))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NAT rule 5")
+}
+
+func TestStaticTranslate(t *testing.T) {
+	matched := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24"), netip.MustParsePrefix("10.0.1.0/24")}
+
+	tests := []struct {
+		name       string
+		idx        int
+		translated []string
+		addr       string
+		want       string
+	}{
+		{"same position", 1, []string{"100.64.0.0/24", "100.64.1.0/24"}, "10.0.1.7", "100.64.1.7"},
+		{"falls back to the first prefix", 1, []string{"100.64.0.0/24"}, "10.0.1.7", "100.64.0.7"},
+		{"host prefix", 0, []string{"192.0.2.1/32"}, "10.0.0.7", "192.0.2.1"},
+		{"no matched prefix", -1, []string{"192.0.2.0/24"}, "10.0.0.7", "192.0.2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var translated []netip.Prefix
+			for _, p := range tt.translated {
+				translated = append(translated, netip.MustParsePrefix(p))
+			}
+
+			got, err := staticTranslate(matched, tt.idx, translated, netip.MustParseAddr(tt.addr))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+
+	_, err := staticTranslate(matched, 0, nil, netip.MustParseAddr("10.0.0.1"))
+	require.Error(t, err)
+}
+
+func TestPolicyNatEvaluationPassedRules(t *testing.T) {
+	// The rules passed in are evaluated without reading any policy.
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	rules := []interface{}{
+		map[string]interface{}{
+			"id":      "31",
+			"name":    "disabled",
+			"enabled": false,
+			"match":   []interface{}{map[string]interface{}{"protocol": "any"}},
+			"action":  []interface{}{map[string]interface{}{"src_addr_translation_type": "NONE"}},
+		},
+		map[string]interface{}{
+			"name":    "hide",
+			"enabled": true,
+			"match": []interface{}{map[string]interface{}{
+				"src_prefixes": []interface{}{"192.168.0.0/16"},
+				"dst_prefixes": []interface{}{"any"},
+				"protocol":     "any",
+			}},
+			"action": []interface{}{map[string]interface{}{
+				"src_addr_translation_type":     "DYNAMIC_IP_AND_PORT",
+				"src_addr_translation_prefixes": []interface{}{"198.51.100.0/30"},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		config     map[string]interface{}
+		wantRule   string
+		wantRuleId string
+		wantSrcIp  string
+	}{
+		{
+			name: "planned rule",
+			config: map[string]interface{}{
+				"rule": rules, "src_ip": "192.168.1.1", "dst_ip": "203.0.113.1", "protocol": "tcp",
+			},
+			wantRule:  "hide",
+			wantSrcIp: "198.51.100.0",
+		},
+		{
+			name: "no rule matches",
+			config: map[string]interface{}{
+				"rule": rules, "src_ip": "10.0.0.1", "dst_ip": "203.0.113.1", "protocol": "tcp",
+			},
+			wantSrcIp: "10.0.0.1",
+		},
+		{
+			name: "excluded group",
+			config: map[string]interface{}{
+				"rule": rules, "src_ip": "192.168.1.1", "dst_ip": "203.0.113.1", "protocol": "tcp",
+				"group_id": 9, "excluded_group_ids": []interface{}{9},
+			},
+			wantSrcIp: "192.168.1.1",
+		},
+		{
+			name: "rule ID",
+			config: map[string]interface{}{
+				"rule": []interface{}{
+					map[string]interface{}{
+						"id":      "32",
+						"name":    "any",
+						"enabled": true,
+						"match":   []interface{}{map[string]interface{}{"protocol": "any"}},
+						"action":  []interface{}{map[string]interface{}{"src_addr_translation_type": "NONE"}},
+					},
+				},
+				"src_ip": "10.0.0.1", "dst_ip": "203.0.113.1", "protocol": "tcp",
+			},
+			wantRule:   "any",
+			wantRuleId: "32",
+			wantSrcIp:  "10.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := dataSourceAlkiraPolicyNatEvaluation()
+			d := schema.TestResourceDataRaw(t, r.Schema, tt.config)
+
+			diags := r.ReadContext(context.Background(), d, client)
+			require.False(t, diags.HasError(), "%v", diags)
+
+			assert.Equal(t, tt.wantRule != "", d.Get("matched"))
+			assert.Equal(t, tt.wantRule, d.Get("rule_name"))
+			assert.Equal(t, tt.wantRuleId, d.Get("rule_id"))
+			assert.Equal(t, tt.wantSrcIp, d.Get("translated_src_ip"))
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package alkira
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAlkiraPolicyRoutingEvaluation() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to run a candidate route through " +
+			"the rules of an `alkira_policy_routing` or " +
+			"`alkira_policy_inter_cxp_routing` without provisioning " +
+			"anything.\n\n" +
+			"Rules are evaluated by ascending `sequence_no` and the first " +
+			"matching rule decides. A rule matches when every configured " +
+			"match condition matches; within a condition one matching " +
+			"list is enough. A community or extended community list " +
+			"matches when all of its values are present on the route.\n\n" +
+			"The rules are either passed in with `rule` or `inter_cxp_rule`, " +
+			"e.g. `rule = alkira_policy_routing.example.rule`, which " +
+			"evaluates the planned rules before they are applied, or read " +
+			"from the policy of `policy_routing_id` or " +
+			"`policy_inter_cxp_routing_id`.",
+
+		Read: dataSourceAlkiraPolicyRoutingEvaluationRead,
+
+		Schema: map[string]*schema.Schema{
+			"rule": {
+				Description: "The rules of an `alkira_policy_routing` to " +
+					"evaluate, in the same format. Rules without " +
+					"`sequence_no` are evaluated in the given order.",
+				Type:       schema.TypeList,
+				Optional:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
+				Elem:       evaluationRuleElem(resourceAlkiraPolicyRouting().Schema["rule"].Elem.(*schema.Resource).Schema),
+				ExactlyOneOf: []string{"rule", "inter_cxp_rule",
+					"policy_routing_id", "policy_inter_cxp_routing_id"},
+			},
+			"inter_cxp_rule": {
+				Description: "The rules of an `alkira_policy_inter_cxp_routing` " +
+					"to evaluate, in the same format. Rules without " +
+					"`sequence_no` are evaluated in the given order.",
+				Type:       schema.TypeList,
+				Optional:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
+				Elem:       evaluationRuleElem(resourceAlkiraPolicyInterCxpRouting().Schema["rule"].Elem.(*schema.Resource).Schema),
+			},
+			"source_cxps": {
+				Description: "The source CXPs of the inter-CXP routing " +
+					"policy of `inter_cxp_rule`. Routes from other CXPs " +
+					"get the default action.",
+				Type:         schema.TypeList,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				Optional:     true,
+				RequiredWith: []string{"inter_cxp_rule"},
+			},
+			"policy_routing_id": {
+				Description: "The ID of the `alkira_policy_routing` whose " +
+					"rules to evaluate.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"policy_inter_cxp_routing_id": {
+				Description: "The ID of the `alkira_policy_inter_cxp_routing` " +
+					"whose rules to evaluate.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"prefix": {
+				Description:  "The prefix of the route, e.g. `10.1.0.0/16`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsCIDR,
+			},
+			"as_path": {
+				Description: "The AS path of the route as space separated " +
+					"AS numbers, e.g. `65001 65002`.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"communities": {
+				Description: "The communities of the route in `AA:NN` format.",
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+			},
+			"extended_communities": {
+				Description: "The extended communities of the route, e.g. " +
+					"`soo:65512:100`.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			"med": {
+				Description: "The MED of the route.",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			"connector_group_id": {
+				Description: "The ID of the group of the connector the " +
+					"route comes from.",
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"segment_resource_id": {
+				Description: "The ID of the segment resource the route " +
+					"comes from.",
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"cxp": {
+				Description: "The CXP the route comes from.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"segment_asn": {
+				Description: "The ASN of the segment, used by " +
+					"`set_as_path_replace_with_segment_asn`.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"default_action": {
+				Description: "The action reported when no rule matches, " +
+					"either `ALLOW` or `DENY`. The default value is `DENY`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "DENY",
+				ValidateFunc: validation.StringInSlice([]string{"ALLOW", "DENY"}, false),
+			},
+			"matched": {
+				Description: "Whether a rule matched the route.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"allowed": {
+				Description: "Whether the route is allowed.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"action": {
+				Description: "The resulting action, `ALLOW`, `DENY` or " +
+					"`ALLOW_W_SET`.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"rule_name": {
+				Description: "The name of the matching rule.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"rule_sequence_no": {
+				Description: "The sequence number of the matching rule.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"result_as_path": {
+				Description: "The AS path after the set actions.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"result_communities": {
+				Description: "The communities after the set actions.",
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
+			"result_extended_communities": {
+				Description: "The extended communities after the set actions.",
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
+			"result_med": {
+				Description: "The MED after the set actions.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"routes_distribution_type": {
+				Description: "The inter-CXP redistribution of the matching " +
+					"rule of an `alkira_policy_routing`.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"routes_distribution_restricted_cxps": {
+				Description: "The CXPs the route is redistributed to with " +
+					"`RESTRICTED_CXPS`.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"trace": {
+				Description: "The evaluation steps, explaining why rules " +
+					"were skipped.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraPolicyRoutingEvaluationRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*alkira.AlkiraClient)
+
+	_, prefix, err := net.ParseCIDR(d.Get("prefix").(string))
+	if err != nil {
+		return err
+	}
+
+	asPath, err := parseAsPath(d.Get("as_path").(string))
+	if err != nil {
+		return err
+	}
+
+	route := routeEvaluationRoute{
+		Prefix:              prefix,
+		AsPath:              asPath,
+		Communities:         convertTypeListToStringList(d.Get("communities").([]interface{})),
+		ExtendedCommunities: convertTypeListToStringList(d.Get("extended_communities").([]interface{})),
+		Med:                 d.Get("med").(int),
+		ConnectorGroupId:    d.Get("connector_group_id").(int),
+		SegmentResourceId:   d.Get("segment_resource_id").(int),
+		Cxp:                 d.Get("cxp").(string),
+		SegmentAsn:          d.Get("segment_asn").(string),
+	}
+
+	rules, policyId, mismatch, err := routingEvaluationRules(d, client, route)
+	if err != nil {
+		return err
+	}
+
+	defaultAction := d.Get("default_action").(string)
+
+	var result *routeEvaluationResult
+
+	if mismatch != "" {
+		result = &routeEvaluationResult{Action: defaultAction, Route: route}
+		result.tracef("%s, default action %s", mismatch, defaultAction)
+	} else {
+		evaluator := newRouteEvaluator(
+			func(id int) (*alkira.PolicyPrefixList, error) {
+				list, _, err := alkira.NewPolicyPrefixList(client).GetById(strconv.Itoa(id))
+				return list, err
+			},
+			func(id int) (*alkira.List, error) {
+				list, _, err := alkira.NewListAsPath(client).GetById(strconv.Itoa(id))
+				return list, err
+			},
+			func(id int) (*alkira.List, error) {
+				list, _, err := alkira.NewListCommunity(client).GetById(strconv.Itoa(id))
+				return list, err
+			},
+			func(id int) (*alkira.List, error) {
+				list, _, err := alkira.NewListExtendedCommunity(client).GetById(strconv.Itoa(id))
+				return list, err
+			},
+		)
+
+		result, err = evaluator.evaluate(rules, route, defaultAction)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s-%s", policyId, prefix))
+	d.Set("matched", result.Matched)
+	d.Set("allowed", result.Action != "DENY")
+	d.Set("action", result.Action)
+	d.Set("result_as_path", strings.Join(result.Route.AsPath, " "))
+	d.Set("result_communities", result.Route.Communities)
+	d.Set("result_extended_communities", result.Route.ExtendedCommunities)
+	d.Set("result_med", result.Route.Med)
+	d.Set("trace", result.Trace)
+
+	d.Set("rule_name", "")
+	d.Set("rule_sequence_no", 0)
+	if result.Rule != nil {
+		d.Set("rule_name", result.Rule.Name)
+		d.Set("rule_sequence_no", result.Rule.SequenceNo)
+	}
+
+	d.Set("routes_distribution_type", "")
+	d.Set("routes_distribution_restricted_cxps", nil)
+	if r := result.Redistribution; r != nil {
+		d.Set("routes_distribution_type", r.DistributionType)
+		d.Set("routes_distribution_restricted_cxps", r.RestrictedCxps)
+	}
+
+	return nil
+}
+
+// routingEvaluationRules returns the rules to evaluate, either passed in
+// or read from a policy, the ID of the evaluation and why the route
+// doesn't reach the rules at all, if it doesn't.
+func routingEvaluationRules(d *schema.ResourceData, client *alkira.AlkiraClient, route routeEvaluationRoute) ([]alkira.RoutePolicyRules, string, string, error) {
+	if in, ok := d.GetOk("rule"); ok {
+		rules, err := expandPolicyRoutingRule(in.([]interface{}))
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		return evaluationSequenceNos(rules, in.([]interface{})), "rules", "", nil
+	}
+
+	if in, ok := d.GetOk("inter_cxp_rule"); ok {
+		interCxpRules, err := expandPolicyInterCxpRoutingRule(in.([]interface{}))
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		var mismatch string
+		sourceCxps := convertTypeListToStringList(d.Get("source_cxps").([]interface{}))
+		if route.Cxp != "" && len(sourceCxps) > 0 && !stringInSlice(route.Cxp, sourceCxps) {
+			mismatch = fmt.Sprintf("CXP %q is not a source CXP %v", route.Cxp, sourceCxps)
+		}
+
+		rules := routePolicyRulesFromInterCxp(interCxpRules)
+		return evaluationSequenceNos(rules, in.([]interface{})), "rules", mismatch, nil
+	}
+
+	if id, ok := d.GetOk("policy_routing_id"); ok {
+		policy, _, err := alkira.NewRoutePolicy(client).GetById(id.(string))
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		var mismatch string
+		if !policy.Enabled {
+			mismatch = fmt.Sprintf("policy %s is disabled", policy.Name)
+		}
+
+		return policy.Rules, id.(string), mismatch, nil
+	}
+
+	id := d.Get("policy_inter_cxp_routing_id").(string)
+
+	policy, _, err := alkira.NewInterCxpRoutePolicy(client).GetById(id)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	var mismatch string
+	switch {
+	case !policy.Enabled:
+		mismatch = fmt.Sprintf("policy %s is disabled", policy.Name)
+	case route.Cxp != "" && len(policy.SourceCxps) > 0 && !stringInSlice(route.Cxp, policy.SourceCxps):
+		mismatch = fmt.Sprintf("CXP %q is not a source CXP %v of policy %s", route.Cxp, policy.SourceCxps, policy.Name)
+	}
+
+	return routePolicyRulesFromInterCxp(policy.Rules), id, mismatch, nil
+}
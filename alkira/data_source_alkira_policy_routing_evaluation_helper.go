@@ -0,0 +1,486 @@
+package alkira
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// routeEvaluationRoute is the candidate route evaluated by
+// alkira_policy_routing_evaluation together with where it comes from.
+type routeEvaluationRoute struct {
+	Prefix              *net.IPNet
+	AsPath              []string
+	Communities         []string
+	ExtendedCommunities []string
+	Med                 int
+	ConnectorGroupId    int
+	SegmentResourceId   int
+	Cxp                 string
+	SegmentAsn          string
+}
+
+// routeEvaluationResult is the outcome of an evaluation. Route holds the
+// attributes after the set actions of the matching rule.
+type routeEvaluationResult struct {
+	Matched        bool
+	Rule           *alkira.RoutePolicyRules
+	Action         string
+	Route          routeEvaluationRoute
+	Trace          []string
+	Redistribution *alkira.RoutePolicyRulesInterCxpRoutesRedistribution
+}
+
+func (r *routeEvaluationResult) tracef(format string, args ...interface{}) {
+	r.Trace = append(r.Trace, fmt.Sprintf(format, args...))
+}
+
+// routeEvaluator runs a route through the rules of a routing policy.
+// Lists referenced by the rules are loaded on demand and cached.
+type routeEvaluator struct {
+	getPrefixList          func(id int) (*alkira.PolicyPrefixList, error)
+	getAsPathList          func(id int) (*alkira.List, error)
+	getCommunityList       func(id int) (*alkira.List, error)
+	getExtCommunityList    func(id int) (*alkira.List, error)
+	prefixLists            map[int]*alkira.PolicyPrefixList
+	asPathLists            map[int][]*regexp.Regexp
+	communityLists         map[int][]string
+	extendedCommunityLists map[int][]string
+}
+
+func newRouteEvaluator(
+	getPrefixList func(id int) (*alkira.PolicyPrefixList, error),
+	getAsPathList func(id int) (*alkira.List, error),
+	getCommunityList func(id int) (*alkira.List, error),
+	getExtCommunityList func(id int) (*alkira.List, error),
+) *routeEvaluator {
+	return &routeEvaluator{
+		getPrefixList:          getPrefixList,
+		getAsPathList:          getAsPathList,
+		getCommunityList:       getCommunityList,
+		getExtCommunityList:    getExtCommunityList,
+		prefixLists:            make(map[int]*alkira.PolicyPrefixList),
+		asPathLists:            make(map[int][]*regexp.Regexp),
+		communityLists:         make(map[int][]string),
+		extendedCommunityLists: make(map[int][]string),
+	}
+}
+
+// evaluationRuleElem returns the schema of the rules passed in to an
+// evaluation from the schema of the rules of a resource. Its computed
+// attributes, such as sequence_no, are optional, so that the rules of a
+// resource can be passed in as a whole, and its blocks are attributes
+// like the rules themselves. Constraints between attributes are left to
+// the resource, their paths don't resolve in a nested schema.
+func evaluationRuleElem(in map[string]*schema.Schema) *schema.Resource {
+	rule := make(map[string]*schema.Schema, len(in))
+	for name, s := range in {
+		attribute := *s
+		attribute.ConflictsWith = nil
+		attribute.ExactlyOneOf = nil
+		attribute.AtLeastOneOf = nil
+		attribute.RequiredWith = nil
+		if attribute.Computed && !attribute.Optional {
+			attribute.Optional = true
+		}
+		if elem, ok := attribute.Elem.(*schema.Resource); ok {
+			attribute.ConfigMode = schema.SchemaConfigModeAttr
+			attribute.Elem = evaluationRuleElem(elem.Schema)
+		}
+		rule[name] = &attribute
+	}
+
+	return &schema.Resource{Schema: rule}
+}
+
+// evaluationSequenceNos sets the sequence numbers of the rules passed
+// in to an evaluation from their sequence_no. Rules without one, e.g.
+// the planned rules of a policy, are numbered after the rule before
+// them starting at 1000, which keeps them in the given order.
+func evaluationSequenceNos(rules []alkira.RoutePolicyRules, in []interface{}) []alkira.RoutePolicyRules {
+	next := 1000
+
+	for i := range rules {
+		if v, ok := in[i].(map[string]interface{})["sequence_no"].(int); ok && v > 0 {
+			rules[i].SequenceNo = v
+		} else {
+			rules[i].SequenceNo = next
+		}
+		next = rules[i].SequenceNo + 1
+	}
+
+	return rules
+}
+
+// routePolicyRulesFromInterCxp converts the rules of an inter-CXP
+// routing policy to the rules of a routing policy, of which they are a
+// subset.
+func routePolicyRulesFromInterCxp(rules []alkira.InterCxpRoutePolicyRule) []alkira.RoutePolicyRules {
+	out := make([]alkira.RoutePolicyRules, 0, len(rules))
+
+	for _, r := range rules {
+		rule := alkira.RoutePolicyRules{
+			Action:     r.Action,
+			Name:       r.Name,
+			SequenceNo: r.SequenceNo,
+			Match: alkira.RoutePolicyRulesMatch{
+				All:                      r.Match.All,
+				AsPathListIds:            r.Match.AsPathListIds,
+				CommunityListIds:         r.Match.CommunityListIds,
+				ExtendedCommunityListIds: r.Match.ExtendedCommunityListIds,
+				PrefixListIds:            r.Match.PrefixListIds,
+				SegmentResourceIds:       r.Match.SegmentResourceIds,
+				ConnectorGroupIds:        r.Match.ConnectorGroupIds,
+			},
+		}
+
+		if r.Set != nil {
+			rule.Set = &alkira.RoutePolicyRulesSet{
+				AsPathPrepend:     r.Set.AsPathPrepend,
+				Community:         r.Set.Community,
+				ExtendedCommunity: r.Set.ExtendedCommunity,
+			}
+		}
+
+		out = append(out, rule)
+	}
+
+	return out
+}
+
+// evaluate runs the route through the rules ordered by sequence_no.
+// The first matching rule decides: DENY drops the route, ALLOW accepts
+// it unchanged and ALLOW_W_SET accepts it with the set actions applied.
+// A route matching no rule gets defaultAction.
+func (e *routeEvaluator) evaluate(rules []alkira.RoutePolicyRules, route routeEvaluationRoute, defaultAction string) (*routeEvaluationResult, error) {
+	result := &routeEvaluationResult{Action: defaultAction, Route: route}
+
+	ordered := make([]alkira.RoutePolicyRules, len(rules))
+	copy(ordered, rules)
+	sort.SliceStable(ordered, func(a, b int) bool {
+		return ordered[a].SequenceNo < ordered[b].SequenceNo
+	})
+
+	for i := range ordered {
+		rule := &ordered[i]
+
+		reason, err := e.ruleMismatch(rule, route)
+		if err != nil {
+			return nil, err
+		}
+
+		if reason != "" {
+			result.tracef("rule %s (%d): skipped, %s", rule.Name, rule.SequenceNo, reason)
+			continue
+		}
+
+		result.tracef("rule %s (%d): matched, action %s", rule.Name, rule.SequenceNo, rule.Action)
+
+		result.Matched = true
+		result.Rule = rule
+		result.Action = rule.Action
+		result.Redistribution = rule.InterCxpRoutesRedistribution
+
+		if rule.Action == "ALLOW_W_SET" && rule.Set != nil {
+			result.Route = applyRouteSet(rule.Set, route, result)
+		}
+
+		return result, nil
+	}
+
+	result.tracef("no rule matched, default action %s", defaultAction)
+
+	return result, nil
+}
+
+// ruleMismatch returns why the rule doesn't match the route or an empty
+// string if it does. Every configured match condition has to match;
+// within a condition a single matching list is enough.
+func (e *routeEvaluator) ruleMismatch(rule *alkira.RoutePolicyRules, route routeEvaluationRoute) (string, error) {
+	m := rule.Match
+
+	if m.All {
+		return "", nil
+	}
+
+	if len(m.PrefixListIds) > 0 {
+		ok, err := e.anyList(m.PrefixListIds, func(id int) (bool, error) { return e.prefixListMatches(id, route.Prefix) })
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return fmt.Sprintf("prefix %s is not in prefix lists %v", route.Prefix, m.PrefixListIds), nil
+		}
+	}
+
+	if len(m.AsPathListIds) > 0 {
+		ok, err := e.anyList(m.AsPathListIds, func(id int) (bool, error) { return e.asPathListMatches(id, route.AsPath) })
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return fmt.Sprintf("AS path %q does not match AS path lists %v", strings.Join(route.AsPath, " "), m.AsPathListIds), nil
+		}
+	}
+
+	if len(m.CommunityListIds) > 0 {
+		ok, err := e.anyList(m.CommunityListIds, func(id int) (bool, error) {
+			return e.valueListMatches(e.communityLists, e.getCommunityList, "community", id, route.Communities)
+		})
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return fmt.Sprintf("communities %v do not match community lists %v", route.Communities, m.CommunityListIds), nil
+		}
+	}
+
+	if len(m.ExtendedCommunityListIds) > 0 {
+		ok, err := e.anyList(m.ExtendedCommunityListIds, func(id int) (bool, error) {
+			return e.valueListMatches(e.extendedCommunityLists, e.getExtCommunityList, "extended community", id, route.ExtendedCommunities)
+		})
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return fmt.Sprintf("extended communities %v do not match extended community lists %v", route.ExtendedCommunities, m.ExtendedCommunityListIds), nil
+		}
+	}
+
+	if len(m.ConnectorGroupIds) > 0 && !intInSlice(route.ConnectorGroupId, m.ConnectorGroupIds) {
+		return fmt.Sprintf("connector group %d is not in %v", route.ConnectorGroupId, m.ConnectorGroupIds), nil
+	}
+
+	if len(m.SegmentResourceIds) > 0 && !intInSlice(route.SegmentResourceId, m.SegmentResourceIds) {
+		return fmt.Sprintf("segment resource %d is not in %v", route.SegmentResourceId, m.SegmentResourceIds), nil
+	}
+
+	if len(m.Cxps) > 0 && !stringInSlice(route.Cxp, m.Cxps) {
+		return fmt.Sprintf("CXP %q is not in %v", route.Cxp, m.Cxps), nil
+	}
+
+	return "", nil
+}
+
+func (e *routeEvaluator) anyList(ids []int, match func(id int) (bool, error)) (bool, error) {
+	for _, id := range ids {
+		ok, err := match(id)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+// prefixListMatches matches the route prefix exactly against the
+// prefixes of the list and against its ranges with ge/le semantics.
+func (e *routeEvaluator) prefixListMatches(id int, prefix *net.IPNet) (bool, error) {
+	list, ok := e.prefixLists[id]
+	if !ok {
+		var err error
+		if list, err = e.getPrefixList(id); err != nil {
+			return false, fmt.Errorf("failed to get policy prefix list %d: %w", id, err)
+		}
+		e.prefixLists[id] = list
+	}
+
+	for _, p := range list.Prefixes {
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return false, fmt.Errorf("invalid prefix %q in policy prefix list %d: %w", p, id, err)
+		}
+		if n.String() == prefix.String() {
+			return true, nil
+		}
+	}
+
+	for _, r := range list.PrefixRanges {
+		ok, err := prefixRangeMatches(r, prefix)
+		if err != nil {
+			return false, fmt.Errorf("invalid prefix range %q in policy prefix list %d: %w", r.Prefix, id, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// prefixRangeMatches reports whether prefix is covered by the range
+// prefix and its length is within ge and le. Without ge and le only the
+// range prefix itself matches; with only ge every longer prefix does.
+func prefixRangeMatches(r alkira.PolicyPrefixListRange, prefix *net.IPNet) (bool, error) {
+	_, n, err := net.ParseCIDR(r.Prefix)
+	if err != nil {
+		return false, err
+	}
+
+	rangeLen, bits := n.Mask.Size()
+	length, prefixBits := prefix.Mask.Size()
+
+	if bits != prefixBits || !n.Contains(prefix.IP) || length < rangeLen {
+		return false, nil
+	}
+
+	min, max := rangeLen, rangeLen
+	if r.Ge > 0 {
+		min, max = r.Ge, bits
+	}
+	if r.Le > 0 {
+		max = r.Le
+	}
+
+	return length >= min && length <= max, nil
+}
+
+// asPathListMatches matches the AS path against the values of the list.
+// A value is a BGP regular expression, where "_" matches a delimiter,
+// or a plain sequence of AS numbers.
+func (e *routeEvaluator) asPathListMatches(id int, asPath []string) (bool, error) {
+	exprs, ok := e.asPathLists[id]
+	if !ok {
+		list, err := e.getAsPathList(id)
+		if err != nil {
+			return false, fmt.Errorf("failed to get AS path list %d: %w", id, err)
+		}
+
+		for _, v := range list.Values {
+			re, err := compileAsPathRegexp(v)
+			if err != nil {
+				return false, fmt.Errorf("invalid value %q in AS path list %d: %w", v, id, err)
+			}
+			exprs = append(exprs, re)
+		}
+		e.asPathLists[id] = exprs
+	}
+
+	path := strings.Join(asPath, " ")
+	for _, re := range exprs {
+		if re.MatchString(path) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+var asPathSequenceRegex = regexp.MustCompile(`^[0-9]+( [0-9]+)*$`)
+
+func compileAsPathRegexp(v string) (*regexp.Regexp, error) {
+	v = strings.TrimSpace(v)
+
+	if asPathSequenceRegex.MatchString(v) {
+		return regexp.Compile(`(^| )` + regexp.QuoteMeta(v) + `( |$)`)
+	}
+
+	return regexp.Compile(strings.ReplaceAll(v, "_", `(^|$|[ ,{}()])`))
+}
+
+// valueListMatches reports whether every value of a community or
+// extended community list is present on the route.
+func (e *routeEvaluator) valueListMatches(cache map[int][]string, get func(id int) (*alkira.List, error), kind string, id int, values []string) (bool, error) {
+	list, ok := cache[id]
+	if !ok {
+		l, err := get(id)
+		if err != nil {
+			return false, fmt.Errorf("failed to get %s list %d: %w", kind, id, err)
+		}
+		list = l.Values
+		cache[id] = list
+	}
+
+	if len(list) == 0 {
+		return false, nil
+	}
+
+	for _, want := range list {
+		found := false
+		for _, v := range values {
+			if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(want)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// applyRouteSet returns the route with the set actions of a rule
+// applied.
+func applyRouteSet(set *alkira.RoutePolicyRulesSet, route routeEvaluationRoute, result *routeEvaluationResult) routeEvaluationRoute {
+	out := route
+	out.AsPath = append([]string{}, route.AsPath...)
+	out.Communities = append([]string{}, route.Communities...)
+	out.ExtendedCommunities = append([]string{}, route.ExtendedCommunities...)
+
+	if set.AsPathReplaceWithSegmentAsn != "" {
+		if route.SegmentAsn == "" {
+			result.tracef("set_as_path_replace_with_segment_asn %q not applied, segment_asn is not set", set.AsPathReplaceWithSegmentAsn)
+		} else {
+			replace := strings.Split(set.AsPathReplaceWithSegmentAsn, ",")
+			for i, asn := range out.AsPath {
+				if strings.EqualFold(set.AsPathReplaceWithSegmentAsn, "ALL") || stringInSlice(asn, trimAll(replace)) {
+					out.AsPath[i] = route.SegmentAsn
+				}
+			}
+		}
+	}
+
+	if set.AsPathPrepend != "" {
+		out.AsPath = append(strings.Fields(set.AsPathPrepend), out.AsPath...)
+	}
+
+	if set.Med != 0 {
+		out.Med = set.Med
+	}
+
+	out.Communities = appendMissing(out.Communities, strings.Fields(set.Community))
+	out.ExtendedCommunities = appendMissing(out.ExtendedCommunities, strings.Fields(set.ExtendedCommunity))
+
+	return out
+}
+
+func appendMissing(list []string, values []string) []string {
+	for _, v := range values {
+		if !stringInSlice(v, list) {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+func trimAll(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, strings.TrimSpace(v))
+	}
+
+	return out
+}
+
+// parseAsPath splits an AS path like "65001 65002" or "65001,65002"
+// into AS numbers.
+func parseAsPath(v string) ([]string, error) {
+	fields := strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+
+	for _, f := range fields {
+		if _, err := strconv.ParseUint(f, 10, 32); err != nil {
+			return nil, fmt.Errorf("invalid AS number %q in AS path %q", f, v)
+		}
+	}
+
+	return fields, nil
+}
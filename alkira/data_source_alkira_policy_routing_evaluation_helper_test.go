@@ -0,0 +1,379 @@
+package alkira
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRouteEvaluator() *routeEvaluator {
+	prefixLists := map[int]*alkira.PolicyPrefixList{
+		1: {Prefixes: []string{"0.0.0.0/0"}},
+		2: {PrefixRanges: []alkira.PolicyPrefixListRange{{Prefix: "10.0.0.0/8", Ge: 16, Le: 24}}},
+	}
+	asPathLists := map[int]*alkira.List{
+		3: {Values: []string{"_65100_"}},
+		4: {Values: []string{"65001 65002"}},
+	}
+	communityLists := map[int]*alkira.List{
+		5: {Values: []string{"65000:100", "65000:200"}},
+	}
+	extendedCommunityLists := map[int]*alkira.List{
+		6: {Values: []string{"soo:65000:1"}},
+	}
+
+	get := func(lists map[int]*alkira.List, kind string) func(int) (*alkira.List, error) {
+		return func(id int) (*alkira.List, error) {
+			if l, ok := lists[id]; ok {
+				return l, nil
+			}
+			return nil, fmt.Errorf("%s list %d not found", kind, id)
+		}
+	}
+
+	return newRouteEvaluator(
+		func(id int) (*alkira.PolicyPrefixList, error) {
+			if l, ok := prefixLists[id]; ok {
+				return l, nil
+			}
+			return nil, fmt.Errorf("prefix list %d not found", id)
+		},
+		get(asPathLists, "as path"),
+		get(communityLists, "community"),
+		get(extendedCommunityLists, "extended community"),
+	)
+}
+
+// testRouteRules are sequenced out of order on purpose:
+//
+//	1000 deny the default route
+//	1100 deny routes through AS 65100 (route leak)
+//	1200 prepend and tag /16-/24 routes in 10.0.0.0/8 from group 7
+//	1300 allow routes with both communities of list 5
+func testRouteRules() []alkira.RoutePolicyRules {
+	return []alkira.RoutePolicyRules{
+		{
+			Name: "tag-internal", SequenceNo: 1200, Action: "ALLOW_W_SET",
+			Match: alkira.RoutePolicyRulesMatch{PrefixListIds: []int{2}, ConnectorGroupIds: []int{7}},
+			Set: &alkira.RoutePolicyRulesSet{
+				AsPathPrepend: "65500 65500",
+				Community:     "65500:1",
+				Med:           50,
+			},
+			InterCxpRoutesRedistribution: &alkira.RoutePolicyRulesInterCxpRoutesRedistribution{
+				DistributionType: "LOCAL_ONLY",
+			},
+		},
+		{
+			Name: "deny-default", SequenceNo: 1000, Action: "DENY",
+			Match: alkira.RoutePolicyRulesMatch{PrefixListIds: []int{1}},
+		},
+		{
+			Name: "deny-leak", SequenceNo: 1100, Action: "DENY",
+			Match: alkira.RoutePolicyRulesMatch{AsPathListIds: []int{3}},
+		},
+		{
+			Name: "allow-tagged", SequenceNo: 1300, Action: "ALLOW",
+			Match: alkira.RoutePolicyRulesMatch{CommunityListIds: []int{5}},
+		},
+	}
+}
+
+func testRoute(t *testing.T, prefix string, asPath ...string) routeEvaluationRoute {
+	_, n, err := net.ParseCIDR(prefix)
+	require.NoError(t, err)
+
+	return routeEvaluationRoute{Prefix: n, AsPath: asPath}
+}
+
+func TestRouteEvaluatorEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		route      func(t *testing.T) routeEvaluationRoute
+		wantRule   string
+		wantAction string
+		wantAsPath []string
+		wantComms  []string
+		wantMed    int
+	}{
+		{
+			name:       "default route is denied",
+			route:      func(t *testing.T) routeEvaluationRoute { return testRoute(t, "0.0.0.0/0", "65001") },
+			wantRule:   "deny-default",
+			wantAction: "DENY",
+			wantAsPath: []string{"65001"},
+		},
+		{
+			name: "route leaked through 65100 is denied",
+			route: func(t *testing.T) routeEvaluationRoute {
+				return testRoute(t, "192.0.2.0/24", "65001", "65100", "65200")
+			},
+			wantRule:   "deny-leak",
+			wantAction: "DENY",
+			wantAsPath: []string{"65001", "65100", "65200"},
+		},
+		{
+			name:       "65100 as part of a longer ASN is not a leak",
+			route:      func(t *testing.T) routeEvaluationRoute { return testRoute(t, "192.0.2.0/24", "651001") },
+			wantAction: "DENY",
+			wantAsPath: []string{"651001"},
+		},
+		{
+			name: "internal route gets prepended and tagged",
+			route: func(t *testing.T) routeEvaluationRoute {
+				r := testRoute(t, "10.20.0.0/16", "65001")
+				r.ConnectorGroupId = 7
+				r.Communities = []string{"65500:1", "65000:9"}
+				return r
+			},
+			wantRule:   "tag-internal",
+			wantAction: "ALLOW_W_SET",
+			wantAsPath: []string{"65500", "65500", "65001"},
+			wantComms:  []string{"65500:1", "65000:9"},
+			wantMed:    50,
+		},
+		{
+			name: "internal route too specific for the range",
+			route: func(t *testing.T) routeEvaluationRoute {
+				r := testRoute(t, "10.20.30.0/25")
+				r.ConnectorGroupId = 7
+				return r
+			},
+			wantAction: "DENY",
+		},
+		{
+			name: "all communities of the list are required",
+			route: func(t *testing.T) routeEvaluationRoute {
+				r := testRoute(t, "198.51.100.0/24")
+				r.Communities = []string{"65000:100"}
+				return r
+			},
+			wantAction: "DENY",
+			wantComms:  []string{"65000:100"},
+		},
+		{
+			name: "tagged route is allowed",
+			route: func(t *testing.T) routeEvaluationRoute {
+				r := testRoute(t, "198.51.100.0/24")
+				r.Communities = []string{"65000:200", "65000:100"}
+				return r
+			},
+			wantRule:   "allow-tagged",
+			wantAction: "ALLOW",
+			wantComms:  []string{"65000:200", "65000:100"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := tt.route(t)
+
+			result, err := testRouteEvaluator().evaluate(testRouteRules(), route, "DENY")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantRule != "", result.Matched)
+			assert.Equal(t, tt.wantAction, result.Action)
+			if tt.wantRule != "" {
+				assert.Equal(t, tt.wantRule, result.Rule.Name)
+			}
+			assert.Equal(t, tt.wantAsPath, result.Route.AsPath)
+			assert.Equal(t, tt.wantComms, result.Route.Communities)
+			assert.Equal(t, tt.wantMed, result.Route.Med)
+		})
+	}
+}
+
+func TestRouteEvaluatorDoesNotModifyInput(t *testing.T) {
+	route := testRoute(t, "10.20.0.0/16", "65001")
+	route.ConnectorGroupId = 7
+
+	result, err := testRouteEvaluator().evaluate(testRouteRules(), route, "DENY")
+	require.NoError(t, err)
+	require.Equal(t, "LOCAL_ONLY", result.Redistribution.DistributionType)
+
+	assert.Equal(t, []string{"65001"}, route.AsPath)
+	assert.Empty(t, route.Communities)
+	assert.Equal(t, []string{
+		"rule deny-default (1000): skipped, prefix 10.20.0.0/16 is not in prefix lists [1]",
+		"rule deny-leak (1100): skipped, AS path \"65001\" does not match AS path lists [3]",
+		"rule tag-internal (1200): matched, action ALLOW_W_SET",
+	}, result.Trace)
+}
+
+func TestRouteEvaluatorMissingList(t *testing.T) {
+	rules := []alkira.RoutePolicyRules{
+		{Name: "broken", SequenceNo: 1, Action: "DENY", Match: alkira.RoutePolicyRulesMatch{CommunityListIds: []int{99}}},
+	}
+
+	_, err := testRouteEvaluator().evaluate(rules, testRoute(t, "10.0.0.0/8"), "DENY")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "community list 99")
+}
+
+func TestApplyRouteSetReplaceWithSegmentAsn(t *testing.T) {
+	tests := []struct {
+		replace    string
+		segmentAsn string
+		want       []string
+	}{
+		{"ALL", "64512", []string{"64512", "64512"}},
+		{"65001, 65003", "64512", []string{"64512", "65002"}},
+		{"ALL", "", []string{"65001", "65002"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.replace+"/"+tt.segmentAsn, func(t *testing.T) {
+			route := testRoute(t, "10.0.0.0/8", "65001", "65002")
+			route.SegmentAsn = tt.segmentAsn
+
+			result := &routeEvaluationResult{}
+			out := applyRouteSet(&alkira.RoutePolicyRulesSet{AsPathReplaceWithSegmentAsn: tt.replace}, route, result)
+
+			assert.Equal(t, tt.want, out.AsPath)
+			assert.Equal(t, []string{"65001", "65002"}, route.AsPath)
+		})
+	}
+}
+
+func TestPrefixRangeMatches(t *testing.T) {
+	tests := []struct {
+		r      alkira.PolicyPrefixListRange
+		prefix string
+		want   bool
+	}{
+		{alkira.PolicyPrefixListRange{Prefix: "10.0.0.0/8"}, "10.0.0.0/8", true},
+		{alkira.PolicyPrefixListRange{Prefix: "10.0.0.0/8"}, "10.1.0.0/16", false},
+		{alkira.PolicyPrefixListRange{Prefix: "10.0.0.0/8", Ge: 16}, "10.1.2.0/24", true},
+		{alkira.PolicyPrefixListRange{Prefix: "10.0.0.0/8", Ge: 16}, "10.0.0.0/8", false},
+		{alkira.PolicyPrefixListRange{Prefix: "10.0.0.0/8", Le: 16}, "10.1.0.0/16", true},
+		{alkira.PolicyPrefixListRange{Prefix: "10.0.0.0/8", Le: 16}, "10.1.2.0/24", false},
+		{alkira.PolicyPrefixListRange{Prefix: "10.0.0.0/8", Le: 32}, "11.0.0.0/16", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%+v/%s", tt.r, tt.prefix), func(t *testing.T) {
+			_, n, err := net.ParseCIDR(tt.prefix)
+			require.NoError(t, err)
+
+			got, err := prefixRangeMatches(tt.r, n)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseAsPath(t *testing.T) {
+	path, err := parseAsPath("65001 65002,65003")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"65001", "65002", "65003"}, path)
+
+	path, err = parseAsPath("")
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	_, err = parseAsPath("65001 AS2")
+	require.Error(t, err)
+}
+
+func TestPolicyRoutingEvaluationPassedRules(t *testing.T) {
+	// The rules passed in are evaluated without reading any policy.
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	tests := []struct {
+		name         string
+		config       map[string]interface{}
+		wantAction   string
+		wantRule     string
+		wantSequence int
+	}{
+		{
+			name: "planned rules in the given order",
+			config: map[string]interface{}{
+				"prefix":             "10.1.0.0/16",
+				"connector_group_id": 7,
+				"rule": []interface{}{
+					map[string]interface{}{"name": "deny-group", "action": "DENY", "match_group_ids": []interface{}{7}},
+					map[string]interface{}{"name": "allow-all", "action": "ALLOW", "match_all": true},
+				},
+			},
+			wantAction:   "DENY",
+			wantRule:     "deny-group",
+			wantSequence: 1000,
+		},
+		{
+			name: "next planned rule",
+			config: map[string]interface{}{
+				"prefix":             "10.1.0.0/16",
+				"connector_group_id": 8,
+				"rule": []interface{}{
+					map[string]interface{}{"name": "deny-group", "action": "DENY", "match_group_ids": []interface{}{7}},
+					map[string]interface{}{"name": "allow-all", "action": "ALLOW", "match_all": true},
+				},
+			},
+			wantAction:   "ALLOW",
+			wantRule:     "allow-all",
+			wantSequence: 1001,
+		},
+		{
+			name: "applied rules by sequence_no",
+			config: map[string]interface{}{
+				"prefix":             "10.1.0.0/16",
+				"connector_group_id": 7,
+				"rule": []interface{}{
+					map[string]interface{}{"name": "deny-group", "sequence_no": 1100, "action": "DENY", "match_group_ids": []interface{}{7}},
+					map[string]interface{}{"name": "allow-all", "sequence_no": 1000, "action": "ALLOW", "match_all": true},
+				},
+			},
+			wantAction:   "ALLOW",
+			wantRule:     "allow-all",
+			wantSequence: 1000,
+		},
+		{
+			name: "inter-CXP rules",
+			config: map[string]interface{}{
+				"prefix": "10.1.0.0/16",
+				"cxp":    "US-WEST",
+				"inter_cxp_rule": []interface{}{
+					map[string]interface{}{"name": "allow-all", "action": "ALLOW", "match_all": true},
+				},
+				"source_cxps": []interface{}{"US-WEST"},
+			},
+			wantAction:   "ALLOW",
+			wantRule:     "allow-all",
+			wantSequence: 1000,
+		},
+		{
+			name: "inter-CXP rules from another CXP",
+			config: map[string]interface{}{
+				"prefix": "10.1.0.0/16",
+				"cxp":    "US-EAST",
+				"inter_cxp_rule": []interface{}{
+					map[string]interface{}{"name": "allow-all", "action": "ALLOW", "match_all": true},
+				},
+				"source_cxps": []interface{}{"US-WEST"},
+			},
+			wantAction: "DENY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := dataSourceAlkiraPolicyRoutingEvaluation()
+			d := schema.TestResourceDataRaw(t, r.Schema, tt.config)
+
+			require.NoError(t, r.Read(d, client))
+			assert.Equal(t, tt.wantAction, d.Get("action"))
+			assert.Equal(t, tt.wantRule, d.Get("rule_name"))
+			assert.Equal(t, tt.wantSequence, d.Get("rule_sequence_no"))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package alkira
+
+import (
+	"fmt"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAlkiraUnreferencedObjects() *schema.Resource {
+	s := map[string]*schema.Schema{}
+
+	for _, kind := range unreferencedKinds {
+		s[kind.attribute] = &schema.Schema{
+			Description: fmt.Sprintf("The %s no other object refers to, "+
+				"sorted by ID.", kind.description),
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"id": {
+						Description: "The ID of the object.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+					"name": {
+						Description: "The name of the object.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+				},
+			},
+		}
+	}
+
+	return &schema.Resource{
+		Description: "Use this data source to find the prefix, community, " +
+			"extended community, AS path and policy rule lists, billing " +
+			"tags, credentials and IP reservations of the tenant network " +
+			"no other object refers to, so they can be cleaned up or " +
+			"imported.\n\n" +
+			"Every collection the provider can list is read to find the " +
+			"references. Objects only referred to by objects the provider " +
+			"can't list, or by configurations not applied yet, are " +
+			"reported too, so review them before deleting.",
+
+		Read: dataSourceAlkiraUnreferencedObjectsRead,
+
+		Schema: s,
+	}
+}
+
+func dataSourceAlkiraUnreferencedObjectsRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*alkira.AlkiraClient)
+
+	graph, err := buildReferenceGraph(client)
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range unreferencedKinds {
+		items, err := kind.list(client)
+		if err != nil {
+			return fmt.Errorf("failed to list the %s: %w", kind.description, err)
+		}
+
+		d.Set(kind.attribute, unreferencedObjects(items, graph[kind.attribute]))
+	}
+
+	d.SetId(client.TenantNetworkId + "-unreferenced-objects")
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package alkira
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+)
+
+// unreferencedKind is a type of object other objects refer to by ID.
+// Key matches the fields of the API objects holding references to it,
+// at any level.
+type unreferencedKind struct {
+	attribute   string
+	description string
+	list        exportListFunc
+	key         *regexp.Regexp
+}
+
+var unreferencedKinds = []unreferencedKind{
+	{"prefix_lists", "prefix lists", exportList(alkira.NewPolicyPrefixList), regexp.MustCompile(`(?i)prefixListIds?$`)},
+	{"community_lists", "community lists", exportList(alkira.NewListCommunity), regexp.MustCompile(`^communityListIds$`)},
+	{"extended_community_lists", "extended community lists", exportList(alkira.NewListExtendedCommunity), regexp.MustCompile(`^extendedCommunityListIds$`)},
+	{"as_path_lists", "AS path lists", exportList(alkira.NewListAsPath), regexp.MustCompile(`^asPathListIds$`)},
+	{"billing_tags", "billing tags", exportList(alkira.NewBillingTag), regexp.MustCompile(`^billingTags?$`)},
+	{"credentials", "credentials", listReferenceCredentials, regexp.MustCompile(`(?i)credentialId$`)},
+	{"ip_reservations", "IP reservations", exportList(alkira.NewIPReservation), regexp.MustCompile(`(?i)ipReservationId$`)},
+	{"rule_lists", "policy rule lists", exportList(alkira.NewPolicyRuleList), regexp.MustCompile(`^ruleListId$`)},
+}
+
+// referenceGraph holds the objects referring to each object, keyed by
+// the attribute of its kind and then by its ID.
+type referenceGraph map[string]map[string][]string
+
+// add records the references of an API object, walking its nested
+// objects and lists. Empty and zero IDs stand for no reference.
+func (g referenceGraph) add(from string, object interface{}) {
+	switch o := object.(type) {
+	case map[string]interface{}:
+		for key, value := range o {
+			for _, kind := range unreferencedKinds {
+				if !kind.key.MatchString(key) {
+					continue
+				}
+
+				for _, id := range referenceIds(value) {
+					if g[kind.attribute] == nil {
+						g[kind.attribute] = make(map[string][]string)
+					}
+					g[kind.attribute][id] = append(g[kind.attribute][id], from)
+				}
+			}
+
+			g.add(from, value)
+		}
+	case []interface{}:
+		for _, value := range o {
+			g.add(from, value)
+		}
+	}
+}
+
+func referenceIds(value interface{}) []string {
+	var values []interface{}
+
+	switch v := value.(type) {
+	case nil, map[string]interface{}:
+		return nil
+	case []interface{}:
+		values = v
+	default:
+		values = []interface{}{v}
+	}
+
+	var ids []string
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		if _, ok := v.(map[string]interface{}); ok {
+			continue
+		}
+
+		id := fmt.Sprint(v)
+		if id != "" && id != "0" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// buildReferenceGraph lists every collection the provider knows and
+// records their references. A collection failing to list is an error,
+// since the objects it refers to would look unreferenced.
+func buildReferenceGraph(client *alkira.AlkiraClient) (referenceGraph, error) {
+	graph := make(referenceGraph)
+
+	for _, c := range exportCollections {
+		items, err := c.list(client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", c.resourceType, err)
+		}
+
+		for _, item := range items {
+			if item.Object != nil {
+				graph.add(c.resourceType+"/"+item.Id, item.Object)
+			}
+		}
+	}
+
+	return graph, nil
+}
+
+// unreferencedObjects returns the objects of a kind nothing refers to,
+// sorted by ID.
+func unreferencedObjects(items []exportItem, referenced map[string][]string) []map[string]interface{} {
+	sort.SliceStable(items, func(i, j int) bool { return exportIdLess(items[i].Id, items[j].Id) })
+
+	objects := []map[string]interface{}{}
+	for _, item := range items {
+		if len(referenced[item.Id]) > 0 {
+			continue
+		}
+
+		objects = append(objects, map[string]interface{}{
+			"id":   item.Id,
+			"name": item.Name,
+		})
+	}
+
+	return objects
+}
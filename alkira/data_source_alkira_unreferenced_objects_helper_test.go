@@ -0,0 +1,105 @@
+package alkira
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferenceGraphAdd(t *testing.T) {
+	var object map[string]interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(`{
+		"id": 5,
+		"billingTags": [1, 2],
+		"credentialId": "c1",
+		"rules": [
+			{"match": {"prefixListIds": [3], "communityListIds": [], "asPathListIds": null}},
+			{"srcPrefixListId": 4, "dstPrefixListId": 0}
+		],
+		"instances": [{"credentialId": "c2", "licenseCredentialId": ""}],
+		"overlayIpReservationId": "r1"
+	}`))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&object))
+
+	graph := make(referenceGraph)
+	graph.add("alkira_policy_routing/5", object)
+
+	// The remote access connector template refers to a single billing
+	// tag per user group mapping.
+	decoder = json.NewDecoder(strings.NewReader(`{
+		"id": 6,
+		"segmentOptions": [{"userGroupMappings": [{"billingTag": 7}, {"billingTag": 0}]}]
+	}`))
+	decoder.UseNumber()
+
+	var template map[string]interface{}
+	require.NoError(t, decoder.Decode(&template))
+
+	graph.add("alkira_connector_remote_access_template/6", template)
+
+	assert.Equal(t, referenceGraph{
+		"billing_tags": {
+			"1": {"alkira_policy_routing/5"},
+			"2": {"alkira_policy_routing/5"},
+			"7": {"alkira_connector_remote_access_template/6"},
+		},
+		"credentials":     {"c1": {"alkira_policy_routing/5"}, "c2": {"alkira_policy_routing/5"}},
+		"prefix_lists":    {"3": {"alkira_policy_routing/5"}, "4": {"alkira_policy_routing/5"}},
+		"ip_reservations": {"r1": {"alkira_policy_routing/5"}},
+	}, graph)
+}
+
+func TestDataSourceAlkiraUnreferencedObjectsRead(t *testing.T) {
+	responses := map[string]string{
+		"/tenantnetworks/0/policy/prefixlists": `[{"id":1,"name":"used"},{"id":2,"name":"orphan"},{"id":10,"name":"rule"}]`,
+		"/tenantnetworks/0/policy/rulelists":   `[{"id":3,"name":"used"},{"id":4,"name":"orphan"}]`,
+		"/tenantnetworks/0/policy/policies":    `[{"id":20,"name":"allow","ruleListId":3}]`,
+		"/tenantnetworks/0/policy/rules":       `[{"id":30,"name":"r","match":{"srcPrefixListId":10}}]`,
+		"/tenantnetworks/0/route-policies":     `[{"id":40,"name":"rp","rules":[{"match":{"prefixListIds":[1]}}]}]`,
+		"/tenantnetworks/0/awsvpcconnectors":   `[{"id":50,"name":"vpc","credentialId":"aws","billingTags":[]}]`,
+		"/api/credentials/":                    `[{"credentialId":"aws","credentialType":"awsvpc","name":"aws"},{"credentialId":"old","credentialType":"awsvpc","name":"old"}]`,
+	}
+
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		body, ok := responses[req.URL.Path]
+		if !ok {
+			body = "[]"
+		}
+		w.Write([]byte(body))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAlkiraUnreferencedObjects().Schema, map[string]interface{}{})
+	require.NoError(t, dataSourceAlkiraUnreferencedObjectsRead(d, client))
+
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "2", "name": "orphan"}}, d.Get("prefix_lists"))
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "4", "name": "orphan"}}, d.Get("rule_lists"))
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "old", "name": "old"}}, d.Get("credentials"))
+	assert.Empty(t, d.Get("billing_tags"))
+}
+
+func TestDataSourceAlkiraUnreferencedObjectsReadListFailure(t *testing.T) {
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/tenantnetworks/0/route-policies" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAlkiraUnreferencedObjects().Schema, map[string]interface{}{})
+
+	err := dataSourceAlkiraUnreferencedObjectsRead(d, client)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list alkira_policy_routing")
+}
@@ -0,0 +1,88 @@
+package alkira
+
+import (
+	"fmt"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAlkiraUser() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to get an existing user by user " +
+			"name or email address.",
+
+		Read: dataSourceAlkiraUserRead,
+
+		Schema: map[string]*schema.Schema{
+			"user_name": {
+				Description:  "The user name of the user.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"user_name", "email"},
+			},
+			"email": {
+				Description: "The email address of the user.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			"first_name": {
+				Description: "The first name of the user.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"last_name": {
+				Description: "The last name of the user.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"group_ids": {
+				Description: "The IDs of the user groups of the user.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraUserRead(d *schema.ResourceData, m interface{}) error {
+	users, err := getAllPortalUsers(m.(*alkira.AlkiraClient))
+	if err != nil {
+		return err
+	}
+
+	userName := d.Get("user_name").(string)
+	email := d.Get("email").(string)
+
+	var matches []portalUser
+	for _, u := range users {
+		if (userName != "" && u.UserName == userName) || (email != "" && u.Email == email) {
+			matches = append(matches, u)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if userName != "" {
+			return fmt.Errorf("user %q does not exist", userName)
+		}
+		return fmt.Errorf("no user with email %q", email)
+	case 1:
+	default:
+		return fmt.Errorf("%d users have the email %q, use user_name instead", len(matches), email)
+	}
+
+	user := flattenPortalUser(matches[0])
+
+	d.SetId(matches[0].Id)
+	d.Set("user_name", user["user_name"])
+	d.Set("email", user["email"])
+	d.Set("first_name", user["first_name"])
+	d.Set("last_name", user["last_name"])
+	d.Set("group_ids", user["group_ids"])
+
+	return nil
+}
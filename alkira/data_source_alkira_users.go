@@ -0,0 +1,106 @@
+package alkira
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAlkiraUsers() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to list the users of the portal, " +
+			"optionally only the members of a user group.",
+
+		Read: dataSourceAlkiraUsersRead,
+
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Description: "Only list the members of the user group " +
+					"with this ID.",
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ids": {
+				Description: "The IDs of the users.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"users": {
+				Description: "The users, sorted by user name.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: "The ID of the user.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"user_name": {
+							Description: "The user name of the user.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"email": {
+							Description: "The email address of the user.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"first_name": {
+							Description: "The first name of the user.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"last_name": {
+							Description: "The last name of the user.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"group_ids": {
+							Description: "The IDs of the user groups of the user.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAlkiraUsersRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*alkira.AlkiraClient)
+
+	users, err := getAllPortalUsers(client)
+	if err != nil {
+		return err
+	}
+
+	groupId := d.Get("group_id").(string)
+
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].UserName < users[j].UserName
+	})
+
+	ids := []string{}
+	list := []map[string]interface{}{}
+
+	for _, u := range users {
+		if groupId != "" && !u.inGroup(groupId) {
+			continue
+		}
+
+		ids = append(ids, u.Id)
+		list = append(list, flattenPortalUser(u))
+	}
+
+	d.SetId(fmt.Sprintf("%s-users-%s", client.TenantNetworkId, groupId))
+	d.Set("ids", ids)
+	d.Set("users", list)
+
+	return nil
+}
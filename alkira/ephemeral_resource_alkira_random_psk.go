@@ -0,0 +1,129 @@
+package alkira
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	randomPskCharset       = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	randomPskDefaultLength = 32
+	randomPskMinLength     = 8
+	randomPskMaxLength     = 64
+)
+
+type ephemeralResourceAlkiraRandomPsk struct{}
+
+type ephemeralResourceAlkiraRandomPskModel struct {
+	Length types.Int64  `tfsdk:"length"`
+	Value  types.String `tfsdk:"value"`
+}
+
+func newEphemeralResourceAlkiraRandomPsk() ephemeral.EphemeralResource {
+	return &ephemeralResourceAlkiraRandomPsk{}
+}
+
+func (r *ephemeralResourceAlkiraRandomPsk) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_random_psk"
+}
+
+func (r *ephemeralResourceAlkiraRandomPsk) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Use this ephemeral resource to generate a random " +
+			"pre-shared key for an IPSec tunnel without storing it in plan " +
+			"or state.\n\n" +
+			"Pass `value` to a write-only argument such as " +
+			"`preshared_key_wo` of the `tunnel` block of " +
+			"`alkira_connector_ipsec_adv` and bump the matching " +
+			"`*_wo_version` argument to rotate the key. A new key is " +
+			"generated on every run.",
+		Attributes: map[string]schema.Attribute{
+			"length": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The length of the key, between "+
+					"`%d` and `%d`. The default value is `%d`.",
+					randomPskMinLength, randomPskMaxLength, randomPskDefaultLength),
+				Optional: true,
+			},
+			"value": schema.StringAttribute{
+				MarkdownDescription: "The generated key of letters and digits.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (r *ephemeralResourceAlkiraRandomPsk) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var length types.Int64
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("length"), &length)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := randomPskLength(length); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("length"), "INVALID CONFIGURATION", err.Error())
+	}
+}
+
+func (r *ephemeralResourceAlkiraRandomPsk) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data ephemeralResourceAlkiraRandomPskModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	length, err := randomPskLength(data.Length)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("length"), "INVALID CONFIGURATION", err.Error())
+		return
+	}
+
+	value, err := randomPsk(length)
+	if err != nil {
+		resp.Diagnostics.AddError("FAILED TO GENERATE KEY", err.Error())
+		return
+	}
+
+	data.Value = types.StringValue(value)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+// randomPskLength returns the configured length of the key, the default
+// one when it isn't set or not known yet.
+func randomPskLength(v types.Int64) (int, error) {
+	if v.IsNull() || v.IsUnknown() {
+		return randomPskDefaultLength, nil
+	}
+
+	length := v.ValueInt64()
+	if length < randomPskMinLength || length > randomPskMaxLength {
+		return 0, fmt.Errorf("length must be between %d and %d, got %d",
+			randomPskMinLength, randomPskMaxLength, length)
+	}
+
+	return int(length), nil
+}
+
+func randomPsk(length int) (string, error) {
+	max := big.NewInt(int64(len(randomPskCharset)))
+	key := make([]byte, length)
+
+	for i := range key {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate key: %w", err)
+		}
+		key[i] = randomPskCharset[n.Int64()]
+	}
+
+	return string(key), nil
+}
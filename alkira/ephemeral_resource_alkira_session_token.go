@@ -0,0 +1,329 @@
+package alkira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type ephemeralResourceAlkiraSessionToken struct {
+	client *alkira.AlkiraClient
+}
+
+type ephemeralResourceAlkiraSessionTokenModel struct {
+	ApiUrl          types.String `tfsdk:"api_url"`
+	TenantNetworkId types.String `tfsdk:"tenant_network_id"`
+	HeaderName      types.String `tfsdk:"header_name"`
+	HeaderValue     types.String `tfsdk:"header_value"`
+}
+
+func newEphemeralResourceAlkiraSessionToken() ephemeral.EphemeralResource {
+	return &ephemeralResourceAlkiraSessionToken{}
+}
+
+func (r *ephemeralResourceAlkiraSessionToken) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_session_token"
+}
+
+func (r *ephemeralResourceAlkiraSessionToken) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Use this ephemeral resource to log in to the portal " +
+			"with the credentials of the provider and pass the session " +
+			"to other tooling, e.g. a `local-exec` provisioner calling " +
+			"the portal API, without storing it in plan or state.\n\n" +
+			"Send `header_value` in a `header_name` header with every " +
+			"request to `api_url`. Terraform keeps the session alive " +
+			"while it's in use and logs it out when it's done with it.",
+		Attributes: map[string]schema.Attribute{
+			"api_url": schema.StringAttribute{
+				MarkdownDescription: "The base URL of the portal API.",
+				Computed:            true,
+			},
+			"tenant_network_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the tenant network of the provider.",
+				Computed:            true,
+			},
+			"header_name": schema.StringAttribute{
+				MarkdownDescription: "The name of the header authenticating the " +
+					"session, `Cookie`.",
+				Computed: true,
+			},
+			"header_value": schema.StringAttribute{
+				MarkdownDescription: "The cookies of the session.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (r *ephemeralResourceAlkiraSessionToken) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if client, ok := req.ProviderData.(*alkira.AlkiraClient); ok {
+		r.client = client
+	}
+}
+
+func (r *ephemeralResourceAlkiraSessionToken) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("FAILED TO OPEN SESSION", "the provider is not configured")
+		return
+	}
+
+	cookie, renewAt, err := openPortalSession(ctx, r.client)
+	if err != nil {
+		resp.Diagnostics.AddError("FAILED TO OPEN SESSION", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, sessionTokenPrivateKey, sessionTokenPrivate(cookie))...)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &ephemeralResourceAlkiraSessionTokenModel{
+		ApiUrl:          types.StringValue(r.client.URI),
+		TenantNetworkId: types.StringValue(r.client.TenantNetworkId),
+		HeaderName:      types.StringValue("Cookie"),
+		HeaderValue:     types.StringValue(cookie),
+	})...)
+	resp.RenewAt = renewAt
+}
+
+func (r *ephemeralResourceAlkiraSessionToken) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	cookie, diags := sessionTokenCookie(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.client == nil {
+		resp.Diagnostics.AddError("FAILED TO RENEW SESSION", "the provider is not configured")
+		return
+	}
+
+	renewAt, err := renewPortalSession(ctx, r.client, cookie)
+	if err != nil {
+		resp.Diagnostics.AddError("FAILED TO RENEW SESSION", err.Error())
+		return
+	}
+
+	resp.RenewAt = renewAt
+}
+
+func (r *ephemeralResourceAlkiraSessionToken) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	cookie, diags := sessionTokenCookie(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.client == nil {
+		resp.Diagnostics.AddError("FAILED TO CLOSE SESSION", "the provider is not configured")
+		return
+	}
+
+	if err := closePortalSession(ctx, r.client, cookie); err != nil {
+		resp.Diagnostics.AddError("FAILED TO CLOSE SESSION", err.Error())
+	}
+}
+
+// sessionTokenPrivateKey is the key of the private data of the session
+// token, holding the cookies of the session for Renew and Close.
+const sessionTokenPrivateKey = "session"
+
+type sessionTokenPrivateData struct {
+	Cookie string `json:"cookie"`
+}
+
+func sessionTokenPrivate(cookie string) []byte {
+	data, _ := json.Marshal(sessionTokenPrivateData{Cookie: cookie})
+	return data
+}
+
+// privateKeyGetter is the private data of the requests of ephemeral
+// resources.
+type privateKeyGetter interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+func sessionTokenCookie(ctx context.Context, private privateKeyGetter) (string, diag.Diagnostics) {
+	raw, diags := private.GetKey(ctx, sessionTokenPrivateKey)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	var data sessionTokenPrivateData
+	if err := json.Unmarshal(raw, &data); err != nil || data.Cookie == "" {
+		diags.AddError("MISSING SESSION", "the session of the session token is missing")
+		return "", diags
+	}
+
+	return data.Cookie, diags
+}
+
+// sessionTokenRenewInterval is how often an open session is kept alive
+// when its cookies don't expire earlier.
+const sessionTokenRenewInterval = 5 * time.Minute
+
+// sessionHTTPClient returns an HTTP client sharing the transport of the
+// provider client, so that its TLS, proxy and rate limit settings apply,
+// but not its session.
+func sessionHTTPClient(client *alkira.AlkiraClient) (*http.Client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{Jar: jar}
+	if client.Client != nil && client.Client.HTTPClient != nil {
+		httpClient.Transport = client.Client.HTTPClient.Transport
+		httpClient.Timeout = client.Client.HTTPClient.Timeout
+	}
+
+	return httpClient, nil
+}
+
+// openPortalSession logs in to the portal with the credentials of the
+// client the same way the client does, returning the cookies of the new
+// session and when to renew it.
+func openPortalSession(ctx context.Context, client *alkira.AlkiraClient) (string, time.Time, error) {
+	httpClient, err := sessionHTTPClient(client)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"userName": client.Username,
+		"password": client.Password,
+		"secret":   client.Secret,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	login, loginCookies, err := portalSessionRequest(ctx, httpClient, http.MethodPost, client.URI+"/user/login", "", body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to log in to portal: %w", err)
+	}
+
+	_, cookies, err := portalSessionRequest(ctx, httpClient, http.MethodPost, client.URI+"/sessions", "", login)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get portal session: %w", err)
+	}
+	cookies = append(loginCookies, cookies...)
+
+	// Later cookies replace earlier ones of the same name, like they do
+	// in the cookie jar of the client.
+	var names []string
+	values := make(map[string]string)
+	for _, c := range cookies {
+		if _, ok := values[c.Name]; !ok {
+			names = append(names, c.Name)
+		}
+		values[c.Name] = c.Value
+	}
+
+	if len(names) == 0 {
+		return "", time.Time{}, fmt.Errorf("the portal returned no session cookie")
+	}
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+values[name])
+	}
+
+	return strings.Join(pairs, "; "), sessionRenewAt(cookies, time.Now()), nil
+}
+
+// renewPortalSession keeps the session alive with an authenticated
+// request, returning when to renew it next.
+func renewPortalSession(ctx context.Context, client *alkira.AlkiraClient, cookie string) (time.Time, error) {
+	httpClient, err := sessionHTTPClient(client)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	_, cookies, err := portalSessionRequest(ctx, httpClient, http.MethodGet, client.URI+"/tenantnetworksummaries", cookie, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return sessionRenewAt(cookies, time.Now()), nil
+}
+
+// closePortalSession logs the session out of the portal.
+func closePortalSession(ctx context.Context, client *alkira.AlkiraClient, cookie string) error {
+	httpClient, err := sessionHTTPClient(client)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = portalSessionRequest(ctx, httpClient, http.MethodDelete, client.URI+"/sessions", cookie, nil)
+	return err
+}
+
+func portalSessionRequest(ctx context.Context, httpClient *http.Client, method, url, cookie string, body []byte) ([]byte, []*http.Cookie, error) {
+	request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if cookie != "" {
+		request.Header.Set("Cookie", cookie)
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, nil, fmt.Errorf("%s %s failed (%d)", method, request.URL.Path, response.StatusCode)
+	}
+
+	return data, response.Cookies(), nil
+}
+
+// sessionRenewAt returns when to renew a session, a minute before the
+// first of its cookies expires, or after sessionTokenRenewInterval.
+func sessionRenewAt(cookies []*http.Cookie, now time.Time) time.Time {
+	renewAt := now.Add(sessionTokenRenewInterval)
+
+	for _, c := range cookies {
+		var expires time.Time
+
+		switch {
+		case c.MaxAge > 0:
+			expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			expires = c.Expires
+		default:
+			continue
+		}
+
+		if at := expires.Add(-time.Minute); at.Before(renewAt) {
+			renewAt = at
+		}
+	}
+
+	if renewAt.Before(now) {
+		return now
+	}
+
+	return renewAt
+}
@@ -0,0 +1,455 @@
+package alkira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// exportItem is an object of a collection to export. Object holds the
+// listed object when the collection returns more than IDs and names.
+type exportItem struct {
+	Id     string
+	Name   string
+	Object map[string]interface{}
+}
+
+type exportListFunc func(client *alkira.AlkiraClient) ([]exportItem, error)
+
+// exportCollection maps a resource type to the collection behind it.
+// Collections are exported in this order, so the ones referenced by
+// others come first.
+type exportCollection struct {
+	resourceType string
+	list         exportListFunc
+}
+
+var exportCollections = []exportCollection{
+	{"alkira_billing_tag", exportList(alkira.NewBillingTag)},
+	{"alkira_segment", exportList(alkira.NewSegment)},
+	{"alkira_group", exportList(alkira.NewGroup)},
+	{"alkira_group_user", exportList(alkira.NewUserGroup)},
+	{"alkira_group_direct_inter_connector", exportList(alkira.NewInterConnectorCommunicationGroup)},
+	{"alkira_credential_aws_vpc", exportCredentials(alkira.CredentialTypeAwsVpc)},
+	{"alkira_credential_azure_vnet", exportCredentials(alkira.CredentialTypeAzureVnet)},
+	{"alkira_credential_gcp_vpc", exportCredentials(alkira.CredentialTypeGcpVpc)},
+	{"alkira_credential_oci_vcn", exportCredentials(alkira.CredentialTypeOciVcn)},
+	{"alkira_credential_ssh_key_pair", exportCredentials(alkira.CredentialTypeKeyPair)},
+	{"alkira_policy_prefix_list", exportList(alkira.NewPolicyPrefixList)},
+	{"alkira_list_as_path", exportList(alkira.NewListAsPath)},
+	{"alkira_list_community", exportList(alkira.NewListCommunity)},
+	{"alkira_list_extended_community", exportList(alkira.NewListExtendedCommunity)},
+	{"alkira_list_dns_server", exportList(alkira.NewDnsServerList)},
+	{"alkira_list_global_cidr", exportList(alkira.NewGlobalCidrList)},
+	{"alkira_list_policy_fqdn", exportList(alkira.NewPolicyFqdnList)},
+	{"alkira_list_udr", exportList(alkira.NewUdrList)},
+	{"alkira_byoip_prefix", exportList(alkira.NewByoip)},
+	{"alkira_cloudvisor_account", exportList(alkira.NewCloudProviderAccounts)},
+	{"alkira_internet_application", exportList(alkira.NewInternetApplication)},
+	{"alkira_ip_reservation", exportList(alkira.NewIPReservation)},
+	{"alkira_segment_resource", exportList(alkira.NewSegmentResource)},
+	{"alkira_segment_resource_share", exportList(alkira.NewSegmentResourceShare)},
+	{"alkira_connector_ipsec_tunnel_profile", exportList(alkira.NewConnectorIPSecTunnelProfile)},
+	{"alkira_connector_akamai_prolexic", exportList(alkira.NewConnectorAkamaiProlexic)},
+	{"alkira_connector_aruba_edge", exportList(alkira.NewConnectorArubaEdge)},
+	{"alkira_connector_aws_dx", exportList(alkira.NewConnectorAwsDirectConnect)},
+	{"alkira_connector_aws_tgw", exportList(alkira.NewConnectorAwsTgw)},
+	{"alkira_connector_aws_vpc", exportList(alkira.NewConnectorAwsVpc)},
+	{"alkira_connector_azure_expressroute", exportList(alkira.NewConnectorAzureExpressRoute)},
+	{"alkira_connector_azure_vhub", exportList(alkira.NewConnectorAzureVhub)},
+	{"alkira_connector_azure_vnet", exportList(alkira.NewConnectorAzureVnet)},
+	{"alkira_connector_azure_vnet_third_party", exportList(alkira.NewAzureVnetThirdPartyConnector)},
+	{"alkira_connector_cisco_sdwan", exportList(alkira.NewConnectorCiscoSdwan)},
+	{"alkira_connector_fortinet_sdwan", exportList(alkira.NewConnectorFortinetSdwan)},
+	{"alkira_connector_gcp_interconnect", exportList(alkira.NewConnectorGcpInterconnect)},
+	{"alkira_connector_gcp_vpc", exportList(alkira.NewConnectorGcpVpc)},
+	{"alkira_connector_internet_exit", exportList(alkira.NewConnectorInternet)},
+	{"alkira_connector_ipsec", exportList(alkira.NewConnectorIPSec)},
+	{"alkira_connector_ipsec_adv", exportList(alkira.NewConnectorAdvIPSec)},
+	{"alkira_connector_juniper_sdwan", exportList(alkira.NewConnectorJuniperSdwan)},
+	{"alkira_connector_oci_vcn", exportList(alkira.NewConnectorOciVcn)},
+	{"alkira_connector_remote_access", exportList(alkira.NewConnectorRemoteAccessTemplate)},
+	{"alkira_connector_versa_sdwan", exportList(alkira.NewConnectorVersaSdwan)},
+	{"alkira_connector_vmware_sdwan", exportList(alkira.NewConnectorVmwareSdwan)},
+	{"alkira_peering_gateway_aws_tgw", exportList(alkira.NewPeeringGatewayAwsTgw)},
+	{"alkira_peering_gateway_aws_tgw_attachment", exportList(alkira.NewPeeringGatewayAwsTgwAttachment)},
+	{"alkira_peering_gateway_azure_vnet_third_party_connector_attachment", exportList(alkira.NewAzureVnetThirdPartyConnectorAttachment)},
+	{"alkira_peering_gateway_cxp", exportList(alkira.NewPeeringGatewayCxp)},
+	{"alkira_service_bluecat", exportList(alkira.NewServiceBluecat)},
+	{"alkira_service_checkpoint", exportList(alkira.NewServiceCheckpoint)},
+	{"alkira_service_cisco_ftdv", exportList(alkira.NewServiceCiscoFTDv)},
+	{"alkira_service_f5_lb", exportList(alkira.NewServiceF5Lb)},
+	{"alkira_service_f5_vserver_endpoint", exportList(alkira.NewF5vServerEndpoint)},
+	{"alkira_service_fortinet", exportList(alkira.NewServiceFortinet)},
+	{"alkira_service_infoblox", exportList(alkira.NewServiceInfoblox)},
+	{"alkira_service_pan", exportList(alkira.NewServicePan)},
+	{"alkira_service_zscaler", exportList(alkira.NewServiceZscaler)},
+	{"alkira_flow_collector", exportList(alkira.NewFlowCollector)},
+	{"alkira_network_entity_scale_options", exportList(alkira.NewNetworkEntityScaleOptions)},
+	{"alkira_probe_http", exportListWhere(alkira.NewProbe, "type", "HTTP")},
+	{"alkira_probe_https", exportListWhere(alkira.NewProbe, "type", "HTTPS")},
+	{"alkira_probe_tcp", exportListWhere(alkira.NewProbe, "type", "TCP")},
+	{"alkira_policy_rule", exportList(alkira.NewTrafficPolicyRule)},
+	{"alkira_policy_rule_list", exportList(alkira.NewPolicyRuleList)},
+	{"alkira_policy", exportList(alkira.NewTrafficPolicy)},
+	{"alkira_policy_nat_rule", exportList(alkira.NewNatRule)},
+	{"alkira_policy_nat", exportList(alkira.NewNatPolicy)},
+	{"alkira_policy_routing", exportList(alkira.NewRoutePolicy)},
+	{"alkira_policy_inter_cxp_routing", exportList(alkira.NewInterCxpRoutePolicy)},
+}
+
+func exportList[T any](newApi func(*alkira.AlkiraClient) *alkira.AlkiraAPI[T]) exportListFunc {
+	return exportListWhere(newApi, "", "")
+}
+
+// exportListWhere lists a collection, keeping only the objects whose
+// field has the given value when a field is given.
+func exportListWhere[T any](newApi func(*alkira.AlkiraClient) *alkira.AlkiraAPI[T], field, value string) exportListFunc {
+	return func(client *alkira.AlkiraClient) ([]exportItem, error) {
+		data, err := newApi(client).GetAll()
+		if err != nil {
+			return nil, err
+		}
+
+		decoder := json.NewDecoder(strings.NewReader(data))
+		decoder.UseNumber()
+
+		var objects []map[string]interface{}
+		if err := decoder.Decode(&objects); err != nil {
+			return nil, fmt.Errorf("failed to parse the collection: %w", err)
+		}
+
+		var items []exportItem
+		for _, o := range objects {
+			if field != "" && fmt.Sprint(o[field]) != value {
+				continue
+			}
+
+			id := fmt.Sprint(o["id"])
+			if o["id"] == nil || id == "" {
+				continue
+			}
+
+			name, _ := o["name"].(string)
+			items = append(items, exportItem{Id: id, Name: name, Object: o})
+		}
+
+		return items, nil
+	}
+}
+
+func exportCredentials(credentialType alkira.CredentialType) exportListFunc {
+	return func(client *alkira.AlkiraClient) ([]exportItem, error) {
+		data, err := client.GetCredentials()
+		if err != nil {
+			return nil, err
+		}
+
+		var credentials []alkira.CredentialResponseDetail
+		if err := json.Unmarshal([]byte(data), &credentials); err != nil {
+			return nil, fmt.Errorf("failed to parse credentials: %w", err)
+		}
+
+		var items []exportItem
+		for _, c := range credentials {
+			if c.Type == string(credentialType) {
+				items = append(items, exportItem{Id: c.Id, Name: c.Name})
+			}
+		}
+
+		return items, nil
+	}
+}
+
+// exportReferenceRules map attribute names to the resources their values
+// refer to. Values without a matching exported object are kept as is.
+var exportReferenceRules = []struct {
+	attribute *regexp.Regexp
+	types     []string
+	byName    bool
+}{
+	{regexp.MustCompile(`(^|_)segment_ids?$`), []string{"alkira_segment"}, false},
+	{regexp.MustCompile(`(^|_)billing_tag_ids?$`), []string{"alkira_billing_tag"}, false},
+	{regexp.MustCompile(`(^|_)group_ids?$`), []string{"alkira_group"}, false},
+	{regexp.MustCompile(`^groups?$`), []string{"alkira_group"}, true},
+	{regexp.MustCompile(`(^|_)prefix_list_ids?$`), []string{"alkira_policy_prefix_list"}, false},
+	{regexp.MustCompile(`(^|_)credential_id$`), []string{
+		"alkira_credential_aws_vpc",
+		"alkira_credential_azure_vnet",
+		"alkira_credential_gcp_vpc",
+		"alkira_credential_oci_vcn",
+		"alkira_credential_ssh_key_pair",
+	}, false},
+}
+
+// exportSecret matches attributes holding secrets that aren't marked
+// sensitive in their schema.
+var exportSecret = regexp.MustCompile(`(^|_)(secret|password|passphrase|psk|pre_shared_key|private_key|token|access_key|secret_key|api_key)(_|$)`)
+
+// exportedResource is an object read through its resource.
+type exportedResource struct {
+	resourceType string
+	label        string
+	id           string
+	resource     *schema.Resource
+	data         *schema.ResourceData
+}
+
+// exportResult holds the generated files, keyed by file name, and the
+// problems found on the way.
+type exportResult struct {
+	Files    map[string][]byte
+	Warnings []string
+}
+
+func (r *exportResult) warnf(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// exportTenant reads every object of the given resource types, all
+// resource types when none is given, matching the filter and renders
+// them as HCL with the import blocks to adopt them.
+func exportTenant(ctx context.Context, p *schema.Provider, meta interface{}, types []string, filter listFilter) (*exportResult, error) {
+	client := meta.(*alkira.AlkiraClient)
+	result := &exportResult{Files: make(map[string][]byte)}
+
+	if err := filter.resolve(client); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool)
+	for _, t := range types {
+		if _, ok := p.ResourcesMap[t]; !ok {
+			return nil, fmt.Errorf("unknown resource type %q", t)
+		}
+		wanted[t] = true
+	}
+
+	known := make(map[string]bool)
+	for _, c := range exportCollections {
+		known[c.resourceType] = true
+	}
+
+	var unknown []string
+	for t := range p.ResourcesMap {
+		if !known[t] && (len(wanted) == 0 || wanted[t]) {
+			unknown = append(unknown, t)
+		}
+	}
+	sort.Strings(unknown)
+	for _, t := range unknown {
+		result.warnf("%s: not exported, there is no collection to list", t)
+	}
+
+	var exported []*exportedResource
+	labels := make(map[string]map[string]bool)
+
+	for _, c := range exportCollections {
+		if len(wanted) > 0 && !wanted[c.resourceType] {
+			continue
+		}
+
+		if !filter.empty() && !listSupported(c.resourceType) {
+			continue
+		}
+
+		r, ok := p.ResourcesMap[c.resourceType]
+		if !ok {
+			continue
+		}
+
+		if r.Importer == nil {
+			result.warnf("%s: not exported, the resource doesn't support import", c.resourceType)
+			continue
+		}
+
+		items, err := c.list(client)
+		if err != nil {
+			result.warnf("%s: not exported, failed to list: %s", c.resourceType, err)
+			continue
+		}
+
+		if !filter.empty() {
+			var matched []exportItem
+			for _, item := range items {
+				if filter.match(c.resourceType, item) {
+					matched = append(matched, item)
+				}
+			}
+			items = matched
+		}
+
+		sort.SliceStable(items, func(i, j int) bool { return exportIdLess(items[i].Id, items[j].Id) })
+
+		if len(items) > 0 && strings.HasPrefix(c.resourceType, "alkira_credential_") {
+			result.warnf("%s: the API doesn't return secrets, add them before applying", c.resourceType)
+		}
+
+		if labels[c.resourceType] == nil {
+			labels[c.resourceType] = make(map[string]bool)
+		}
+
+		for _, item := range items {
+			data, err := exportRead(ctx, r, item, meta)
+			if err != nil {
+				result.warnf("%s %s: not exported, %s", c.resourceType, item.Id, err)
+				continue
+			}
+
+			exported = append(exported, &exportedResource{
+				resourceType: c.resourceType,
+				label:        exportLabel(item, labels[c.resourceType]),
+				id:           item.Id,
+				resource:     r,
+				data:         data,
+			})
+		}
+	}
+
+	renderExport(exported, result)
+
+	return result, nil
+}
+
+// exportRead reads an object through the Read of its resource, like a
+// refresh after an import.
+func exportRead(ctx context.Context, r *schema.Resource, item exportItem, meta interface{}) (*schema.ResourceData, error) {
+	attributes := map[string]string{"id": item.Id}
+
+	// Some resources, e.g. credentials, can't read their name back.
+	if _, ok := r.Schema["name"]; ok && item.Name != "" {
+		attributes["name"] = item.Name
+	}
+
+	state, diags := r.RefreshWithoutUpgrade(ctx, &terraform.InstanceState{ID: item.Id, Attributes: attributes}, meta)
+	for _, d := range diags {
+		// Most resources report a failed read as a warning.
+		if d.Severity == diag.Error || d.Summary == "FAILED TO GET RESOURCE" {
+			return nil, fmt.Errorf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+
+	if state == nil || state.ID == "" {
+		return nil, fmt.Errorf("the object doesn't exist anymore")
+	}
+
+	return r.Data(state), nil
+}
+
+var exportLabelInvalid = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// exportLabel returns a unique resource label derived from the name of
+// the object, falling back on its ID.
+func exportLabel(item exportItem, used map[string]bool) string {
+	label := strings.Trim(exportLabelInvalid.ReplaceAllString(strings.ToLower(item.Name), "_"), "_")
+	if label == "" {
+		label = "id_" + exportLabelInvalid.ReplaceAllString(strings.ToLower(item.Id), "_")
+	}
+	if label[0] >= '0' && label[0] <= '9' {
+		label = "r_" + label
+	}
+
+	unique := label
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", label, i)
+	}
+	used[unique] = true
+
+	return unique
+}
+
+func exportIdLess(a, b string) bool {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return x < y
+	}
+
+	return a < b
+}
+
+// RunExport implements the export subcommand of the provider binary. It
+// configures the provider from the same environment variables Terraform
+// would use and writes one file per resource type, imports.tf and
+// variables.tf to the output directory.
+func RunExport(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	dir := flags.String("dir", ".", "the directory to write the files to")
+	resources := flags.String("resources", "", "a comma separated list of the resource types to export, all by default")
+
+	var filter listFilter
+	flags.StringVar(&filter.Name, "name", "", "only export objects whose name matches the pattern, e.g. branch-*")
+	flags.StringVar(&filter.Segment, "segment", "", "only export objects attached to the segment")
+	flags.StringVar(&filter.Cxp, "cxp", "", "only export objects in the CXP")
+
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: terraform-provider-alkira export [-dir DIR] [-resources TYPE,...] [-name PATTERN] [-segment NAME] [-cxp CXP]\n\n"+
+			"The provider is configured with the ALKIRA_* environment variables. With a filter, only\n"+
+			"connectors, services, segments, policies and lists are exported.\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var types []string
+	for _, t := range strings.Split(*resources, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+
+	p := Provider()
+	if diags := p.Configure(ctx, terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		for _, d := range diags {
+			fmt.Fprintf(stderr, "%s: %s\n", d.Summary, d.Detail)
+		}
+		return fmt.Errorf("failed to configure the provider")
+	}
+
+	result, err := exportTenant(ctx, p, p.Meta(), types, filter)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Fprintf(stderr, "warning: %s\n", w)
+	}
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(result.Files))
+	for name := range result.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(*dir, name)
+		if err := os.WriteFile(path, result.Files[name], 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "wrote %s (%d lines)\n", path, bytes.Count(result.Files[name], []byte("\n")))
+	}
+
+	return nil
+}
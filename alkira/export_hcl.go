@@ -0,0 +1,339 @@
+package alkira
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// exportRenderer renders exported objects as HCL. References to other
+// exported objects replace IDs and names, and sensitive values and
+// required values the API doesn't return are replaced with variables.
+type exportRenderer struct {
+	byId      map[string]map[string]string
+	byName    map[string]map[string]string
+	variables bytes.Buffer
+	varNames  map[string]bool
+}
+
+func renderExport(exported []*exportedResource, result *exportResult) {
+	x := &exportRenderer{
+		byId:     make(map[string]map[string]string),
+		byName:   make(map[string]map[string]string),
+		varNames: make(map[string]bool),
+	}
+
+	for _, e := range exported {
+		address := e.resourceType + "." + e.label
+
+		if x.byId[e.resourceType] == nil {
+			x.byId[e.resourceType] = make(map[string]string)
+			x.byName[e.resourceType] = make(map[string]string)
+		}
+		x.byId[e.resourceType][e.id] = address
+
+		if name, ok := e.data.GetOk("name"); ok {
+			x.byName[e.resourceType][name.(string)] = address
+		}
+	}
+
+	files := make(map[string]*bytes.Buffer)
+	var imports bytes.Buffer
+
+	for _, e := range exported {
+		file := strings.TrimPrefix(e.resourceType, "alkira_") + ".tf"
+		if files[file] == nil {
+			files[file] = &bytes.Buffer{}
+		} else {
+			files[file].WriteString("\n")
+		}
+
+		x.resource(files[file], e)
+
+		if imports.Len() > 0 {
+			imports.WriteString("\n")
+		}
+		fmt.Fprintf(&imports, "import {\n  to = %s.%s\n  id = %s\n}\n", e.resourceType, e.label, exportQuote(e.id))
+	}
+
+	for name, b := range files {
+		result.Files[name] = b.Bytes()
+	}
+
+	if imports.Len() > 0 {
+		result.Files["imports.tf"] = imports.Bytes()
+	}
+
+	if x.variables.Len() > 0 {
+		result.Files["variables.tf"] = x.variables.Bytes()
+	}
+}
+
+func (x *exportRenderer) resource(b *bytes.Buffer, e *exportedResource) {
+	values := make(map[string]interface{}, len(e.resource.Schema))
+	for k := range e.resource.Schema {
+		values[k] = e.data.Get(k)
+	}
+
+	fmt.Fprintf(b, "resource %q %q {\n", e.resourceType, e.label)
+	x.body(b, 1, e.resource.Schema, values, strings.TrimPrefix(e.resourceType, "alkira_")+"_"+e.label)
+	b.WriteString("}\n")
+}
+
+// body renders the attributes of a body, aligned like terraform fmt
+// does, followed by its nested blocks.
+func (x *exportRenderer) body(b *bytes.Buffer, depth int, s map[string]*schema.Schema, values map[string]interface{}, varPrefix string) {
+	indent := strings.Repeat("  ", depth)
+
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == "name" || keys[j] == "name" {
+			return keys[i] == "name"
+		}
+		return keys[i] < keys[j]
+	})
+
+	type attribute struct{ key, value string }
+	var attributes []attribute
+	var blocks []string
+	width := 0
+
+	for _, k := range keys {
+		ks := s[k]
+		v := values[k]
+
+		if k == "id" || ks.Deprecated != "" || (ks.Computed && !ks.Optional && !ks.Required) {
+			continue
+		}
+
+		if r, ok := ks.Elem.(*schema.Resource); ok && (ks.Type == schema.TypeList || ks.Type == schema.TypeSet) {
+			for i, item := range exportItems(v) {
+				m, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				var nested bytes.Buffer
+				fmt.Fprintf(&nested, "%s%s {\n", indent, k)
+				x.body(&nested, depth+1, r.Schema, m, fmt.Sprintf("%s_%s_%d", varPrefix, k, i))
+				fmt.Fprintf(&nested, "%s}\n", indent)
+				blocks = append(blocks, nested.String())
+			}
+			continue
+		}
+
+		var value string
+		switch {
+		case ks.Sensitive || exportSecret.MatchString(k):
+			if !ks.Required && exportIsZero(v) {
+				continue
+			}
+			value = "var." + x.variable(varPrefix+"_"+k, ks, true)
+		case ks.Required && exportIsZero(v):
+			// The API doesn't return it, e.g. the secrets of a
+			// credential.
+			value = "var." + x.variable(varPrefix+"_"+k, ks, false)
+		case !ks.Required && exportOmit(ks, v):
+			continue
+		default:
+			value = x.value(k, ks, v)
+		}
+
+		attributes = append(attributes, attribute{k, value})
+		if len(k) > width {
+			width = len(k)
+		}
+	}
+
+	for _, a := range attributes {
+		fmt.Fprintf(b, "%s%-*s = %s\n", indent, width, a.key, a.value)
+	}
+
+	for _, block := range blocks {
+		b.WriteString("\n")
+		b.WriteString(block)
+	}
+}
+
+func (x *exportRenderer) value(k string, s *schema.Schema, v interface{}) string {
+	switch s.Type {
+	case schema.TypeList, schema.TypeSet:
+		var elems []string
+		for _, e := range exportItems(v) {
+			elems = append(elems, x.primitive(k, e))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+
+	case schema.TypeMap:
+		m, _ := v.(map[string]interface{})
+
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var elems []string
+		for _, key := range keys {
+			elems = append(elems, fmt.Sprintf("%s = %s", exportQuote(key), exportLiteral(m[key])))
+		}
+		return "{ " + strings.Join(elems, ", ") + " }"
+	}
+
+	return x.primitive(k, v)
+}
+
+// primitive renders a single value, as a reference when it's the ID or
+// name of an exported object the attribute refers to.
+func (x *exportRenderer) primitive(k string, v interface{}) string {
+	value := fmt.Sprint(v)
+
+	if value != "" && value != "0" {
+		for _, rule := range exportReferenceRules {
+			if !rule.attribute.MatchString(k) {
+				continue
+			}
+
+			for _, t := range rule.types {
+				if rule.byName {
+					if address, ok := x.byName[t][value]; ok {
+						return address + ".name"
+					}
+				} else if address, ok := x.byId[t][value]; ok {
+					return address + ".id"
+				}
+			}
+		}
+	}
+
+	return exportLiteral(v)
+}
+
+// variable declares a variable and returns its name.
+func (x *exportRenderer) variable(name string, s *schema.Schema, sensitive bool) string {
+	name = strings.Trim(exportLabelInvalid.ReplaceAllString(strings.ToLower(name), "_"), "_")
+
+	unique := name
+	for i := 2; x.varNames[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	x.varNames[unique] = true
+
+	if x.variables.Len() > 0 {
+		x.variables.WriteString("\n")
+	}
+	if sensitive {
+		fmt.Fprintf(&x.variables, "variable %q {\n  type      = %s\n  sensitive = true\n}\n", unique, exportVariableType(s))
+	} else {
+		fmt.Fprintf(&x.variables, "variable %q {\n  type = %s\n}\n", unique, exportVariableType(s))
+	}
+
+	return unique
+}
+
+func exportVariableType(s *schema.Schema) string {
+	primitive := func(t schema.ValueType) string {
+		switch t {
+		case schema.TypeInt, schema.TypeFloat:
+			return "number"
+		case schema.TypeBool:
+			return "bool"
+		}
+		return "string"
+	}
+
+	elem := schema.TypeString
+	if e, ok := s.Elem.(*schema.Schema); ok {
+		elem = e.Type
+	}
+
+	switch s.Type {
+	case schema.TypeList, schema.TypeSet:
+		return "list(" + primitive(elem) + ")"
+	case schema.TypeMap:
+		return "map(" + primitive(elem) + ")"
+	}
+
+	return primitive(s.Type)
+}
+
+// exportOmit returns whether an optional value can be left out because
+// it's empty or the default.
+func exportOmit(s *schema.Schema, v interface{}) bool {
+	if s.Default != nil {
+		return fmt.Sprint(s.Default) == fmt.Sprint(v)
+	}
+
+	return exportIsZero(v)
+}
+
+func exportIsZero(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case int:
+		return t == 0
+	case float64:
+		return t == 0
+	case bool:
+		return !t
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	case *schema.Set:
+		return t.Len() == 0
+	}
+
+	return false
+}
+
+func exportItems(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		return t
+	case *schema.Set:
+		return t.List()
+	}
+
+	return nil
+}
+
+func exportLiteral(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return exportQuote(t)
+	case int:
+		return strconv.Itoa(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	}
+
+	return exportQuote(fmt.Sprint(v))
+}
+
+var exportQuoteReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"${", "$${",
+	"%{", "%%{",
+)
+
+// exportQuote quotes a string for HCL, escaping template sequences.
+func exportQuote(s string) string {
+	return `"` + exportQuoteReplacer.Replace(s) + `"`
+}
@@ -0,0 +1,126 @@
+package alkira
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testExportTenant is a small recorded tenant: a segment resource
+// referring to a segment, a group and a prefix list, and an AWS
+// credential.
+var testExportTenant = map[string]string{
+	"/tags":                                    `[{"id":"1","name":"Team A"}]`,
+	"/tags/1":                                  `{"id":"1","name":"Team A","description":"network team"}`,
+	"/tenantnetworks/0/segments":               `[{"id":2,"name":"prod"}]`,
+	"/tenantnetworks/0/segments/2":             `{"id":2,"name":"prod","asn":65514,"ipBlock":"10.255.0.0/24"}`,
+	"/tenantnetworks/0/groups":                 `[{"id":3,"name":"branches"}]`,
+	"/tenantnetworks/0/groups/3":               `{"id":3,"name":"branches"}`,
+	"/tenantnetworks/0/policy/prefixlists":     `[{"id":4,"name":"corp"}]`,
+	"/tenantnetworks/0/policy/prefixlists/4":   `{"id":4,"name":"corp","prefixes":["10.0.0.0/8"]}`,
+	"/tenantnetworks/0/segment-resources":      `[{"id":5,"name":"shared services"}]`,
+	"/tenantnetworks/0/segment-resources/5":    `{"id":5,"name":"shared services","segment":"prod","groupPrefixes":[{"groupId":3,"prefixListId":4}]}`,
+	"/api/credentials/":                        `[{"credentialId":"c-1","credentialType":"awsvpc","name":"aws prod"},{"credentialId":"c-2","credentialType":"keypair","name":"ssh"}]`,
+	"/tenantnetworks/0/segments?name=prod":     `[{"id":2,"name":"prod"}]`,
+	"/tenantnetworks/0/segments?name=unknown":  `[]`,
+	"/tenantnetworks/0/groups?name=branches":   `[{"id":3,"name":"branches"}]`,
+	"/tenantnetworks/0/segment-resources?name": `[]`,
+}
+
+func testExportClient(t *testing.T) interface{} {
+	return createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path
+		if name := r.URL.Query().Get("name"); name != "" {
+			key += "?name=" + name
+		}
+
+		body, ok := testExportTenant[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+}
+
+func TestExportTenant(t *testing.T) {
+	p := Provider()
+
+	result, err := exportTenant(context.Background(), p, testExportClient(t), []string{
+		"alkira_billing_tag",
+		"alkira_segment",
+		"alkira_group",
+		"alkira_policy_prefix_list",
+		"alkira_segment_resource",
+		"alkira_credential_aws_vpc",
+	}, listFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"alkira_credential_aws_vpc: the API doesn't return secrets, add them before applying",
+	}, result.Warnings)
+
+	for name, content := range result.Files {
+		_, diags := hclsyntax.ParseConfig(content, name, hcl.InitialPos)
+		require.False(t, diags.HasErrors(), "%s: %s\n%s", name, diags.Error(), content)
+	}
+
+	assert.Equal(t, `resource "alkira_segment_resource" "shared_services" {
+  name       = "shared services"
+  segment_id = alkira_segment.prod.id
+
+  group_prefix {
+    group_id       = alkira_group.branches.id
+    prefix_list_id = alkira_policy_prefix_list.corp.id
+  }
+}
+`, string(result.Files["segment_resource.tf"]))
+
+	credential := string(result.Files["credential_aws_vpc.tf"])
+	assert.Contains(t, credential, `name = "aws prod"`)
+	assert.Contains(t, credential, `type = var.credential_aws_vpc_aws_prod_type`)
+
+	variables := string(result.Files["variables.tf"])
+	assert.Equal(t, "variable \"credential_aws_vpc_aws_prod_type\" {\n  type = string\n}\n", variables)
+
+	imports := string(result.Files["imports.tf"])
+	assert.Contains(t, imports, "import {\n  to = alkira_billing_tag.team_a\n  id = \"1\"\n}\n")
+	assert.Contains(t, imports, "import {\n  to = alkira_credential_aws_vpc.aws_prod\n  id = \"c-1\"\n}\n")
+	assert.Equal(t, 6, strings.Count(imports, "import {"))
+}
+
+func TestExportTenantWarnings(t *testing.T) {
+	p := Provider()
+
+	result, err := exportTenant(context.Background(), p, testExportClient(t), []string{"alkira_ip_pool", "alkira_list_udr"}, listFilter{})
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 2)
+	assert.Equal(t, "alkira_ip_pool: not exported, there is no collection to list", result.Warnings[0])
+	assert.Contains(t, result.Warnings[1], "alkira_list_udr: not exported, failed to list")
+
+	_, err = exportTenant(context.Background(), p, testExportClient(t), []string{"alkira_unknown"}, listFilter{})
+	require.Error(t, err)
+}
+
+func TestExportLabel(t *testing.T) {
+	used := map[string]bool{}
+
+	assert.Equal(t, "branch_1", exportLabel(exportItem{Id: "1", Name: "Branch 1"}, used))
+	assert.Equal(t, "branch_1_2", exportLabel(exportItem{Id: "2", Name: "branch-1"}, used))
+	assert.Equal(t, "r_10_net", exportLabel(exportItem{Id: "3", Name: "10 net"}, used))
+	assert.Equal(t, "id_c_4", exportLabel(exportItem{Id: "c-4"}, used))
+}
+
+func TestExportQuote(t *testing.T) {
+	assert.Equal(t, `"plain"`, exportQuote("plain"))
+	assert.Equal(t, `"say \"hi\"\n"`, exportQuote("say \"hi\"\n"))
+	assert.Equal(t, `"$${var} %%{if}"`, exportQuote("${var} %{if}"))
+	assert.Equal(t, `"C:\\dir"`, exportQuote(`C:\dir`))
+}
@@ -0,0 +1,250 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type functionCidrOverlaps struct{}
+
+func newFunctionCidrOverlaps() function.Function {
+	return &functionCidrOverlaps{}
+}
+
+func (f *functionCidrOverlaps) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_overlaps"
+}
+
+func (f *functionCidrOverlaps) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Checks whether two CIDRs share any address.",
+		MarkdownDescription: "Returns `true` when `a` and `b` share any address, " +
+			"e.g. to keep the `cidrs` of segments or the prefixes of " +
+			"connectors apart. CIDRs of different address families never " +
+			"overlap.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "a",
+				MarkdownDescription: "The first CIDR.",
+			},
+			function.StringParameter{
+				Name:                "b",
+				MarkdownDescription: "The second CIDR.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *functionCidrOverlaps) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var a, b string
+
+	resp.Error = req.Arguments.Get(ctx, &a, &b)
+	if resp.Error != nil {
+		return
+	}
+
+	prefixA, err := parsePrefixArgument(a, 0)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+
+	prefixB, err := parsePrefixArgument(b, 1)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, prefixA.Overlaps(prefixB))
+}
+
+type functionCidrContains struct{}
+
+func newFunctionCidrContains() function.Function {
+	return &functionCidrContains{}
+}
+
+func (f *functionCidrContains) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_contains"
+}
+
+func (f *functionCidrContains) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Checks whether a CIDR contains an address or another CIDR.",
+		MarkdownDescription: "Returns `true` when every address of `value`, either " +
+			"an address or a CIDR, is within `cidr`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr",
+				MarkdownDescription: "The containing CIDR.",
+			},
+			function.StringParameter{
+				Name:                "value",
+				MarkdownDescription: "The address or CIDR to look for.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *functionCidrContains) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidr, value string
+
+	resp.Error = req.Arguments.Get(ctx, &cidr, &value)
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, err := parsePrefixArgument(cidr, 0)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+
+	if addr, err := netip.ParseAddr(value); err == nil {
+		resp.Error = resp.Result.Set(ctx, prefix.Contains(addr))
+		return
+	}
+
+	other, err := parsePrefixArgument(value, 1)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+
+	contains := other.Bits() >= prefix.Bits() && prefix.Contains(other.Addr())
+
+	resp.Error = resp.Result.Set(ctx, contains)
+}
+
+type functionCidrSplitPerCxp struct{}
+
+func newFunctionCidrSplitPerCxp() function.Function {
+	return &functionCidrSplitPerCxp{}
+}
+
+func (f *functionCidrSplitPerCxp) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_split_per_cxp"
+}
+
+func (f *functionCidrSplitPerCxp) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Splits a CIDR into one equal block per CXP.",
+		MarkdownDescription: "Splits `cidr` into the smallest power of two of " +
+			"equal blocks that has one block for every CXP of `cxps` and " +
+			"returns a map of the CXP to its block. The blocks are " +
+			"assigned in the order of `cxps`, so append new CXPs to keep " +
+			"the blocks of the existing ones while there are spare blocks.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr",
+				MarkdownDescription: "The CIDR to split, e.g. one of the `cidrs` of a segment.",
+			},
+			function.ListParameter{
+				Name:                "cxps",
+				ElementType:         types.StringType,
+				MarkdownDescription: "The CXPs, e.g. `[\"US-WEST\", \"US-EAST\"]`.",
+			},
+		},
+		Return: function.MapReturn{ElementType: types.StringType},
+	}
+}
+
+func (f *functionCidrSplitPerCxp) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidr string
+	var elems []types.String
+
+	resp.Error = req.Arguments.Get(ctx, &cidr, &elems)
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, ferr := parsePrefixArgument(cidr, 0)
+	if ferr != nil {
+		resp.Error = ferr
+		return
+	}
+
+	cxps := make([]string, 0, len(elems))
+	for _, e := range elems {
+		cxp := e.ValueString()
+		if e.IsNull() || e.IsUnknown() || cxp == "" {
+			resp.Error = argumentErrorf(1, "cxps must not contain null or empty values")
+			return
+		}
+
+		if stringInSlice(cxp, cxps) {
+			resp.Error = argumentErrorf(1, "duplicate CXP %s", cxp)
+			return
+		}
+
+		cxps = append(cxps, cxp)
+	}
+
+	blocks, err := splitPrefix(prefix, len(cxps))
+	if err != nil {
+		resp.Error = argumentErrorf(0, "%s", err)
+		return
+	}
+
+	result := make(map[string]string, len(cxps))
+	for i, cxp := range cxps {
+		result[cxp] = blocks[i].String()
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
+
+// splitPrefix returns the first n of the smallest power of two of equal
+// blocks of prefix that holds at least n blocks.
+func splitPrefix(prefix netip.Prefix, n int) ([]netip.Prefix, error) {
+	if n == 0 {
+		return nil, nil
+	}
+
+	extra := bits.Len(uint(n - 1))
+	length := prefix.Bits() + extra
+
+	if length > prefix.Addr().BitLen() {
+		return nil, fmt.Errorf("%s is too small to split into %d blocks", prefix, n)
+	}
+
+	blocks := make([]netip.Prefix, 0, n)
+	addr := prefix.Addr()
+
+	for i := 0; i < n; i++ {
+		block := netip.PrefixFrom(addr, length)
+		blocks = append(blocks, block)
+		addr = lastAddr(block).Next()
+	}
+
+	return blocks, nil
+}
+
+// lastAddr returns the last address of a prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// parsePrefixArgument parses the CIDR of the argument at the given
+// position of a call.
+func parsePrefixArgument(v string, argument int) (netip.Prefix, *function.FuncError) {
+	prefix, err := netip.ParsePrefix(v)
+	if err != nil {
+		return netip.Prefix{}, argumentErrorf(argument, "%q is not a valid CIDR", v)
+	}
+
+	return prefix.Masked(), nil
+}
@@ -0,0 +1,136 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// communityFormats are the formats of community values, in the order
+// they are checked.
+var communityFormats = []string{"standard", "large", "extended"}
+
+type functionCommunityValid struct{}
+
+func newFunctionCommunityValid() function.Function {
+	return &functionCommunityValid{}
+}
+
+func (f *functionCommunityValid) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "community_valid"
+}
+
+func (f *functionCommunityValid) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Checks whether a string is a valid BGP community value.",
+		MarkdownDescription: "Returns `true` when `value` is a community in one of " +
+			"the following formats:\n\n" +
+			"* `standard`, `AA:NN` where `AA` and `NN` are `0-65535`, as " +
+			"used by `alkira_list_community`.\n" +
+			"* `large`, `AA:NN1:NN2` where all parts are `0-4294967295`.\n" +
+			"* `extended`, `soo:AA:NN` where `AA` is `0-65535` and `NN` is " +
+			"`0-4294967295` or `soo:IPaddr:NN` where `NN` is `0-65535`, as " +
+			"used by `alkira_list_extended_community`.\n\n" +
+			"Pass any of the formats as additional arguments to accept " +
+			"only those formats, e.g. " +
+			"`provider::alkira::community_valid(var.community, \"standard\")`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "value",
+				MarkdownDescription: "The community value to check.",
+			},
+		},
+		VariadicParameter: function.StringParameter{
+			Name: "formats",
+			MarkdownDescription: "The accepted formats, `standard`, `large` or " +
+				"`extended`. All formats are accepted when none is given.",
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *functionCommunityValid) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var value string
+	var formats []string
+
+	resp.Error = req.Arguments.Get(ctx, &value, &formats)
+	if resp.Error != nil {
+		return
+	}
+
+	for i, format := range formats {
+		if !stringInSlice(format, communityFormats) {
+			resp.Error = argumentErrorf(i+1, "format must be one of %s, got %q",
+				strings.Join(communityFormats, ", "), format)
+			return
+		}
+	}
+
+	if len(formats) == 0 {
+		formats = communityFormats
+	}
+
+	resp.Error = resp.Result.Set(ctx, communityFormat(value, formats) != "")
+}
+
+// communityFormat returns the first of the given formats the community
+// value is in, or "" when it's in none of them.
+func communityFormat(value string, formats []string) string {
+	for _, format := range formats {
+		if validateCommunity(value, format) == nil {
+			return format
+		}
+	}
+
+	return ""
+}
+
+// validateCommunity checks a community value in the given format.
+func validateCommunity(value, format string) error {
+	parts := strings.Split(value, ":")
+
+	switch format {
+	case "standard":
+		if len(parts) != 2 {
+			return fmt.Errorf("%q is not in the format AA:NN", value)
+		}
+		return communityNumbers(parts, 16)
+	case "large":
+		if len(parts) != 3 {
+			return fmt.Errorf("%q is not in the format AA:NN1:NN2", value)
+		}
+		return communityNumbers(parts, 32)
+	case "extended":
+		if len(parts) != 3 || parts[0] != "soo" {
+			return fmt.Errorf("%q is not in the format soo:AA:NN", value)
+		}
+
+		if addr, err := netip.ParseAddr(parts[1]); err == nil {
+			if !addr.Is4() {
+				return fmt.Errorf("%q is not an IPv4 address", parts[1])
+			}
+			return communityNumbers(parts[2:], 16)
+		}
+
+		if err := communityNumbers(parts[1:2], 16); err != nil {
+			return err
+		}
+		return communityNumbers(parts[2:], 32)
+	}
+
+	return fmt.Errorf("unknown community format %s", format)
+}
+
+func communityNumbers(parts []string, bits int) error {
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(part, 10, bits); err != nil {
+			return fmt.Errorf("%q is not a number between 0 and %d", part, uint64(1)<<bits-1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package alkira
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// tunnelPair is the result of the tunnel_pair function.
+type tunnelPair struct {
+	Prefix     string `tfsdk:"prefix"`
+	Netmask    string `tfsdk:"netmask"`
+	CxpIp      string `tfsdk:"cxp_ip"`
+	CustomerIp string `tfsdk:"customer_ip"`
+}
+
+var tunnelPairAttributeTypes = map[string]attr.Type{
+	"prefix":      types.StringType,
+	"netmask":     types.StringType,
+	"cxp_ip":      types.StringType,
+	"customer_ip": types.StringType,
+}
+
+type functionTunnelPair struct{}
+
+func newFunctionTunnelPair() function.Function {
+	return &functionTunnelPair{}
+}
+
+func (f *functionTunnelPair) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "tunnel_pair"
+}
+
+func (f *functionTunnelPair) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Returns the addresses of both ends of a /30 of a tunnel CIDR.",
+		MarkdownDescription: "Carves the `/30` at `index` out of `cidr` and returns " +
+			"an object with its `prefix`, its `netmask` and the " +
+			"addresses of both ends, `cxp_ip` for the first host address " +
+			"and `customer_ip` for the second one, like " +
+			"`alkira_ip_pool_allocation` does.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr",
+				MarkdownDescription: "The IPv4 CIDR of the tunnels, e.g. `169.254.0.0/24`.",
+			},
+			function.NumberParameter{
+				Name:                "index",
+				MarkdownDescription: "The index of the `/30` within the CIDR, starting from `0`.",
+			},
+		},
+		Return: function.ObjectReturn{AttributeTypes: tunnelPairAttributeTypes},
+	}
+}
+
+func (f *functionTunnelPair) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidr string
+	var index *big.Float
+
+	resp.Error = req.Arguments.Get(ctx, &cidr, &index)
+	if resp.Error != nil {
+		return
+	}
+
+	pool, err := newIpPool(cidr, 30, nil)
+	if err != nil {
+		resp.Error = argumentErrorf(0, "%s", err)
+		return
+	}
+
+	i, accuracy := index.Int64()
+	if accuracy != big.Exact || i < 0 || i >= int64(pool.size()) {
+		resp.Error = argumentErrorf(1, "index must be a whole number between 0 and %d, got %s",
+			pool.size()-1, index.String())
+		return
+	}
+
+	prefix := pool.block(int(i))
+
+	hosts, err := ipPoolBlockHosts(prefix)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, tunnelPair{
+		Prefix:     prefix.String(),
+		Netmask:    "255.255.255.252",
+		CxpIp:      hosts[0],
+		CustomerIp: hosts[1],
+	})
+}
@@ -6,13 +6,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"math/rand"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -81,13 +82,15 @@ func expandSegmentOptions(in *schema.Set, m interface{}) (alkira.SegmentNameToZo
 // but users never configure it in HCL.
 const alkiraManagementZoneName = "ALKIRA_MGMT_ZONE"
 
-func deflateSegmentOptions(c alkira.SegmentNameToZone) []map[string]interface{} {
+func deflateSegmentOptions(ctx context.Context, c alkira.SegmentNameToZone) []map[string]interface{} {
 	var options []map[string]interface{}
 
 	for _, outerZoneToGroups := range c {
 		for zone, groups := range outerZoneToGroups.ZonesToGroups {
 			if zone == alkiraManagementZoneName {
-				log.Printf("[DEBUG] Filtering out backend-injected %s from segment_options", alkiraManagementZoneName)
+				tflog.SubsystemDebug(ctx, logSubsystemSchema, "filtering out backend-injected zone from segment_options", map[string]interface{}{
+					"zone": alkiraManagementZoneName,
+				})
 				continue
 			}
 			i := map[string]interface{}{
@@ -105,7 +108,6 @@ func deflateSegmentOptions(c alkira.SegmentNameToZone) []map[string]interface{}
 // convertTypeListToIntList convert a TypeList into a list of int
 func convertTypeListToIntList(in []interface{}) []int {
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] empty TypeList to convert to IntList")
 		return nil
 	}
 
@@ -120,10 +122,7 @@ func convertTypeListToIntList(in []interface{}) []int {
 
 // convertTypeListToStringList convert a TypeList into a list of string
 func convertTypeListToStringList(in []interface{}) []string {
-	log.Printf("[DEBUG] Convert TypeList %v", in)
-
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] empty TypeList to convert to StringList")
 		return nil
 	}
 
@@ -144,7 +143,6 @@ func convertTypeListToStringList(in []interface{}) []string {
 func convertTypeSetToIntList(in *schema.Set) []int {
 
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] empty TypeSet to convert to IntList")
 		return nil
 	}
 
@@ -161,7 +159,6 @@ func convertTypeSetToIntList(in *schema.Set) []int {
 func convertTypeSetToStringList(in *schema.Set) []string {
 
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] empty TypeSet to convert to StringList")
 		return nil
 	}
 
@@ -186,15 +183,13 @@ func convertStringArrToInterfaceArr(sArr []string) []interface{} {
 func getAllCredentialsAsCredentialResponseDetails(client *alkira.AlkiraClient) ([]alkira.CredentialResponseDetail, error) {
 	credentials, err := client.GetCredentials()
 	if err != nil {
-		log.Printf("[INFO] Failed getting Credential list")
 		return nil, err
 	}
 
 	var result []alkira.CredentialResponseDetail
 	err = json.Unmarshal([]byte(credentials), &result)
 	if err != nil {
-		log.Printf("[INFO] Failed Unmarshalling Credentials")
-		return nil, err
+		return nil, fmt.Errorf("failed to unmarshal credentials: %w", err)
 	}
 
 	return result, nil
@@ -238,7 +233,6 @@ func convertInputTimeToEpoch(t string) (int64, error) {
 	timeInput, err := time.Parse(layout, t)
 
 	if err != nil {
-		log.Printf("[ERROR] Failed parse the time input.")
 		return 0, err
 	}
 
@@ -269,6 +263,61 @@ func importWithReadValidation(readFunc schema.ReadContextFunc) schema.StateConte
 	}
 }
 
+// getWriteOnlyString returns the value of a write-only attribute, which
+// is only available in the raw configuration, or "" when it isn't set.
+func getWriteOnlyString(d *schema.ResourceData, path cty.Path) (string, error) {
+	if d.GetRawConfig().IsNull() {
+		return "", nil
+	}
+
+	v, diags := d.GetRawConfigAt(path)
+	if diags.HasError() {
+		return "", fmt.Errorf("failed to read write-only attribute: %s", diags[0].Detail)
+	}
+
+	if v.IsNull() || !v.IsKnown() || !v.Type().Equals(cty.String) {
+		return "", nil
+	}
+
+	return v.AsString(), nil
+}
+
+// rawConfigBlocks returns the elements of the list or block name of a
+// raw configuration value, or nil when either of them is null or
+// unknown.
+func rawConfigBlocks(v cty.Value, name string) []cty.Value {
+	if v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+
+	blocks := v.GetAttr(name)
+	if blocks.IsNull() || !blocks.IsKnown() {
+		return nil
+	}
+
+	return blocks.AsValueSlice()
+}
+
+// rawConfigIsSet returns whether the attribute name of a raw
+// configuration value is set. Unknown values count as set, empty
+// strings and lists don't.
+func rawConfigIsSet(v cty.Value, name string) bool {
+	attr := v.GetAttr(name)
+
+	switch {
+	case attr.IsNull():
+		return false
+	case !attr.IsKnown():
+		return true
+	case attr.Type().Equals(cty.String):
+		return attr.AsString() != ""
+	case attr.Type().IsListType() || attr.Type().IsSetType() || attr.Type().IsTupleType():
+		return attr.LengthInt() > 0
+	}
+
+	return true
+}
+
 // toInt converts a value to int, handling both int and string representations
 // that may appear in raw state maps.
 func toInt(v interface{}) int {
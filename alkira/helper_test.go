@@ -341,7 +341,7 @@ func TestDeflateSegmentOptionsHandlesNilGroups(t *testing.T) {
 		ZonesToGroups: zonesToGroups,
 	}
 
-	result := deflateSegmentOptions(segmentOptions)
+	result := deflateSegmentOptions(context.Background(), segmentOptions)
 
 	// Should have 2 entries (one for each zone)
 	assert.Len(t, result, 2)
@@ -408,7 +408,7 @@ func TestDeflateSegmentOptionsCanBeSetInTerraformState(t *testing.T) {
 	}
 
 	// Call deflateSegmentOptions (with fix: converts int to string)
-	deflatedOptions := deflateSegmentOptions(segmentOptions)
+	deflatedOptions := deflateSegmentOptions(context.Background(), segmentOptions)
 
 	// Attempt d.Set() - this is where the bug would cause failure
 	err := d.Set("segment_options", deflatedOptions)
@@ -467,7 +467,7 @@ func TestDeflateSegmentOptionsFiltersManagementZone(t *testing.T) {
 		ZonesToGroups: zonesToGroups,
 	}
 
-	result := deflateSegmentOptions(segmentOptions)
+	result := deflateSegmentOptions(context.Background(), segmentOptions)
 
 	assert.Len(t, result, 1, "ALKIRA_MGMT_ZONE should be filtered out")
 	assert.Equal(t, "trust-zone", result[0]["zone_name"])
@@ -493,7 +493,7 @@ func TestDeflateSegmentOptionsFiltersManagementZoneMultipleSegments(t *testing.T
 		ZonesToGroups: zones2,
 	}
 
-	result := deflateSegmentOptions(segmentOptions)
+	result := deflateSegmentOptions(context.Background(), segmentOptions)
 
 	assert.Len(t, result, 2, "Only non-mgmt zones should remain")
 
@@ -0,0 +1,181 @@
+package alkira
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+)
+
+// listFilter narrows the objects of a collection down for discovery.
+// Name is a shell pattern, e.g. "branch-*", Segment the name of a
+// segment the objects are attached to and Cxp the CXP they are
+// provisioned in. Empty fields match everything.
+//
+// It backs the filters of the export subcommand and of the list
+// resources for `terraform query`, see listResource.
+type listFilter struct {
+	Name    string
+	Segment string
+	Cxp     string
+
+	segmentId string
+}
+
+func (f *listFilter) empty() bool {
+	return f.Name == "" && f.Segment == "" && f.Cxp == ""
+}
+
+// resolve validates the filter and looks up the ID of its segment.
+func (f *listFilter) resolve(client *alkira.AlkiraClient) error {
+	if _, err := path.Match(f.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern %q: %w", f.Name, err)
+	}
+
+	if f.Segment == "" {
+		return nil
+	}
+
+	segment, _, err := alkira.NewSegment(client).GetByName(f.Segment)
+	if err != nil {
+		return fmt.Errorf("failed to find segment %q: %w", f.Segment, err)
+	}
+	f.segmentId = string(segment.Id)
+
+	return nil
+}
+
+func (f *listFilter) match(resourceType string, item exportItem) bool {
+	if f.Name != "" {
+		if ok, _ := path.Match(f.Name, item.Name); !ok {
+			return false
+		}
+	}
+
+	if f.Segment != "" {
+		if resourceType == "alkira_segment" {
+			if item.Name != f.Segment {
+				return false
+			}
+		} else if !listReferencesSegment(item.Object, f.Segment, f.segmentId) {
+			return false
+		}
+	}
+
+	if f.Cxp != "" {
+		cxp, _ := item.Object["cxp"].(string)
+		if !strings.EqualFold(cxp, f.Cxp) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// listReferencesSegment returns whether an object refers to a segment,
+// by name in segment and segments fields or by ID in segmentId and
+// segmentIds fields, at any depth.
+func listReferencesSegment(v interface{}, name, id string) bool {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, value := range t {
+			switch k {
+			case "segment", "segments":
+				if listContains(value, name) {
+					return true
+				}
+			case "segmentId", "segmentIds":
+				if id != "" && listContains(value, id) {
+					return true
+				}
+			}
+
+			if listReferencesSegment(value, name, id) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, e := range t {
+			if listReferencesSegment(e, name, id) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func listContains(v interface{}, s string) bool {
+	switch t := v.(type) {
+	case []interface{}:
+		for _, e := range t {
+			if fmt.Sprint(e) == s {
+				return true
+			}
+		}
+		return false
+	case nil, map[string]interface{}:
+		return false
+	}
+
+	return fmt.Sprint(v) == s
+}
+
+// listSupported returns whether a resource type can be discovered:
+// connectors, services, segments, policies and lists.
+func listSupported(resourceType string) bool {
+	for _, prefix := range []string{
+		"alkira_connector_",
+		"alkira_service_",
+		"alkira_segment",
+		"alkira_policy",
+		"alkira_list_",
+	} {
+		if strings.HasPrefix(resourceType, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listObjects lists the objects of a resource type matching a filter,
+// sorted by ID.
+func listObjects(client *alkira.AlkiraClient, resourceType string, filter listFilter) ([]exportItem, error) {
+	if !listSupported(resourceType) {
+		return nil, fmt.Errorf("resource type %q doesn't support listing", resourceType)
+	}
+
+	var list exportListFunc
+	for _, c := range exportCollections {
+		if c.resourceType == resourceType {
+			list = c.list
+		}
+	}
+
+	if list == nil {
+		return nil, fmt.Errorf("resource type %q doesn't support listing", resourceType)
+	}
+
+	if err := filter.resolve(client); err != nil {
+		return nil, err
+	}
+
+	items, err := list(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []exportItem
+	for _, item := range items {
+		if filter.match(resourceType, item) {
+			matched = append(matched, item)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool { return exportIdLess(matched[i].Id, matched[j].Id) })
+
+	return matched, nil
+}
@@ -0,0 +1,216 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	listschema "github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// listResource lists the objects of a resource type of the SDK provider
+// for `terraform query`. The objects are read through the SDK server,
+// like an import, when the query asks for the whole resource.
+type listResource struct {
+	resourceType string
+	resource     *schema.Resource
+	sdk          tfprotov5.ProviderServer
+	client       *alkira.AlkiraClient
+}
+
+type listResourceModel struct {
+	Name    types.String `tfsdk:"name"`
+	Segment types.String `tfsdk:"segment"`
+	Cxp     types.String `tfsdk:"cxp"`
+}
+
+// listResources returns the list resources of the resource types of the
+// provider that can be discovered, see listSupported.
+func listResources(p *schema.Provider, sdk tfprotov5.ProviderServer) []func() list.ListResource {
+	var resourceTypes []string
+	for _, c := range exportCollections {
+		if _, ok := p.ResourcesMap[c.resourceType]; ok && listSupported(c.resourceType) {
+			resourceTypes = append(resourceTypes, c.resourceType)
+		}
+	}
+	sort.Strings(resourceTypes)
+
+	resources := make([]func() list.ListResource, 0, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		r := &listResource{
+			resourceType: resourceType,
+			resource:     p.ResourcesMap[resourceType],
+			sdk:          sdk,
+		}
+		resources = append(resources, func() list.ListResource { return r })
+	}
+
+	return resources
+}
+
+func (r *listResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = r.resourceType
+}
+
+func (r *listResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = listschema.Schema{
+		MarkdownDescription: fmt.Sprintf("Lists the `%s` objects of the tenant network.", r.resourceType),
+		Attributes: map[string]listschema.Attribute{
+			"name": listschema.StringAttribute{
+				MarkdownDescription: "A shell pattern the name of the objects must match, e.g. `branch-*`.",
+				Optional:            true,
+			},
+			"segment": listschema.StringAttribute{
+				MarkdownDescription: "The name of a segment the objects must be attached to.",
+				Optional:            true,
+			},
+			"cxp": listschema.StringAttribute{
+				MarkdownDescription: "The CXP the objects must be provisioned in.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// RawV5Schemas returns the schemas of the resource of the SDK, which the
+// framework has no schema of.
+func (r *listResource) RawV5Schemas(ctx context.Context, req list.RawV5SchemaRequest, resp *list.RawV5SchemaResponse) {
+	resp.ProtoV5Schema = r.resource.ProtoSchema(ctx)()
+	resp.ProtoV5IdentitySchema = r.resource.ProtoIdentitySchema(ctx)()
+}
+
+func (r *listResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if client, ok := req.ProviderData.(*alkira.AlkiraClient); ok {
+		r.client = client
+	}
+}
+
+func (r *listResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config listResourceModel
+	var diags diag.Diagnostics
+
+	diags.Append(req.Config.Get(ctx, &config)...)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	if r.client == nil {
+		diags.AddError("FAILED TO LIST OBJECTS", "the provider is not configured")
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	items, err := listObjects(r.client, r.resourceType, listFilter{
+		Name:    config.Name.ValueString(),
+		Segment: config.Segment.ValueString(),
+		Cxp:     config.Cxp.ValueString(),
+	})
+	if err != nil {
+		diags.AddError("FAILED TO LIST OBJECTS", err.Error())
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for i, item := range items {
+			if req.Limit > 0 && int64(i) >= req.Limit {
+				return
+			}
+
+			result := req.NewListResult(ctx)
+
+			result.DisplayName = item.Name
+			if result.DisplayName == "" {
+				result.DisplayName = item.Id
+			}
+
+			result.Diagnostics.Append(result.Identity.SetAttribute(ctx, path.Root("tenant_network_id"), r.client.TenantNetworkId)...)
+			result.Diagnostics.Append(result.Identity.SetAttribute(ctx, path.Root("type"), r.resourceType)...)
+			result.Diagnostics.Append(result.Identity.SetAttribute(ctx, path.Root("id"), item.Id)...)
+
+			if req.IncludeResource && !result.Diagnostics.HasError() {
+				result.Diagnostics.Append(r.read(ctx, result.Identity, result.Resource)...)
+			}
+
+			if !push(result) {
+				return
+			}
+		}
+	}
+}
+
+// read reads the object of an identity through the SDK server into the
+// resource of a list result.
+func (r *listResource) read(ctx context.Context, identity *tfsdk.ResourceIdentity, res *tfsdk.Resource) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	s := r.resource.ProtoSchema(ctx)()
+	typ := s.ValueType().(tftypes.Object)
+
+	var id string
+	diags.Append(identity.GetAttribute(ctx, path.Root("id"), &id)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	// Like an import, the state only has the ID set.
+	attributes := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+	for name, t := range typ.AttributeTypes {
+		attributes[name] = tftypes.NewValue(t, nil)
+	}
+	attributes["id"] = tftypes.NewValue(tftypes.String, id)
+
+	state, err := tfprotov5.NewDynamicValue(typ, tftypes.NewValue(typ, attributes))
+	if err != nil {
+		diags.AddError("FAILED TO READ OBJECT", err.Error())
+		return diags
+	}
+
+	identityData, err := tfprotov5.NewDynamicValue(identity.Raw.Type(), identity.Raw)
+	if err != nil {
+		diags.AddError("FAILED TO READ OBJECT", err.Error())
+		return diags
+	}
+
+	resp, err := r.sdk.ReadResource(ctx, &tfprotov5.ReadResourceRequest{
+		TypeName:        r.resourceType,
+		CurrentState:    &state,
+		CurrentIdentity: &tfprotov5.ResourceIdentityData{IdentityData: &identityData},
+	})
+	if err != nil {
+		diags.AddError("FAILED TO READ OBJECT", err.Error())
+		return diags
+	}
+
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			diags.AddError(d.Summary, d.Detail)
+		} else {
+			diags.AddWarning(d.Summary, d.Detail)
+		}
+	}
+
+	if diags.HasError() || resp.NewState == nil {
+		return diags
+	}
+
+	v, err := resp.NewState.Unmarshal(typ)
+	if err != nil {
+		diags.AddError("FAILED TO READ OBJECT", err.Error())
+		return diags
+	}
+	res.Raw = v
+
+	return diags
+}
@@ -0,0 +1,171 @@
+package alkira
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testListClient(t *testing.T) *alkira.AlkiraClient {
+	responses := map[string]string{
+		"/tenantnetworks/0/segments":             `[{"id":2,"name":"prod"},{"id":7,"name":"dev"}]`,
+		"/tenantnetworks/0/segments/2":           `{"id":2,"name":"prod","asn":65514,"ipBlock":"10.255.0.0/24"}`,
+		"/tenantnetworks/0/segments?name=prod":   `[{"id":2,"name":"prod"}]`,
+		"/tenantnetworks/0/awsvpcconnectors":     `[{"id":12,"name":"branch-2","cxp":"US-WEST","segments":["prod"]},{"id":11,"name":"branch-1","cxp":"us-east","segments":["prod"]},{"id":13,"name":"hub","cxp":"US-WEST","segments":["dev"]}]`,
+		"/tenantnetworks/0/policy/policies":      `[{"id":20,"name":"allow","segmentIds":[2]},{"id":21,"name":"deny","segmentIds":[7]}]`,
+		"/tenantnetworks/0/segments?name=absent": `[]`,
+	}
+
+	return createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path
+		if name := r.URL.Query().Get("name"); name != "" {
+			key += "?name=" + name
+		}
+
+		body, ok := responses[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+}
+
+func TestListObjects(t *testing.T) {
+	client := testListClient(t)
+
+	tests := []struct {
+		name         string
+		resourceType string
+		filter       listFilter
+		want         []string
+	}{
+		{"all", "alkira_connector_aws_vpc", listFilter{}, []string{"11", "12", "13"}},
+		{"name pattern", "alkira_connector_aws_vpc", listFilter{Name: "branch-*"}, []string{"11", "12"}},
+		{"cxp", "alkira_connector_aws_vpc", listFilter{Cxp: "us-west"}, []string{"12", "13"}},
+		{"segment by name", "alkira_connector_aws_vpc", listFilter{Segment: "prod"}, []string{"11", "12"}},
+		{"segment by ID", "alkira_policy", listFilter{Segment: "prod"}, []string{"20"}},
+		{"segment itself", "alkira_segment", listFilter{Segment: "prod"}, []string{"2"}},
+		{"combined", "alkira_connector_aws_vpc", listFilter{Name: "branch-*", Segment: "prod", Cxp: "US-WEST"}, []string{"12"}},
+		{"no objects without a cxp", "alkira_policy", listFilter{Cxp: "US-WEST"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := listObjects(client, tt.resourceType, tt.filter)
+			require.NoError(t, err)
+
+			var got []string
+			for _, item := range items {
+				got = append(got, item.Id)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestListObjectsErrors(t *testing.T) {
+	client := testListClient(t)
+
+	_, err := listObjects(client, "alkira_billing_tag", listFilter{})
+	assert.ErrorContains(t, err, "doesn't support listing")
+
+	_, err = listObjects(client, "alkira_connector_aws_vpc", listFilter{Name: "["})
+	assert.ErrorContains(t, err, "invalid name pattern")
+
+	_, err = listObjects(client, "alkira_connector_aws_vpc", listFilter{Segment: "absent"})
+	assert.ErrorContains(t, err, `failed to find segment "absent"`)
+}
+
+// testListResource lists the objects of a resource type through the
+// muxed server, returning the results by display name.
+func testListResource(t *testing.T, s tfprotov5.ProviderServer, resourceType string, config map[string]tftypes.Value, includeResource bool) map[string]tfprotov5.ListResourceResult {
+	schemas, err := s.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	require.Contains(t, schemas.ListResourceSchemas, resourceType)
+
+	typ := schemas.ListResourceSchemas[resourceType].ValueType().(tftypes.Object)
+	for name, attributeType := range typ.AttributeTypes {
+		if _, ok := config[name]; !ok {
+			config[name] = tftypes.NewValue(attributeType, nil)
+		}
+	}
+
+	dv, err := tfprotov5.NewDynamicValue(typ, tftypes.NewValue(typ, config))
+	require.NoError(t, err)
+
+	stream, err := s.(tfprotov5.ProviderServerWithListResource).ListResource(context.Background(), &tfprotov5.ListResourceRequest{
+		TypeName:        resourceType,
+		Config:          &dv,
+		IncludeResource: includeResource,
+		Limit:           100,
+	})
+	require.NoError(t, err)
+
+	results := make(map[string]tfprotov5.ListResourceResult)
+	for result := range stream.Results {
+		require.Empty(t, result.Diagnostics)
+		results[result.DisplayName] = result
+	}
+
+	return results
+}
+
+func TestListResource(t *testing.T) {
+	p := Provider()
+	s := testProviderServer(t, p)
+	testConfigureProvider(t, s, p, testListClient(t))
+
+	metadata, err := s.GetMetadata(context.Background(), &tfprotov5.GetMetadataRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, metadata.ListResources, tfprotov5.ListResourceMetadata{TypeName: "alkira_connector_aws_vpc"})
+	assert.Contains(t, metadata.ListResources, tfprotov5.ListResourceMetadata{TypeName: "alkira_policy"})
+	assert.NotContains(t, metadata.ListResources, tfprotov5.ListResourceMetadata{TypeName: "alkira_billing_tag"})
+
+	results := testListResource(t, s, "alkira_connector_aws_vpc", map[string]tftypes.Value{
+		"name": tftypes.NewValue(tftypes.String, "branch-*"),
+		"cxp":  tftypes.NewValue(tftypes.String, "US-WEST"),
+	}, false)
+	require.Len(t, results, 1)
+	require.Contains(t, results, "branch-2")
+	assert.Nil(t, results["branch-2"].Resource)
+
+	identitySchemas, err := s.GetResourceIdentitySchemas(context.Background(), &tfprotov5.GetResourceIdentitySchemasRequest{})
+	require.NoError(t, err)
+
+	identity, err := results["branch-2"].Identity.IdentityData.Unmarshal(
+		identitySchemas.IdentitySchemas["alkira_connector_aws_vpc"].ValueType())
+	require.NoError(t, err)
+
+	values := make(map[string]tftypes.Value)
+	require.NoError(t, identity.As(&values))
+	assert.Equal(t, tftypes.NewValue(tftypes.String, "0"), values["tenant_network_id"])
+	assert.Equal(t, tftypes.NewValue(tftypes.String, "alkira_connector_aws_vpc"), values["type"])
+	assert.Equal(t, tftypes.NewValue(tftypes.String, "12"), values["id"])
+
+	// The whole object is read through the resource of the SDK.
+	results = testListResource(t, s, "alkira_segment", map[string]tftypes.Value{
+		"name": tftypes.NewValue(tftypes.String, "prod"),
+	}, true)
+	require.Contains(t, results, "prod")
+	require.NotNil(t, results["prod"].Resource)
+
+	schemas, err := s.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+
+	segment, err := results["prod"].Resource.Unmarshal(schemas.ResourceSchemas["alkira_segment"].ValueType())
+	require.NoError(t, err)
+
+	values = make(map[string]tftypes.Value)
+	require.NoError(t, segment.As(&values))
+	assert.Equal(t, tftypes.NewValue(tftypes.String, "2"), values["id"])
+	assert.Equal(t, tftypes.NewValue(tftypes.String, "prod"), values["name"])
+}
@@ -0,0 +1,458 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// tflog subsystems of the provider. The level of each can be tuned
+// independently with TF_LOG_PROVIDER_ALKIRA_<SUBSYSTEM>, e.g.
+// TF_LOG_PROVIDER_ALKIRA_CLIENT=DEBUG.
+const (
+	// logSubsystemClient is used for all HTTP traffic between the
+	// provider and the Alkira portal.
+	logSubsystemClient = "client"
+
+	// logSubsystemSchema is used when converting between Terraform
+	// attributes and portal objects.
+	logSubsystemSchema = "schema"
+
+	// logSubsystemCredential is used when creating the credentials
+	// referenced by connectors and services.
+	logSubsystemCredential = "credential"
+)
+
+// logResourceSubsystems are the subsystems available to the CRUD
+// functions of resources and data sources.
+var logResourceSubsystems = []string{logSubsystemSchema, logSubsystemCredential}
+
+// Structured log field keys shared by the provider and the client
+// subsystem.
+const (
+	logFieldId                 = "alkira_id"
+	logFieldRequestId          = "alkira_request_id"
+	logFieldProvisionRequestId = "alkira_provision_request_id"
+)
+
+// logMask is what every redacted value is replaced with. It matches
+// the mask used by tflog so redacted output looks the same regardless
+// of which logger wrote it.
+const logMask = "***"
+
+// apiSensitiveKeys are the JSON keys of request and response bodies
+// exchanged with the portal that carry secret material. They are
+// matched case-insensitively, so the Go field names printed by %+v
+// (e.g. Password, LicenseKey) are covered as well.
+var apiSensitiveKeys = []string{
+	"accountKey",
+	"activationCode",
+	"activationKey",
+	"adminPassword",
+	"apiKey",
+	"authCode",
+	"authKey",
+	"bgpAuthenticationKey",
+	"bindPassword",
+	"configData",
+	"ec2AccessKey",
+	"ec2SecretKey",
+	"fmcRegistrationKey",
+	"key",
+	"licenseKey",
+	"localPublicSharedKey",
+	"masterKey",
+	"password",
+	"preSharedKey",
+	"presharedKeys",
+	"private_key",
+	"private_key_id",
+	"privateKey",
+	"registrationKey",
+	"registrationPinValue",
+	"remotePublicSharedKey",
+	"secret",
+	"secretKey",
+	"sharedSecret",
+	"sicKey",
+	"tlsCertificate",
+}
+
+// attributeSensitiveKeys are the Terraform attributes, across all
+// resources, that hold secret material. Most of them predate the use
+// of Sensitive in this provider and cannot be flagged without breaking
+// existing configurations that output them, so they are listed here
+// explicitly. Attributes flagged Sensitive are picked up from the
+// schema in addition to this list.
+var attributeSensitiveKeys = []string{
+	"account_key",
+	"activation_code",
+	"activation_key",
+	"admin_password",
+	"akamai_bgp_authentication_key",
+	"api_key",
+	"auth_code",
+	"auth_key",
+	"aws_access_key",
+	"aws_secret_key",
+	"bgp_auth_key",
+	"bgp_auth_key_alkira",
+	"f5_password",
+	"f5_registration_key",
+	"fmc_registration_key",
+	"license_key",
+	"local_public_shared_key",
+	"master_key",
+	"pan_license_key",
+	"pan_password",
+	"password",
+	"pre_shared_key",
+	"preshared_key",
+	"preshared_keys",
+	"private_key",
+	"private_key_id",
+	"registration_key",
+	"registration_pin_value",
+	"remote_auth_value",
+	"remote_public_shared_key",
+	"secret_key",
+	"shared_secret",
+	"sic_key",
+}
+
+// authorizationRegex matches the credentials of an Authorization
+// header as built by the client ("api-key <base64>" or
+// "basic <base64>").
+var authorizationRegex = regexp.MustCompile(`(?i)\b(api-key|basic)\s+[A-Za-z0-9+/=]{8,}`)
+
+// logRedactor masks sensitive values in free-form log text and carries
+// the matching tflog mask configuration.
+type logRedactor struct {
+	keys      []string
+	jsonRegex *regexp.Regexp
+	goRegex   *regexp.Regexp
+}
+
+// newLogRedactor builds a redactor for the given attribute and JSON
+// keys. Duplicates and empty keys are ignored.
+func newLogRedactor(keys ...string) *logRedactor {
+	seen := make(map[string]bool)
+	var uniq []string
+
+	for _, k := range keys {
+		lk := strings.ToLower(k)
+		if k == "" || seen[lk] {
+			continue
+		}
+		seen[lk] = true
+		uniq = append(uniq, k)
+	}
+
+	sort.Strings(uniq)
+
+	quoted := make([]string, len(uniq))
+	for i, k := range uniq {
+		quoted[i] = regexp.QuoteMeta(k)
+	}
+	alternation := strings.Join(quoted, "|")
+
+	return &logRedactor{
+		keys: uniq,
+		// "key": "value" and "key": ["v1", "v2"] in JSON bodies
+		jsonRegex: regexp.MustCompile(`(?i)("(?:` + alternation + `)"\s*:\s*)("(?:[^"\\]|\\.)*"|\[[^\]]*\])`),
+		// Key:value in Go struct dumps (%+v)
+		goRegex: regexp.MustCompile(`(?i)\b((?:` + alternation + `):[ \t]*)([^\s{}\[\]]+)`),
+	}
+}
+
+// minMaskedValueLength is the shortest secret value masked literally.
+// Shorter values would mask unrelated parts of log messages.
+const minMaskedValueLength = 4
+
+// isSensitiveKey reports whether k is one of the redacted keys.
+func (r *logRedactor) isSensitiveKey(k string) bool {
+	for _, key := range r.keys {
+		if strings.EqualFold(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns s with every sensitive value replaced by the mask.
+func (r *logRedactor) Redact(s string) string {
+	s = r.jsonRegex.ReplaceAllString(s, `${1}"`+logMask+`"`)
+	s = r.goRegex.ReplaceAllString(s, "${1}"+logMask)
+	s = authorizationRegex.ReplaceAllString(s, "${1} "+logMask)
+	return s
+}
+
+// messageRegexes returns the expressions used to mask tflog messages.
+func (r *logRedactor) messageRegexes() []*regexp.Regexp {
+	return []*regexp.Regexp{r.jsonRegex, r.goRegex, authorizationRegex}
+}
+
+// maskContext applies the redactor to the provider root logger in ctx.
+func (r *logRedactor) maskContext(ctx context.Context) context.Context {
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, r.keys...)
+	ctx = tflog.MaskMessageRegexes(ctx, r.messageRegexes()...)
+	return ctx
+}
+
+// maskSubsystemContext applies the redactor to a tflog subsystem in ctx.
+func (r *logRedactor) maskSubsystemContext(ctx context.Context, subsystem string) context.Context {
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, subsystem, r.keys...)
+	ctx = tflog.SubsystemMaskMessageRegexes(ctx, subsystem, r.messageRegexes()...)
+	return ctx
+}
+
+// subsystemContext adds a tflog subsystem to ctx with the fields of the
+// root logger, its level read from TF_LOG_PROVIDER_ALKIRA_<SUBSYSTEM>
+// and the redactor applied.
+func (r *logRedactor) subsystemContext(ctx context.Context, subsystem string) context.Context {
+	ctx = tflog.NewSubsystem(ctx, subsystem,
+		tflog.WithLevelFromEnv("TF_LOG_PROVIDER_ALKIRA", subsystem),
+		tflog.WithRootFields(),
+	)
+	return r.maskSubsystemContext(ctx, subsystem)
+}
+
+// redactingWriter redacts every write before passing it on. It is
+// installed as the output of the standard library logger, which the
+// Alkira client uses to log raw HTTP request and response bodies.
+type redactingWriter struct {
+	redactor *logRedactor
+	out      io.Writer
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	redacted := w.redactor.Redact(string(p))
+
+	if _, err := io.WriteString(w.out, redacted); err != nil {
+		return 0, err
+	}
+
+	// Report the original length; the caller does not care that the
+	// redacted line is shorter.
+	return len(p), nil
+}
+
+var installRedactingWriterOnce sync.Once
+
+// installRedactingWriter wraps the standard logger output so that HTTP
+// bodies logged by the client are redacted before reaching any sink.
+func installRedactingWriter(r *logRedactor) {
+	installRedactingWriterOnce.Do(func() {
+		if _, ok := log.Writer().(*redactingWriter); ok {
+			return
+		}
+		log.SetOutput(&redactingWriter{redactor: r, out: log.Writer()})
+	})
+}
+
+// sensitiveSchemaKeys walks the schema of every resource and data
+// source and returns the names of all attributes marked Sensitive,
+// including attributes of nested blocks.
+func sensitiveSchemaKeys(p *schema.Provider) []string {
+	var keys []string
+
+	var walk func(map[string]*schema.Schema)
+	walk = func(m map[string]*schema.Schema) {
+		for k, s := range m {
+			if s.Sensitive {
+				keys = append(keys, k)
+			}
+			if r, ok := s.Elem.(*schema.Resource); ok {
+				walk(r.Schema)
+			}
+		}
+	}
+
+	walk(p.Schema)
+	for _, r := range p.ResourcesMap {
+		walk(r.Schema)
+	}
+	for _, r := range p.DataSourcesMap {
+		walk(r.Schema)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// configureLogRedaction builds the redactor for provider p and wraps
+// all its resources and data sources so their CRUD functions log with
+// the object ID as a structured field and the mask rules applied.
+func configureLogRedaction(p *schema.Provider) *logRedactor {
+	keys := append([]string{}, apiSensitiveKeys...)
+	keys = append(keys, attributeSensitiveKeys...)
+	keys = append(keys, sensitiveSchemaKeys(p)...)
+	r := newLogRedactor(keys...)
+
+	for _, res := range p.ResourcesMap {
+		withResourceLogging(res, r)
+	}
+	for _, res := range p.DataSourcesMap {
+		withResourceLogging(res, r)
+	}
+
+	return r
+}
+
+// withResourceLogging wraps the context-aware CRUD and CustomizeDiff
+// functions of res.
+// Terraform already adds tf_resource_type and tf_req_id to the logger;
+// this adds the Alkira object ID, the resource subsystems and the
+// redaction masks, including the literal values of the top-level
+// secret attributes of res.
+func withResourceLogging(res *schema.Resource, r *logRedactor) {
+	var secretKeys []string
+	for k, s := range res.Schema {
+		if s.Type == schema.TypeString && (s.Sensitive || r.isSensitiveKey(k)) {
+			secretKeys = append(secretKeys, k)
+		}
+	}
+	sort.Strings(secretKeys)
+
+	wrap := func(fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if fn == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			ctx = r.maskContext(ctx)
+
+			if d.Id() != "" {
+				ctx = tflog.SetField(ctx, logFieldId, d.Id())
+			}
+
+			var values []string
+			for _, k := range secretKeys {
+				if v, ok := d.Get(k).(string); ok && len(v) >= minMaskedValueLength {
+					values = append(values, v)
+				}
+			}
+			if len(values) > 0 {
+				ctx = tflog.MaskLogStrings(ctx, values...)
+			}
+
+			for _, subsystem := range logResourceSubsystems {
+				ctx = r.subsystemContext(ctx, subsystem)
+				if len(values) > 0 {
+					ctx = tflog.SubsystemMaskLogStrings(ctx, subsystem, values...)
+				}
+			}
+			return fn(ctx, d, m)
+		}
+	}
+
+	res.CreateContext = wrap(res.CreateContext)
+	res.ReadContext = wrap(res.ReadContext)
+	res.UpdateContext = wrap(res.UpdateContext)
+	res.DeleteContext = wrap(res.DeleteContext)
+
+	if fn := res.CustomizeDiff; fn != nil {
+		res.CustomizeDiff = func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			ctx = r.maskContext(ctx)
+			for _, subsystem := range logResourceSubsystems {
+				ctx = r.subsystemContext(ctx, subsystem)
+			}
+			return fn(ctx, d, m)
+		}
+	}
+}
+
+// configureClientLogging routes the logging of the retryable HTTP
+// client through the tflog client subsystem. Each attempt is logged
+// with the request ID the client sets in x-ak-request-id and, when the
+// portal returns one, the provision request ID.
+func configureClientLogging(ctx context.Context, client *alkira.AlkiraClient, r *logRedactor) {
+	ctx = r.subsystemContext(ctx, logSubsystemClient)
+
+	installRedactingWriter(r)
+
+	if client == nil || client.Client == nil {
+		return
+	}
+
+	client.Client.Logger = &tflogLeveledLogger{ctx: ctx, redactor: r}
+	client.Client.RequestLogHook = func(_ retryablehttp.Logger, req *http.Request, attempt int) {
+		tflog.SubsystemDebug(ctx, logSubsystemClient, "sending request", map[string]interface{}{
+			logFieldRequestId: req.Header.Get("x-ak-request-id"),
+			"http_method":     req.Method,
+			"http_url":        req.URL.String(),
+			"attempt":         attempt,
+		})
+	}
+	client.Client.ResponseLogHook = func(_ retryablehttp.Logger, resp *http.Response) {
+		fields := map[string]interface{}{
+			"http_status": resp.StatusCode,
+		}
+		if resp.Request != nil {
+			fields[logFieldRequestId] = resp.Request.Header.Get("x-ak-request-id")
+		}
+		if id := resp.Header.Get("x-provision-request-id"); id != "" {
+			fields[logFieldProvisionRequestId] = id
+		}
+		tflog.SubsystemDebug(ctx, logSubsystemClient, "received response", fields)
+	}
+}
+
+// tflogLeveledLogger adapts the client subsystem to the
+// retryablehttp.LeveledLogger interface.
+type tflogLeveledLogger struct {
+	ctx      context.Context
+	redactor *logRedactor
+}
+
+func (l *tflogLeveledLogger) fields(keysAndValues []interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(keysAndValues)/2)
+
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		key := fmt.Sprintf("%v", keysAndValues[i])
+		value := keysAndValues[i+1]
+
+		// retryablehttp passes the request and response objects
+		// through; log a redacted representation only.
+		switch v := value.(type) {
+		case *http.Request:
+			value = v.Method + " " + v.URL.String()
+		case *http.Response:
+			value = v.Status
+		case error:
+			value = l.redactor.Redact(v.Error())
+		case string:
+			value = l.redactor.Redact(v)
+		}
+		fields[key] = value
+	}
+
+	return fields
+}
+
+func (l *tflogLeveledLogger) Error(msg string, keysAndValues ...interface{}) {
+	tflog.SubsystemError(l.ctx, logSubsystemClient, msg, l.fields(keysAndValues))
+}
+
+func (l *tflogLeveledLogger) Info(msg string, keysAndValues ...interface{}) {
+	tflog.SubsystemInfo(l.ctx, logSubsystemClient, msg, l.fields(keysAndValues))
+}
+
+func (l *tflogLeveledLogger) Debug(msg string, keysAndValues ...interface{}) {
+	tflog.SubsystemDebug(l.ctx, logSubsystemClient, msg, l.fields(keysAndValues))
+}
+
+func (l *tflogLeveledLogger) Warn(msg string, keysAndValues ...interface{}) {
+	tflog.SubsystemWarn(l.ctx, logSubsystemClient, msg, l.fields(keysAndValues))
+}
@@ -0,0 +1,159 @@
+package alkira
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRedactorRedact(t *testing.T) {
+	r := newLogRedactor(append(append([]string{}, apiSensitiveKeys...), attributeSensitiveKeys...)...)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "json credential body",
+			input:    `client-create REQ: {"name":"pan","credentials":{"userName":"admin","password":"s3cr3t!","licenseKey":"LK-123"}}`,
+			expected: `client-create REQ: {"name":"pan","credentials":{"userName":"admin","password":"***","licenseKey":"***"}}`,
+		},
+		{
+			name:     "json with spaces and escaped quotes",
+			input:    `{"preSharedKey" : "a\"b\\c", "name": "ipsec"}`,
+			expected: `{"preSharedKey" : "***", "name": "ipsec"}`,
+		},
+		{
+			name:     "json array of preshared keys",
+			input:    `{"presharedKeys":["key1","key2"],"id":1}`,
+			expected: `{"presharedKeys":"***","id":1}`,
+		},
+		{
+			name:     "key match is case-insensitive",
+			input:    `{"PASSWORD":"x1y2z3"}`,
+			expected: `{"PASSWORD":"***"}`,
+		},
+		{
+			name:     "terraform attribute names",
+			input:    `{"pan_password":"hunter22","name":"pan"}`,
+			expected: `{"pan_password":"***","name":"pan"}`,
+		},
+		{
+			name:     "go struct dump",
+			input:    `Request: {Name:conn Password:hunter22 SecretKey:abc Cxp:US-WEST}`,
+			expected: `Request: {Name:conn Password:*** SecretKey:*** Cxp:US-WEST}`,
+		},
+		{
+			name:     "authorization header",
+			input:    `Authorization: api-key c2VjcmV0LWFwaS1rZXk=`,
+			expected: `Authorization: api-key ***`,
+		},
+		{
+			name:     "non-sensitive content is untouched",
+			input:    `client-get(client-1): 200 RSP: {"id":12,"name":"segment","cidrs":["10.0.0.0/16"]}`,
+			expected: `client-get(client-1): 200 RSP: {"id":12,"name":"segment","cidrs":["10.0.0.0/16"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, r.Redact(tt.input))
+		})
+	}
+}
+
+func TestLogRedactorDeduplicatesKeys(t *testing.T) {
+	r := newLogRedactor("password", "Password", "", "secret")
+
+	assert.Equal(t, []string{"password", "secret"}, r.keys)
+	assert.True(t, r.isSensitiveKey("PASSWORD"))
+	assert.False(t, r.isSensitiveKey("name"))
+}
+
+func TestRedactingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &redactingWriter{redactor: newLogRedactor("password"), out: &buf}
+
+	line := []byte(`[DEBUG] client-update: REQ: {"password":"hunter22"}` + "\n")
+	n, err := w.Write(line)
+
+	require.NoError(t, err)
+	assert.Equal(t, len(line), n)
+	assert.Equal(t, `[DEBUG] client-update: REQ: {"password":"***"}`+"\n", buf.String())
+}
+
+func TestSensitiveSchemaKeys(t *testing.T) {
+	keys := sensitiveSchemaKeys(Provider())
+
+	assert.Contains(t, keys, "pre_shared_key")
+	assert.Contains(t, keys, "f5_password")
+}
+
+func TestConfigureLogRedactionKeys(t *testing.T) {
+	r := configureLogRedaction(Provider())
+
+	assert.True(t, r.isSensitiveKey("pan_password"))
+	assert.True(t, r.isSensitiveKey("preSharedKey"))
+	assert.True(t, r.isSensitiveKey("pre_shared_key"))
+}
+
+func TestTflogLeveledLoggerFields(t *testing.T) {
+	l := &tflogLeveledLogger{redactor: newLogRedactor("password")}
+
+	req, err := http.NewRequest("POST", "https://portal.example.com/api/credentials", nil)
+	require.NoError(t, err)
+
+	fields := l.fields([]interface{}{
+		"request", req,
+		"body", `{"password":"hunter22"}`,
+		"attempt", 2,
+		"dangling",
+	})
+
+	assert.Equal(t, "POST https://portal.example.com/api/credentials", fields["request"])
+	assert.Equal(t, `{"password":"***"}`, fields["body"])
+	assert.Equal(t, 2, fields["attempt"])
+	assert.NotContains(t, fields, "dangling")
+}
+
+func TestConfigureClientLoggingSetsHooks(t *testing.T) {
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	configureClientLogging(t.Context(), client, newLogRedactor(apiSensitiveKeys...))
+
+	assert.NotNil(t, client.Client.Logger)
+	assert.NotNil(t, client.Client.RequestLogHook)
+	assert.NotNil(t, client.Client.ResponseLogHook)
+
+	_, err := client.GetTenantNetworks()
+	assert.NoError(t, err)
+}
+
+func TestWithResourceLoggingWrapsCustomizeDiff(t *testing.T) {
+	called := false
+	res := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {Type: schema.TypeString, Optional: true},
+		},
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			called = true
+			tflog.SubsystemDebug(ctx, logSubsystemSchema, "customizing diff")
+			return nil
+		},
+	}
+
+	withResourceLogging(res, newLogRedactor(apiSensitiveKeys...))
+
+	require.NotNil(t, res.CustomizeDiff)
+	assert.NoError(t, res.CustomizeDiff(t.Context(), nil, nil))
+	assert.True(t, called)
+}
@@ -1,18 +1,25 @@
 package alkira
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 // Provider returns a schema.Provider for Alkira.
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"portal": {
 				Description: "The URL for Alkira Custom Portal.",
@@ -71,6 +78,162 @@ func Provider() *schema.Provider {
 				Default:     120,
 				DefaultFunc: envDefaultFunc("ALKIRA_API_SERIALIZATION_TIMEOUT"),
 			},
+			"serialization_concurrency": {
+				Description: "Number of mutating API requests that can run " +
+					"concurrently when serialization is enabled. Requests " +
+					"on the same collection (e.g. segments or billing " +
+					"tags) are always serialized and provisioning " +
+					"requests always run alone. Default is `1`, which " +
+					"serializes all mutating requests.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  intEnvDefaultFunc("ALKIRA_API_SERIALIZATION_CONCURRENCY", 1),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"tenant_network_id": {
+				Description: "The ID of the tenant network to manage. Use it " +
+					"when the credential has access to more than one " +
+					"tenant network. If neither `tenant_network_id` nor " +
+					"`tenant_network_name` is set, the first tenant " +
+					"network is used.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  envDefaultFunc("ALKIRA_TENANT_NETWORK_ID"),
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[0-9]+$`), "must be a numeric tenant network ID"),
+			},
+			"tenant_network_name": {
+				Description: "The name of the tenant network to manage. " +
+					"Conflicts with `tenant_network_id`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: envDefaultFunc("ALKIRA_TENANT_NETWORK_NAME"),
+			},
+			"ca_cert_file": {
+				Description: "Path to a PEM file with additional CA " +
+					"certificates to trust for the portal connection, " +
+					"e.g. for a TLS-intercepting proxy or a portal " +
+					"with an internal CA.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: envDefaultFunc("ALKIRA_CA_CERT_FILE"),
+			},
+			"ca_cert_pem": {
+				Description: "Additional CA certificates to trust in PEM " +
+					"format. Can be combined with `ca_cert_file`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: envDefaultFunc("ALKIRA_CA_CERT_PEM"),
+			},
+			"client_cert_file": {
+				Description: "Path to a PEM client certificate used for " +
+					"mutual TLS with the portal. Requires " +
+					"`client_key_file` or `client_key_pem`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: envDefaultFunc("ALKIRA_CLIENT_CERT_FILE"),
+			},
+			"client_key_file": {
+				Description: "Path to the PEM private key of the client " +
+					"certificate.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: envDefaultFunc("ALKIRA_CLIENT_KEY_FILE"),
+			},
+			"client_cert_pem": {
+				Description: "Client certificate in PEM format. " +
+					"Conflicts with `client_cert_file`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"client_cert_file"},
+			},
+			"client_key_pem": {
+				Description: "Private key of the client certificate in " +
+					"PEM format. Conflicts with `client_key_file`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"client_key_file"},
+			},
+			"proxy_url": {
+				Description: "URL of the proxy used to reach the portal " +
+					"(`http`, `https` or `socks5`). By default the " +
+					"`HTTPS_PROXY` and `NO_PROXY` ENV variables are used.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: envDefaultFunc("ALKIRA_PROXY_URL"),
+			},
+			"max_retries": {
+				Description: "Maximum number of retries of a failed API " +
+					"request. Default is `5`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  intEnvDefaultFunc("ALKIRA_MAX_RETRIES", 5),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_wait_min": {
+				Description: "Minimum time in seconds to wait before " +
+					"retrying a failed API request. Default is `1`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  intEnvDefaultFunc("ALKIRA_RETRY_WAIT_MIN", 1),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_wait_max": {
+				Description: "Maximum time in seconds to wait before " +
+					"retrying a failed API request. A `Retry-After` " +
+					"header returned by the portal takes precedence. " +
+					"Default is `30`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  intEnvDefaultFunc("ALKIRA_RETRY_WAIT_MAX", 30),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"rate_limit": {
+				Description: "Maximum number of API requests per second " +
+					"sent by the provider, shared by all resources. " +
+					"`0` disables rate limiting. Default is `0`.",
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  floatEnvDefaultFunc("ALKIRA_RATE_LIMIT", 0),
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"rate_limit_burst": {
+				Description: "Number of API requests that can be sent at " +
+					"once before `rate_limit` applies. Default is `1`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  intEnvDefaultFunc("ALKIRA_RATE_LIMIT_BURST", 1),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"expiry_warning_days": {
+				Description: "Number of days before an expiry date, e.g. " +
+					"`auth_expiry` of `alkira_service_pan` or a license " +
+					"of `alkira_service_fortinet`, from which plans warn " +
+					"about it. Default is `30`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  intEnvDefaultFunc("ALKIRA_EXPIRY_WARNING_DAYS", defaultExpiryWarningDays),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"check_references": {
+				Description: "Check at plan time that the objects " +
+					"referenced by ID, e.g. `segment_id`, `credential_id` " +
+					"or `billing_tag_ids`, exist and are of the expected " +
+					"type. The checks list the referenced collections, " +
+					"so plans fail when the portal can't be reached. " +
+					"Default is `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: boolEnvDefaultFunc("ALKIRA_CHECK_REFERENCES", false),
+			},
+			"insecure_skip_verify": {
+				Description: "Skip verification of the portal TLS " +
+					"certificate. **INSECURE**, only use it for lab " +
+					"portals. Default is `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: boolEnvDefaultFunc("ALKIRA_INSECURE_SKIP_VERIFY", false),
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
@@ -108,8 +271,10 @@ func Provider() *schema.Provider {
 			"alkira_flow_collector":                                              resourceAlkiraFlowCollector(),
 			"alkira_group":                                                       resourceAlkiraGroup(),
 			"alkira_group_user":                                                  resourceAlkiraGroupUser(),
+			"alkira_group_user_membership":                                       resourceAlkiraGroupUserMembership(),
 			"alkira_group_direct_inter_connector":                                resourceAlkiraDirectInterConnectorGroup(),
 			"alkira_internet_application":                                        resourceAlkiraInternetApplication(),
+			"alkira_ip_pool":                                                     resourceAlkiraIpPool(),
 			"alkira_ip_reservation":                                              resourceAlkiraIpReservation(),
 			"alkira_list_as_path":                                                resourceAlkiraListAsPath(),
 			"alkira_list_community":                                              resourceAlkiraListCommunity(),
@@ -145,12 +310,16 @@ func Provider() *schema.Provider {
 			"alkira_service_pan":                                                 resourceAlkiraServicePan(),
 			"alkira_network_entity_scale_options":                                resourceAlkiraNetworkEntityScaleOptions(),
 			"alkira_service_bluecat":                                             resourceAlkiraBluecat(),
+			"alkira_user":                                                        resourceAlkiraUser(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"alkira_billing_tag":                        dataSourceAlkiraBillingTag(),
 			"alkira_byoip_prefix":                       dataSourceAlkiraByoipPrefix(),
+			"alkira_byoip_message":                      dataSourceAlkiraByoipMessage(),
 			"alkira_byoip":                              dataSourceAlkiraByoip(),
+			"alkira_cidr_summary":                       dataSourceAlkiraCidrSummary(),
 			"alkira_credential":                         dataSourceAlkiraCredential(),
+			"alkira_credentials":                        dataSourceAlkiraCredentials(),
 			"alkira_connector_akamai_prolexic":          dataSourceAlkiraConnectorAkamaiProlexic(),
 			"alkira_connector_aruba_edge":               dataSourceAlkiraConnectorArubaEdge(),
 			"alkira_connector_aws_tgw":                  dataSourceAlkiraConnectorAwsTgw(),
@@ -166,12 +335,15 @@ func Provider() *schema.Provider {
 			"alkira_connector_internet_exit":            dataSourceAlkiraConnectorInternetExit(),
 			"alkira_connector_ipsec":                    dataSourceAlkiraConnectorIpsec(),
 			"alkira_connector_ipsec_adv":                dataSourceAlkiraConnectorIpsecAdv(),
+			"alkira_connector_ipsec_customer_config":    dataSourceAlkiraConnectorIpsecCustomerConfig(),
 			"alkira_connector_oci_vcn":                  dataSourceAlkiraConnectorOciVcn(),
 			"alkira_connector_remote_access":            dataSourceAlkiraConnectorRemoteAccess(),
 			"alkira_connector_vmware_sdwan":             dataSourceAlkiraConnectorVmwareSdwan(),
+			"alkira_expiring_secrets":                   dataSourceAlkiraExpiringSecrets(),
 			"alkira_group":                              dataSourceAlkiraGroup(),
 			"alkira_group_user":                         dataSourceAlkiraGroupUser(),
 			"alkira_internet_application":               dataSourceAlkiraInternetApplication(),
+			"alkira_ip_pool_allocation":                 dataSourceAlkiraIpPoolAllocation(),
 			"alkira_ip_reservation":                     dataSourceAlkiraIpReservation(),
 			"alkira_list_as_path":                       dataSourceAlkiraListAsPath(),
 			"alkira_list_community":                     dataSourceAlkiraListCommunity(),
@@ -183,15 +355,39 @@ func Provider() *schema.Provider {
 			"alkira_peering_gateway_azure_vnet_third_party_connector_attachment": dataSourceAlkiraPeeringGatewayAzureVnetThirdPartyConnectorAttachment(),
 			"alkira_peering_gateway_cxp":                                         dataSourceAlkiraPeeringGatewayCxp(),
 			"alkira_policy":                                                      dataSourceAlkiraPolicy(),
+			"alkira_policy_evaluation":                                           dataSourceAlkiraPolicyEvaluation(),
+			"alkira_policy_nat_evaluation":                                       dataSourceAlkiraPolicyNatEvaluation(),
 			"alkira_policy_nat_rule":                                             dataSourceAlkiraPolicyNatRule(),
 			"alkira_policy_prefix_list":                                          dataSourceAlkiraPolicyPrefixList(),
+			"alkira_policy_routing_evaluation":                                   dataSourceAlkiraPolicyRoutingEvaluation(),
 			"alkira_policy_rule":                                                 dataSourceAlkiraPolicyRule(),
 			"alkira_policy_rule_list":                                            dataSourceAlkiraPolicyRuleList(),
 			"alkira_segment":                                                     dataSourceAlkiraSegment(),
+			"alkira_unreferenced_objects":                                        dataSourceAlkiraUnreferencedObjects(),
+			"alkira_user":                                                        dataSourceAlkiraUser(),
+			"alkira_users":                                                       dataSourceAlkiraUsers(),
 			"alkira_zta_profile":                                                 dataSourceZtaProfile(),
 		},
-		ConfigureFunc: alkiraConfigure,
 	}
+
+	for name, res := range p.ResourcesMap {
+		withResourceIdentity(name, res)
+		withReferenceChecks(name, res)
+		withRetryDiagnostics(res)
+	}
+	for _, res := range p.DataSourcesMap {
+		withRetryDiagnostics(res)
+	}
+
+	// The logging wrappers go last, so the functions added above, e.g.
+	// the reference checks, also log with the object ID and masks.
+	redactor := configureLogRedaction(p)
+
+	p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		return alkiraConfigure(ctx, d, redactor)
+	}
+
+	return p
 }
 
 func envDefaultFunc(k string) schema.SchemaDefaultFunc {
@@ -224,22 +420,227 @@ func boolEnvDefaultFunc(k string, dv bool) schema.SchemaDefaultFunc {
 	}
 }
 
-func alkiraConfigure(d *schema.ResourceData) (interface{}, error) {
-	alkiraClient, err := alkira.NewAlkiraClient(
-		d.Get("portal").(string),
-		d.Get("username").(string),
-		d.Get("password").(string),
-		d.Get("api_key").(string),
-		d.Get("provision").(bool),
-		d.Get("validation").(bool),
+// intEnvDefaultFunc resolves an integer schema default from an
+// environment variable, falling back to dv when the variable is unset.
+func intEnvDefaultFunc(k string, dv int) schema.SchemaDefaultFunc {
+	return func() (interface{}, error) {
+		v := os.Getenv(k)
+		if v == "" {
+			return dv, nil
+		}
+
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer value %q for %s: %w", v, k, err)
+		}
+
+		return parsed, nil
+	}
+}
+
+// floatEnvDefaultFunc resolves a float schema default from an
+// environment variable, falling back to dv when the variable is unset.
+func floatEnvDefaultFunc(k string, dv float64) schema.SchemaDefaultFunc {
+	return func() (interface{}, error) {
+		v := os.Getenv(k)
+		if v == "" {
+			return dv, nil
+		}
+
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number value %q for %s: %w", v, k, err)
+		}
+
+		return parsed, nil
+	}
+}
+
+func alkiraConfigure(ctx context.Context, d *schema.ResourceData, redactor *logRedactor) (interface{}, diag.Diagnostics) {
+	// Install the redacting log writer before the client sends its
+	// first request, as the login exchange is logged too.
+	installRedactingWriter(redactor)
+
+	var diags diag.Diagnostics
+
+	transportConfig := portalTransportConfig{
+		CACertFile:         d.Get("ca_cert_file").(string),
+		CACertPEM:          d.Get("ca_cert_pem").(string),
+		ClientCertFile:     d.Get("client_cert_file").(string),
+		ClientKeyFile:      d.Get("client_key_file").(string),
+		ClientCertPEM:      d.Get("client_cert_pem").(string),
+		ClientKeyPEM:       d.Get("client_key_pem").(string),
+		ProxyURL:           d.Get("proxy_url").(string),
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+	}
+
+	if transportConfig.InsecureSkipVerify {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "TLS CERTIFICATE VERIFICATION DISABLED",
+			Detail: "insecure_skip_verify is enabled, the portal " +
+				"certificate is not verified and the connection is " +
+				"open to man-in-the-middle attacks. Only use it for " +
+				"lab portals.",
+		})
+	}
+
+	retryConfig := clientRetryConfig{
+		MaxRetries:        d.Get("max_retries").(int),
+		WaitMin:           time.Duration(d.Get("retry_wait_min").(int)) * time.Second,
+		WaitMax:           time.Duration(d.Get("retry_wait_max").(int)) * time.Second,
+		RequestsPerSecond: d.Get("rate_limit").(float64),
+		Burst:             d.Get("rate_limit_burst").(int),
+	}
+
+	if err := retryConfig.validate(); err != nil {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "INVALID RETRY CONFIGURATION",
+			Detail:   err.Error(),
+		})
+	}
+
+	var alkiraClient *alkira.AlkiraClient
+	var err error
+
+	if transportConfig.isSet() {
+		tr, trErr := transportConfig.newTransport()
+		if trErr != nil {
+			return nil, append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "INVALID TLS OR PROXY CONFIGURATION",
+				Detail:   trErr.Error(),
+			})
+		}
+
+		alkiraClient, err = newAlkiraClientWithTransport(
+			d.Get("portal").(string),
+			d.Get("username").(string),
+			d.Get("password").(string),
+			d.Get("api_key").(string),
+			d.Get("provision").(bool),
+			d.Get("validation").(bool),
+			tr,
+		)
+	} else {
+		alkiraClient, err = alkira.NewAlkiraClient(
+			d.Get("portal").(string),
+			d.Get("username").(string),
+			d.Get("password").(string),
+			d.Get("api_key").(string),
+			d.Get("provision").(bool),
+			d.Get("validation").(bool),
+			d.Get("serialization_enabled").(bool),
+			d.Get("serialization_timeout").(int),
+			"header",
+		)
+	}
+	if err != nil {
+		tflog.Error(ctx, "failed to initialize alkira provider, please check your credential and portal URI", map[string]interface{}{
+			"portal": d.Get("portal").(string),
+			"error":  redactor.Redact(err.Error()),
+		})
+		return nil, append(diags, diag.FromErr(err)...)
+	}
+
+	configureClientSerialization(
+		alkiraClient,
 		d.Get("serialization_enabled").(bool),
 		d.Get("serialization_timeout").(int),
-		"header",
+		d.Get("serialization_concurrency").(int),
 	)
+	configureClientLogging(ctx, alkiraClient, redactor)
+	configureClientRetry(ctx, alkiraClient, redactor, retryConfig)
+	configureClientExpiryWarning(alkiraClient, d.Get("expiry_warning_days").(int))
+	configureClientReferenceChecks(alkiraClient, d.Get("check_references").(bool))
+
+	tenantNetworkId, err := selectTenantNetwork(
+		alkiraClient,
+		d.Get("tenant_network_id").(string),
+		d.Get("tenant_network_name").(string),
+	)
+	if err != nil {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "FAILED TO SELECT TENANT NETWORK",
+			Detail:   err.Error(),
+		})
+	}
+	alkiraClient.TenantNetworkId = tenantNetworkId
+
+	tflog.Debug(ctx, "configured alkira provider", map[string]interface{}{
+		"portal":            d.Get("portal").(string),
+		"tenant_network_id": alkiraClient.TenantNetworkId,
+		"custom_transport":  transportConfig.isSet(),
+	})
+
+	return alkiraClient, diags
+}
+
+// tenantNetworkSummary is an entry returned by /tenantnetworksummaries.
+type tenantNetworkSummary struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// selectTenantNetwork resolves the tenant network the provider should
+// manage. The client picks the first tenant network summary on
+// login; an explicit id or name is validated against the full list
+// so that a typo is reported instead of silently managing the wrong
+// network. Without id and name, the tenant network of the client is
+// kept, or the first summary is used when the client has none yet.
+func selectTenantNetwork(client *alkira.AlkiraClient, id string, name string) (string, error) {
+	if id == "" && name == "" && client.TenantNetworkId != "" {
+		return client.TenantNetworkId, nil
+	}
+
+	if id != "" && name != "" {
+		return "", fmt.Errorf("only one of tenant_network_id or tenant_network_name can be specified")
+	}
+
+	api := &alkira.AlkiraAPI[tenantNetworkSummary]{
+		Client: client,
+		Uri:    client.URI + "/tenantnetworksummaries",
+	}
+
+	data, err := api.GetAll()
 	if err != nil {
-		log.Printf("[ERROR] failed to initialize alkira provider, please check your credential and portal URI.")
-		return nil, err
+		return "", fmt.Errorf("failed to get tenant network summaries: %w", err)
+	}
+
+	var summaries []tenantNetworkSummary
+	if err := json.Unmarshal([]byte(data), &summaries); err != nil {
+		return "", fmt.Errorf("failed to unmarshal tenant network summaries: %w", err)
+	}
+
+	if id == "" && name == "" {
+		if len(summaries) == 0 {
+			return "", fmt.Errorf("failed to get tenant network ID")
+		}
+		return strconv.Itoa(summaries[0].Id), nil
 	}
 
-	return alkiraClient, nil
+	var available []string
+	var matches []tenantNetworkSummary
+
+	for _, s := range summaries {
+		available = append(available, fmt.Sprintf("%s (%d)", s.Name, s.Id))
+
+		if (id != "" && strconv.Itoa(s.Id) == id) || (name != "" && s.Name == name) {
+			matches = append(matches, s)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if id != "" {
+			return "", fmt.Errorf("tenant network ID %s is not accessible, available tenant networks: %s", id, strings.Join(available, ", "))
+		}
+		return "", fmt.Errorf("tenant network %q is not accessible, available tenant networks: %s", name, strings.Join(available, ", "))
+	case 1:
+		return strconv.Itoa(matches[0].Id), nil
+	default:
+		return "", fmt.Errorf("tenant network name %q is ambiguous, use tenant_network_id instead", name)
+	}
 }
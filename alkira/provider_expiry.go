@@ -0,0 +1,224 @@
+package alkira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const (
+	expiryDateLayout         = "2006-01-02"
+	defaultExpiryWarningDays = 30
+)
+
+// clientExpiryWarningDays maps *alkira.AlkiraClient to the number of
+// days before an expiry date that plans warn about it.
+var clientExpiryWarningDays sync.Map
+
+func configureClientExpiryWarning(client *alkira.AlkiraClient, days int) {
+	clientExpiryWarningDays.Store(client, days)
+}
+
+// expiryWarningDaysFor returns the expiry warning threshold of the
+// client passed as provider meta.
+func expiryWarningDaysFor(m interface{}) int {
+	client, ok := m.(*alkira.AlkiraClient)
+	if !ok || client == nil {
+		return defaultExpiryWarningDays
+	}
+
+	days, ok := clientExpiryWarningDays.Load(client)
+	if !ok {
+		return defaultExpiryWarningDays
+	}
+
+	return days.(int)
+}
+
+// validateExpiryDate checks an expiry date in the format YYYY-MM-DD.
+func validateExpiryDate(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+
+	if v == "" {
+		return nil, nil
+	}
+
+	if _, err := time.Parse(expiryDateLayout, v); err != nil {
+		return nil, []error{fmt.Errorf("%s must be a date in the format YYYY-MM-DD, got %q", k, v)}
+	}
+
+	return nil, nil
+}
+
+// daysRemaining returns the number of whole days from now until the
+// given date, negative once the date has passed.
+func daysRemaining(date string, now time.Time) (int, error) {
+	expiry, err := time.Parse(expiryDateLayout, date)
+	if err != nil {
+		return 0, err
+	}
+
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	return int(math.Round(expiry.Sub(today).Hours() / 24)), nil
+}
+
+// daysRemainingOrNil is daysRemaining for dates which may be empty. It
+// returns nil for an empty or invalid date, so the days remaining are
+// null rather than 0, which would read as expiring today.
+func daysRemainingOrNil(date string) interface{} {
+	days, err := daysRemaining(date, time.Now())
+	if err != nil {
+		return nil
+	}
+
+	return days
+}
+
+// isExpiryAttribute reports whether an attribute holds an expiry date,
+// i.e. it's named *_expiry or expiry_date.
+func isExpiryAttribute(name string) bool {
+	return strings.HasSuffix(name, "_expiry") || name == "expiry_date"
+}
+
+// expiryWarnings returns a warning for every expiry date in a planned
+// state that is within the given number of days or has passed.
+func expiryWarnings(planned cty.Value, now time.Time, threshold int) []*tfprotov5.Diagnostic {
+	var diags []*tfprotov5.Diagnostic
+
+	cty.Walk(planned, func(path cty.Path, v cty.Value) (bool, error) {
+		if len(path) == 0 || !v.IsKnown() || v.IsNull() || v.Type() != cty.String {
+			return true, nil
+		}
+
+		step, ok := path[len(path)-1].(cty.GetAttrStep)
+		if !ok || !isExpiryAttribute(step.Name) {
+			return true, nil
+		}
+
+		days, err := daysRemaining(v.AsString(), now)
+		if err != nil || days > threshold {
+			return true, nil
+		}
+
+		diag := &tfprotov5.Diagnostic{
+			Severity:  tfprotov5.DiagnosticSeverityWarning,
+			Summary:   "EXPIRING SOON",
+			Detail:    fmt.Sprintf("%s expires on %s, in %d days.", ctyPathString(path), v.AsString(), days),
+			Attribute: expiryAttributePath(path),
+		}
+
+		if days < 0 {
+			diag.Summary = "EXPIRED"
+			diag.Detail = fmt.Sprintf("%s expired on %s.", ctyPathString(path), v.AsString())
+		}
+
+		diags = append(diags, diag)
+
+		return true, nil
+	})
+
+	return diags
+}
+
+// ctyPathString returns a path in the dotted notation of the state,
+// e.g. instance.0.auth_expiry.
+func ctyPathString(path cty.Path) string {
+	var parts []string
+
+	for _, step := range path {
+		switch s := step.(type) {
+		case cty.GetAttrStep:
+			parts = append(parts, s.Name)
+		case cty.IndexStep:
+			if s.Key.Type() == cty.String {
+				parts = append(parts, s.Key.AsString())
+			} else if s.Key.Type() == cty.Number {
+				parts = append(parts, s.Key.AsBigFloat().Text('f', 0))
+			}
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func expiryAttributePath(path cty.Path) *tftypes.AttributePath {
+	p := tftypes.NewAttributePath()
+
+	for _, step := range path {
+		switch s := step.(type) {
+		case cty.GetAttrStep:
+			p = p.WithAttributeName(s.Name)
+		case cty.IndexStep:
+			switch {
+			case s.Key.Type() == cty.String:
+				p = p.WithElementKeyString(s.Key.AsString())
+			case s.Key.Type() == cty.Number:
+				i, _ := s.Key.AsBigFloat().Int64()
+				p = p.WithElementKeyInt(int(i))
+			default:
+				// Elements of sets have no stable address.
+				return nil
+			}
+		}
+	}
+
+	return p
+}
+
+// PlanResourceChange warns about the expiry dates of the planned state
+// that are within the expiry_warning_days of the provider. The plugin SDK
+// has no way to return warnings from a plan and the framework can't add
+// them to the plans of the resources of the SDK, so they are added to
+// the response of the SDK server.
+func (s *sdkProviderServer) PlanResourceChange(ctx context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	resp, err := s.ProviderServer.PlanResourceChange(ctx, req)
+	if err != nil || resp == nil || resp.PlannedState == nil {
+		return resp, err
+	}
+
+	r, ok := s.provider.ResourcesMap[req.TypeName]
+	if !ok {
+		return resp, nil
+	}
+
+	planned, err := msgpack.Unmarshal(resp.PlannedState.MsgPack, r.CoreConfigSchema().ImpliedType())
+	if err != nil || planned.IsNull() {
+		return resp, nil
+	}
+
+	threshold := expiryWarningDaysFor(s.provider.Meta())
+	resp.Diagnostics = append(resp.Diagnostics, expiryWarnings(planned, time.Now(), threshold)...)
+
+	return resp, nil
+}
+
+// epochTime returns the time of an epoch in seconds or milliseconds,
+// e.g. the expires and createdAt of a credential returned by the API.
+func epochTime(epoch json.Number) (time.Time, bool) {
+	v, err := epoch.Int64()
+	if err != nil || v <= 0 {
+		return time.Time{}, false
+	}
+
+	// Epochs in milliseconds are past the year 5000 in seconds.
+	if v > 100000000000 {
+		return time.UnixMilli(v).UTC(), true
+	}
+
+	return time.Unix(v, 0).UTC(), true
+}
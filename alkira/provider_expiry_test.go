@@ -0,0 +1,201 @@
+package alkira
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaysRemaining(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		date    string
+		want    int
+		wantErr bool
+	}{
+		{date: "2026-10-19", want: 0},
+		{date: "2026-10-20", want: 1},
+		{date: "2026-11-18", want: 30},
+		{date: "2027-10-19", want: 365},
+		{date: "2026-10-01", want: -18},
+		{date: "19/10/2026", wantErr: true},
+		{date: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			days, err := daysRemaining(tt.date, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, days)
+		})
+	}
+}
+
+func TestDaysRemainingOrNil(t *testing.T) {
+	assert.Nil(t, daysRemainingOrNil(""))
+	assert.Nil(t, daysRemainingOrNil("19/10/2026"))
+	assert.Equal(t, 1, daysRemainingOrNil(time.Now().AddDate(0, 0, 1).Format(expiryDateLayout)))
+}
+
+func TestValidateExpiryDate(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "2026-10-19"},
+		{value: "2026-13-01", wantErr: true},
+		{value: "Oct 19 2026", wantErr: true},
+		{value: 20261019, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, errs := validateExpiryDate(tt.value, "auth_expiry")
+		assert.Equal(t, tt.wantErr, len(errs) > 0, "%v", tt.value)
+	}
+}
+
+func TestExpiryWarnings(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	planned := cty.ObjectVal(map[string]cty.Value{
+		"name":                    cty.StringVal("pan"),
+		"master_key_expiry":       cty.StringVal("2026-10-29"),
+		"registration_pin_expiry": cty.StringVal("2027-10-19"),
+		"description":             cty.StringVal("2026-10-20"),
+		"instance": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"auth_expiry": cty.StringVal("2026-10-01"),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"auth_expiry": cty.StringVal(""),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"auth_expiry": cty.UnknownVal(cty.String),
+			}),
+		}),
+	})
+
+	diags := expiryWarnings(planned, now, 30)
+	require.Len(t, diags, 2)
+
+	byDetail := make(map[string]*tfprotov5.Diagnostic)
+	for _, d := range diags {
+		assert.Equal(t, tfprotov5.DiagnosticSeverityWarning, d.Severity)
+		byDetail[d.Detail] = d
+	}
+
+	expiring := byDetail["master_key_expiry expires on 2026-10-29, in 10 days."]
+	require.NotNil(t, expiring)
+	assert.Equal(t, "EXPIRING SOON", expiring.Summary)
+	assert.Equal(t, tftypes.NewAttributePath().WithAttributeName("master_key_expiry"), expiring.Attribute)
+
+	expired := byDetail["instance.0.auth_expiry expired on 2026-10-01."]
+	require.NotNil(t, expired)
+	assert.Equal(t, "EXPIRED", expired.Summary)
+	assert.Equal(t, tftypes.NewAttributePath().WithAttributeName("instance").WithElementKeyInt(0).WithAttributeName("auth_expiry"), expired.Attribute)
+
+	assert.Len(t, expiryWarnings(planned, now, 5), 1)
+	assert.Len(t, expiryWarnings(planned, now, 365), 3)
+}
+
+func TestExpiryWarningDays(t *testing.T) {
+	client := &alkira.AlkiraClient{}
+	assert.Equal(t, defaultExpiryWarningDays, expiryWarningDaysFor(client))
+
+	configureClientExpiryWarning(client, 45)
+	assert.Equal(t, 45, expiryWarningDaysFor(client))
+	assert.Equal(t, defaultExpiryWarningDays, expiryWarningDaysFor(nil))
+}
+
+func TestExpiringSecrets(t *testing.T) {
+	now := time.Now().UTC()
+	day := func(days int) int64 {
+		y, m, d := now.AddDate(0, 0, days).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix()
+	}
+
+	credentials := []map[string]interface{}{
+		{"credentialId": "1", "credentialType": "pan-registration", "name": "pin", "expires": day(10)},
+		{"credentialId": "2", "credentialType": "pan-master-key", "name": "key", "expires": day(-3) * 1000},
+		{"credentialId": "3", "credentialType": "pan-instance", "name": "auth", "expires": day(90)},
+		{"credentialId": "4", "credentialType": "aws-vpc", "name": "aws"},
+		{"credentialId": "5", "credentialType": "pan-instance", "name": "soon", "expires": day(2)},
+	}
+
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(credentials)
+	})
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   []string
+	}{
+		{
+			name:   "default threshold",
+			config: map[string]interface{}{},
+			want:   []string{"2", "5", "1"},
+		},
+		{
+			name:   "without expired",
+			config: map[string]interface{}{"include_expired": false},
+			want:   []string{"5", "1"},
+		},
+		{
+			name:   "within a year",
+			config: map[string]interface{}{"within_days": 365},
+			want:   []string{"2", "5", "1", "3"},
+		},
+		{
+			name:   "within a week",
+			config: map[string]interface{}{"within_days": 7, "include_expired": false},
+			want:   []string{"5"},
+		},
+		{
+			name:   "within zero days",
+			config: map[string]interface{}{"within_days": 0},
+			want:   []string{"2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := dataSourceAlkiraExpiringSecrets()
+			d := testResourceDataWithRawConfig(t, r, tt.config)
+
+			require.NoError(t, r.Read(d, client))
+
+			var got []string
+			for _, s := range d.Get("secrets").([]interface{}) {
+				got = append(got, s.(map[string]interface{})["credential_id"].(string))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	r := dataSourceAlkiraExpiringSecrets()
+	d := testResourceDataWithRawConfig(t, r, map[string]interface{}{})
+	require.NoError(t, r.Read(d, client))
+
+	secret := d.Get("secrets.0").(map[string]interface{})
+	assert.Equal(t, "key", secret["name"])
+	assert.Equal(t, "pan-master-key", secret["type"])
+	assert.Equal(t, -3, secret["days_remaining"])
+	assert.Equal(t, now.AddDate(0, 0, -3).Format(expiryDateLayout), secret["expiry_date"])
+}
@@ -0,0 +1,133 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// frameworkProvider serves what the plugin SDK has no support for: the
+// provider functions, the ephemeral resources and the list resources. It
+// is muxed with the provider of the SDK, which configures the client both
+// of them use.
+type frameworkProvider struct {
+	sdk *schema.Provider
+
+	// sdkServer is the protocol server of the SDK provider, which reads
+	// the listed objects.
+	sdkServer tfprotov5.ProviderServer
+
+	// providerSchema is the provider schema of the SDK provider, which
+	// the mux server requires to be the same in both providers.
+	providerSchema *tfprotov5.Schema
+}
+
+func newFrameworkProvider(p *schema.Provider, sdkServer tfprotov5.ProviderServer, providerSchema *tfprotov5.Schema) func() provider.Provider {
+	return func() provider.Provider {
+		return &frameworkProvider{sdk: p, sdkServer: sdkServer, providerSchema: providerSchema}
+	}
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "alkira"
+}
+
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	s, err := frameworkProviderSchema(p.providerSchema)
+	if err != nil {
+		resp.Diagnostics.AddError("INVALID PROVIDER SCHEMA", err.Error())
+		return
+	}
+
+	resp.Schema = s
+}
+
+// Configure passes the client configured by the SDK provider, which the
+// mux server configures first, to the ephemeral and list resources.
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	resp.EphemeralResourceData = p.sdk.Meta()
+	resp.ListResourceData = p.sdk.Meta()
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newEphemeralResourceAlkiraRandomPsk,
+		newEphemeralResourceAlkiraSessionToken,
+	}
+}
+
+func (p *frameworkProvider) ListResources(ctx context.Context) []func() list.ListResource {
+	return listResources(p.sdk, p.sdkServer)
+}
+
+func (p *frameworkProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		newFunctionCidrContains,
+		newFunctionCidrOverlaps,
+		newFunctionCidrSplitPerCxp,
+		newFunctionCommunityValid,
+		newFunctionTunnelPair,
+	}
+}
+
+// frameworkProviderSchema converts the provider schema of the SDK
+// provider, which only has attributes of primitive types.
+func frameworkProviderSchema(s *tfprotov5.Schema) (providerschema.Schema, error) {
+	attributes := make(map[string]providerschema.Attribute, len(s.Block.Attributes))
+
+	for _, a := range s.Block.Attributes {
+		deprecation := a.DeprecationMessage
+		if a.Deprecated && deprecation == "" {
+			deprecation = "Deprecated"
+		}
+
+		switch {
+		case a.Type.Is(tftypes.String):
+			attributes[a.Name] = providerschema.StringAttribute{
+				Description:        a.Description,
+				Optional:           a.Optional,
+				Required:           a.Required,
+				Sensitive:          a.Sensitive,
+				DeprecationMessage: deprecation,
+			}
+		case a.Type.Is(tftypes.Bool):
+			attributes[a.Name] = providerschema.BoolAttribute{
+				Description:        a.Description,
+				Optional:           a.Optional,
+				Required:           a.Required,
+				Sensitive:          a.Sensitive,
+				DeprecationMessage: deprecation,
+			}
+		case a.Type.Is(tftypes.Number):
+			attributes[a.Name] = providerschema.NumberAttribute{
+				Description:        a.Description,
+				Optional:           a.Optional,
+				Required:           a.Required,
+				Sensitive:          a.Sensitive,
+				DeprecationMessage: deprecation,
+			}
+		default:
+			return providerschema.Schema{}, fmt.Errorf("unsupported type %s of provider attribute %s", a.Type, a.Name)
+		}
+	}
+
+	return providerschema.Schema{Attributes: attributes}, nil
+}
@@ -0,0 +1,17 @@
+package alkira
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// The provider functions are called as provider::alkira::<name> in the
+// configuration. The plugin SDK has no support for them, so they are
+// served by the framework provider, see frameworkProvider.
+
+// argumentErrorf returns an error pointing the user at the argument at
+// the given position of a call.
+func argumentErrorf(argument int, format string, a ...interface{}) *function.FuncError {
+	return function.NewArgumentFuncError(int64(argument), fmt.Sprintf(format, a...))
+}
@@ -0,0 +1,369 @@
+package alkira
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCallFunction calls a function with Go values as arguments,
+// returning the result as a tftypes.Value.
+func testCallFunction(t *testing.T, s tfprotov5.ProviderServer, name string, args ...interface{}) (tftypes.Value, *tfprotov5.FunctionError) {
+	schemas, err := s.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+
+	f := schemas.Functions[name]
+	require.NotNil(t, f)
+
+	var dvs []*tfprotov5.DynamicValue
+	for i, arg := range args {
+		param := f.VariadicParameter
+		if i < len(f.Parameters) {
+			param = f.Parameters[i]
+		}
+
+		var v tftypes.Value
+		switch a := arg.(type) {
+		case []string:
+			elems := make([]tftypes.Value, len(a))
+			for j, e := range a {
+				elems[j] = tftypes.NewValue(tftypes.String, e)
+			}
+			v = tftypes.NewValue(param.Type, elems)
+		default:
+			v = tftypes.NewValue(param.Type, a)
+		}
+
+		dv, err := tfprotov5.NewDynamicValue(param.Type, v)
+		require.NoError(t, err)
+		dvs = append(dvs, &dv)
+	}
+
+	resp, err := s.CallFunction(context.Background(), &tfprotov5.CallFunctionRequest{Name: name, Arguments: dvs})
+	require.NoError(t, err)
+
+	if resp.Error != nil {
+		return tftypes.Value{}, resp.Error
+	}
+
+	v, err := resp.Result.Unmarshal(f.Return.Type)
+	require.NoError(t, err)
+
+	return v, nil
+}
+
+func TestProviderFunctionsSchema(t *testing.T) {
+	s := testProviderServer(t, Provider())
+
+	metadata, err := s.GetMetadata(context.Background(), &tfprotov5.GetMetadataRequest{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []tfprotov5.FunctionMetadata{
+		{Name: "cidr_contains"},
+		{Name: "cidr_overlaps"},
+		{Name: "cidr_split_per_cxp"},
+		{Name: "community_valid"},
+		{Name: "tunnel_pair"},
+	}, metadata.Functions)
+
+	schemas, err := s.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	require.Contains(t, schemas.Functions, "tunnel_pair")
+	assert.Len(t, schemas.Functions["tunnel_pair"].Parameters, 2)
+
+	resp, err := s.CallFunction(context.Background(), &tfprotov5.CallFunctionRequest{Name: "unknown"})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Text, "Missing function: unknown")
+
+	functions, err := s.GetFunctions(context.Background(), &tfprotov5.GetFunctionsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, functions.Diagnostics)
+	assert.Len(t, functions.Functions, 5)
+	assert.NotNil(t, functions.Functions["community_valid"].VariadicParameter)
+}
+
+func TestFunctionTunnelPair(t *testing.T) {
+	s := testProviderServer(t, Provider())
+
+	tests := []struct {
+		name    string
+		cidr    string
+		index   int
+		want    map[string]string
+		wantErr string
+		wantArg int64
+	}{
+		{
+			name:  "first pair",
+			cidr:  "169.254.0.0/24",
+			index: 0,
+			want: map[string]string{
+				"prefix":      "169.254.0.0/30",
+				"netmask":     "255.255.255.252",
+				"cxp_ip":      "169.254.0.1",
+				"customer_ip": "169.254.0.2",
+			},
+		},
+		{
+			name:  "later pair of unmasked cidr",
+			cidr:  "169.254.0.9/28",
+			index: 3,
+			want: map[string]string{
+				"prefix":      "169.254.0.12/30",
+				"netmask":     "255.255.255.252",
+				"cxp_ip":      "169.254.0.13",
+				"customer_ip": "169.254.0.14",
+			},
+		},
+		{
+			name:    "index out of range",
+			cidr:    "169.254.0.0/28",
+			index:   4,
+			wantErr: "between 0 and 3",
+			wantArg: 1,
+		},
+		{
+			name:    "negative index",
+			cidr:    "169.254.0.0/28",
+			index:   -1,
+			wantErr: "between 0 and 3",
+			wantArg: 1,
+		},
+		{
+			name:    "cidr smaller than a /30",
+			cidr:    "169.254.0.0/31",
+			wantErr: "smaller than a /30",
+			wantArg: 0,
+		},
+		{
+			name:    "ipv6 cidr",
+			cidr:    "fd00::/64",
+			wantErr: "not an IPv4 CIDR",
+			wantArg: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ferr := testCallFunction(t, s, "tunnel_pair", tt.cidr, tt.index)
+			if tt.wantErr != "" {
+				require.NotNil(t, ferr)
+				assert.Contains(t, ferr.Text, tt.wantErr)
+				require.NotNil(t, ferr.FunctionArgument)
+				assert.Equal(t, tt.wantArg, *ferr.FunctionArgument)
+				return
+			}
+
+			require.Nil(t, ferr)
+
+			values := make(map[string]tftypes.Value)
+			require.NoError(t, v.As(&values))
+
+			got := make(map[string]string)
+			for k, e := range values {
+				var s string
+				require.NoError(t, e.As(&s))
+				got[k] = s
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFunctionCommunityValid(t *testing.T) {
+	s := testProviderServer(t, Provider())
+
+	tests := []struct {
+		name    string
+		args    []interface{}
+		want    bool
+		wantErr string
+	}{
+		{name: "standard", args: []interface{}{"65000:100"}, want: true},
+		{name: "standard bounds", args: []interface{}{"0:65535"}, want: true},
+		{name: "standard out of range", args: []interface{}{"65536:100"}, want: false},
+		{name: "large", args: []interface{}{"4200000000:1:2"}, want: true},
+		{name: "large out of range", args: []interface{}{"4294967296:1:2"}, want: false},
+		{name: "extended as number", args: []interface{}{"soo:65000:4294967295"}, want: true},
+		{name: "extended as number out of range", args: []interface{}{"soo:65536:1"}, want: false},
+		{name: "extended ip address", args: []interface{}{"soo:10.0.0.1:100"}, want: true},
+		{name: "extended ip address out of range", args: []interface{}{"soo:10.0.0.1:65536"}, want: false},
+		{name: "extended ipv6 address", args: []interface{}{"soo:fd00::1:100"}, want: false},
+		{name: "extended other type", args: []interface{}{"rt:65000:100"}, want: false},
+		{name: "signed number", args: []interface{}{"+65000:100"}, want: false},
+		{name: "empty part", args: []interface{}{"65000:"}, want: false},
+		{name: "not a community", args: []interface{}{"internet"}, want: false},
+		{name: "standard only", args: []interface{}{"65000:100", "standard"}, want: true},
+		{name: "large not accepted", args: []interface{}{"1:2:3", "standard"}, want: false},
+		{name: "several formats", args: []interface{}{"1:2:3", "standard", "large"}, want: true},
+		{name: "unknown format", args: []interface{}{"65000:100", "short"}, wantErr: "format must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ferr := testCallFunction(t, s, "community_valid", tt.args...)
+			if tt.wantErr != "" {
+				require.NotNil(t, ferr)
+				assert.Contains(t, ferr.Text, tt.wantErr)
+				return
+			}
+
+			require.Nil(t, ferr)
+
+			var got bool
+			require.NoError(t, v.As(&got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFunctionCidrOverlaps(t *testing.T) {
+	s := testProviderServer(t, Provider())
+
+	tests := []struct {
+		name    string
+		a, b    string
+		want    bool
+		wantErr string
+	}{
+		{name: "contained", a: "10.0.0.0/16", b: "10.0.1.0/24", want: true},
+		{name: "unmasked", a: "10.0.0.1/24", b: "10.0.0.128/25", want: true},
+		{name: "adjacent", a: "10.0.0.0/24", b: "10.0.1.0/24", want: false},
+		{name: "ipv6", a: "fd00::/48", b: "fd00:0:0:1::/64", want: true},
+		{name: "different families", a: "10.0.0.0/8", b: "fd00::/8", want: false},
+		{name: "invalid cidr", a: "10.0.0.0/8", b: "10.0.0.0", wantErr: "not a valid CIDR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ferr := testCallFunction(t, s, "cidr_overlaps", tt.a, tt.b)
+			if tt.wantErr != "" {
+				require.NotNil(t, ferr)
+				assert.Contains(t, ferr.Text, tt.wantErr)
+				return
+			}
+
+			require.Nil(t, ferr)
+
+			var got bool
+			require.NoError(t, v.As(&got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFunctionCidrContains(t *testing.T) {
+	s := testProviderServer(t, Provider())
+
+	tests := []struct {
+		name        string
+		cidr, value string
+		want        bool
+		wantErr     string
+	}{
+		{name: "address", cidr: "10.0.0.0/24", value: "10.0.0.7", want: true},
+		{name: "address outside", cidr: "10.0.0.0/24", value: "10.0.1.7", want: false},
+		{name: "cidr", cidr: "10.0.0.0/16", value: "10.0.3.0/24", want: true},
+		{name: "larger cidr", cidr: "10.0.3.0/24", value: "10.0.0.0/16", want: false},
+		{name: "same cidr", cidr: "10.0.0.0/24", value: "10.0.0.0/24", want: true},
+		{name: "invalid value", cidr: "10.0.0.0/24", value: "ten", wantErr: "not a valid CIDR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ferr := testCallFunction(t, s, "cidr_contains", tt.cidr, tt.value)
+			if tt.wantErr != "" {
+				require.NotNil(t, ferr)
+				assert.Contains(t, ferr.Text, tt.wantErr)
+				return
+			}
+
+			require.Nil(t, ferr)
+
+			var got bool
+			require.NoError(t, v.As(&got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFunctionCidrSplitPerCxp(t *testing.T) {
+	s := testProviderServer(t, Provider())
+
+	tests := []struct {
+		name    string
+		cidr    string
+		cxps    []string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "two cxps",
+			cidr: "10.0.0.0/16",
+			cxps: []string{"US-WEST", "US-EAST"},
+			want: map[string]string{"US-WEST": "10.0.0.0/17", "US-EAST": "10.0.128.0/17"},
+		},
+		{
+			name: "three cxps leave a spare block",
+			cidr: "10.0.0.0/16",
+			cxps: []string{"US-WEST", "US-EAST", "EU-WEST"},
+			want: map[string]string{
+				"US-WEST": "10.0.0.0/18",
+				"US-EAST": "10.0.64.0/18",
+				"EU-WEST": "10.0.128.0/18",
+			},
+		},
+		{
+			name: "single cxp",
+			cidr: "10.0.0.0/16",
+			cxps: []string{"US-WEST"},
+			want: map[string]string{"US-WEST": "10.0.0.0/16"},
+		},
+		{
+			name: "ipv6",
+			cidr: "fd00::/48",
+			cxps: []string{"US-WEST", "US-EAST"},
+			want: map[string]string{"US-WEST": "fd00::/49", "US-EAST": "fd00:0:0:8000::/49"},
+		},
+		{
+			name:    "too small",
+			cidr:    "10.0.0.0/31",
+			cxps:    []string{"US-WEST", "US-EAST", "EU-WEST"},
+			wantErr: "too small",
+		},
+		{
+			name:    "duplicate cxp",
+			cidr:    "10.0.0.0/16",
+			cxps:    []string{"US-WEST", "US-WEST"},
+			wantErr: "duplicate CXP US-WEST",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ferr := testCallFunction(t, s, "cidr_split_per_cxp", tt.cidr, tt.cxps)
+			if tt.wantErr != "" {
+				require.NotNil(t, ferr)
+				assert.Contains(t, ferr.Text, tt.wantErr)
+				return
+			}
+
+			require.Nil(t, ferr)
+
+			values := make(map[string]tftypes.Value)
+			require.NoError(t, v.As(&values))
+
+			got := make(map[string]string)
+			for k, e := range values {
+				var s string
+				require.NoError(t, e.As(&s))
+				got[k] = s
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// identityParents maps the resource types of child objects to the
+// attribute holding the ID of their parent. NAT rules are standalone
+// objects referenced by NAT policies, so they have no parent.
+var identityParents = map[string]string{
+	"alkira_peering_gateway_aws_tgw_attachment":                          "peering_gateway_aws_tgw_id",
+	"alkira_peering_gateway_azure_vnet_third_party_connector_attachment": "cxp_peering_gateway_id",
+	"alkira_service_f5_vserver_endpoint":                                 "f5_service_id",
+}
+
+func identitySchema(parent bool) func() map[string]*schema.Schema {
+	return func() map[string]*schema.Schema {
+		s := map[string]*schema.Schema{
+			"tenant_network_id": {
+				Description: "The ID of the tenant network the object " +
+					"belongs to. Defaults to the tenant network of the " +
+					"provider.",
+				Type:              schema.TypeString,
+				OptionalForImport: true,
+			},
+			"type": {
+				Description:       "The resource type of the object, e.g. `alkira_segment`.",
+				Type:              schema.TypeString,
+				OptionalForImport: true,
+			},
+			"id": {
+				Description:       "The ID of the object.",
+				Type:              schema.TypeString,
+				RequiredForImport: true,
+			},
+		}
+
+		if parent {
+			s["parent_id"] = &schema.Schema{
+				Description:       "The ID of the parent object.",
+				Type:              schema.TypeString,
+				OptionalForImport: true,
+			}
+		}
+
+		return s
+	}
+}
+
+// withResourceIdentity declares the identity of a resource, the tenant
+// network ID, resource type and object ID plus the parent ID of child
+// objects, and keeps it up to date. Objects are imported by identity
+// through the importer of the resource, and objects whose identity
+// belongs to another tenant network are refused, which catches state
+// copied between workspaces.
+func withResourceIdentity(resourceType string, res *schema.Resource) {
+	parentAttribute, parent := identityParents[resourceType]
+
+	res.Identity = &schema.ResourceIdentity{
+		SchemaFunc: identitySchema(parent),
+	}
+
+	// The parent of a child object can be changed in place.
+	res.ResourceBehavior.MutableIdentity = parent
+
+	check := func(d *schema.ResourceData, m interface{}) error {
+		identity, err := d.Identity()
+		if err != nil {
+			return err
+		}
+
+		if t, ok := identity.GetOk("type"); ok && t.(string) != resourceType {
+			return fmt.Errorf("the identity is of type %s, not %s", t, resourceType)
+		}
+
+		client, ok := m.(*alkira.AlkiraClient)
+		if !ok {
+			return nil
+		}
+
+		if tenant, ok := identity.GetOk("tenant_network_id"); ok && tenant.(string) != client.TenantNetworkId {
+			return fmt.Errorf("the object belongs to tenant network %s, but the provider "+
+				"is configured for tenant network %s, was the state copied from "+
+				"another workspace?", tenant, client.TenantNetworkId)
+		}
+
+		return nil
+	}
+
+	set := func(d *schema.ResourceData, m interface{}) error {
+		if d.Id() == "" {
+			return nil
+		}
+
+		identity, err := d.Identity()
+		if err != nil {
+			return err
+		}
+
+		if client, ok := m.(*alkira.AlkiraClient); ok {
+			if err := identity.Set("tenant_network_id", client.TenantNetworkId); err != nil {
+				return err
+			}
+		}
+
+		if err := identity.Set("type", resourceType); err != nil {
+			return err
+		}
+
+		if err := identity.Set("id", d.Id()); err != nil {
+			return err
+		}
+
+		if parent {
+			return identity.Set("parent_id", fmt.Sprint(d.Get(parentAttribute)))
+		}
+
+		return nil
+	}
+
+	wrap := func(fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics, checkFirst bool) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if fn == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if checkFirst {
+				if err := check(d, m); err != nil {
+					return diag.FromErr(err)
+				}
+			}
+
+			diags := fn(ctx, d, m)
+			if diags.HasError() {
+				return diags
+			}
+
+			if err := set(d, m); err != nil {
+				return append(diags, diag.FromErr(err)...)
+			}
+
+			return diags
+		}
+	}
+
+	res.CreateContext = wrap(res.CreateContext, false)
+	res.ReadContext = wrap(res.ReadContext, true)
+	res.UpdateContext = wrap(res.UpdateContext, true)
+
+	if res.Importer == nil || res.Importer.StateContext == nil {
+		return
+	}
+
+	importer := res.Importer.StateContext
+	res.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+		if d.Id() == "" {
+			if err := check(d, m); err != nil {
+				return nil, err
+			}
+
+			identity, err := d.Identity()
+			if err != nil {
+				return nil, err
+			}
+
+			id, ok := identity.GetOk("id")
+			if !ok {
+				return nil, fmt.Errorf("the identity has no id")
+			}
+			d.SetId(id.(string))
+		}
+
+		return importer(ctx, d, m)
+	}
+}
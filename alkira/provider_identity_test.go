@@ -0,0 +1,116 @@
+package alkira
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIdentityResource(resourceType string) *schema.Resource {
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name":                       {Type: schema.TypeString, Optional: true},
+			"peering_gateway_aws_tgw_id": {Type: schema.TypeInt, Optional: true},
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			d.Set("name", "read")
+			d.Set("peering_gateway_aws_tgw_id", 7)
+			return nil
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+
+	withResourceIdentity(resourceType, r)
+
+	return r
+}
+
+func TestResourceIdentityRead(t *testing.T) {
+	client := &alkira.AlkiraClient{TenantNetworkId: "10"}
+
+	r := testIdentityResource("alkira_peering_gateway_aws_tgw_attachment")
+	require.NoError(t, r.Identity.InternalIdentityValidate())
+	assert.True(t, r.ResourceBehavior.MutableIdentity)
+
+	d := r.Data(&terraform.InstanceState{ID: "5"})
+	diags := r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	identity, err := d.Identity()
+	require.NoError(t, err)
+	assert.Equal(t, "10", identity.Get("tenant_network_id"))
+	assert.Equal(t, "alkira_peering_gateway_aws_tgw_attachment", identity.Get("type"))
+	assert.Equal(t, "5", identity.Get("id"))
+	assert.Equal(t, "7", identity.Get("parent_id"))
+
+	// State copied from a workspace of another tenant network.
+	d = r.Data(&terraform.InstanceState{ID: "5", Identity: map[string]string{
+		"tenant_network_id": "11",
+		"id":                "5",
+	}})
+	diags = r.ReadContext(context.Background(), d, client)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "tenant network 11")
+}
+
+func TestResourceIdentityImport(t *testing.T) {
+	client := &alkira.AlkiraClient{TenantNetworkId: "10"}
+	r := testIdentityResource("alkira_segment")
+
+	_, hasParent := r.Identity.SchemaMap()["parent_id"]
+	assert.False(t, hasParent)
+	assert.False(t, r.ResourceBehavior.MutableIdentity)
+
+	tests := []struct {
+		name     string
+		identity map[string]string
+		wantId   string
+		wantErr  string
+	}{
+		{
+			name:     "id only",
+			identity: map[string]string{"id": "5"},
+			wantId:   "5",
+		},
+		{
+			name:     "full identity",
+			identity: map[string]string{"tenant_network_id": "10", "type": "alkira_segment", "id": "6"},
+			wantId:   "6",
+		},
+		{
+			name:     "other tenant network",
+			identity: map[string]string{"tenant_network_id": "11", "id": "5"},
+			wantErr:  "tenant network 11",
+		},
+		{
+			name:     "other type",
+			identity: map[string]string{"type": "alkira_group", "id": "5"},
+			wantErr:  "of type alkira_group, not alkira_segment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := r.Data(&terraform.InstanceState{Identity: tt.identity})
+
+			imported, err := r.Importer.StateContext(context.Background(), d, client)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, imported, 1)
+			assert.Equal(t, tt.wantId, imported[0].Id())
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package alkira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// referenceCacheTTL is how long the objects of a collection are reused
+// between the plans of the resources of a run. A reference missing from
+// the cached objects always refreshes them, so objects created earlier
+// in the same apply are found.
+const referenceCacheTTL = time.Minute
+
+// referenceKind is a type of object attributes refer to by ID.
+type referenceKind struct {
+	name string
+	list exportListFunc
+
+	// byName also accepts references by name, e.g. segment options
+	// naming their segment.
+	byName bool
+}
+
+var (
+	referenceSegment         = &referenceKind{name: "segment", list: exportList(alkira.NewSegment), byName: true}
+	referenceCredential      = &referenceKind{name: "credential", list: listReferenceCredentials}
+	referencePrefixList      = &referenceKind{name: "prefix list", list: exportList(alkira.NewPolicyPrefixList)}
+	referenceRuleList        = &referenceKind{name: "policy rule list", list: exportList(alkira.NewPolicyRuleList)}
+	referenceBillingTag      = &referenceKind{name: "billing tag", list: exportList(alkira.NewBillingTag)}
+	referenceFlowCollector   = &referenceKind{name: "flow collector", list: exportList(alkira.NewFlowCollector)}
+	referenceZtaProfile      = &referenceKind{name: "ZTA profile", list: exportList(alkira.NewZtaProfile)}
+	referenceByoip           = &referenceKind{name: "BYOIP prefix", list: exportList(alkira.NewByoip)}
+	referenceSegmentResource = &referenceKind{name: "segment resource", list: exportList(alkira.NewSegmentResource)}
+)
+
+// referenceAttributes maps the attributes referring to other objects,
+// at any level of a resource, to the kind of object they refer to.
+var referenceAttributes = map[string]*referenceKind{
+	"segment_id":                 referenceSegment,
+	"segment_ids":                referenceSegment,
+	"credential_id":              referenceCredential,
+	"prefix_list_id":             referencePrefixList,
+	"prefix_list_ids":            referencePrefixList,
+	"rule_list_id":               referenceRuleList,
+	"billing_tag_ids":            referenceBillingTag,
+	"flow_collector_ids":         referenceFlowCollector,
+	"zta_profile_ids":            referenceZtaProfile,
+	"byoip_id":                   referenceByoip,
+	"end_a_segment_resource_ids": referenceSegmentResource,
+	"end_b_segment_resource_ids": referenceSegmentResource,
+}
+
+// referenceCredentialTypes maps resource types to the type of the
+// credential their top level credential_id refers to.
+var referenceCredentialTypes = map[string]alkira.CredentialType{
+	"alkira_connector_aws_vpc":    alkira.CredentialTypeAwsVpc,
+	"alkira_connector_azure_vnet": alkira.CredentialTypeAzureVnet,
+	"alkira_connector_gcp_vpc":    alkira.CredentialTypeGcpVpc,
+	"alkira_connector_oci_vcn":    alkira.CredentialTypeOciVcn,
+}
+
+// clientReferenceChecks maps *alkira.AlkiraClient to whether plans
+// check the references of resources.
+var clientReferenceChecks sync.Map
+
+func configureClientReferenceChecks(client *alkira.AlkiraClient, enabled bool) {
+	clientReferenceChecks.Store(client, enabled)
+}
+
+func referenceChecksEnabled(m interface{}) bool {
+	client, ok := m.(*alkira.AlkiraClient)
+	if !ok || client == nil {
+		return false
+	}
+
+	enabled, ok := clientReferenceChecks.Load(client)
+	return ok && enabled.(bool)
+}
+
+func listReferenceCredentials(client *alkira.AlkiraClient) ([]exportItem, error) {
+	data, err := client.GetCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials []alkira.CredentialResponseDetail
+	if err := json.Unmarshal([]byte(data), &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+
+	items := make([]exportItem, 0, len(credentials))
+	for _, c := range credentials {
+		items = append(items, exportItem{
+			Id:     c.Id,
+			Name:   c.Name,
+			Object: map[string]interface{}{"type": c.Type},
+		})
+	}
+
+	return items, nil
+}
+
+type referenceCollection struct {
+	fetched time.Time
+	byId    map[string]exportItem
+	byName  map[string]exportItem
+}
+
+// referenceResolver looks up referenced objects, listing each
+// collection at most once per plan unless a reference is missing.
+type referenceResolver struct {
+	mu          sync.Mutex
+	client      *alkira.AlkiraClient
+	collections map[*referenceKind]*referenceCollection
+}
+
+// clientReferenceResolvers maps *alkira.AlkiraClient to its
+// *referenceResolver.
+var clientReferenceResolvers sync.Map
+
+func referenceResolverFor(client *alkira.AlkiraClient) *referenceResolver {
+	r, _ := clientReferenceResolvers.LoadOrStore(client, &referenceResolver{
+		client:      client,
+		collections: make(map[*referenceKind]*referenceCollection),
+	})
+	return r.(*referenceResolver)
+}
+
+func (r *referenceResolver) load(kind *referenceKind) (*referenceCollection, error) {
+	items, err := kind.list(r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the %s objects: %w", kind.name, err)
+	}
+
+	c := &referenceCollection{
+		fetched: time.Now(),
+		byId:    make(map[string]exportItem, len(items)),
+		byName:  make(map[string]exportItem, len(items)),
+	}
+
+	for _, item := range items {
+		c.byId[item.Id] = item
+		if item.Name != "" {
+			c.byName[item.Name] = item
+		}
+	}
+
+	r.collections[kind] = c
+	return c, nil
+}
+
+// resolve returns the object a reference refers to, or false when it
+// doesn't exist.
+func (r *referenceResolver) resolve(kind *referenceKind, ref string) (exportItem, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lookup := func(c *referenceCollection) (exportItem, bool) {
+		if item, ok := c.byId[ref]; ok {
+			return item, true
+		}
+		if kind.byName {
+			item, ok := c.byName[ref]
+			return item, ok
+		}
+		return exportItem{}, false
+	}
+
+	c, ok := r.collections[kind]
+	if ok && time.Since(c.fetched) < referenceCacheTTL {
+		if item, found := lookup(c); found {
+			return item, true, nil
+		}
+	}
+
+	c, err := r.load(kind)
+	if err != nil {
+		return exportItem{}, false, err
+	}
+
+	item, found := lookup(c)
+	return item, found, nil
+}
+
+// reference is a known reference of the configuration of a resource.
+type reference struct {
+	path  string
+	kind  *referenceKind
+	value string
+}
+
+// configReferences returns the known references of a configuration,
+// skipping empty and zero IDs which stand for no reference.
+func configReferences(config cty.Value) []reference {
+	var refs []reference
+
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+
+	cty.Walk(config, func(path cty.Path, v cty.Value) (bool, error) {
+		if len(path) == 0 || v.IsNull() || !v.IsKnown() {
+			return true, nil
+		}
+
+		step, ok := path[len(path)-1].(cty.GetAttrStep)
+		if !ok {
+			return true, nil
+		}
+
+		kind, ok := referenceAttributes[step.Name]
+		if !ok {
+			return true, nil
+		}
+
+		var values []cty.Value
+		switch {
+		case v.Type().IsPrimitiveType():
+			values = []cty.Value{v}
+		case v.Type().IsListType() || v.Type().IsSetType():
+			for it := v.ElementIterator(); it.Next(); {
+				_, e := it.Element()
+				values = append(values, e)
+			}
+		}
+
+		for _, e := range values {
+			if e.IsNull() || !e.IsKnown() || !e.Type().IsPrimitiveType() {
+				continue
+			}
+
+			var value string
+			switch e.Type() {
+			case cty.String:
+				value = e.AsString()
+			case cty.Number:
+				value = e.AsBigFloat().Text('f', -1)
+			default:
+				continue
+			}
+
+			if value == "" || value == "0" {
+				continue
+			}
+
+			refs = append(refs, reference{
+				path:  ctyPathString(path),
+				kind:  kind,
+				value: value,
+			})
+		}
+
+		return true, nil
+	})
+
+	return refs
+}
+
+// danglingReferences returns a message for every reference of a
+// resource to an object that doesn't exist, is of the wrong type or,
+// for the segment resources of one attribute, on different segments.
+//
+// Prefix lists, rule lists and credentials aren't scoped by segment, so
+// references to them are only checked for existence and type.
+func danglingReferences(resourceType string, config cty.Value, resolver *referenceResolver) ([]string, error) {
+	var dangling []string
+
+	// segmentResources maps the attributes referring to segment
+	// resources to the segment resources of each segment.
+	segmentResources := make(map[string]map[string][]string)
+
+	for _, ref := range configReferences(config) {
+		item, found, err := resolver.resolve(ref.kind, ref.value)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			dangling = append(dangling, fmt.Sprintf("%s: %s %s does not exist", ref.path, ref.kind.name, ref.value))
+			continue
+		}
+
+		if ref.kind == referenceCredential && ref.path == "credential_id" {
+			want, ok := referenceCredentialTypes[resourceType]
+			if got, _ := item.Object["type"].(string); ok && got != string(want) {
+				dangling = append(dangling, fmt.Sprintf("%s: credential %s is of type %s, not %s",
+					ref.path, ref.value, got, want))
+			}
+		}
+
+		// A BYOIP prefix can only be used in its own CXP.
+		if ref.kind == referenceByoip && config.Type().HasAttribute("cxp") {
+			cxp := config.GetAttr("cxp")
+			got, _ := item.Object["cxp"].(string)
+
+			if cxp.IsKnown() && !cxp.IsNull() && got != "" && got != cxp.AsString() {
+				dangling = append(dangling, fmt.Sprintf("%s: %s %s is in CXP %s, not %s",
+					ref.path, ref.kind.name, ref.value, got, cxp.AsString()))
+			}
+		}
+
+		if ref.kind == referenceSegmentResource {
+			segment, _ := item.Object["segment"].(string)
+			if segmentResources[ref.path] == nil {
+				segmentResources[ref.path] = make(map[string][]string)
+			}
+			segmentResources[ref.path][segment] = append(segmentResources[ref.path][segment], ref.value)
+		}
+	}
+
+	// All segment resources of an end of a share must be on the same
+	// segment.
+	for path, bySegment := range segmentResources {
+		if len(bySegment) < 2 {
+			continue
+		}
+
+		var segments []string
+		for segment, ids := range bySegment {
+			segments = append(segments, fmt.Sprintf("%s (%s)", segment, strings.Join(ids, ", ")))
+		}
+		sort.Strings(segments)
+
+		dangling = append(dangling, fmt.Sprintf("%s: segment resources are on different segments: %s",
+			path, strings.Join(segments, "; ")))
+	}
+
+	sort.Strings(dangling)
+	return dangling, nil
+}
+
+// referenceAttributesOf returns the top level attributes of a schema
+// holding references, directly or in nested blocks.
+func referenceAttributesOf(s map[string]*schema.Schema) []string {
+	var attributes []string
+
+	for name, attr := range s {
+		if _, ok := referenceAttributes[name]; ok && (attr.Optional || attr.Required) {
+			attributes = append(attributes, name)
+			continue
+		}
+
+		if elem, ok := attr.Elem.(*schema.Resource); ok && len(referenceAttributesOf(elem.Schema)) > 0 {
+			attributes = append(attributes, name)
+		}
+	}
+
+	sort.Strings(attributes)
+	return attributes
+}
+
+// withReferenceChecks checks at plan time that the references of a
+// resource to other objects, e.g. segment_id or billing_tag_ids, exist
+// and are of the expected type. Only the references of new resources
+// and of changed attributes are checked, and all dangling references
+// of the resource are reported at once.
+func withReferenceChecks(resourceType string, res *schema.Resource) {
+	attributes := referenceAttributesOf(res.Schema)
+	if len(attributes) == 0 {
+		return
+	}
+
+	customizeDiff := res.CustomizeDiff
+
+	res.CustomizeDiff = func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if customizeDiff != nil {
+			if err := customizeDiff(ctx, d, m); err != nil {
+				return err
+			}
+		}
+
+		if !referenceChecksEnabled(m) {
+			return nil
+		}
+
+		changed := d.Id() == ""
+		for _, attribute := range attributes {
+			changed = changed || d.HasChange(attribute)
+		}
+
+		if !changed {
+			return nil
+		}
+
+		dangling, err := danglingReferences(resourceType, d.GetRawConfig(), referenceResolverFor(m.(*alkira.AlkiraClient)))
+		if err != nil {
+			return fmt.Errorf("failed to check references: %w", err)
+		}
+
+		if len(dangling) > 0 {
+			return fmt.Errorf("dangling references:\n  - %s", strings.Join(dangling, "\n  - "))
+		}
+
+		return nil
+	}
+}
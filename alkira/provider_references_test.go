@@ -0,0 +1,168 @@
+package alkira
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigReferences(t *testing.T) {
+	config := cty.ObjectVal(map[string]cty.Value{
+		"name":            cty.StringVal("vpc"),
+		"segment_id":      cty.StringVal("1"),
+		"credential_id":   cty.UnknownVal(cty.String),
+		"billing_tag_ids": cty.SetVal([]cty.Value{cty.NumberIntVal(7), cty.UnknownVal(cty.Number)}),
+		"prefix_list_id":  cty.NumberIntVal(0),
+		"vpc_route_table": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"prefix_list_ids": cty.ListVal([]cty.Value{cty.NumberIntVal(3)}),
+			}),
+		}),
+	})
+
+	var got []string
+	for _, ref := range configReferences(config) {
+		got = append(got, ref.path+"="+ref.kind.name+":"+ref.value)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"segment_id=segment:1",
+		"billing_tag_ids=billing tag:7",
+		"vpc_route_table.0.prefix_list_ids=prefix list:3",
+	}, got)
+
+	assert.Empty(t, configReferences(cty.NullVal(config.Type())))
+}
+
+func TestReferenceAttributesOf(t *testing.T) {
+	assert.Equal(t,
+		[]string{"billing_tag_ids", "credential_id", "segment_id", "vpc_route_table"},
+		referenceAttributesOf(resourceAlkiraConnectorAwsVpc().Schema))
+
+	// Provider managed credentials aren't references.
+	assert.NotContains(t, referenceAttributesOf(resourceAlkiraServiceFortinet().Schema), "credential_id")
+}
+
+func TestDanglingReferences(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+
+	segments := []map[string]interface{}{
+		{"id": 1, "name": "corp"},
+	}
+
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests[req.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+
+		switch req.URL.Path {
+		case "/tenantnetworks/0/segments":
+			json.NewEncoder(w).Encode(segments)
+		case "/api/credentials/":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"credentialId": "aws", "credentialType": "awsvpc", "name": "aws"},
+				{"credentialId": "gcp", "credentialType": "gcpvpc", "name": "gcp"},
+			})
+		case "/tags":
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 7, "name": "prod"}})
+		case "/tenantnetworks/0/segment-resources":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": 11, "name": "web", "segment": "corp"},
+				{"id": 12, "name": "db", "segment": "corp"},
+				{"id": 13, "name": "lab", "segment": "guest"},
+			})
+		case "/tenantnetworks/0/byoips":
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 4, "prefix": "198.51.100.0/24", "cxp": "US-WEST"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	resolver := referenceResolverFor(client)
+
+	config := cty.ObjectVal(map[string]cty.Value{
+		"segment_id":      cty.StringVal("2"),
+		"credential_id":   cty.StringVal("gcp"),
+		"billing_tag_ids": cty.SetVal([]cty.Value{cty.NumberIntVal(7), cty.NumberIntVal(8)}),
+	})
+
+	dangling, err := danglingReferences("alkira_connector_aws_vpc", config, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"billing_tag_ids: billing tag 8 does not exist",
+		"credential_id: credential gcp is of type gcpvpc, not awsvpc",
+		"segment_id: segment 2 does not exist",
+	}, dangling)
+
+	// Segments are also referenced by name.
+	config = cty.ObjectVal(map[string]cty.Value{
+		"segment_id":      cty.StringVal("corp"),
+		"credential_id":   cty.StringVal("aws"),
+		"billing_tag_ids": cty.SetVal([]cty.Value{cty.NumberIntVal(7)}),
+	})
+
+	dangling, err = danglingReferences("alkira_connector_aws_vpc", config, resolver)
+	require.NoError(t, err)
+	assert.Empty(t, dangling)
+
+	// A segment created since the last listing is found.
+	mu.Lock()
+	segments = append(segments, map[string]interface{}{"id": 2, "name": "guest"})
+	listed := requests["/tenantnetworks/0/segments"]
+	mu.Unlock()
+
+	config = cty.ObjectVal(map[string]cty.Value{"segment_id": cty.StringVal("2")})
+
+	dangling, err = danglingReferences("alkira_segment_resource", config, resolver)
+	require.NoError(t, err)
+	assert.Empty(t, dangling)
+	assert.Equal(t, listed+1, requests["/tenantnetworks/0/segments"])
+
+	// Known objects are served from the cache.
+	_, err = danglingReferences("alkira_segment_resource", config, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, listed+1, requests["/tenantnetworks/0/segments"])
+
+	// A BYOIP prefix of another CXP.
+	config = cty.ObjectVal(map[string]cty.Value{
+		"cxp":      cty.StringVal("US-EAST"),
+		"byoip_id": cty.NumberIntVal(4),
+	})
+
+	dangling, err = danglingReferences("alkira_connector_internet_exit", config, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"byoip_id: BYOIP prefix 4 is in CXP US-WEST, not US-EAST"}, dangling)
+
+	// The segment resources of each end of a share must be on the
+	// same segment.
+	config = cty.ObjectVal(map[string]cty.Value{
+		"end_a_segment_resource_ids": cty.ListVal([]cty.Value{cty.NumberIntVal(11), cty.NumberIntVal(12)}),
+		"end_b_segment_resource_ids": cty.ListVal([]cty.Value{cty.NumberIntVal(12), cty.NumberIntVal(13)}),
+	})
+
+	dangling, err = danglingReferences("alkira_segment_resource_share", config, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"end_b_segment_resource_ids: segment resources are on different segments: corp (12); guest (13)",
+	}, dangling)
+}
+
+func TestReferenceChecksEnabled(t *testing.T) {
+	client := &alkira.AlkiraClient{}
+	assert.False(t, referenceChecksEnabled(client))
+
+	configureClientReferenceChecks(client, true)
+	assert.True(t, referenceChecksEnabled(client))
+
+	configureClientReferenceChecks(client, false)
+	assert.False(t, referenceChecksEnabled(client))
+}
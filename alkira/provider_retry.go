@@ -0,0 +1,296 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// clientRetryConfig holds the retry and rate limit settings of the
+// provider.
+type clientRetryConfig struct {
+	MaxRetries        int
+	WaitMin           time.Duration
+	WaitMax           time.Duration
+	RequestsPerSecond float64
+	Burst             int
+}
+
+func (c clientRetryConfig) validate() error {
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+
+	if c.WaitMin > c.WaitMax {
+		return fmt.Errorf("retry_wait_min (%v) must not be greater than retry_wait_max (%v)", c.WaitMin, c.WaitMax)
+	}
+
+	if c.RequestsPerSecond < 0 {
+		return fmt.Errorf("rate_limit must not be negative")
+	}
+
+	if c.RequestsPerSecond > 0 && c.Burst < 1 {
+		return fmt.Errorf("rate_limit_burst must be at least 1")
+	}
+
+	return nil
+}
+
+// clientMetrics counts the retries and rate limit waits of a client.
+// The counters are shared by all resources using the client.
+type clientMetrics struct {
+	retries      int64
+	throttled    int64
+	throttleWait int64 // nanoseconds
+}
+
+type clientMetricsSnapshot struct {
+	Retries      int64
+	Throttled    int64
+	ThrottleWait time.Duration
+}
+
+func (m *clientMetrics) snapshot() clientMetricsSnapshot {
+	return clientMetricsSnapshot{
+		Retries:      atomic.LoadInt64(&m.retries),
+		Throttled:    atomic.LoadInt64(&m.throttled),
+		ThrottleWait: time.Duration(atomic.LoadInt64(&m.throttleWait)),
+	}
+}
+
+func (s clientMetricsSnapshot) sub(o clientMetricsSnapshot) clientMetricsSnapshot {
+	return clientMetricsSnapshot{
+		Retries:      s.Retries - o.Retries,
+		Throttled:    s.Throttled - o.Throttled,
+		ThrottleWait: s.ThrottleWait - o.ThrottleWait,
+	}
+}
+
+// clientMetricsRegistry maps *alkira.AlkiraClient to its *clientMetrics,
+// as the client itself has no room for provider state.
+var clientMetricsRegistry sync.Map
+
+// clientMetricsFor returns the metrics of the client passed as provider
+// meta, or nil when the client was not configured by the provider.
+func clientMetricsFor(m interface{}) *clientMetrics {
+	client, ok := m.(*alkira.AlkiraClient)
+	if !ok || client == nil {
+		return nil
+	}
+
+	metrics, ok := clientMetricsRegistry.Load(client)
+	if !ok {
+		return nil
+	}
+
+	return metrics.(*clientMetrics)
+}
+
+// configureClientRetry applies the retry and rate limit settings to the
+// retryable HTTP client. Retries and throttle waits are logged in the
+// client subsystem, with its level and masking, and counted for the
+// diagnostics added by withRetryDiagnostics.
+func configureClientRetry(ctx context.Context, client *alkira.AlkiraClient, r *logRedactor, cfg clientRetryConfig) *clientMetrics {
+	ctx = r.subsystemContext(ctx, logSubsystemClient)
+	metrics := &clientMetrics{}
+
+	client.Client.RetryMax = cfg.MaxRetries
+	client.Client.RetryWaitMin = cfg.WaitMin
+	client.Client.RetryWaitMax = cfg.WaitMax
+	client.Client.Backoff = func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		// Backoff is only called when the request is retried, unlike
+		// CheckRetry which also runs for the last attempt.
+		atomic.AddInt64(&metrics.retries, 1)
+		wait := portalBackoff(min, max, attemptNum, resp)
+
+		fields := map[string]interface{}{
+			"attempt": attemptNum + 1,
+			"wait":    wait.String(),
+		}
+		if resp != nil {
+			fields["http_status"] = resp.StatusCode
+			if resp.Request != nil {
+				fields[logFieldRequestId] = resp.Request.Header.Get("x-ak-request-id")
+			}
+		}
+		tflog.SubsystemWarn(ctx, logSubsystemClient, "retrying request", fields)
+
+		return wait
+	}
+	client.Client.CheckRetry = portalCheckRetry
+
+	if cfg.RequestsPerSecond > 0 {
+		client.Client.HTTPClient.Transport = &rateLimitedTransport{
+			next:    client.Client.HTTPClient.Transport,
+			bucket:  newTokenBucket(cfg.RequestsPerSecond, cfg.Burst),
+			metrics: metrics,
+			logCtx:  ctx,
+		}
+	}
+
+	clientMetricsRegistry.Store(client, metrics)
+
+	return metrics
+}
+
+// withRetryDiagnostics adds a warning with the retries and throttle
+// waits that happened while a failed operation was running. The
+// counters are shared by the client, so with parallel operations the
+// numbers include requests of other resources.
+func withRetryDiagnostics(res *schema.Resource) {
+	wrap := func(fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if fn == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			metrics := clientMetricsFor(m)
+			if metrics == nil {
+				return fn(ctx, d, m)
+			}
+
+			before := metrics.snapshot()
+			diags := fn(ctx, d, m)
+
+			if !diags.HasError() {
+				return diags
+			}
+
+			delta := metrics.snapshot().sub(before)
+			if delta.Retries == 0 && delta.Throttled == 0 {
+				return diags
+			}
+
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "API RETRIES AND THROTTLING",
+				Detail: fmt.Sprintf("While this operation was running the provider "+
+					"retried %d API request(s) and delayed %d request(s) by %v "+
+					"in total to honour rate_limit. Consider lowering "+
+					"rate_limit or parallelism if the portal keeps "+
+					"throttling requests.",
+					delta.Retries, delta.Throttled, delta.ThrottleWait.Round(time.Millisecond)),
+			})
+		}
+	}
+
+	res.CreateContext = wrap(res.CreateContext)
+	res.ReadContext = wrap(res.ReadContext)
+	res.UpdateContext = wrap(res.UpdateContext)
+	res.DeleteContext = wrap(res.DeleteContext)
+}
+
+// rateLimitedTransport delays requests so that no more than the
+// configured requests per second are sent to the portal. Every attempt
+// of a retried request takes a token.
+type rateLimitedTransport struct {
+	next    http.RoundTripper
+	bucket  *tokenBucket
+	metrics *clientMetrics
+	logCtx  context.Context
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	wait, err := t.bucket.Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	if wait > 0 {
+		atomic.AddInt64(&t.metrics.throttled, 1)
+		atomic.AddInt64(&t.metrics.throttleWait, int64(wait))
+
+		tflog.SubsystemDebug(t.logCtx, logSubsystemClient, "request throttled by rate_limit", map[string]interface{}{
+			logFieldRequestId: req.Header.Get("x-ak-request-id"),
+			"wait":            wait.String(),
+		})
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// tokenBucket is a token bucket rate limiter refilled with rate tokens
+// per second up to burst tokens.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	sleep  func(context.Context, time.Duration) error
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait
+// before using it.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// Wait blocks until a token is available and returns how long it
+// waited.
+func (b *tokenBucket) Wait(ctx context.Context) (time.Duration, error) {
+	wait := b.reserve()
+	if wait == 0 {
+		return 0, nil
+	}
+
+	if err := b.sleep(ctx, wait); err != nil {
+		b.cancel()
+		return 0, err
+	}
+
+	return wait, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
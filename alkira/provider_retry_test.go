@@ -0,0 +1,183 @@
+package alkira
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRetryConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  clientRetryConfig
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			config: clientRetryConfig{MaxRetries: 5, WaitMin: time.Second, WaitMax: 30 * time.Second, Burst: 1},
+		},
+		{
+			name:    "negative retries",
+			config:  clientRetryConfig{MaxRetries: -1},
+			wantErr: "max_retries",
+		},
+		{
+			name:    "min greater than max",
+			config:  clientRetryConfig{WaitMin: 10 * time.Second, WaitMax: time.Second},
+			wantErr: "retry_wait_min",
+		},
+		{
+			name:    "rate limit without burst",
+			config:  clientRetryConfig{RequestsPerSecond: 2},
+			wantErr: "rate_limit_burst",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTokenBucketReserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(2, 2)
+	b.now = func() time.Time { return now }
+
+	// The burst is available immediately.
+	assert.Equal(t, time.Duration(0), b.reserve())
+	assert.Equal(t, time.Duration(0), b.reserve())
+
+	// Then tokens come at 2 per second.
+	assert.Equal(t, 500*time.Millisecond, b.reserve())
+	assert.Equal(t, time.Second, b.reserve())
+
+	// After 2 seconds the two outstanding reservations are paid back.
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, time.Duration(0), b.reserve())
+}
+
+func TestTokenBucketWaitCanceled(t *testing.T) {
+	b := newTokenBucket(1, 1)
+	b.sleep = func(ctx context.Context, _ time.Duration) error { return context.Canceled }
+
+	_, err := b.Wait(context.Background())
+	require.NoError(t, err)
+
+	_, err = b.Wait(context.Background())
+	require.ErrorIs(t, err, context.Canceled)
+	assert.InDelta(t, 0, b.tokens, 0.1)
+}
+
+func TestConfigureClientRetryCountsRetries(t *testing.T) {
+	var calls int32
+
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	metrics := configureClientRetry(t.Context(), client, newLogRedactor(apiSensitiveKeys...), clientRetryConfig{
+		MaxRetries: 3,
+		WaitMin:    time.Millisecond,
+		WaitMax:    time.Millisecond,
+	})
+
+	_, err := client.GetTenantNetworks()
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), metrics.snapshot().Retries)
+	assert.Same(t, metrics, clientMetricsFor(client))
+}
+
+func TestConfigureClientRetryGivesUp(t *testing.T) {
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	configureClientRetry(t.Context(), client, newLogRedactor(apiSensitiveKeys...), clientRetryConfig{
+		MaxRetries: 1,
+		WaitMin:    time.Millisecond,
+		WaitMax:    time.Millisecond,
+	})
+
+	_, err := client.GetTenantNetworks()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "giving up after 2 attempt(s)")
+}
+
+func TestConfigureClientRetryRateLimit(t *testing.T) {
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	metrics := configureClientRetry(t.Context(), client, newLogRedactor(apiSensitiveKeys...), clientRetryConfig{
+		MaxRetries:        1,
+		WaitMin:           time.Millisecond,
+		WaitMax:           time.Millisecond,
+		RequestsPerSecond: 100,
+		Burst:             1,
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetTenantNetworks()
+		require.NoError(t, err)
+	}
+
+	snapshot := metrics.snapshot()
+	assert.GreaterOrEqual(t, snapshot.Throttled, int64(1))
+	assert.Greater(t, snapshot.ThrottleWait, time.Duration(0))
+}
+
+func TestWithRetryDiagnostics(t *testing.T) {
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	configureClientRetry(t.Context(), client, newLogRedactor(apiSensitiveKeys...), clientRetryConfig{
+		MaxRetries: 2,
+		WaitMin:    time.Millisecond,
+		WaitMax:    time.Millisecond,
+	})
+
+	res := &schema.Resource{
+		Schema: map[string]*schema.Schema{},
+		CreateContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if _, err := client.GetTenantNetworks(); err != nil {
+				return diag.FromErr(err)
+			}
+			return nil
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			return nil
+		},
+	}
+	withRetryDiagnostics(res)
+
+	diags := res.CreateContext(t.Context(), res.TestResourceData(), client)
+	require.Len(t, diags, 2)
+	assert.Equal(t, diag.Error, diags[0].Severity)
+	assert.Equal(t, "API RETRIES AND THROTTLING", diags[1].Summary)
+	assert.Contains(t, diags[1].Detail, "retried 2 API request(s)")
+
+	assert.Empty(t, res.ReadContext(t.Context(), res.TestResourceData(), client))
+	assert.Nil(t, res.UpdateContext)
+}
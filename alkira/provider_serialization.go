@@ -0,0 +1,212 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+)
+
+// defaultSerializationTimeout matches the default of the client.
+const defaultSerializationTimeout = 120
+
+// apiVersionRegex matches the version prefix of versioned API paths.
+var apiVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// configureClientSerialization replaces the single global queue of the
+// client with serializingTransport. The queue of the client is internal
+// to the client package and always has one slot, so it is disabled and
+// serialization is done at the transport level instead.
+func configureClientSerialization(client *alkira.AlkiraClient, enabled bool, timeout int, concurrency int) {
+	client.SerializationEnabled = false
+
+	if !enabled {
+		return
+	}
+
+	if timeout <= 0 {
+		timeout = defaultSerializationTimeout
+	}
+
+	client.Client.HTTPClient.Transport = newSerializingTransport(
+		client.Client.HTTPClient.Transport,
+		time.Duration(timeout)*time.Second,
+		concurrency,
+	)
+}
+
+// serializationLockKey returns the lock key of a portal request, which
+// is the API collection the request works on, e.g. "segments" for
+// /api/tenantnetworks/1/segments/2, "ipsecconnectors" for
+// /api/v1/tenantnetworks/1/ipsecconnectors and "credentials" for
+// /api/credentials/...
+func serializationLockKey(u string) string {
+	path := strings.Trim(u, "/")
+
+	if i := strings.Index(path, "api/"); i >= 0 {
+		path = path[i+len("api/"):]
+	}
+
+	parts := strings.Split(path, "/")
+
+	if len(parts) > 1 && apiVersionRegex.MatchString(parts[0]) {
+		parts = parts[1:]
+	}
+
+	if len(parts) >= 3 && parts[0] == "tenantnetworks" {
+		return parts[2]
+	}
+
+	return parts[0]
+}
+
+// isExclusiveRequest reports whether a request has to run alone. The
+// portal requires provisioning requests to be serialized with every
+// other mutating request.
+func isExclusiveRequest(req *http.Request) bool {
+	return req.URL.Query().Get("provision") == "true"
+}
+
+// serializingTransport limits the number of mutating requests (anything
+// but GET and HEAD) in flight to the number of slots:
+//
+//   - requests on the same collection never run concurrently;
+//   - requests on different collections share the slots;
+//   - provisioning requests take all slots and run alone.
+//
+// With one slot it behaves like the global queue of the client. A slot
+// is held until the response body is closed, so the body is drained
+// before the next request starts, and each request is bounded by
+// timeout.
+type serializingTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+
+	slots       chan struct{}
+	exclusiveMu sync.Mutex
+
+	keysMu sync.Mutex
+	keys   map[string]chan struct{}
+}
+
+func newSerializingTransport(next http.RoundTripper, timeout time.Duration, concurrency int) *serializingTransport {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &serializingTransport{
+		next:    next,
+		timeout: timeout,
+		slots:   make(chan struct{}, concurrency),
+		keys:    make(map[string]chan struct{}),
+	}
+}
+
+func (t *serializingTransport) keyLock(key string) chan struct{} {
+	t.keysMu.Lock()
+	defer t.keysMu.Unlock()
+
+	lock, ok := t.keys[key]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		t.keys[key] = lock
+	}
+
+	return lock
+}
+
+// acquire takes the lock of the key and n slots. It returns a function
+// releasing them.
+func (t *serializingTransport) acquire(ctx context.Context, key string, exclusive bool) (func(), error) {
+	var held []chan struct{}
+
+	release := func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			<-held[i]
+		}
+	}
+
+	take := func(ch chan struct{}) error {
+		select {
+		case ch <- struct{}{}:
+			held = append(held, ch)
+			return nil
+		case <-ctx.Done():
+			release()
+			return ctx.Err()
+		}
+	}
+
+	if err := take(t.keyLock(key)); err != nil {
+		return nil, err
+	}
+
+	if !exclusive {
+		if err := take(t.slots); err != nil {
+			return nil, err
+		}
+		return release, nil
+	}
+
+	// Only one exclusive request collects slots at a time, otherwise
+	// two of them could each hold part of the slots forever.
+	t.exclusiveMu.Lock()
+	defer t.exclusiveMu.Unlock()
+
+	for i := 0; i < cap(t.slots); i++ {
+		if err := take(t.slots); err != nil {
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+func (t *serializingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return t.next.RoundTrip(req)
+	}
+
+	unlock, err := t.acquire(req.Context(), serializationLockKey(req.URL.Path), isExclusiveRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	release := func() {
+		cancel()
+		unlock()
+	}
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		release()
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("request execution timed out after %v: %w", t.timeout, err)
+		}
+		return nil, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+// releasingBody calls release exactly once when the body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
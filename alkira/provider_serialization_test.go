@@ -0,0 +1,220 @@
+package alkira
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializationLockKey(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/tenantnetworks/1/segments", "segments"},
+		{"/api/tenantnetworks/1/segments/22", "segments"},
+		{"/api/tenantnetworks/1/list-communities/3", "list-communities"},
+		{"/api/v1/tenantnetworks/1/ipsecconnectors/5", "ipsecconnectors"},
+		{"/api/v1/tenantnetworks/1/billingtags", "billingtags"},
+		{"/api/credentials/pan-instance", "credentials"},
+		{"/api/billing-tags", "billing-tags"},
+		{"/api/tenantnetworks/1", "tenantnetworks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, serializationLockKey(tt.path))
+		})
+	}
+}
+
+// concurrencyServer records the maximum number of concurrent requests
+// overall and for exclusive (provisioning) requests.
+type concurrencyServer struct {
+	inFlight, maxInFlight int32
+	exclusive, overlap    int32
+}
+
+func (s *concurrencyServer) handler(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&s.inFlight, 1)
+	for {
+		m := atomic.LoadInt32(&s.maxInFlight)
+		if n <= m || atomic.CompareAndSwapInt32(&s.maxInFlight, m, n) {
+			break
+		}
+	}
+
+	if r.URL.Query().Get("provision") == "true" {
+		atomic.AddInt32(&s.exclusive, 1)
+		if n > 1 {
+			atomic.AddInt32(&s.overlap, 1)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	atomic.AddInt32(&s.inFlight, -1)
+	_, _ = w.Write([]byte(`{}`))
+}
+
+func postConcurrently(t *testing.T, client *http.Client, urls []string) {
+	var wg sync.WaitGroup
+	for _, u := range urls {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			resp, err := client.Post(u, "application/json", strings.NewReader(`{}`))
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, _ = io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+		}(u)
+	}
+	wg.Wait()
+}
+
+func TestSerializingTransport(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		paths       []string
+		wantMax     int32
+	}{
+		{
+			name:        "single slot serializes everything",
+			concurrency: 1,
+			paths:       []string{"/api/a", "/api/b", "/api/c", "/api/d"},
+			wantMax:     1,
+		},
+		{
+			name:        "different collections run in parallel",
+			concurrency: 4,
+			paths:       []string{"/api/a", "/api/b", "/api/c", "/api/d"},
+			wantMax:     4,
+		},
+		{
+			name:        "same collection is serialized",
+			concurrency: 4,
+			paths:       []string{"/api/tenantnetworks/1/segments", "/api/tenantnetworks/1/segments/2", "/api/tenantnetworks/1/segments/3"},
+			wantMax:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &concurrencyServer{}
+			server := httptest.NewServer(http.HandlerFunc(s.handler))
+			t.Cleanup(server.Close)
+
+			client := &http.Client{Transport: newSerializingTransport(http.DefaultTransport, time.Second, tt.concurrency)}
+
+			var urls []string
+			for _, p := range tt.paths {
+				urls = append(urls, server.URL+p)
+			}
+			postConcurrently(t, client, urls)
+
+			assert.Equal(t, tt.wantMax, atomic.LoadInt32(&s.maxInFlight))
+		})
+	}
+}
+
+func TestSerializingTransportProvisionIsExclusive(t *testing.T) {
+	s := &concurrencyServer{}
+	server := httptest.NewServer(http.HandlerFunc(s.handler))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: newSerializingTransport(http.DefaultTransport, time.Second, 4)}
+
+	postConcurrently(t, client, []string{
+		server.URL + "/api/a",
+		server.URL + "/api/b?provision=true",
+		server.URL + "/api/c",
+		server.URL + "/api/d?provision=true",
+		server.URL + "/api/e",
+	})
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&s.exclusive))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&s.overlap))
+}
+
+func TestSerializingTransportGetIsNotSerialized(t *testing.T) {
+	s := &concurrencyServer{}
+	server := httptest.NewServer(http.HandlerFunc(s.handler))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: newSerializingTransport(http.DefaultTransport, time.Second, 1)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL + "/api/a")
+			if assert.NoError(t, err) {
+				_ = resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Greater(t, atomic.LoadInt32(&s.maxInFlight), int32(1))
+}
+
+func TestSerializingTransportTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: newSerializingTransport(http.DefaultTransport, 20*time.Millisecond, 1)}
+
+	_, err := client.Post(server.URL, "application/json", strings.NewReader(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestSerializingTransportCanceledWhileQueued(t *testing.T) {
+	tr := newSerializingTransport(http.DefaultTransport, time.Second, 1)
+
+	unlock, err := tr.acquire(context.Background(), "a", false)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = tr.acquire(ctx, "b", true)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+
+	// Everything was released, so an exclusive request goes through.
+	unlock, err = tr.acquire(context.Background(), "b", true)
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestConfigureClientSerialization(t *testing.T) {
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	client.SerializationEnabled = true
+
+	configureClientSerialization(client, false, 0, 1)
+	assert.False(t, client.SerializationEnabled)
+	_, ok := client.Client.HTTPClient.Transport.(*serializingTransport)
+	assert.False(t, ok)
+
+	configureClientSerialization(client, true, 0, 3)
+	tr, ok := client.Client.HTTPClient.Transport.(*serializingTransport)
+	require.True(t, ok)
+	assert.Equal(t, 3, cap(tr.slots))
+	assert.Equal(t, time.Duration(defaultSerializationTimeout)*time.Second, tr.timeout)
+}
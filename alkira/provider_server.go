@@ -0,0 +1,51 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// sdkProviderServer is the protocol server of the provider built with
+// the plugin SDK. It only adds the expiry warnings to the plans of its
+// resources, see PlanResourceChange.
+type sdkProviderServer struct {
+	tfprotov5.ProviderServer
+
+	provider *schema.Provider
+}
+
+// NewProviderServer returns the protocol server of the provider, the
+// provider of the plugin SDK muxed with the framework provider serving
+// the provider functions, the ephemeral resources and the list resources.
+func NewProviderServer(ctx context.Context) (func() tfprotov5.ProviderServer, error) {
+	return newProviderServer(ctx, Provider())
+}
+
+func newProviderServer(ctx context.Context, p *schema.Provider) (func() tfprotov5.ProviderServer, error) {
+	sdk := &sdkProviderServer{
+		ProviderServer: schema.NewGRPCProviderServer(p),
+		provider:       p,
+	}
+
+	schemas, err := sdk.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider schema: %w", err)
+	}
+
+	// The SDK provider comes first so that it's configured before the
+	// framework provider, which uses its client.
+	mux, err := tf5muxserver.NewMuxServer(ctx,
+		func() tfprotov5.ProviderServer { return sdk },
+		providerserver.NewProtocol5(newFrameworkProvider(p, sdk, schemas.Provider)()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return mux.ProviderServer, nil
+}
@@ -0,0 +1,311 @@
+package alkira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testProviderServer returns the muxed protocol server of a provider.
+func testProviderServer(t *testing.T, p *schema.Provider) tfprotov5.ProviderServer {
+	factory, err := newProviderServer(context.Background(), p)
+	require.NoError(t, err)
+
+	return factory()
+}
+
+// testConfigureProvider configures the provider of the server with an
+// empty configuration, the SDK provider returning client as its meta.
+func testConfigureProvider(t *testing.T, s tfprotov5.ProviderServer, p *schema.Provider, client *alkira.AlkiraClient) {
+	p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		return client, nil
+	}
+
+	schemas, err := s.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+
+	typ := schemas.Provider.ValueType()
+	config, err := tfprotov5.NewDynamicValue(typ, tftypes.NewValue(typ, nil))
+	require.NoError(t, err)
+
+	resp, err := s.ConfigureProvider(context.Background(), &tfprotov5.ConfigureProviderRequest{
+		TerraformVersion: "1.10.0",
+		Config:           &config,
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Diagnostics)
+}
+
+func testEphemeralType(t *testing.T, s tfprotov5.ProviderServer, typeName string) tftypes.Type {
+	schemas, err := s.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	require.Contains(t, schemas.EphemeralResourceSchemas, typeName)
+
+	return schemas.EphemeralResourceSchemas[typeName].ValueType()
+}
+
+func testEphemeralConfig(t *testing.T, s tfprotov5.ProviderServer, typeName string, values map[string]tftypes.Value) *tfprotov5.DynamicValue {
+	typ := testEphemeralType(t, s, typeName)
+
+	for name := range typ.(tftypes.Object).AttributeTypes {
+		if _, ok := values[name]; !ok {
+			values[name] = tftypes.NewValue(typ.(tftypes.Object).AttributeTypes[name], nil)
+		}
+	}
+
+	dv, err := tfprotov5.NewDynamicValue(typ, tftypes.NewValue(typ, values))
+	require.NoError(t, err)
+
+	return &dv
+}
+
+func testEphemeralOpen(t *testing.T, s tfprotov5.ProviderServer, typeName string, values map[string]tftypes.Value) (map[string]tftypes.Value, []*tfprotov5.Diagnostic) {
+	resp, err := s.OpenEphemeralResource(context.Background(), &tfprotov5.OpenEphemeralResourceRequest{
+		TypeName: typeName,
+		Config:   testEphemeralConfig(t, s, typeName, values),
+	})
+	require.NoError(t, err)
+
+	if resp.Result == nil {
+		return nil, resp.Diagnostics
+	}
+
+	v, err := resp.Result.Unmarshal(testEphemeralType(t, s, typeName))
+	require.NoError(t, err)
+
+	if v.IsNull() || !v.IsKnown() {
+		return nil, resp.Diagnostics
+	}
+
+	result := make(map[string]tftypes.Value)
+	require.NoError(t, v.As(&result))
+
+	return result, resp.Diagnostics
+}
+
+func TestProviderServerSchema(t *testing.T) {
+	s := testProviderServer(t, Provider())
+
+	metadata, err := s.GetMetadata(context.Background(), &tfprotov5.GetMetadataRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, metadata.Diagnostics)
+	assert.ElementsMatch(t, []tfprotov5.EphemeralResourceMetadata{
+		{TypeName: "alkira_random_psk"},
+		{TypeName: "alkira_session_token"},
+	}, metadata.EphemeralResources)
+	assert.NotEmpty(t, metadata.Resources)
+	assert.NotEmpty(t, metadata.ListResources)
+
+	// The mux server fails when the provider schemas of the SDK and the
+	// framework provider differ.
+	schemas, err := s.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, schemas.Diagnostics)
+	assert.Contains(t, schemas.EphemeralResourceSchemas, "alkira_random_psk")
+	assert.Contains(t, schemas.EphemeralResourceSchemas, "alkira_session_token")
+	assert.Contains(t, schemas.ListResourceSchemas, "alkira_segment")
+	assert.Contains(t, schemas.ResourceSchemas, "alkira_segment")
+}
+
+func TestEphemeralRandomPsk(t *testing.T) {
+	s := testProviderServer(t, Provider())
+
+	result, diags := testEphemeralOpen(t, s, "alkira_random_psk", map[string]tftypes.Value{})
+	require.Empty(t, diags)
+
+	var value string
+	require.NoError(t, result["value"].As(&value))
+	assert.Regexp(t, regexp.MustCompile(`^[A-Za-z0-9]{32}$`), value)
+
+	result, diags = testEphemeralOpen(t, s, "alkira_random_psk", map[string]tftypes.Value{
+		"length": tftypes.NewValue(tftypes.Number, 12),
+	})
+	require.Empty(t, diags)
+
+	var other string
+	require.NoError(t, result["value"].As(&other))
+	assert.Len(t, other, 12)
+
+	// Unknown lengths are only checked once they are known.
+	validate, err := s.ValidateEphemeralResourceConfig(context.Background(), &tfprotov5.ValidateEphemeralResourceConfigRequest{
+		TypeName: "alkira_random_psk",
+		Config: testEphemeralConfig(t, s, "alkira_random_psk", map[string]tftypes.Value{
+			"length": tftypes.NewValue(tftypes.Number, tftypes.UnknownValue),
+		}),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, validate.Diagnostics)
+
+	validate, err = s.ValidateEphemeralResourceConfig(context.Background(), &tfprotov5.ValidateEphemeralResourceConfigRequest{
+		TypeName: "alkira_random_psk",
+		Config: testEphemeralConfig(t, s, "alkira_random_psk", map[string]tftypes.Value{
+			"length": tftypes.NewValue(tftypes.Number, 4),
+		}),
+	})
+	require.NoError(t, err)
+	require.Len(t, validate.Diagnostics, 1)
+	assert.Contains(t, validate.Diagnostics[0].Detail, "between 8 and 64")
+}
+
+// testPortalSessions is a portal logging in sessions, recording the
+// requests of the sessions.
+type testPortalSessions struct {
+	requests []string
+	cookies  []string
+}
+
+func (p *testPortalSessions) handler(w http.ResponseWriter, r *http.Request) {
+	p.requests = append(p.requests, r.Method+" "+r.URL.Path)
+	p.cookies = append(p.cookies, r.Header.Get("Cookie"))
+
+	switch r.Method + " " + r.URL.Path {
+	case "POST /user/login":
+		var login map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&login)
+
+		if login["secret"] != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"t"}`))
+	case "POST /sessions":
+		http.SetCookie(w, &http.Cookie{Name: "SESSION", Value: "abc", MaxAge: 1800})
+		http.SetCookie(w, &http.Cookie{Name: "XSRF", Value: "def"})
+	case "GET /tenantnetworksummaries", "DELETE /sessions":
+		if r.Header.Get("Cookie") != "SESSION=abc; XSRF=def" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestEphemeralSessionToken(t *testing.T) {
+	p := Provider()
+	s := testProviderServer(t, p)
+
+	_, diags := testEphemeralOpen(t, s, "alkira_session_token", map[string]tftypes.Value{})
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Detail, "not configured")
+
+	portal := &testPortalSessions{}
+	client := createMockAlkiraClient(t, portal.handler)
+	client.TenantNetworkId = "12"
+	client.Secret = "key"
+	client.Authorization = "api-key a2V5"
+
+	testConfigureProvider(t, s, p, client)
+
+	before := time.Now()
+	open, err := s.OpenEphemeralResource(context.Background(), &tfprotov5.OpenEphemeralResourceRequest{
+		TypeName: "alkira_session_token",
+		Config:   testEphemeralConfig(t, s, "alkira_session_token", map[string]tftypes.Value{}),
+	})
+	require.NoError(t, err)
+	require.Empty(t, open.Diagnostics)
+
+	v, err := open.Result.Unmarshal(testEphemeralType(t, s, "alkira_session_token"))
+	require.NoError(t, err)
+
+	values := make(map[string]tftypes.Value)
+	require.NoError(t, v.As(&values))
+
+	got := make(map[string]string)
+	for k, v := range values {
+		var value string
+		require.NoError(t, v.As(&value))
+		got[k] = value
+	}
+
+	// The session is a new one, not the credentials of the provider.
+	assert.Equal(t, map[string]string{
+		"api_url":           client.URI,
+		"tenant_network_id": "12",
+		"header_name":       "Cookie",
+		"header_value":      "SESSION=abc; XSRF=def",
+	}, got)
+	assert.WithinDuration(t, before.Add(sessionTokenRenewInterval), open.RenewAt, 5*time.Second)
+
+	renew, err := s.RenewEphemeralResource(context.Background(), &tfprotov5.RenewEphemeralResourceRequest{
+		TypeName: "alkira_session_token",
+		Private:  open.Private,
+	})
+	require.NoError(t, err)
+	require.Empty(t, renew.Diagnostics)
+	assert.False(t, renew.RenewAt.IsZero())
+
+	closed, err := s.CloseEphemeralResource(context.Background(), &tfprotov5.CloseEphemeralResourceRequest{
+		TypeName: "alkira_session_token",
+		Private:  open.Private,
+	})
+	require.NoError(t, err)
+	require.Empty(t, closed.Diagnostics)
+
+	assert.Equal(t, []string{
+		"POST /user/login",
+		"POST /sessions",
+		"GET /tenantnetworksummaries",
+		"DELETE /sessions",
+	}, portal.requests)
+	assert.Equal(t, []string{"", "", "SESSION=abc; XSRF=def", "SESSION=abc; XSRF=def"}, portal.cookies)
+
+	// A failed login is reported.
+	client.Secret = "other"
+
+	_, diags = testEphemeralOpen(t, s, "alkira_session_token", map[string]tftypes.Value{})
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Detail, "failed to log in to portal")
+}
+
+func TestSessionRenewAt(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		cookies []*http.Cookie
+		want    time.Time
+	}{
+		{
+			name:    "session cookies",
+			cookies: []*http.Cookie{{Name: "SESSION", Value: "abc"}},
+			want:    now.Add(sessionTokenRenewInterval),
+		},
+		{
+			name:    "max age",
+			cookies: []*http.Cookie{{Name: "SESSION", Value: "abc", MaxAge: 180}},
+			want:    now.Add(2 * time.Minute),
+		},
+		{
+			name:    "expires",
+			cookies: []*http.Cookie{{Name: "SESSION", Value: "abc", Expires: now.Add(3 * time.Minute)}},
+			want:    now.Add(2 * time.Minute),
+		},
+		{
+			name:    "longer than the interval",
+			cookies: []*http.Cookie{{Name: "SESSION", Value: "abc", MaxAge: 3600}},
+			want:    now.Add(sessionTokenRenewInterval),
+		},
+		{
+			name:    "about to expire",
+			cookies: []*http.Cookie{{Name: "SESSION", Value: "abc", MaxAge: 30}},
+			want:    now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sessionRenewAt(tt.cookies, now))
+		})
+	}
+}
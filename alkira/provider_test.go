@@ -1,7 +1,9 @@
 package alkira
 
 import (
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -101,6 +103,52 @@ func TestBoolEnvDefaultFunc(t *testing.T) {
 	}
 }
 
+func TestSelectTenantNetwork(t *testing.T) {
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tenantnetworksummaries" {
+			t.Errorf("unexpected request path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"id":10,"name":"prod"},{"id":20,"name":"staging"},{"id":30,"name":"dup"},{"id":31,"name":"dup"}]`))
+	})
+	client.TenantNetworkId = "10"
+
+	cases := []struct {
+		name    string
+		id      string
+		tnName  string
+		want    string
+		wantErr string
+	}{
+		{name: "default keeps login tenant network", want: "10"},
+		{name: "select by id", id: "20", want: "20"},
+		{name: "select by name", tnName: "staging", want: "20"},
+		{name: "unknown id", id: "99", wantErr: "tenant network ID 99 is not accessible"},
+		{name: "unknown name", tnName: "dev", wantErr: "prod (10), staging (20)"},
+		{name: "ambiguous name", tnName: "dup", wantErr: "ambiguous"},
+		{name: "both id and name", id: "20", tnName: "staging", wantErr: "only one of"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := selectTenantNetwork(client, tc.id, tc.tnName)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
 // UNUSED: Commented out to suppress linter warnings
 // func testAccPreCheck(t *testing.T) {
 // 	if v := os.Getenv("ALKIRA_PORTAL"); v == "" {
@@ -0,0 +1,213 @@
+package alkira
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// portalTransportConfig holds the TLS and proxy settings used to reach
+// the portal. The zero value means "use the client defaults".
+type portalTransportConfig struct {
+	CACertFile         string
+	CACertPEM          string
+	ClientCertFile     string
+	ClientKeyFile      string
+	ClientCertPEM      string
+	ClientKeyPEM       string
+	ProxyURL           string
+	InsecureSkipVerify bool
+}
+
+// isSet reports whether any setting deviates from the defaults of the
+// transport built by alkira.NewAlkiraClient.
+func (c portalTransportConfig) isSet() bool {
+	return c != portalTransportConfig{}
+}
+
+// newTransport builds the HTTP transport used for every portal
+// request, including the initial tenant network lookup.
+func (c portalTransportConfig) newTransport() (*http.Transport, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec // explicit opt-in for lab portals
+	}
+
+	if c.CACertFile != "" || c.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		if c.CACertFile != "" {
+			pem, err := os.ReadFile(c.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ca_cert_file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("ca_cert_file %s does not contain any PEM certificate", c.CACertFile)
+			}
+		}
+
+		if c.CACertPEM != "" && !pool.AppendCertsFromPEM([]byte(c.CACertPEM)) {
+			return nil, fmt.Errorf("ca_cert_pem does not contain any PEM certificate")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	cert, err := c.clientCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+
+	proxy := http.ProxyFromEnvironment
+
+	if c.ProxyURL != "" {
+		u, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy_url: %w", err)
+		}
+
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("invalid proxy_url %q: scheme must be http, https or socks5", c.ProxyURL)
+		}
+
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy_url %q: missing host", c.ProxyURL)
+		}
+
+		proxy = http.ProxyURL(u)
+	}
+
+	return &http.Transport{
+		Proxy:           proxy,
+		TLSClientConfig: tlsConfig,
+	}, nil
+}
+
+// clientCertificate loads the client certificate either from files or
+// from inline PEM. It returns nil when client authentication is not
+// configured.
+func (c portalTransportConfig) clientCertificate() (*tls.Certificate, error) {
+	certPEM, keyPEM := []byte(c.ClientCertPEM), []byte(c.ClientKeyPEM)
+
+	if c.ClientCertFile != "" {
+		data, err := os.ReadFile(c.ClientCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client_cert_file: %w", err)
+		}
+		certPEM = data
+	}
+
+	if c.ClientKeyFile != "" {
+		data, err := os.ReadFile(c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client_key_file: %w", err)
+		}
+		keyPEM = data
+	}
+
+	if len(certPEM) == 0 && len(keyPEM) == 0 {
+		return nil, nil
+	}
+
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return nil, fmt.Errorf("client certificate authentication requires both a certificate and a private key")
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	return &cert, nil
+}
+
+// newAlkiraClientWithTransport creates a client whose requests, login
+// included, go through the given transport. It mirrors the header
+// authentication of alkira.NewAlkiraClient, which always builds its own
+// transport and can't be told to trust a custom CA or use a proxy.
+//
+// Serialization is left to configureClientSerialization.
+func newAlkiraClientWithTransport(portal string, username string, password string, secret string, provision bool, validate bool, tr http.RoundTripper) (*alkira.AlkiraClient, error) {
+	var auth string
+
+	if len(secret) > 0 {
+		auth = "api-key " + base64.StdEncoding.EncodeToString([]byte(secret))
+	} else if len(username) > 0 {
+		auth = "basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	} else {
+		return nil, fmt.Errorf("invalid credentials to authenticate")
+	}
+
+	retryClient := retryablehttp.NewClient()
+	retryClient.HTTPClient.Transport = tr
+	retryClient.RetryMax = 5
+	retryClient.Backoff = portalBackoff
+	retryClient.CheckRetry = portalCheckRetry
+
+	client := &alkira.AlkiraClient{
+		Client:        retryClient,
+		URI:           "https://" + portal + "/api",
+		Username:      username,
+		Password:      password,
+		Secret:        secret,
+		Authorization: auth,
+		Provision:     provision,
+		Validate:      validate,
+	}
+
+	tenantNetworkId, err := selectTenantNetwork(client, "", "")
+	if err != nil {
+		return nil, err
+	}
+	client.TenantNetworkId = tenantNetworkId
+
+	return client, nil
+}
+
+// portalBackoff honours the Retry-After header of throttled responses,
+// the same way alkira.NewAlkiraClient does. Throttled responses without
+// Retry-After back off exponentially so that parallel requests spread
+// out instead of hitting the portal again in lockstep; everything else
+// uses a linear jitter backoff.
+func portalBackoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		return retryablehttp.DefaultBackoff(min, max, attemptNum, resp)
+	}
+
+	return retryablehttp.LinearJitterBackoff(min, max, attemptNum, resp)
+}
+
+// portalCheckRetry retries on 429 and 500 in addition to the default
+// retryablehttp policy.
+func portalCheckRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	shouldRetry, e := retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError:
+			return true, fmt.Errorf("retryable status code: %d", resp.StatusCode)
+		}
+	}
+
+	return shouldRetry, e
+}
@@ -0,0 +1,124 @@
+package alkira
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortalTransportConfigIsSet(t *testing.T) {
+	assert.False(t, portalTransportConfig{}.isSet())
+	assert.True(t, portalTransportConfig{ProxyURL: "http://proxy:3128"}.isSet())
+	assert.True(t, portalTransportConfig{InsecureSkipVerify: true}.isSet())
+}
+
+func TestPortalTransportConfigNewTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  portalTransportConfig
+		wantErr string
+	}{
+		{
+			name:   "insecure skip verify",
+			config: portalTransportConfig{InsecureSkipVerify: true},
+		},
+		{
+			name:    "invalid ca pem",
+			config:  portalTransportConfig{CACertPEM: "not a certificate"},
+			wantErr: "ca_cert_pem does not contain any PEM certificate",
+		},
+		{
+			name:    "missing ca file",
+			config:  portalTransportConfig{CACertFile: "/nonexistent/ca.pem"},
+			wantErr: "failed to read ca_cert_file",
+		},
+		{
+			name:    "client cert without key",
+			config:  portalTransportConfig{ClientCertPEM: "cert"},
+			wantErr: "requires both a certificate and a private key",
+		},
+		{
+			name:    "invalid client key pair",
+			config:  portalTransportConfig{ClientCertPEM: "cert", ClientKeyPEM: "key"},
+			wantErr: "failed to load client certificate",
+		},
+		{
+			name:    "unsupported proxy scheme",
+			config:  portalTransportConfig{ProxyURL: "ftp://proxy:21"},
+			wantErr: "scheme must be http, https or socks5",
+		},
+		{
+			name:    "proxy without host",
+			config:  portalTransportConfig{ProxyURL: "http://"},
+			wantErr: "missing host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := tt.config.newTransport()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.config.InsecureSkipVerify, tr.TLSClientConfig.InsecureSkipVerify)
+		})
+	}
+}
+
+func TestPortalTransportConfigProxyURL(t *testing.T) {
+	tr, err := portalTransportConfig{ProxyURL: "http://proxy.example.com:3128"}.newTransport()
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("GET", "https://tenant.portal.alkira.com/api", nil)
+	require.NoError(t, err)
+
+	proxy, err := tr.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "proxy.example.com:3128", proxy.Host)
+}
+
+func TestNewAlkiraClientWithTransportCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/tenantnetworksummaries", r.URL.Path)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "api-key "))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":42,"name":"prod"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	t.Run("trusted with ca_cert_pem", func(t *testing.T) {
+		tr, err := portalTransportConfig{CACertPEM: caPEM}.newTransport()
+		require.NoError(t, err)
+
+		client, err := newAlkiraClientWithTransport(u.Host, "", "", "secret", false, false, tr)
+		require.NoError(t, err)
+		assert.Equal(t, "42", client.TenantNetworkId)
+		assert.Equal(t, "https://"+u.Host+"/api", client.URI)
+	})
+
+	t.Run("untrusted without ca", func(t *testing.T) {
+		_, err := newAlkiraClientWithTransport(u.Host, "", "", "secret", false, false, &http.Transport{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "certificate")
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		_, err := newAlkiraClientWithTransport(u.Host, "", "", "", false, false, http.DefaultTransport)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid credentials")
+	})
+}
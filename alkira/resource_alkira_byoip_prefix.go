@@ -11,7 +11,11 @@ import (
 
 func resourceAlkiraByoipPrefix() *schema.Resource {
 	return &schema.Resource{
-		Description:   "Manage BYOIP Prefix.",
+		Description: "Manage BYOIP Prefix.\n\n" +
+			"The `signature` of the `message` is verified against " +
+			"`public_key` at plan time, and the `message` must be for " +
+			"`prefix` and not expired. The data source " +
+			"`alkira_byoip_message` builds the message to sign.",
 		CreateContext: resourceByoipPrefix,
 		ReadContext:   resourceByoipPrefixRead,
 		UpdateContext: warnOnFailedStateUpdate(resourceByoipPrefixUpdate),
@@ -25,7 +29,7 @@ func resourceAlkiraByoipPrefix() *schema.Resource {
 				d.SetNew("provision_state", "SUCCESS")
 			}
 
-			return nil
+			return checkByoipSignature(d)
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: importWithReadValidation(resourceByoipPrefixRead),
@@ -76,14 +80,17 @@ func resourceAlkiraByoipPrefix() *schema.Resource {
 			},
 			"signature": {
 				Description: "Signature from the BYOIP." +
+					"For AWS, the signature scheme is RSASSA-PSS with " +
+					"SHA256 and the base64 signature is URL safe. " +
 					"For AZURE, the signature scheme is `SHA256RSA`.",
 				Type:     schema.TypeString,
 				Required: true,
 			},
 			"public_key": {
-				Description: "The RSA 2048-bit public key from the BYOIP.",
-				Type:        schema.TypeString,
-				Required:    true,
+				Description: "The RSA 2048-bit public key from the BYOIP, " +
+					"as a PEM public key or certificate.",
+				Type:     schema.TypeString,
+				Required: true,
 			},
 		},
 	}
@@ -0,0 +1,295 @@
+package alkira
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	byoipMessageDateLayout = "20060102"
+	byoipPublicKeyBits     = 2048
+)
+
+var (
+	byoipAwsAccountRegexp        = regexp.MustCompile(`^[0-9]{12}$`)
+	byoipAzureSubscriptionRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// byoipAwsSignatureReplacer undoes the `tr -- '+=/' '-_~'` AWS asks
+	// for to make the base64 signature URL safe.
+	byoipAwsSignatureReplacer = strings.NewReplacer("-", "+", "_", "=", "~", "/")
+)
+
+// byoipMessageFields are the fields of the message of a BYOIP prefix.
+type byoipMessageFields struct {
+	account string
+	prefix  netip.Prefix
+	expiry  time.Time
+}
+
+// byoipMessageFormat returns the format of the message of a cloud
+// provider.
+func byoipMessageFormat(cloudProvider string) string {
+	if strings.EqualFold(cloudProvider, "AZURE") {
+		return "subscriptionId|cidr|YYYYMMDD"
+	}
+	return "1|aws|account|cidr|YYYYMMDD|SHA256|RSAPSS"
+}
+
+// byoipMessage returns the message to sign for a prefix, which is the
+// account ID for AWS or the subscription ID for AZURE, the prefix and
+// the expiry date.
+func byoipMessage(cloudProvider, account string, prefix netip.Prefix, expiry time.Time) (string, error) {
+	date := expiry.Format(byoipMessageDateLayout)
+
+	switch strings.ToUpper(cloudProvider) {
+	case "AWS":
+		if !byoipAwsAccountRegexp.MatchString(account) {
+			return "", fmt.Errorf("%q is not a 12-digit AWS account ID", account)
+		}
+		return strings.Join([]string{"1", "aws", account, prefix.String(), date, "SHA256", "RSAPSS"}, "|"), nil
+	case "AZURE":
+		if !byoipAzureSubscriptionRegexp.MatchString(account) {
+			return "", fmt.Errorf("%q is not an Azure subscription ID", account)
+		}
+		return strings.Join([]string{account, prefix.String(), date}, "|"), nil
+	default:
+		return "", fmt.Errorf("unsupported cloud_provider %q, expected AWS or AZURE", cloudProvider)
+	}
+}
+
+// parseByoipMessage parses the message of a BYOIP prefix of a cloud
+// provider.
+func parseByoipMessage(cloudProvider, message string) (byoipMessageFields, error) {
+	var fields byoipMessageFields
+	var prefix, date string
+
+	parts := strings.Split(message, "|")
+
+	switch strings.ToUpper(cloudProvider) {
+	case "AWS":
+		if len(parts) != 7 || parts[0] != "1" || parts[1] != "aws" || parts[5] != "SHA256" || parts[6] != "RSAPSS" {
+			return fields, fmt.Errorf("message must be in the format %s, got %q", byoipMessageFormat(cloudProvider), message)
+		}
+		fields.account, prefix, date = parts[2], parts[3], parts[4]
+
+		if !byoipAwsAccountRegexp.MatchString(fields.account) {
+			return fields, fmt.Errorf("message account %q is not a 12-digit AWS account ID", fields.account)
+		}
+	case "AZURE":
+		if len(parts) != 3 {
+			return fields, fmt.Errorf("message must be in the format %s, got %q", byoipMessageFormat(cloudProvider), message)
+		}
+		fields.account, prefix, date = parts[0], parts[1], parts[2]
+
+		if !byoipAzureSubscriptionRegexp.MatchString(fields.account) {
+			return fields, fmt.Errorf("message subscriptionId %q is not an Azure subscription ID", fields.account)
+		}
+	default:
+		return fields, fmt.Errorf("unsupported cloud_provider %q, expected AWS or AZURE", cloudProvider)
+	}
+
+	var err error
+
+	fields.prefix, err = parseNetworkPrefix(prefix)
+	if err != nil {
+		return fields, fmt.Errorf("message cidr: %w", err)
+	}
+
+	fields.expiry, err = time.Parse(byoipMessageDateLayout, date)
+	if err != nil {
+		return fields, fmt.Errorf("message date %q is not in the format YYYYMMDD", date)
+	}
+
+	return fields, nil
+}
+
+// parseByoipPublicKey parses an RSA 2048-bit public key given as a PEM
+// public key, RSA public key or certificate, or as the base64 DER of
+// one of them without the PEM armor.
+func parseByoipPublicKey(s string) (*rsa.PublicKey, error) {
+	var der []byte
+	var blockType string
+
+	if block, _ := pem.Decode([]byte(strings.TrimSpace(s))); block != nil {
+		der, blockType = block.Bytes, block.Type
+	} else {
+		var err error
+		der, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+		if err != nil {
+			return nil, fmt.Errorf("public_key is neither PEM nor base64")
+		}
+	}
+
+	var key interface{}
+	var err error
+
+	switch blockType {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(der)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(der)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(der); err == nil {
+			key = cert.PublicKey
+		}
+	case "":
+		if key, err = x509.ParsePKIXPublicKey(der); err != nil {
+			if key, err = x509.ParsePKCS1PublicKey(der); err != nil {
+				var cert *x509.Certificate
+				if cert, err = x509.ParseCertificate(der); err == nil {
+					key = cert.PublicKey
+				}
+			}
+		}
+	default:
+		if strings.HasSuffix(blockType, "PRIVATE KEY") {
+			return nil, fmt.Errorf("public_key is a private key, use its public key instead")
+		}
+		return nil, fmt.Errorf("public_key is a PEM %s, expected a PUBLIC KEY, RSA PUBLIC KEY or CERTIFICATE", blockType)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("public_key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() != byoipPublicKeyBits {
+			return nil, fmt.Errorf("public_key is a %d-bit RSA key, expected %d bits", k.N.BitLen(), byoipPublicKeyBits)
+		}
+		return k, nil
+	case *ecdsa.PublicKey:
+		return nil, fmt.Errorf("public_key is an ECDSA key, expected an RSA key")
+	case ed25519.PublicKey:
+		return nil, fmt.Errorf("public_key is an Ed25519 key, expected an RSA key")
+	default:
+		return nil, fmt.Errorf("public_key is a %T, expected an RSA key", key)
+	}
+}
+
+// decodeByoipSignature decodes the base64 signature of a cloud provider,
+// URL safe for AWS.
+func decodeByoipSignature(cloudProvider, signature string) ([]byte, error) {
+	s := strings.Join(strings.Fields(signature), "")
+	if strings.EqualFold(cloudProvider, "AWS") {
+		s = byoipAwsSignatureReplacer.Replace(s)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("signature is not base64")
+	}
+
+	if len(sig) != byoipPublicKeyBits/8 {
+		return nil, fmt.Errorf("signature is %d bytes, expected %d for an RSA %d-bit signature", len(sig), byoipPublicKeyBits/8, byoipPublicKeyBits)
+	}
+
+	return sig, nil
+}
+
+// verifyByoipMessageSignature verifies the signature of a message, with
+// RSASSA-PSS for AWS and RSASSA-PKCS1-v1_5 for AZURE, both over SHA256.
+// When it doesn't match, the usual mistakes are told apart: the other
+// padding or the message signed with a trailing newline, e.g. by echo.
+func verifyByoipMessageSignature(cloudProvider string, key *rsa.PublicKey, message string, sig []byte) error {
+	verify := func(message string, pss bool) bool {
+		digest := sha256.Sum256([]byte(message))
+		if pss {
+			return rsa.VerifyPSS(key, crypto.SHA256, digest[:], sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) == nil
+		}
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	}
+
+	pss := !strings.EqualFold(cloudProvider, "AZURE")
+
+	switch {
+	case verify(message, pss):
+		return nil
+	case verify(message+"\n", pss):
+		return errors.New("signature is of the message followed by a newline, sign the message without one, e.g. with printf '%s'")
+	case pss && verify(message, false):
+		return errors.New("signature uses PKCS #1 v1.5 padding, AWS expects RSASSA-PSS, e.g. with openssl dgst -sigopt rsa_padding_mode:pss")
+	case !pss && verify(message, true):
+		return errors.New("signature uses RSASSA-PSS padding, AZURE expects SHA256RSA with PKCS #1 v1.5 padding")
+	default:
+		return errors.New("signature doesn't match message and public_key, check that message was signed as is with the private key of public_key")
+	}
+}
+
+// verifyByoipSignature checks that the message of a BYOIP prefix is for
+// the prefix and not expired, and that the signature is the signature of
+// the message by the private key of the public key.
+func verifyByoipSignature(cloudProvider, prefix, message, signature, publicKey string, now time.Time) error {
+	fields, err := parseByoipMessage(cloudProvider, message)
+	if err != nil {
+		return err
+	}
+
+	cidr, err := parseNetworkPrefix(prefix)
+	if err != nil {
+		return fmt.Errorf("prefix: %w", err)
+	}
+
+	if fields.prefix != cidr {
+		return fmt.Errorf("message cidr %s doesn't match prefix %s", fields.prefix, cidr)
+	}
+
+	if days, _ := daysRemaining(fields.expiry.Format(expiryDateLayout), now); days < 0 {
+		return fmt.Errorf("message expired on %s", fields.expiry.Format(expiryDateLayout))
+	}
+
+	key, err := parseByoipPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	sig, err := decodeByoipSignature(cloudProvider, signature)
+	if err != nil {
+		return err
+	}
+
+	return verifyByoipMessageSignature(cloudProvider, key, message, sig)
+}
+
+// byoipSignatureAttributes are the attributes the signature check of a
+// BYOIP prefix depends on.
+var byoipSignatureAttributes = []string{"cloud_provider", "prefix", "message", "signature", "public_key"}
+
+// checkByoipSignature verifies at plan time the signature of a new BYOIP
+// prefix or of one whose signature attributes changed, once they are
+// all known.
+func checkByoipSignature(d *schema.ResourceDiff) error {
+	if d.Id() != "" && !d.HasChanges(byoipSignatureAttributes...) {
+		return nil
+	}
+
+	for _, attribute := range byoipSignatureAttributes {
+		if !d.NewValueKnown(attribute) {
+			return nil
+		}
+	}
+
+	return verifyByoipSignature(
+		d.Get("cloud_provider").(string),
+		d.Get("prefix").(string),
+		d.Get("message").(string),
+		d.Get("signature").(string),
+		d.Get("public_key").(string),
+		time.Now(),
+	)
+}
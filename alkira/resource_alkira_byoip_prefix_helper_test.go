@@ -0,0 +1,257 @@
+package alkira
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testByoipAwsMessage   = "1|aws|012345678901|198.51.100.0/24|20301231|SHA256|RSAPSS"
+	testByoipAzureMessage = "00000000-1111-2222-3333-444444444444|198.51.100.0/24|20301231"
+)
+
+func testByoipSign(t *testing.T, key *rsa.PrivateKey, message string, pss bool) string {
+	digest := sha256.Sum256([]byte(message))
+
+	var sig []byte
+	var err error
+	if pss {
+		sig, err = rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	} else {
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	}
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func testByoipPublicKeyPem(t *testing.T, key *rsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestVerifyByoipSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	publicKey := testByoipPublicKeyPem(t, key)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	awsSignature := testByoipSign(t, key, testByoipAwsMessage, true)
+	awsUrlSafeSignature := strings.NewReplacer("+", "-", "=", "_", "/", "~").Replace(awsSignature)
+	azureSignature := testByoipSign(t, key, testByoipAzureMessage, false)
+
+	tampered, _ := base64.StdEncoding.DecodeString(awsSignature)
+	tampered[0] ^= 0xff
+
+	tests := []struct {
+		name          string
+		cloudProvider string
+		prefix        string
+		message       string
+		signature     string
+		publicKey     string
+		wantErr       string
+	}{
+		{
+			name:          "AWS",
+			cloudProvider: "AWS",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAwsMessage,
+			signature:     awsUrlSafeSignature,
+			publicKey:     publicKey,
+		},
+		{
+			name:          "AWS without URL safe base64",
+			cloudProvider: "AWS",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAwsMessage,
+			signature:     awsSignature,
+			publicKey:     publicKey,
+		},
+		{
+			name:          "AZURE",
+			cloudProvider: "AZURE",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAzureMessage,
+			signature:     azureSignature,
+			publicKey:     publicKey,
+		},
+		{
+			name:          "bare base64 public key",
+			cloudProvider: "AZURE",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAzureMessage,
+			signature:     azureSignature,
+			publicKey:     base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PublicKey(&key.PublicKey)),
+		},
+		{
+			name:          "message for another prefix",
+			cloudProvider: "AWS",
+			prefix:        "198.51.101.0/24",
+			message:       testByoipAwsMessage,
+			signature:     awsSignature,
+			publicKey:     publicKey,
+			wantErr:       "message cidr 198.51.100.0/24 doesn't match prefix 198.51.101.0/24",
+		},
+		{
+			name:          "message of another cloud provider",
+			cloudProvider: "AWS",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAzureMessage,
+			signature:     azureSignature,
+			publicKey:     publicKey,
+			wantErr:       `message must be in the format 1|aws|account|cidr|YYYYMMDD|SHA256|RSAPSS, got "` + testByoipAzureMessage + `"`,
+		},
+		{
+			name:          "expired message",
+			cloudProvider: "AZURE",
+			prefix:        "198.51.100.0/24",
+			message:       "00000000-1111-2222-3333-444444444444|198.51.100.0/24|20251231",
+			signature:     azureSignature,
+			publicKey:     publicKey,
+			wantErr:       "message expired on 2025-12-31",
+		},
+		{
+			name:          "small key",
+			cloudProvider: "AWS",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAwsMessage,
+			signature:     awsSignature,
+			publicKey:     testByoipPublicKeyPem(t, smallKey),
+			wantErr:       "public_key is a 1024-bit RSA key, expected 2048 bits",
+		},
+		{
+			name:          "private key",
+			cloudProvider: "AWS",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAwsMessage,
+			signature:     awsSignature,
+			publicKey:     string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+			wantErr:       "public_key is a private key, use its public key instead",
+		},
+		{
+			name:          "truncated signature",
+			cloudProvider: "AWS",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAwsMessage,
+			signature:     awsSignature[:40],
+			publicKey:     publicKey,
+			wantErr:       "signature is 30 bytes, expected 256 for an RSA 2048-bit signature",
+		},
+		{
+			name:          "tampered signature",
+			cloudProvider: "AWS",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAwsMessage,
+			signature:     base64.StdEncoding.EncodeToString(tampered),
+			publicKey:     publicKey,
+			wantErr:       "signature doesn't match message and public_key, check that message was signed as is with the private key of public_key",
+		},
+		{
+			name:          "AWS signature without PSS",
+			cloudProvider: "AWS",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAwsMessage,
+			signature:     testByoipSign(t, key, testByoipAwsMessage, false),
+			publicKey:     publicKey,
+			wantErr:       "signature uses PKCS #1 v1.5 padding, AWS expects RSASSA-PSS, e.g. with openssl dgst -sigopt rsa_padding_mode:pss",
+		},
+		{
+			name:          "signed with a trailing newline",
+			cloudProvider: "AZURE",
+			prefix:        "198.51.100.0/24",
+			message:       testByoipAzureMessage,
+			signature:     testByoipSign(t, key, testByoipAzureMessage+"\n", false),
+			publicKey:     publicKey,
+			wantErr:       "signature is of the message followed by a newline, sign the message without one, e.g. with printf '%s'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyByoipSignature(tt.cloudProvider, tt.prefix, tt.message, tt.signature, tt.publicKey, now)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDataSourceAlkiraByoipMessageRead(t *testing.T) {
+	tests := []struct {
+		name          string
+		cloudProvider string
+		accountId     string
+		prefix        string
+		wantMessage   string
+		wantErr       string
+	}{
+		{
+			name:          "AWS",
+			cloudProvider: "AWS",
+			accountId:     "012345678901",
+			prefix:        "198.51.100.0/24",
+			wantMessage:   testByoipAwsMessage,
+		},
+		{
+			name:          "AZURE",
+			cloudProvider: "AZURE",
+			accountId:     "00000000-1111-2222-3333-444444444444",
+			prefix:        "198.51.100.0/24",
+			wantMessage:   testByoipAzureMessage,
+		},
+		{
+			name:          "AZURE with an AWS account",
+			cloudProvider: "AZURE",
+			accountId:     "012345678901",
+			prefix:        "198.51.100.0/24",
+			wantErr:       `account_id: "012345678901" is not an Azure subscription ID`,
+		},
+		{
+			name:          "host bits",
+			cloudProvider: "AWS",
+			accountId:     "012345678901",
+			prefix:        "198.51.100.1/24",
+			wantErr:       "prefix: 198.51.100.1/24 has host bits set, did you mean 198.51.100.0/24?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := dataSourceAlkiraByoipMessage().TestResourceData()
+			d.Set("cloud_provider", tt.cloudProvider)
+			d.Set("account_id", tt.accountId)
+			d.Set("prefix", tt.prefix)
+			d.Set("expiry_date", "2030-12-31")
+
+			err := dataSourceAlkiraByoipMessageRead(d, nil)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMessage, d.Get("message"))
+			assert.Contains(t, d.Get("openssl_command"), "'"+tt.wantMessage+"'")
+		})
+	}
+}
@@ -3,9 +3,9 @@ package alkira
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -185,7 +185,7 @@ func resourceConnectorAkamaiProlexicCreate(ctx context.Context, d *schema.Resour
 	api := alkira.NewConnectorAkamaiProlexic(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateConnectorAkamaiProlexicRequest(d, m)
+	request, err := generateConnectorAkamaiProlexicRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -302,7 +302,7 @@ func resourceConnectorAkamaiProlexicUpdate(ctx context.Context, d *schema.Resour
 	api := alkira.NewConnectorAkamaiProlexic(m.(*alkira.AlkiraClient))
 
 	// Construct update request
-	connector, err := generateConnectorAkamaiProlexicRequest(d, m)
+	connector, err := generateConnectorAkamaiProlexicRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -389,10 +389,10 @@ func resourceConnectorAkamaiProlexicDelete(ctx context.Context, d *schema.Resour
 }
 
 // generateConnectorAkamaiProlexicRequest generate request for the connector
-func generateConnectorAkamaiProlexicRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorAkamaiProlexic, error) {
+func generateConnectorAkamaiProlexicRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorAkamaiProlexic, error) {
 
 	byoipOptions := expandConnectorAkamaiByoipOptions(d.Get("byoip_options").(*schema.Set))
-	tunnelConfigurations := expandConnectorAkamaiTunnelConfiguration(d.Get("tunnel_configuration").(*schema.Set))
+	tunnelConfigurations := expandConnectorAkamaiTunnelConfiguration(ctx, d.Get("tunnel_configuration").(*schema.Set))
 
 	// Convert Segment
 	segmentName, err := getSegmentNameById(d.Get("segment_id").(string), m)
@@ -402,7 +402,7 @@ func generateConnectorAkamaiProlexicRequest(d *schema.ResourceData, m interface{
 	}
 
 	// Create implicit akamai-prolexic credential
-	log.Printf("[INFO] Creating credential-akamai-prolexic")
+	tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating akamai prolexic credential")
 	c := alkira.CredentialAkamaiProlexic{
 		BgpAuthenticationKey: d.Get("akamai_bgp_authentication_key").(string),
 	}
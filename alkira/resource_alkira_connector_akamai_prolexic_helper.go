@@ -1,15 +1,16 @@
 package alkira
 
 import (
-	"log"
+	"context"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func expandConnectorAkamaiTunnelIps(in *schema.Set) []alkira.ConnectorAkamaiProlexicTunnelIp {
+func expandConnectorAkamaiTunnelIps(ctx context.Context, in *schema.Set) []alkira.ConnectorAkamaiProlexicTunnelIp {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] invalid input of connector_akamai_prolexic tunnel ips")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid input of connector_akamai_prolexic tunnel ips")
 		return nil
 	}
 
@@ -36,9 +37,9 @@ func expandConnectorAkamaiTunnelIps(in *schema.Set) []alkira.ConnectorAkamaiProl
 }
 
 // expandConnectorAkamaiTunnelConfiguration
-func expandConnectorAkamaiTunnelConfiguration(in *schema.Set) []alkira.ConnectorAkamaiProlexicOverlayConfiguration {
+func expandConnectorAkamaiTunnelConfiguration(ctx context.Context, in *schema.Set) []alkira.ConnectorAkamaiProlexicOverlayConfiguration {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] invalid input of connector_akamai_prolexic tunnel configuration")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid input of connector_akamai_prolexic tunnel configuration")
 		return nil
 	}
 
@@ -51,7 +52,7 @@ func expandConnectorAkamaiTunnelConfiguration(in *schema.Set) []alkira.Connector
 			r.AlkiraPublicIp = v
 		}
 		if v, ok := cfg["tunnel_ips"].(*schema.Set); ok {
-			r.TunnelIps = expandConnectorAkamaiTunnelIps(v)
+			r.TunnelIps = expandConnectorAkamaiTunnelIps(ctx, v)
 		}
 		configurations[i] = r
 	}
@@ -246,7 +246,7 @@ func resourceConnectorAwsVpcCreate(ctx context.Context, d *schema.ResourceData,
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorAwsVpc(client)
 
-	request, err := generateConnectorAwsVpcRequest(d, m)
+	request, err := generateConnectorAwsVpcRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -357,7 +357,7 @@ func resourceConnectorAwsVpcUpdate(ctx context.Context, d *schema.ResourceData,
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorAwsVpc(client)
 
-	request, err := generateConnectorAwsVpcRequest(d, m)
+	request, err := generateConnectorAwsVpcRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -1,10 +1,11 @@
 package alkira
 
 import (
+	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -60,9 +61,9 @@ func setTgwAttachment(d *schema.ResourceData, tgwAttachments []alkira.TgwAttachm
 }
 
 // expandAwsVpcRouteTables expand AWS-VPC route tables
-func expandAwsVpcRouteTables(in *schema.Set) []alkira.RouteTables {
+func expandAwsVpcRouteTables(ctx context.Context, in *schema.Set) []alkira.RouteTables {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty VPC route table input")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty vpc_route_table input")
 		return []alkira.RouteTables{}
 	}
 
@@ -85,14 +86,16 @@ func expandAwsVpcRouteTables(in *schema.Set) []alkira.RouteTables {
 }
 
 // expandUserInputPrefixes generate UserInputPrefixes used in AWS-VPC connector
-func expandUserInputPrefixes(cidr []interface{}, subnets *schema.Set, overlaySubnets []interface{}) ([]alkira.InputPrefixes, error) {
+func expandUserInputPrefixes(ctx context.Context, cidr []interface{}, subnets *schema.Set, overlaySubnets []interface{}) ([]alkira.InputPrefixes, error) {
 
 	if len(cidr) == 0 && subnets == nil {
 		return nil, fmt.Errorf("ERROR: either \"vpc_subnet\" or \"vpc_cidr\" must be specified")
 	}
 
 	// Processing overlay_subnets
-	log.Printf("[DEBUG] Processing overlay_subnets %v", overlaySubnets)
+	tflog.SubsystemDebug(ctx, logSubsystemSchema, "processing overlay_subnets", map[string]interface{}{
+		"overlay_subnets": overlaySubnets,
+	})
 	overlaySubnetList := make([]alkira.InputPrefixes, len(overlaySubnets))
 
 	if len(overlaySubnets) > 0 {
@@ -104,7 +107,9 @@ func expandUserInputPrefixes(cidr []interface{}, subnets *schema.Set, overlaySub
 
 	// Processing vpc_cidr
 	if len(cidr) > 0 {
-		log.Printf("[DEBUG] Processing vpc_cidr %v", cidr)
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "processing vpc_cidr", map[string]interface{}{
+			"vpc_cidr": cidr,
+		})
 		cidrList := make([]alkira.InputPrefixes, len(cidr))
 
 		for i, value := range cidr {
@@ -117,9 +122,9 @@ func expandUserInputPrefixes(cidr []interface{}, subnets *schema.Set, overlaySub
 	}
 
 	// Processing vpc_subnet
-	log.Printf("[DEBUG] Processing vpc_subnet")
+	tflog.SubsystemDebug(ctx, logSubsystemSchema, "processing vpc_subnet")
 	if subnets == nil || subnets.Len() == 0 {
-		log.Printf("[DEBUG] Empty vpc_subnet")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty vpc_subnet")
 		return nil, fmt.Errorf("ERROR: Invalid vpc_subnet")
 	}
 
@@ -167,7 +172,7 @@ func expandAwsVpcTgwAttachments(in []interface{}) []alkira.TgwAttachment {
 }
 
 // generateConnectorAwsVpcRequest generate request for connector_aws_vpc
-func generateConnectorAwsVpcRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorAwsVpc, error) {
+func generateConnectorAwsVpcRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorAwsVpc, error) {
 
 	// Segment
 	segmentName, err := getSegmentNameById(d.Get("segment_id").(string), m)
@@ -176,7 +181,7 @@ func generateConnectorAwsVpcRequest(d *schema.ResourceData, m interface{}) (*alk
 		return nil, err
 	}
 
-	inputPrefixes, err := expandUserInputPrefixes(d.Get("vpc_cidr").([]interface{}), d.Get("vpc_subnet").(*schema.Set), d.Get("overlay_subnets").([]interface{}))
+	inputPrefixes, err := expandUserInputPrefixes(ctx, d.Get("vpc_cidr").([]interface{}), d.Get("vpc_subnet").(*schema.Set), d.Get("overlay_subnets").([]interface{}))
 
 	if err != nil {
 		return nil, err
@@ -187,7 +192,7 @@ func generateConnectorAwsVpcRequest(d *schema.ResourceData, m interface{}) (*alk
 		Prefixes: inputPrefixes,
 	}
 
-	routeTables := expandAwsVpcRouteTables(d.Get("vpc_route_table").(*schema.Set))
+	routeTables := expandAwsVpcRouteTables(ctx, d.Get("vpc_route_table").(*schema.Set))
 	tgwAttachments := expandAwsVpcTgwAttachments(d.Get("tgw_attachment").([]interface{}))
 
 	vpcRouting := alkira.ConnectorAwsVpcRouting{
@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"testing"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
@@ -107,7 +108,7 @@ func TestExpandAwsVpcRouteTables(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := expandAwsVpcRouteTables(tt.input)
+			result := expandAwsVpcRouteTables(context.Background(), tt.input)
 
 			// For sets, order may not be preserved, so check length and content
 			assert.Len(t, result, len(tt.expected))
@@ -229,7 +230,7 @@ func TestExpandUserInputPrefixes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := expandUserInputPrefixes(tt.cidr, tt.subnets, tt.overlaySubnets)
+			result, err := expandUserInputPrefixes(context.Background(), tt.cidr, tt.subnets, tt.overlaySubnets)
 
 			if tt.expectError {
 				assert.Error(t, err)
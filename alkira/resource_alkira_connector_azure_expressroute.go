@@ -293,7 +293,7 @@ func resourceConnectorAzureExpressRouteCreate(ctx context.Context, d *schema.Res
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorAzureExpressRoute(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorAzureExpressRouteRequest(d, m)
+	request, err := generateConnectorAzureExpressRouteRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -417,7 +417,7 @@ func resourceConnectorAzureExpressRouteUpdate(ctx context.Context, d *schema.Res
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorAzureExpressRoute(m.(*alkira.AlkiraClient))
 
-	connector, err := generateConnectorAzureExpressRouteRequest(d, m)
+	connector, err := generateConnectorAzureExpressRouteRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -506,16 +506,16 @@ func resourceConnectorAzureExpressRouteDelete(ctx context.Context, d *schema.Res
 }
 
 // generateConnectorAzureExpressRouteRequest generate a request for Azure ExpressRoute connector
-func generateConnectorAzureExpressRouteRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorAzureExpressRoute, error) {
+func generateConnectorAzureExpressRouteRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorAzureExpressRoute, error) {
 
 	billingTags := convertTypeSetToIntList(d.Get("billing_tag_ids").(*schema.Set))
 
-	instances, err := expandAzureExpressRouteInstances(d.Get("instances").([]interface{}), m)
+	instances, err := expandAzureExpressRouteInstances(ctx, d.Get("instances").([]interface{}), m)
 	if err != nil {
 		return nil, err
 	}
 
-	segmentOptions, err := expandAzureExpressRouteSegments(d.Get("segment_options").([]interface{}), m)
+	segmentOptions, err := expandAzureExpressRouteSegments(ctx, d.Get("segment_options").([]interface{}), m)
 	if err != nil {
 		return nil, err
 	}
@@ -1,13 +1,14 @@
 package alkira
 
 import (
+	"context"
 	"errors"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
-func expandAzureExpressRouteInstances(in []interface{}, m interface{}) ([]alkira.ConnectorAzureExpressRouteInstance, error) {
+func expandAzureExpressRouteInstances(ctx context.Context, in []interface{}, m interface{}) ([]alkira.ConnectorAzureExpressRouteInstance, error) {
 	if in == nil || len(in) == 0 {
 		return nil, errors.New("ERROR: Invalid Azure ExpressRoute instance input")
 	}
@@ -54,7 +55,7 @@ func expandAzureExpressRouteInstances(in []interface{}, m interface{}) ([]alkira
 
 		// Segment Options
 		if v, ok := instanceCfg["ipsec_customer_gateway"].([]interface{}); ok {
-			segmentOptions, err := expandInstanceSegmentOptions(v, m)
+			segmentOptions, err := expandInstanceSegmentOptions(ctx, v, m)
 			if err != nil {
 				return nil, err
 			}
@@ -66,7 +67,7 @@ func expandAzureExpressRouteInstances(in []interface{}, m interface{}) ([]alkira
 
 	return instances, nil
 }
-func expandInstanceSegmentOptions(in []interface{}, m interface{}) ([]alkira.InstanceSegmentOption, error) {
+func expandInstanceSegmentOptions(ctx context.Context, in []interface{}, m interface{}) ([]alkira.InstanceSegmentOption, error) {
 	segmentOptions := make([]alkira.InstanceSegmentOption, 0)
 	for _, segOpt := range in {
 		segOptMap := segOpt.(map[string]interface{})
@@ -75,7 +76,9 @@ func expandInstanceSegmentOptions(in []interface{}, m interface{}) ([]alkira.Ins
 		if v, ok := segOptMap["segment_id"].(string); ok {
 			segmentName, err := getSegmentNameById(v, m)
 			if err != nil {
-				log.Printf("Unable to fetch segment_name for segment_id: %s", v)
+				tflog.SubsystemError(ctx, logSubsystemSchema, "unable to fetch segment_name for segment_id", map[string]interface{}{
+					"segment_id": v,
+				})
 				return nil, err
 			}
 			segmentOption.SegmentName = segmentName
@@ -156,7 +159,7 @@ func expandCustomerGatewayTunnels(in []interface{}) ([]alkira.CustomerGatewayTun
 	return tunnels, nil
 }
 
-func expandAzureExpressRouteSegments(seg []interface{}, m interface{}) ([]alkira.ConnectorAzureExpressRouteSegment, error) {
+func expandAzureExpressRouteSegments(ctx context.Context, seg []interface{}, m interface{}) ([]alkira.ConnectorAzureExpressRouteSegment, error) {
 	if seg == nil || len(seg) == 0 {
 		return nil, errors.New("ERROR: Invalid Azure ExpressRoute segment options input")
 	}
@@ -169,7 +172,9 @@ func expandAzureExpressRouteSegments(seg []interface{}, m interface{}) ([]alkira
 		if v, ok := instanceCfg["segment_id"].(string); ok {
 			segmentName, err := getSegmentNameById(v, m)
 			if err != nil {
-				log.Printf("Unable to fetch segment_name for segment_id: %s", v)
+				tflog.SubsystemError(ctx, logSubsystemSchema, "unable to fetch segment_name for segment_id", map[string]interface{}{
+					"segment_id": v,
+				})
 				return nil, err
 			}
 			r.SegmentName = segmentName
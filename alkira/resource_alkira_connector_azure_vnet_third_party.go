@@ -3,9 +3,9 @@ package alkira
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -106,7 +106,7 @@ func resourceConnectorAzureVnetThirdPartyCreate(ctx context.Context, d *schema.R
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewAzureVnetThirdPartyConnector(client)
 
-	request, err := generateConnectorAzureVnetThirdPartyRequest(d, m)
+	request, err := generateConnectorAzureVnetThirdPartyRequest(ctx, d, m)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -201,7 +201,7 @@ func resourceConnectorAzureVnetThirdPartyUpdate(ctx context.Context, d *schema.R
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewAzureVnetThirdPartyConnector(client)
 
-	request, err := generateConnectorAzureVnetThirdPartyRequest(d, m)
+	request, err := generateConnectorAzureVnetThirdPartyRequest(ctx, d, m)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -284,7 +284,7 @@ func resourceConnectorAzureVnetThirdPartyDelete(ctx context.Context, d *schema.R
 	return nil
 }
 
-func generateConnectorAzureVnetThirdPartyRequest(d *schema.ResourceData, m interface{}) (*alkira.AzureVnetThirdPartyConnector, error) {
+func generateConnectorAzureVnetThirdPartyRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.AzureVnetThirdPartyConnector, error) {
 	segmentName, err := getSegmentNameById(d.Get("segment_id").(string), m)
 	if err != nil {
 		return nil, err
@@ -306,7 +306,10 @@ func generateConnectorAzureVnetThirdPartyRequest(d *schema.ResourceData, m inter
 		StaticRoutes:                             staticRoutes,
 	}
 
-	log.Printf("[DEBUG] Azure VNET Third Party Connector Request: %+v", request)
+	tflog.SubsystemDebug(ctx, logSubsystemSchema, "generated azure vnet third party connector request", map[string]interface{}{
+		"connector_name": request.Name,
+		"segment_name":   segmentName,
+	})
 
 	return request, nil
 }
@@ -3,10 +3,10 @@ package alkira
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -211,7 +211,7 @@ func resourceConnectorCiscoSdwanCreate(ctx context.Context, d *schema.ResourceDa
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorCiscoSdwan(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorCiscoSdwanRequest(d, m)
+	request, err := generateConnectorCiscoSdwanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -321,7 +321,7 @@ func resourceConnectorCiscoSdwanUpdate(ctx context.Context, d *schema.ResourceDa
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorCiscoSdwan(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorCiscoSdwanRequest(d, m)
+	request, err := generateConnectorCiscoSdwanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -409,10 +409,10 @@ func resourceConnectorCiscoSdwanDelete(ctx context.Context, d *schema.ResourceDa
 }
 
 // generateConnectorCiscoSdwanRequest generate request for Cisco SD-WAN connector
-func generateConnectorCiscoSdwanRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorCiscoSdwan, error) {
+func generateConnectorCiscoSdwanRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorCiscoSdwan, error) {
 
 	// Expand Cisco SDWAN vEdge block
-	vedges, err := expandCiscoSdwanVedges(m.(*alkira.AlkiraClient), d.Get("vedge").([]interface{}))
+	vedges, err := expandCiscoSdwanVedges(ctx, m.(*alkira.AlkiraClient), d.Get("vedge").([]interface{}))
 
 	if err != nil {
 		return nil, err
@@ -422,7 +422,7 @@ func generateConnectorCiscoSdwanRequest(d *schema.ResourceData, m interface{}) (
 	connector := &alkira.ConnectorCiscoSdwan{
 		BillingTags:          convertTypeSetToIntList(d.Get("billing_tag_ids").(*schema.Set)),
 		CiscoEdgeInfo:        vedges,
-		CiscoEdgeVrfMappings: expandCiscoSdwanVrfMappings(d.Get("vrf_segment_mapping").(*schema.Set)),
+		CiscoEdgeVrfMappings: expandCiscoSdwanVrfMappings(ctx, d.Get("vrf_segment_mapping").(*schema.Set)),
 		Cxp:                  d.Get("cxp").(string),
 		Group:                d.Get("group").(string),
 		Enabled:              d.Get("enabled").(bool),
@@ -438,10 +438,10 @@ func generateConnectorCiscoSdwanRequest(d *schema.ResourceData, m interface{}) (
 }
 
 // expandCiscoSdwanVrfMappings expand Cisco SD-WAN VRF segment mapping
-func expandCiscoSdwanVrfMappings(in *schema.Set) []alkira.CiscoSdwanEdgeVrfMapping {
+func expandCiscoSdwanVrfMappings(ctx context.Context, in *schema.Set) []alkira.CiscoSdwanEdgeVrfMapping {
 
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty vrf_segment_mapping")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty vrf_segment_mapping")
 		return []alkira.CiscoSdwanEdgeVrfMapping{}
 	}
 
@@ -473,10 +473,10 @@ func expandCiscoSdwanVrfMappings(in *schema.Set) []alkira.CiscoSdwanEdgeVrfMappi
 }
 
 // expandCiscoSdwanVedges expand Cisco SD-WAN Edge
-func expandCiscoSdwanVedges(ac *alkira.AlkiraClient, in []interface{}) ([]alkira.CiscoSdwanEdgeInfo, error) {
+func expandCiscoSdwanVedges(ctx context.Context, ac *alkira.AlkiraClient, in []interface{}) ([]alkira.CiscoSdwanEdgeInfo, error) {
 
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] Empty vedges")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty vedge")
 		return []alkira.CiscoSdwanEdgeInfo{}, nil
 	}
 
@@ -506,7 +506,7 @@ func expandCiscoSdwanVedges(ac *alkira.AlkiraClient, in []interface{}) ([]alkira
 		}
 		if v, ok := t["credential_id"].(string); ok {
 			if v == "" {
-				log.Printf("[INFO] Creating CISCO-SDWAN Credential")
+				tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating cisco sdwan instance credential")
 				credentialName := r.HostName + randomNameSuffix()
 
 				credential := alkira.CredentialCiscoSdwan{
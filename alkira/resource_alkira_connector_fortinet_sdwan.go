@@ -216,7 +216,7 @@ func resourceConnectorFortinetSdwanCreate(ctx context.Context, d *schema.Resourc
 	api := alkira.NewConnectorFortinetSdwan(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateConnectorFortinetSdwanRequest(d, m)
+	request, err := generateConnectorFortinetSdwanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -325,7 +325,7 @@ func resourceConnectorFortinetSdwanUpdate(ctx context.Context, d *schema.Resourc
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorFortinetSdwan(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorFortinetSdwanRequest(d, m)
+	request, err := generateConnectorFortinetSdwanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -412,12 +412,12 @@ func resourceConnectorFortinetSdwanDelete(ctx context.Context, d *schema.Resourc
 }
 
 // generateConnectorFortinetSdwanRequest generate request for FORTINET SD-WAN connector
-func generateConnectorFortinetSdwanRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorFortinetSdwan, error) {
+func generateConnectorFortinetSdwanRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorFortinetSdwan, error) {
 
 	//
 	// Expand wan_edge block
 	//
-	wanEdges, err := expandFortinetSdwanWanEdges(m.(*alkira.AlkiraClient), d.Get("wan_edge").([]interface{}))
+	wanEdges, err := expandFortinetSdwanWanEdges(ctx, m.(*alkira.AlkiraClient), d.Get("wan_edge").([]interface{}))
 
 	if err != nil {
 		return nil, err
@@ -427,7 +427,7 @@ func generateConnectorFortinetSdwanRequest(d *schema.ResourceData, m interface{}
 	connector := &alkira.ConnectorFortinetSdwan{
 		BillingTags:          convertTypeSetToIntList(d.Get("billing_tag_ids").(*schema.Set)),
 		Instances:            wanEdges,
-		FtntSdWanVRFMappings: expandFortinetSdwanVrfMappings(d.Get("target_segment").(*schema.Set)),
+		FtntSdWanVRFMappings: expandFortinetSdwanVrfMappings(ctx, d.Get("target_segment").(*schema.Set)),
 		Cxp:                  d.Get("cxp").(string),
 		Group:                d.Get("group").(string),
 		AllowList:            convertTypeListToStringList(d.Get("allow_list").([]interface{})),
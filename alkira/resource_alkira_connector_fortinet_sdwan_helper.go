@@ -1,9 +1,10 @@
 package alkira
 
 import (
-	"log"
+	"context"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -77,10 +78,10 @@ func setWanEdge(d *schema.ResourceData, connector *alkira.ConnectorFortinetSdwan
 }
 
 // expandFortinetSdwanVrfMappings expand Fortinet SD-WAN VRF segment mapping
-func expandFortinetSdwanVrfMappings(in *schema.Set) []alkira.ConnectorFortinetSdwanVrfMapping {
+func expandFortinetSdwanVrfMappings(ctx context.Context, in *schema.Set) []alkira.ConnectorFortinetSdwanVrfMapping {
 
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty target_segment")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty target_segment")
 		return []alkira.ConnectorFortinetSdwanVrfMapping{}
 	}
 
@@ -112,10 +113,10 @@ func expandFortinetSdwanVrfMappings(in *schema.Set) []alkira.ConnectorFortinetSd
 }
 
 // expandFortinetSdwanWanEedges expand WAN edge instances
-func expandFortinetSdwanWanEdges(ac *alkira.AlkiraClient, in []interface{}) ([]alkira.ConnectorFortinetSdwanInstance, error) {
+func expandFortinetSdwanWanEdges(ctx context.Context, ac *alkira.AlkiraClient, in []interface{}) ([]alkira.ConnectorFortinetSdwanInstance, error) {
 
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] Empty wan_edge")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty wan_edge")
 		return []alkira.ConnectorFortinetSdwanInstance{}, nil
 	}
 
@@ -154,7 +155,7 @@ func expandFortinetSdwanWanEdges(ac *alkira.AlkiraClient, in []interface{}) ([]a
 		}
 		if v, ok := t["credential_id"].(string); ok {
 			if v == "" {
-				log.Printf("[DEBUG] Creating Fortinet SD-WAN instance credential")
+				tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating fortinet sdwan instance credential")
 				credentialName := r.HostName + randomNameSuffix()
 
 				credential := alkira.CredentialFortinetSdwanInstance{
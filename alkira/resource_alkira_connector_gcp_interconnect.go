@@ -225,7 +225,7 @@ func resourceConnectorGcpInterconnectCreate(ctx context.Context, d *schema.Resou
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorGcpInterconnect(m.(*alkira.AlkiraClient))
 
-	request, err := generateGcpInterconnectRequest(d, m)
+	request, err := generateGcpInterconnectRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -302,7 +302,7 @@ func resourceConnectorGcpInterconnectRead(ctx context.Context, d *schema.Resourc
 	d.Set("loopback_prefixes", connector.LoopbackPrefixes)
 	d.Set("enabled", connector.Enabled)
 	d.Set("implicit_group_id", connector.ImplicitGroupId)
-	instances := setGcpInterconnectInstance(connector.Instances, m)
+	instances := setGcpInterconnectInstance(ctx, connector.Instances, m)
 	d.Set("instances", instances)
 
 	// Set provision state
@@ -319,7 +319,7 @@ func resourceConnectorGcpInterconnectUpdate(ctx context.Context, d *schema.Resou
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorGcpInterconnect(m.(*alkira.AlkiraClient))
 
-	request, err := generateGcpInterconnectRequest(d, m)
+	request, err := generateGcpInterconnectRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -1,16 +1,17 @@
 package alkira
 
 import (
+	"context"
 	"errors"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func expandGcpInterconnectCustomerGateways(d []interface{}) ([]alkira.ConnectorGcpInterconnectCustomerGateway, error) {
+func expandGcpInterconnectCustomerGateways(ctx context.Context, d []interface{}) ([]alkira.ConnectorGcpInterconnectCustomerGateway, error) {
 	if d == nil || len(d) == 0 {
-		log.Printf("[ERROR] invalid GCP interconnect customer gateway input")
+		tflog.SubsystemError(ctx, logSubsystemSchema, "invalid gcp interconnect customer_gateways input")
 		return nil, errors.New("[ERROR] invalid GCP interconnect customer gateway input")
 	}
 	customerGateways := make([]alkira.ConnectorGcpInterconnectCustomerGateway, len(d))
@@ -28,9 +29,9 @@ func expandGcpInterconnectCustomerGateways(d []interface{}) ([]alkira.ConnectorG
 	return customerGateways, nil
 }
 
-func expandGcpInterconnectSegmentOptions(d []interface{}, m interface{}) ([]alkira.ConnectorGcpInterconnectSegmentOption, error) {
+func expandGcpInterconnectSegmentOptions(ctx context.Context, d []interface{}, m interface{}) ([]alkira.ConnectorGcpInterconnectSegmentOption, error) {
 	if d == nil || len(d) == 0 {
-		log.Printf("[ERROR] invalid GCP interconnect segment option input")
+		tflog.SubsystemError(ctx, logSubsystemSchema, "invalid gcp interconnect segment_options input")
 		return nil, errors.New("[ERROR] invalid GCP interconnect segment option input")
 	}
 
@@ -54,7 +55,7 @@ func expandGcpInterconnectSegmentOptions(d []interface{}, m interface{}) ([]alki
 		}
 
 		if v, ok := cfgSegmentOption["customer_gateways"].([]interface{}); ok {
-			customerGateways, err := expandGcpInterconnectCustomerGateways(v)
+			customerGateways, err := expandGcpInterconnectCustomerGateways(ctx, v)
 			if err != nil {
 				return nil, err
 			}
@@ -66,9 +67,9 @@ func expandGcpInterconnectSegmentOptions(d []interface{}, m interface{}) ([]alki
 	return segmentOptions, nil
 }
 
-func expandGcpInterconnectInstances(in []interface{}, m interface{}) ([]alkira.ConnectorGcpInterconnectInstance, error) {
+func expandGcpInterconnectInstances(ctx context.Context, in []interface{}, m interface{}) ([]alkira.ConnectorGcpInterconnectInstance, error) {
 	if in == nil || len(in) == 0 {
-		log.Printf("[ERROR] invalid GCP interconnect instance input")
+		tflog.SubsystemError(ctx, logSubsystemSchema, "invalid gcp interconnect instance input")
 		return nil, errors.New("[ERROR] invalid GCP interconnect instance input")
 	}
 
@@ -102,7 +103,7 @@ func expandGcpInterconnectInstances(in []interface{}, m interface{}) ([]alkira.C
 			newInstance.Vni = v
 		}
 		if v, ok := cfgInstance["segment_options"].([]interface{}); ok {
-			segmentOptions, err := expandGcpInterconnectSegmentOptions(v, m)
+			segmentOptions, err := expandGcpInterconnectSegmentOptions(ctx, v, m)
 			if err != nil {
 				return nil, err
 			}
@@ -114,7 +115,7 @@ func expandGcpInterconnectInstances(in []interface{}, m interface{}) ([]alkira.C
 	return instances, nil
 }
 
-func setGcpInterconnectSegmentOptions(instance alkira.ConnectorGcpInterconnectInstance, m interface{}) ([]map[string]interface{}, error) {
+func setGcpInterconnectSegmentOptions(ctx context.Context, instance alkira.ConnectorGcpInterconnectInstance, m interface{}) ([]map[string]interface{}, error) {
 	var segmentOptions []map[string]interface{}
 	sO := instance.SegmentOptions
 
@@ -122,7 +123,9 @@ func setGcpInterconnectSegmentOptions(instance alkira.ConnectorGcpInterconnectIn
 	for _, aSegmentOption := range sO {
 		segmentId, err := getSegmentIdByName(aSegmentOption.SegmentName, m)
 		if err != nil {
-			log.Printf("[ERROR] error getting segment ID for Segment Name %v", aSegmentOption.SegmentName)
+			tflog.SubsystemError(ctx, logSubsystemSchema, "unable to fetch segment_id for segment_name", map[string]interface{}{
+				"segment_name": aSegmentOption.SegmentName,
+			})
 			return nil, err
 		}
 
@@ -147,12 +150,14 @@ func setGcpInterconnectSegmentOptions(instance alkira.ConnectorGcpInterconnectIn
 	return segmentOptions, nil
 }
 
-func setGcpInterconnectInstance(ins []alkira.ConnectorGcpInterconnectInstance, m interface{}) []map[string]interface{} {
+func setGcpInterconnectInstance(ctx context.Context, ins []alkira.ConnectorGcpInterconnectInstance, m interface{}) []map[string]interface{} {
 	var instances []map[string]interface{}
 	for _, in := range ins {
-		instanceSegmentOptions, err := setGcpInterconnectSegmentOptions(in, m)
+		instanceSegmentOptions, err := setGcpInterconnectSegmentOptions(ctx, in, m)
 		if err != nil {
-			log.Printf("[ERROR] error setting segment options")
+			tflog.SubsystemError(ctx, logSubsystemSchema, "unable to set gcp interconnect segment_options", map[string]interface{}{
+				"error": err,
+			})
 			return nil
 		}
 		instance := map[string]interface{}{
@@ -172,8 +177,8 @@ func setGcpInterconnectInstance(ins []alkira.ConnectorGcpInterconnectInstance, m
 
 }
 
-func generateGcpInterconnectRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorGcpInterconnect, error) {
-	instances, err := expandGcpInterconnectInstances(d.Get("instances").([]interface{}), m)
+func generateGcpInterconnectRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorGcpInterconnect, error) {
+	instances, err := expandGcpInterconnectInstances(ctx, d.Get("instances").([]interface{}), m)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +222,7 @@ func resourceConnectorGcpVpcCreate(ctx context.Context, d *schema.ResourceData,
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorGcpVpc(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorGcpVpcRequest(d, m)
+	request, err := generateConnectorGcpVpcRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -332,7 +332,7 @@ func resourceConnectorGcpVpcUpdate(ctx context.Context, d *schema.ResourceData,
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorGcpVpc(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorGcpVpcRequest(d, m)
+	request, err := generateConnectorGcpVpcRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -422,7 +422,7 @@ func resourceConnectorGcpVpcDelete(ctx context.Context, d *schema.ResourceData,
 // validateExportAllSubnets checks that export_all_subnets and vpc_subnet
 // are not contradictory when the user explicitly set export_all_subnets.
 // Values carried from state (computed) are allowed through —
-// expandGcpRouting() auto-corrects them during Create/Update.
+// expandGcpRouting(ctx) auto-corrects them during Create/Update.
 func validateExportAllSubnets(d *schema.ResourceDiff) error {
 	gcpRouting, ok := d.GetOk("gcp_routing")
 	if !ok {
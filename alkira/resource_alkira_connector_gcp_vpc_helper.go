@@ -1,14 +1,15 @@
 package alkira
 
 import (
+	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func expandGcpRouting(in []interface{}, subnets *schema.Set) (*alkira.ConnectorGcpVpcRouting, error) {
+func expandGcpRouting(ctx context.Context, in []interface{}, subnets *schema.Set) (*alkira.ConnectorGcpVpcRouting, error) {
 
 	importOptions := alkira.ConnectorGcpVpcImportOptions{
 		RouteImportMode: "ADVERTISE_DEFAULT_ROUTE",
@@ -32,7 +33,7 @@ func expandGcpRouting(in []interface{}, subnets *schema.Set) (*alkira.ConnectorG
 		}
 	}
 
-	prefixes, err := generateGCPUserInputPrefixes(subnets)
+	prefixes, err := generateGCPUserInputPrefixes(ctx, subnets)
 
 	if err != nil {
 		return nil, err
@@ -61,7 +62,7 @@ func expandGcpRouting(in []interface{}, subnets *schema.Set) (*alkira.ConnectorG
 }
 
 // generateUserInputPrefixes generate UserInputPrefixes used in GCP-VPC connector
-func generateGCPUserInputPrefixes(subnets *schema.Set) ([]alkira.UserInputPrefixes, error) {
+func generateGCPUserInputPrefixes(ctx context.Context, subnets *schema.Set) ([]alkira.UserInputPrefixes, error) {
 
 	if subnets != nil && subnets.Len() > 0 {
 
@@ -77,7 +78,7 @@ func generateGCPUserInputPrefixes(subnets *schema.Set) ([]alkira.UserInputPrefix
 			}
 
 			if internalId == "" && (t["id"] == "" || t["cidr"] == "") {
-				log.Printf("[ERROR] subnet configuration must have either internal_id or both id and cidr")
+				tflog.SubsystemError(ctx, logSubsystemSchema, "subnet configuration must have either internal_id or both id and cidr")
 				return nil, fmt.Errorf("[ERROR] subnet configuration must have either internal_id or both id and cidr")
 			}
 
@@ -140,15 +141,17 @@ func setGcpVpcSubnets(c *alkira.ConnectorGcpVpcRouting, d *schema.ResourceData)
 	d.Set("vpc_subnet", subnets)
 }
 
-func generateConnectorGcpVpcRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorGcpVpc, error) {
+func generateConnectorGcpVpcRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorGcpVpc, error) {
 
 	//
 	// Routing
 	//
-	gcpRouting, err := expandGcpRouting(d.Get("gcp_routing").([]interface{}), d.Get("vpc_subnet").(*schema.Set))
+	gcpRouting, err := expandGcpRouting(ctx, d.Get("gcp_routing").([]interface{}), d.Get("vpc_subnet").(*schema.Set))
 
 	if err != nil {
-		log.Printf("[ERROR] failed to convert gcp routing")
+		tflog.SubsystemError(ctx, logSubsystemSchema, "failed to convert gcp_routing", map[string]interface{}{
+			"error": err,
+		})
 		return nil, err
 	}
 
@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -206,7 +207,7 @@ func TestGenerateGCPUserInputPrefixes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := generateGCPUserInputPrefixes(tt.subnets)
+			result, err := generateGCPUserInputPrefixes(context.Background(), tt.subnets)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -760,7 +761,7 @@ func TestExpandGcpRouting(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := expandGcpRouting(tt.gcpRouting, tt.subnets)
+			result, err := expandGcpRouting(context.Background(), tt.gcpRouting, tt.subnets)
 
 			if tt.expectError {
 				assert.Error(t, err)
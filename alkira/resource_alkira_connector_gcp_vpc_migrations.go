@@ -2,8 +2,9 @@ package alkira
 
 import (
 	"context"
-	"log"
+	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -142,8 +143,8 @@ func resourceConnectorGcpVpcV0() *schema.Resource {
 // (TypeList for prefix_list_ids) to V1 (TypeSet). The flat-map
 // state keys change from positional indices (e.g. "gcp_routing.0.prefix_list_ids.0")
 // to hash-based keys (e.g. "gcp_routing.0.prefix_list_ids.<hash>").
-func resourceConnectorGcpVpcStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
-	log.Printf("[DEBUG] Starting ConnectorGcpVpc state migration from V0 to V1 (TypeList -> TypeSet for prefix_list_ids)")
+func resourceConnectorGcpVpcStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	tflog.Debug(ctx, "Starting ConnectorGcpVpc state migration from V0 to V1 (TypeList -> TypeSet for prefix_list_ids)")
 
 	// Migrate "gcp_routing.0.prefix_list_ids" from list to set
 	if v, ok := rawState["gcp_routing"]; ok {
@@ -152,7 +153,9 @@ func resourceConnectorGcpVpcStateUpgradeV0(_ context.Context, rawState map[strin
 				if v, ok := routing["prefix_list_ids"]; ok {
 					if prefixListIdsList, ok := v.([]interface{}); ok {
 						oldCount := len(prefixListIdsList)
-						log.Printf("[DEBUG] Migrating 'gcp_routing.0.prefix_list_ids' field: %d entries from TypeList to TypeSet", oldCount)
+						tflog.Debug(ctx, "migrating gcp_routing.0.prefix_list_ids from TypeList to TypeSet", map[string]interface{}{
+							"count": oldCount,
+						})
 
 						// State upgraders must return plain JSON-serializable types.
 						// The SDK handles TypeSet conversion from []interface{}.
@@ -165,26 +168,36 @@ func resourceConnectorGcpVpcStateUpgradeV0(_ context.Context, rawState map[strin
 							case int:
 								migrated = append(migrated, v)
 							default:
-								log.Printf("[WARN] Unexpected type for prefix_list_ids[%d]: %T, skipping", i, item)
+								tflog.Warn(ctx, "skipping prefix_list_ids entry of unexpected type", map[string]interface{}{
+									"index": i,
+									"type":  fmt.Sprintf("%T", item),
+								})
 								continue
 							}
-							log.Printf("[DEBUG] Migrated prefix_list_ids[%d]: %v", i, item)
+							tflog.Debug(ctx, "migrated prefix_list_ids entry", map[string]interface{}{
+								"index": i,
+								"value": item,
+							})
 						}
 						routing["prefix_list_ids"] = migrated
-						log.Printf("[DEBUG] 'gcp_routing.0.prefix_list_ids' migration complete: %d entries migrated", oldCount)
+						tflog.Debug(ctx, "migrated gcp_routing.0.prefix_list_ids", map[string]interface{}{
+							"count": len(migrated),
+						})
 					} else {
-						log.Printf("[DEBUG] 'gcp_routing.0.prefix_list_ids' field exists but is not a TypeList, skipping migration (type=%T)", v)
+						tflog.Debug(ctx, "gcp_routing.0.prefix_list_ids is not a TypeList, skipping migration", map[string]interface{}{
+							"type": fmt.Sprintf("%T", v),
+						})
 					}
 				} else {
-					log.Printf("[DEBUG] 'gcp_routing.0.prefix_list_ids' field not present in state, skipping migration")
+					tflog.Debug(ctx, "'gcp_routing.0.prefix_list_ids' field not present in state, skipping migration")
 				}
 				rawState["gcp_routing"] = []interface{}{routing}
 			}
 		}
 	} else {
-		log.Printf("[DEBUG] 'gcp_routing' field not present in state, skipping migration")
+		tflog.Debug(ctx, "'gcp_routing' field not present in state, skipping migration")
 	}
 
-	log.Printf("[INFO] ConnectorGcpVpc state migration from V0 to V1 completed successfully")
+	tflog.Info(ctx, "ConnectorGcpVpc state migration from V0 to V1 completed successfully")
 	return rawState, nil
 }
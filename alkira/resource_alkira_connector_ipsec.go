@@ -27,7 +27,7 @@ func resourceAlkiraConnectorIPSec() *schema.Resource {
 				d.SetNew("provision_state", "SUCCESS")
 			}
 
-			return nil
+			return validateConnectorIPSecPresharedKeys(d.GetRawConfig())
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: importWithReadValidation(resourceConnectorIPSecRead),
@@ -90,13 +90,34 @@ func resourceAlkiraConnectorIPSec() *schema.Resource {
 						},
 						"preshared_keys": {
 							Description: "An array of preshared keys, one per " +
-								"tunnel. The value needs to be provided explicitly.",
+								"tunnel. The value needs to be provided " +
+								"explicitly. Exactly one of `preshared_keys` " +
+								"or `preshared_keys_wo` must be set.",
 							Type: schema.TypeList,
 							Elem: &schema.Schema{
 								Type:         schema.TypeString,
 								ValidateFunc: validation.StringIsNotWhiteSpace,
 							},
-							Required: true,
+							Optional: true,
+						},
+						"preshared_keys_wo": {
+							Description: "The preshared keys, one per tunnel, " +
+								"as a write-only argument holding a JSON " +
+								"array, e.g. `jsonencode([ephemeral." +
+								"alkira_random_psk.tunnel1.result, ephemeral." +
+								"alkira_random_psk.tunnel2.result])`, which is " +
+								"never stored in plan or state. Requires " +
+								"Terraform 1.11 or later.",
+							Type:      schema.TypeString,
+							Optional:  true,
+							WriteOnly: true,
+							Sensitive: true,
+						},
+						"preshared_keys_wo_version": {
+							Description: "Change this value to update the " +
+								"endpoint with a new `preshared_keys_wo`.",
+							Type:     schema.TypeInt,
+							Optional: true,
 						},
 						"enable_tunnel_redundancy": {
 							Description: "Disable this if all tunnels will not " +
@@ -387,7 +408,7 @@ func resourceConnectorIPSecCreate(ctx context.Context, d *schema.ResourceData, m
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorIPSec(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorIPSecRequest(d, m)
+	request, err := generateConnectorIPSecRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -522,7 +543,8 @@ func resourceConnectorIPSecRead(ctx context.Context, d *schema.ResourceData, m i
 				if keys, ok := endpointConfig["preshared_keys"].([]interface{}); ok {
 					configuredKeyCount = len(keys)
 				}
-				endpoint := setConnectorIPSecEndpoint(site, configuredKeyCount)
+				endpoint := setConnectorIPSecEndpoint(ctx, site, configuredKeyCount)
+				keepConnectorIPSecWriteOnly(endpointConfig, endpoint)
 				endpoints = append(endpoints, endpoint)
 				break
 			}
@@ -551,7 +573,7 @@ func resourceConnectorIPSecRead(ctx context.Context, d *schema.ResourceData, m i
 		// this will generate a diff
 		if new {
 			// New endpoint not in config, pass 0 to disable deduplication
-			endpoint := setConnectorIPSecEndpoint(site, 0)
+			endpoint := setConnectorIPSecEndpoint(ctx, site, 0)
 			endpoints = append(endpoints, endpoint)
 			break
 		}
@@ -573,7 +595,7 @@ func resourceConnectorIPSecUpdate(ctx context.Context, d *schema.ResourceData, m
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorIPSec(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorIPSecRequest(d, m)
+	request, err := generateConnectorIPSecRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -660,9 +682,13 @@ func resourceConnectorIPSecDelete(ctx context.Context, d *schema.ResourceData, m
 }
 
 // generateConnectorIPSecRequest generate request for connector-ipsec
-func generateConnectorIPSecRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorIPSec, error) {
+func generateConnectorIPSecRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorIPSec, error) {
+
+	sites := expandConnectorIPSecEndpoint(ctx, d.Get("endpoint").([]interface{}))
 
-	sites := expandConnectorIPSecEndpoint(d.Get("endpoint").([]interface{}))
+	if err := expandConnectorIPSecPresharedKeys(d, sites); err != nil {
+		return nil, err
+	}
 
 	//
 	// Segment
@@ -676,7 +702,7 @@ func generateConnectorIPSecRequest(d *schema.ResourceData, m interface{}) (*alki
 	//
 	// Construct segment options
 	//
-	segmentOptions, optErr := expandConnectorIPSecSegmentOptions(d.Get("segment_options").(*schema.Set))
+	segmentOptions, optErr := expandConnectorIPSecSegmentOptions(ctx, d.Get("segment_options").(*schema.Set))
 
 	if optErr != nil {
 		return nil, optErr
@@ -695,7 +721,7 @@ func generateConnectorIPSecRequest(d *schema.ResourceData, m interface{}) (*alki
 	switch vpnMode := d.Get("vpn_mode").(string); vpnMode {
 	case "ROUTE_BASED":
 		{
-			routingOptions, err = expandConnectorIPSecRoutingOptions(d.Get("routing_options").(*schema.Set))
+			routingOptions, err = expandConnectorIPSecRoutingOptions(ctx, d.Get("routing_options").(*schema.Set))
 
 			if err != nil {
 				return nil, err
@@ -703,7 +729,7 @@ func generateConnectorIPSecRequest(d *schema.ResourceData, m interface{}) (*alki
 		}
 	case "POLICY_BASED":
 		{
-			policyOptions, err = expandConnectorIPSecPolicyOptions(d.Get("policy_options").(*schema.Set))
+			policyOptions, err = expandConnectorIPSecPolicyOptions(ctx, d.Get("policy_options").(*schema.Set))
 
 			if err != nil {
 				return nil, err
@@ -27,7 +27,7 @@ func resourceAlkiraConnectorIPSecAdv() *schema.Resource {
 				d.SetNew("provision_state", "SUCCESS")
 			}
 
-			return nil
+			return validateConnectorAdvIPSecPresharedKeys(d.GetRawConfig())
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: importWithReadValidation(resourceConnectorIPSecAdvRead),
@@ -188,10 +188,31 @@ func resourceAlkiraConnectorIPSecAdv() *schema.Resource {
 									},
 									"preshared_key": {
 										Description: "The pre-shared key of the " +
-											"tunnel.",
+											"tunnel. Exactly one of `preshared_key` " +
+											"or `preshared_key_wo` must be set.",
 										Type:         schema.TypeString,
 										ValidateFunc: validation.StringIsNotWhiteSpace,
-										Required:     true,
+										Optional:     true,
+									},
+									"preshared_key_wo": {
+										Description: "The pre-shared key of the " +
+											"tunnel as a write-only argument, " +
+											"which is never stored in plan or " +
+											"state, e.g. from the " +
+											"`alkira_random_psk` ephemeral " +
+											"resource. Requires Terraform 1.11 " +
+											"or later.",
+										Type:      schema.TypeString,
+										Optional:  true,
+										WriteOnly: true,
+										Sensitive: true,
+									},
+									"preshared_key_wo_version": {
+										Description: "Change this value to update " +
+											"the tunnel with a new " +
+											"`preshared_key_wo`.",
+										Type:     schema.TypeInt,
+										Optional: true,
 									},
 									"profile_id": {
 										Description: "The ID of the IPSec Tunnel " +
@@ -361,7 +382,7 @@ func resourceConnectorIPSecAdvCreate(ctx context.Context, d *schema.ResourceData
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorAdvIPSec(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorIPSecAdvRequest(d, m)
+	request, err := generateConnectorIPSecAdvRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -427,7 +448,7 @@ func resourceConnectorIPSecAdvRead(ctx context.Context, d *schema.ResourceData,
 	}
 
 	// READ and SET
-	err = setConnectorAdvIPSec(connector, d, m)
+	err = setConnectorAdvIPSec(ctx, connector, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -447,7 +468,7 @@ func resourceConnectorIPSecAdvUpdate(ctx context.Context, d *schema.ResourceData
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorAdvIPSec(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorIPSecAdvRequest(d, m)
+	request, err := generateConnectorIPSecAdvRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -1,23 +1,25 @@
 package alkira
 
 import (
+	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 // expandConnectorAdvIPSecAdvancedOptions
-func expandConnectorAdvIPSecAdvancedOptions(in []interface{}) (*alkira.ConnectorAdvIPSecAdvanced, error) {
+func expandConnectorAdvIPSecAdvancedOptions(ctx context.Context, in []interface{}) (*alkira.ConnectorAdvIPSecAdvanced, error) {
 
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] empty connector-ipsec-adv advanced options")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty connector-ipsec-adv advanced options")
 		return nil, nil
 	}
 
 	if len(in) > 1 {
-		log.Printf("[DEBUG] invalid connector-ipsec-adv endpoint advanced")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid connector-ipsec-adv endpoint advanced")
 		return nil, nil
 	}
 
@@ -44,9 +46,9 @@ func expandConnectorAdvIPSecAdvancedOptions(in []interface{}) (*alkira.Connector
 }
 
 // expandConnectorAdvIPSecTunnel expand IPSec gateway tunnels
-func expandConnectorAdvIPSecTunnel(in []interface{}) []*alkira.ConnectorAdvIPSecTunnel {
+func expandConnectorAdvIPSecTunnel(ctx context.Context, in []interface{}) []*alkira.ConnectorAdvIPSecTunnel {
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] empty IPSec gateway tunnel")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty ipsec gateway tunnel")
 		return nil
 	}
 
@@ -67,10 +69,12 @@ func expandConnectorAdvIPSecTunnel(in []interface{}) []*alkira.ConnectorAdvIPSec
 		if v, ok := config["advanced_options"].([]interface{}); ok {
 
 			var err error
-			r.Advanced, err = expandConnectorAdvIPSecAdvancedOptions(v)
+			r.Advanced, err = expandConnectorAdvIPSecAdvancedOptions(ctx, v)
 
 			if err != nil {
-				log.Printf("[ERROR] failed to parse advanced options.")
+				tflog.SubsystemError(ctx, logSubsystemSchema, "failed to parse advanced options", map[string]interface{}{
+					"error": err,
+				})
 				break
 			}
 		}
@@ -81,9 +85,9 @@ func expandConnectorAdvIPSecTunnel(in []interface{}) []*alkira.ConnectorAdvIPSec
 }
 
 // expandConnectorAdvIPSecGateway expand "gateway" block
-func expandConnectorAdvIPSecGateway(in []interface{}) []*alkira.ConnectorAdvIPSecGateway {
+func expandConnectorAdvIPSecGateway(ctx context.Context, in []interface{}) []*alkira.ConnectorAdvIPSecGateway {
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] empty IPSec gateway input")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty ipsec gateway input")
 		return nil
 	}
 
@@ -100,7 +104,7 @@ func expandConnectorAdvIPSecGateway(in []interface{}) []*alkira.ConnectorAdvIPSe
 
 		if v, ok := gwConfig["tunnel"].([]interface{}); ok {
 
-			r.Tunnels = expandConnectorAdvIPSecTunnel(v)
+			r.Tunnels = expandConnectorAdvIPSecTunnel(ctx, v)
 
 		}
 
@@ -109,10 +113,50 @@ func expandConnectorAdvIPSecGateway(in []interface{}) []*alkira.ConnectorAdvIPSe
 	return gws
 }
 
+// expandConnectorAdvIPSecPresharedKeys sets the pre-shared keys given
+// as write-only arguments, which are only in the raw configuration.
+func expandConnectorAdvIPSecPresharedKeys(d *schema.ResourceData, gateways []*alkira.ConnectorAdvIPSecGateway) error {
+	for i, gw := range gateways {
+		for j, t := range gw.Tunnels {
+			key, err := getWriteOnlyString(d, cty.GetAttrPath("gateway").IndexInt(i).
+				GetAttr("tunnel").IndexInt(j).GetAttr("preshared_key_wo"))
+			if err != nil {
+				return err
+			}
+
+			if key != "" {
+				t.PresharedKey = key
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateConnectorAdvIPSecPresharedKeys checks on the raw
+// configuration that every tunnel has exactly one of preshared_key and
+// preshared_key_wo, which ExactlyOneOf can't express for attributes of
+// nested blocks.
+func validateConnectorAdvIPSecPresharedKeys(config cty.Value) error {
+	for i, gw := range rawConfigBlocks(config, "gateway") {
+		for j, t := range rawConfigBlocks(gw, "tunnel") {
+			key := rawConfigIsSet(t, "preshared_key")
+			keyWo := rawConfigIsSet(t, "preshared_key_wo")
+
+			if key == keyWo {
+				return fmt.Errorf("gateway %d, tunnel %d: exactly one of "+
+					"preshared_key or preshared_key_wo must be set", i, j)
+			}
+		}
+	}
+
+	return nil
+}
+
 // expandConnectorAdvIPSecPolicyOptions expand "policy_options" block
-func expandConnectorAdvIPSecPolicyOptions(in *schema.Set) (*alkira.ConnectorAdvIPSecPolicyOptions, error) {
+func expandConnectorAdvIPSecPolicyOptions(ctx context.Context, in *schema.Set) (*alkira.ConnectorAdvIPSecPolicyOptions, error) {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty policy options of IPSec connector.")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty policy_options of ipsec connector")
 		return &alkira.ConnectorAdvIPSecPolicyOptions{}, nil
 	}
 
@@ -133,9 +177,9 @@ func expandConnectorAdvIPSecPolicyOptions(in *schema.Set) (*alkira.ConnectorAdvI
 }
 
 // expandConnectorAdvIPSecRoutingOptions expand "routing_options" block
-func expandConnectorAdvIPSecRoutingOptions(in *schema.Set) (*alkira.ConnectorAdvIPSecRoutingOptions, error) {
+func expandConnectorAdvIPSecRoutingOptions(ctx context.Context, in *schema.Set) (*alkira.ConnectorAdvIPSecRoutingOptions, error) {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty routing options of IPSec connector.")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty routing_options of ipsec connector")
 		return &alkira.ConnectorAdvIPSecRoutingOptions{}, nil
 	}
 
@@ -236,9 +280,13 @@ func expandConnectorAdvIPSecRoutingOptions(in *schema.Set) (*alkira.ConnectorAdv
 }
 
 // generateConnectorIPSecAdvRequest generate request for connector-ipsec
-func generateConnectorIPSecAdvRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorAdvIPSec, error) {
+func generateConnectorIPSecAdvRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorAdvIPSec, error) {
+
+	gateways := expandConnectorAdvIPSecGateway(ctx, d.Get("gateway").([]interface{}))
 
-	gateways := expandConnectorAdvIPSecGateway(d.Get("gateway").([]interface{}))
+	if err := expandConnectorAdvIPSecPresharedKeys(d, gateways); err != nil {
+		return nil, err
+	}
 
 	//
 	// Segment
@@ -262,7 +310,7 @@ func generateConnectorIPSecAdvRequest(d *schema.ResourceData, m interface{}) (*a
 	switch vpnMode := d.Get("vpn_mode").(string); vpnMode {
 	case "ROUTE_BASED":
 		{
-			routingOptions, err = expandConnectorAdvIPSecRoutingOptions(
+			routingOptions, err = expandConnectorAdvIPSecRoutingOptions(ctx,
 				d.Get("routing_options").(*schema.Set))
 
 			if err != nil {
@@ -271,7 +319,7 @@ func generateConnectorIPSecAdvRequest(d *schema.ResourceData, m interface{}) (*a
 		}
 	case "POLICY_BASED":
 		{
-			policyOptions, err = expandConnectorAdvIPSecPolicyOptions(
+			policyOptions, err = expandConnectorAdvIPSecPolicyOptions(ctx,
 				d.Get("policy_options").(*schema.Set))
 
 			if err != nil {
@@ -304,10 +352,10 @@ func generateConnectorIPSecAdvRequest(d *schema.ResourceData, m interface{}) (*a
 }
 
 // setConnectorAdvIPSecPolicyOptions
-func setConnectorAdvIPSecPolicyOptions(policyOptions *alkira.ConnectorAdvIPSecPolicyOptions, d *schema.ResourceData) {
+func setConnectorAdvIPSecPolicyOptions(ctx context.Context, policyOptions *alkira.ConnectorAdvIPSecPolicyOptions, d *schema.ResourceData) {
 
 	if policyOptions == nil {
-		log.Printf("[DEBUG] empty policy options")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty policy_options")
 		return
 	}
 
@@ -323,10 +371,10 @@ func setConnectorAdvIPSecPolicyOptions(policyOptions *alkira.ConnectorAdvIPSecPo
 }
 
 // setConnectorAdvIPSecRoutingOptions
-func setConnectorAdvIPSecRoutingOptions(routingOptions *alkira.ConnectorAdvIPSecRoutingOptions, d *schema.ResourceData) {
+func setConnectorAdvIPSecRoutingOptions(ctx context.Context, routingOptions *alkira.ConnectorAdvIPSecRoutingOptions, d *schema.ResourceData) {
 
 	if routingOptions == nil {
-		log.Printf("[DEBUG] empty routing options")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty routing_options")
 		return
 	}
 
@@ -334,7 +382,9 @@ func setConnectorAdvIPSecRoutingOptions(routingOptions *alkira.ConnectorAdvIPSec
 
 	// If the routing type is "BOTH"
 	if routingOptions.StaticRouting != nil && routingOptions.DynamicRouting != nil {
-		log.Printf("[DEBUG] routing_type is BOTH")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "setting routing_options", map[string]interface{}{
+			"routing_type": "BOTH",
+		})
 
 		option := map[string]interface{}{
 			"type":                 "BOTH",
@@ -344,7 +394,9 @@ func setConnectorAdvIPSecRoutingOptions(routingOptions *alkira.ConnectorAdvIPSec
 		}
 		options = append(options, option)
 	} else if routingOptions.DynamicRouting == nil {
-		log.Printf("[DEBUG] routing_type is STATIC")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "setting routing_options", map[string]interface{}{
+			"routing_type": "STATIC",
+		})
 
 		option := map[string]interface{}{
 			"type":           "STATIC",
@@ -353,7 +405,9 @@ func setConnectorAdvIPSecRoutingOptions(routingOptions *alkira.ConnectorAdvIPSec
 		}
 		options = append(options, option)
 	} else if routingOptions.StaticRouting == nil {
-		log.Printf("[DEBUG] routing_type is DYNAMIC")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "setting routing_options", map[string]interface{}{
+			"routing_type": "DYNAMIC",
+		})
 
 		option := map[string]interface{}{
 			"type":                 "DYNAMIC",
@@ -363,7 +417,7 @@ func setConnectorAdvIPSecRoutingOptions(routingOptions *alkira.ConnectorAdvIPSec
 		}
 		options = append(options, option)
 	} else {
-		log.Printf("[DEBUG] no routing options")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "no routing_options")
 		return
 	}
 
@@ -371,10 +425,10 @@ func setConnectorAdvIPSecRoutingOptions(routingOptions *alkira.ConnectorAdvIPSec
 }
 
 // deflateConnectorAdvIPSecTunnel
-func deflateConnectorAdvIPSecTunnel(tunnelConfig *alkira.ConnectorAdvIPSecTunnel) map[string]interface{} {
+func deflateConnectorAdvIPSecTunnel(ctx context.Context, tunnelConfig *alkira.ConnectorAdvIPSecTunnel) map[string]interface{} {
 
 	if tunnelConfig == nil {
-		log.Printf("[ERROR] invalid IPSec tunnel")
+		tflog.SubsystemError(ctx, logSubsystemSchema, "invalid ipsec tunnel")
 		return nil
 	}
 
@@ -417,16 +471,16 @@ func deflateConnectorAdvIPSecTunnel(tunnelConfig *alkira.ConnectorAdvIPSecTunnel
 }
 
 // deflateConnectorAdvIPSecGatewayInstance
-func deflateConnectorAdvIPSecGatewayInstance(gatewayConfig *alkira.ConnectorAdvIPSecGateway) map[string]interface{} {
+func deflateConnectorAdvIPSecGatewayInstance(ctx context.Context, gatewayConfig *alkira.ConnectorAdvIPSecGateway) map[string]interface{} {
 	if gatewayConfig == nil {
-		log.Printf("[ERROR] invalid IPSec gateway")
+		tflog.SubsystemError(ctx, logSubsystemSchema, "invalid ipsec gateway")
 		return nil
 	}
 
 	tunnels := make([]interface{}, len(gatewayConfig.Tunnels))
 
 	for i, t := range gatewayConfig.Tunnels {
-		config := deflateConnectorAdvIPSecTunnel(t)
+		config := deflateConnectorAdvIPSecTunnel(ctx, t)
 		tunnels[i] = config
 	}
 
@@ -442,20 +496,48 @@ func deflateConnectorAdvIPSecGatewayInstance(gatewayConfig *alkira.ConnectorAdvI
 }
 
 // deflateConnectorAdvIPSecGateway
-func deflateConnectorAdvIPSecGateway(connector *alkira.ConnectorAdvIPSec) []interface{} {
+func deflateConnectorAdvIPSecGateway(ctx context.Context, connector *alkira.ConnectorAdvIPSec) []interface{} {
 
 	gateways := make([]interface{}, len(connector.Gateways))
 
 	for i, gw := range connector.Gateways {
-		gateway := deflateConnectorAdvIPSecGatewayInstance(gw)
+		gateway := deflateConnectorAdvIPSecGatewayInstance(ctx, gw)
 		gateways[i] = gateway
 	}
 
 	return gateways
 }
 
+// keepConnectorAdvIPSecWriteOnly keeps the pre-shared keys of tunnels
+// using preshared_key_wo out of the state, and their versions in it.
+func keepConnectorAdvIPSecWriteOnly(d *schema.ResourceData, gateways []interface{}) {
+	for i, gw := range gateways {
+		gateway, ok := gw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		tunnels, _ := gateway["tunnel"].([]interface{})
+		known := d.Get(fmt.Sprintf("gateway.%d.tunnel.#", i)).(int)
+
+		for j, t := range tunnels {
+			tunnel, ok := t.(map[string]interface{})
+			if !ok || j >= known {
+				continue
+			}
+
+			prefix := fmt.Sprintf("gateway.%d.tunnel.%d.", i, j)
+
+			tunnel["preshared_key_wo_version"] = d.Get(prefix + "preshared_key_wo_version")
+			if d.Get(prefix+"preshared_key").(string) == "" {
+				tunnel["preshared_key"] = ""
+			}
+		}
+	}
+}
+
 // setConnectorAdvIPSec
-func setConnectorAdvIPSec(connector *alkira.ConnectorAdvIPSec, d *schema.ResourceData, m interface{}) error {
+func setConnectorAdvIPSec(ctx context.Context, connector *alkira.ConnectorAdvIPSec, d *schema.ResourceData, m interface{}) error {
 
 	d.Set("advertise_default_route", connector.AdvertiseDefaultRoute)
 	d.Set("advertise_on_prem_routes", connector.AdvertiseOnPremRoutes)
@@ -472,13 +554,15 @@ func setConnectorAdvIPSec(connector *alkira.ConnectorAdvIPSec, d *schema.Resourc
 	d.Set("description", connector.Description)
 
 	// gateway block
-	d.Set("gateway", deflateConnectorAdvIPSecGateway(connector))
+	gateways := deflateConnectorAdvIPSecGateway(ctx, connector)
+	keepConnectorAdvIPSecWriteOnly(d, gateways)
+	d.Set("gateway", gateways)
 
 	// policy_options block
-	setConnectorAdvIPSecPolicyOptions(connector.PolicyOptions, d)
+	setConnectorAdvIPSecPolicyOptions(ctx, connector.PolicyOptions, d)
 
 	// routing_options block
-	setConnectorAdvIPSecRoutingOptions(connector.RoutingOptions, d)
+	setConnectorAdvIPSecRoutingOptions(ctx, connector.RoutingOptions, d)
 
 	// segment
 	segmentId, err := getSegmentIdByName(connector.Segment, m)
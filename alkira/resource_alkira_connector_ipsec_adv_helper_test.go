@@ -1,11 +1,14 @@
 package alkira
 
 import (
+	"context"
 	"testing"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExpandConnectorAdvIPSecAdvancedOptions(t *testing.T) {
@@ -72,7 +75,7 @@ func TestExpandConnectorAdvIPSecAdvancedOptions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := expandConnectorAdvIPSecAdvancedOptions(tt.input)
+			result, err := expandConnectorAdvIPSecAdvancedOptions(context.Background(), tt.input)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -155,7 +158,7 @@ func TestExpandConnectorAdvIPSecTunnel(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := expandConnectorAdvIPSecTunnel(tt.input)
+			result := expandConnectorAdvIPSecTunnel(context.Background(), tt.input)
 
 			if tt.expectedCount == 0 {
 				assert.Nil(t, result)
@@ -224,7 +227,7 @@ func TestExpandConnectorAdvIPSecGateway(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := expandConnectorAdvIPSecGateway(tt.input)
+			result := expandConnectorAdvIPSecGateway(context.Background(), tt.input)
 
 			if tt.expectedCount == 0 {
 				assert.Nil(t, result)
@@ -290,7 +293,7 @@ func TestExpandConnectorAdvIPSecPolicyOptions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := expandConnectorAdvIPSecPolicyOptions(tt.input)
+			result, err := expandConnectorAdvIPSecPolicyOptions(context.Background(), tt.input)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -411,7 +414,7 @@ func TestExpandConnectorAdvIPSecRoutingOptions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := expandConnectorAdvIPSecRoutingOptions(tt.input)
+			result, err := expandConnectorAdvIPSecRoutingOptions(context.Background(), tt.input)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -483,7 +486,7 @@ func TestDeflateConnectorAdvIPSecTunnel(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := deflateConnectorAdvIPSecTunnel(tt.input)
+			result := deflateConnectorAdvIPSecTunnel(context.Background(), tt.input)
 
 			if tt.expectNil {
 				assert.Nil(t, result)
@@ -515,7 +518,7 @@ func TestInputValidation(t *testing.T) {
 			},
 		}
 
-		result, err := expandConnectorAdvIPSecAdvancedOptions(validInput)
+		result, err := expandConnectorAdvIPSecAdvancedOptions(context.Background(), validInput)
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Equal(t, "2", result.IkeVersion)
@@ -536,8 +539,95 @@ func TestInputValidation(t *testing.T) {
 			},
 		}
 
-		result := expandConnectorAdvIPSecTunnel(tunnelInput)
+		result := expandConnectorAdvIPSecTunnel(context.Background(), tunnelInput)
 		assert.Len(t, result, 1)
 		assert.Nil(t, result[0].Advanced)
 	})
 }
+
+func TestExpandConnectorAdvIPSecPresharedKeys(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceAlkiraConnectorIPSecAdv().Schema, map[string]interface{}{})
+
+	gateways := []*alkira.ConnectorAdvIPSecGateway{{
+		Tunnels: []*alkira.ConnectorAdvIPSecTunnel{{PresharedKey: "secret"}},
+	}}
+	assert.NoError(t, expandConnectorAdvIPSecPresharedKeys(d, gateways))
+	assert.Equal(t, "secret", gateways[0].Tunnels[0].PresharedKey)
+}
+
+func TestValidateConnectorAdvIPSecPresharedKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		tunnel  string
+		wantErr string
+	}{
+		{
+			name:   "preshared_key only",
+			tunnel: `{"preshared_key": "secret"}`,
+		},
+		{
+			name:   "preshared_key_wo only",
+			tunnel: `{"preshared_key_wo": "secret"}`,
+		},
+		{
+			name:    "neither",
+			tunnel:  `{}`,
+			wantErr: "gateway 0, tunnel 0: exactly one of preshared_key or preshared_key_wo must be set",
+		},
+		{
+			name:    "both",
+			tunnel:  `{"preshared_key": "secret", "preshared_key_wo": "secret"}`,
+			wantErr: "gateway 0, tunnel 0: exactly one of preshared_key or preshared_key_wo must be set",
+		},
+	}
+
+	ty := resourceAlkiraConnectorIPSecAdv().CoreConfigSchema().ImpliedType()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ctyjson.Unmarshal([]byte(`{"gateway": [{"tunnel": [`+tt.tunnel+`]}]}`), ty)
+			require.NoError(t, err)
+
+			err = validateConnectorAdvIPSecPresharedKeys(config)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeepConnectorAdvIPSecWriteOnly(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceAlkiraConnectorIPSecAdv().Schema, map[string]interface{}{
+		"gateway": []interface{}{
+			map[string]interface{}{
+				"name":                "gw1",
+				"customer_gateway_ip": "1.1.1.1",
+				"tunnel": []interface{}{
+					map[string]interface{}{"preshared_key_wo_version": 2},
+					map[string]interface{}{"preshared_key": "plain"},
+				},
+			},
+		},
+	})
+
+	gateways := []interface{}{
+		map[string]interface{}{
+			"name": "gw1",
+			"tunnel": []interface{}{
+				map[string]interface{}{"number": 1, "preshared_key": "from-api"},
+				map[string]interface{}{"number": 2, "preshared_key": "plain"},
+				map[string]interface{}{"number": 3, "preshared_key": "new"},
+			},
+		},
+	}
+
+	keepConnectorAdvIPSecWriteOnly(d, gateways)
+
+	tunnels := gateways[0].(map[string]interface{})["tunnel"].([]interface{})
+	assert.Equal(t, "", tunnels[0].(map[string]interface{})["preshared_key"])
+	assert.Equal(t, 2, tunnels[0].(map[string]interface{})["preshared_key_wo_version"])
+	assert.Equal(t, "plain", tunnels[1].(map[string]interface{})["preshared_key"])
+	assert.Equal(t, "new", tunnels[2].(map[string]interface{})["preshared_key"])
+}
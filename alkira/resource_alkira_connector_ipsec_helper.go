@@ -1,23 +1,28 @@
 package alkira
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"strings"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 // expandConnectorIPSecEndpointAdvanced
-func expandConnectorIPSecEndpointAdvanced(in []interface{}) (*alkira.ConnectorIPSecSiteAdvanced, error) {
+func expandConnectorIPSecEndpointAdvanced(ctx context.Context, in []interface{}) (*alkira.ConnectorIPSecSiteAdvanced, error) {
 
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] empty IPSec endpoint advanced")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty ipsec endpoint advanced")
 		return nil, nil
 	}
 
 	if len(in) > 1 {
-		log.Printf("[DEBUG] invalid IPSec endpoint advanced")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid ipsec endpoint advanced")
 		return nil, nil
 	}
 
@@ -62,9 +67,9 @@ func expandConnectorIPSecEndpointAdvanced(in []interface{}) (*alkira.ConnectorIP
 }
 
 // expandIPSecEndpoint expand IPSEC endpoint section
-func expandConnectorIPSecEndpoint(in []interface{}) []*alkira.ConnectorIPSecSite {
+func expandConnectorIPSecEndpoint(ctx context.Context, in []interface{}) []*alkira.ConnectorIPSecSite {
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] empty IPSec endpoint input")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty ipsec endpoint input")
 		return nil
 	}
 
@@ -95,10 +100,12 @@ func expandConnectorIPSecEndpoint(in []interface{}) []*alkira.ConnectorIPSecSite
 		if v, ok := siteConfig["advanced_options"].([]interface{}); ok {
 
 			var err error
-			r.Advanced, err = expandConnectorIPSecEndpointAdvanced(v)
+			r.Advanced, err = expandConnectorIPSecEndpointAdvanced(ctx, v)
 
 			if err != nil {
-				log.Printf("[ERROR] failed to parse advanced block of endpoint.")
+				tflog.SubsystemError(ctx, logSubsystemSchema, "failed to parse advanced block of endpoint", map[string]interface{}{
+					"error": err,
+				})
 				break
 			}
 		}
@@ -108,10 +115,90 @@ func expandConnectorIPSecEndpoint(in []interface{}) []*alkira.ConnectorIPSecSite
 	return sites
 }
 
+// expandConnectorIPSecPresharedKeys sets the preshared keys given as
+// write-only arguments, which are only in the raw configuration.
+func expandConnectorIPSecPresharedKeys(d *schema.ResourceData, sites []*alkira.ConnectorIPSecSite) error {
+	for i, site := range sites {
+		value, err := getWriteOnlyString(d, cty.GetAttrPath("endpoint").IndexInt(i).GetAttr("preshared_keys_wo"))
+		if err != nil {
+			return err
+		}
+
+		if value == "" {
+			continue
+		}
+
+		keys, err := parseConnectorIPSecPresharedKeysWo(value)
+		if err != nil {
+			return fmt.Errorf("endpoint %s: %w", site.Name, err)
+		}
+		site.PresharedKeys = keys
+	}
+
+	return nil
+}
+
+// parseConnectorIPSecPresharedKeysWo parses preshared_keys_wo, a JSON
+// array of keys.
+func parseConnectorIPSecPresharedKeysWo(value string) ([]string, error) {
+	var keys []string
+	if err := json.Unmarshal([]byte(value), &keys); err != nil {
+		return nil, errors.New("preshared_keys_wo must be a JSON array of " +
+			"strings, e.g. jsonencode([...])")
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("preshared_keys_wo must have at least one key")
+	}
+
+	for i, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("key %d of preshared_keys_wo is empty", i)
+		}
+	}
+
+	return keys, nil
+}
+
+// validateConnectorIPSecPresharedKeys checks on the raw configuration
+// that every endpoint has exactly one of preshared_keys and
+// preshared_keys_wo, which ExactlyOneOf can't express for attributes of
+// nested blocks, and that a known preshared_keys_wo can be parsed.
+func validateConnectorIPSecPresharedKeys(config cty.Value) error {
+	for i, endpoint := range rawConfigBlocks(config, "endpoint") {
+		keys := rawConfigIsSet(endpoint, "preshared_keys")
+		keysWo := rawConfigIsSet(endpoint, "preshared_keys_wo")
+
+		if keys == keysWo {
+			return fmt.Errorf("endpoint %d: exactly one of preshared_keys or "+
+				"preshared_keys_wo must be set", i)
+		}
+
+		if v := endpoint.GetAttr("preshared_keys_wo"); keysWo && v.IsKnown() {
+			if _, err := parseConnectorIPSecPresharedKeysWo(v.AsString()); err != nil {
+				return fmt.Errorf("endpoint %d: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// keepConnectorIPSecWriteOnly keeps the preshared keys of an endpoint
+// using preshared_keys_wo out of the state, and their version in it.
+// current is the endpoint in the state.
+func keepConnectorIPSecWriteOnly(current map[string]interface{}, endpoint map[string]interface{}) {
+	endpoint["preshared_keys_wo_version"] = current["preshared_keys_wo_version"]
+
+	if keys, _ := current["preshared_keys"].([]interface{}); len(keys) == 0 {
+		endpoint["preshared_keys"] = nil
+	}
+}
+
 // expandConnectorIPSecSegmentOptions expand segment_options
-func expandConnectorIPSecSegmentOptions(in *schema.Set) (interface{}, error) {
+func expandConnectorIPSecSegmentOptions(ctx context.Context, in *schema.Set) (interface{}, error) {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty segment options of IPSec connector.")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty segment_options of ipsec connector")
 		return nil, nil
 	}
 
@@ -144,9 +231,9 @@ func expandConnectorIPSecSegmentOptions(in *schema.Set) (interface{}, error) {
 }
 
 // expandConnectorIPSecPolicyOptions expand policy_options
-func expandConnectorIPSecPolicyOptions(in *schema.Set) (*alkira.ConnectorIPSecPolicyOptions, error) {
+func expandConnectorIPSecPolicyOptions(ctx context.Context, in *schema.Set) (*alkira.ConnectorIPSecPolicyOptions, error) {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty policy options of IPSec connector.")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty policy_options of ipsec connector")
 		return &alkira.ConnectorIPSecPolicyOptions{}, nil
 	}
 
@@ -167,9 +254,9 @@ func expandConnectorIPSecPolicyOptions(in *schema.Set) (*alkira.ConnectorIPSecPo
 }
 
 // expandConnectorIPSecRoutingOptions expand routing_options
-func expandConnectorIPSecRoutingOptions(in *schema.Set) (*alkira.ConnectorIPSecRoutingOptions, error) {
+func expandConnectorIPSecRoutingOptions(ctx context.Context, in *schema.Set) (*alkira.ConnectorIPSecRoutingOptions, error) {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty routing options of IPSec connector.")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty routing_options of ipsec connector")
 		return &alkira.ConnectorIPSecRoutingOptions{}, nil
 	}
 
@@ -270,9 +357,9 @@ func expandConnectorIPSecRoutingOptions(in *schema.Set) (*alkira.ConnectorIPSecR
 }
 
 // setConnectorIPSecEndpoint
-func setConnectorIPSecEndpoint(site *alkira.ConnectorIPSecSite, configuredKeyCount int) map[string]interface{} {
+func setConnectorIPSecEndpoint(ctx context.Context, site *alkira.ConnectorIPSecSite, configuredKeyCount int) map[string]interface{} {
 	if site == nil {
-		log.Printf("[ERROR] invalid IPSec site")
+		tflog.SubsystemError(ctx, logSubsystemSchema, "invalid ipsec site")
 		return nil
 	}
 
@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFlattenConnectorIPSecSegmentOptions(t *testing.T) {
@@ -237,3 +239,73 @@ func TestFlattenConnectorIPSecPolicyOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateConnectorIPSecPresharedKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		wantErr  string
+	}{
+		{
+			name:     "preshared_keys only",
+			endpoint: `{"preshared_keys": ["key1", "key2"]}`,
+		},
+		{
+			name:     "preshared_keys_wo only",
+			endpoint: `{"preshared_keys_wo": "[\"key1\", \"key2\"]"}`,
+		},
+		{
+			name:     "neither",
+			endpoint: `{}`,
+			wantErr:  "endpoint 0: exactly one of preshared_keys or preshared_keys_wo must be set",
+		},
+		{
+			name:     "both",
+			endpoint: `{"preshared_keys": ["key1"], "preshared_keys_wo": "[\"key1\"]"}`,
+			wantErr:  "endpoint 0: exactly one of preshared_keys or preshared_keys_wo must be set",
+		},
+		{
+			name:     "preshared_keys_wo not a JSON array",
+			endpoint: `{"preshared_keys_wo": "key1"}`,
+			wantErr:  "endpoint 0: preshared_keys_wo must be a JSON array of strings, e.g. jsonencode([...])",
+		},
+		{
+			name:     "preshared_keys_wo with an empty key",
+			endpoint: `{"preshared_keys_wo": "[\"key1\", \" \"]"}`,
+			wantErr:  "endpoint 0: key 1 of preshared_keys_wo is empty",
+		},
+	}
+
+	ty := resourceAlkiraConnectorIPSec().CoreConfigSchema().ImpliedType()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ctyjson.Unmarshal([]byte(`{"endpoint": [`+tt.endpoint+`]}`), ty)
+			require.NoError(t, err)
+
+			err = validateConnectorIPSecPresharedKeys(config)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeepConnectorIPSecWriteOnly(t *testing.T) {
+	endpoint := map[string]interface{}{"preshared_keys": []string{"from-api"}}
+	keepConnectorIPSecWriteOnly(map[string]interface{}{
+		"preshared_keys":            []interface{}{},
+		"preshared_keys_wo_version": 2,
+	}, endpoint)
+	assert.Nil(t, endpoint["preshared_keys"])
+	assert.Equal(t, 2, endpoint["preshared_keys_wo_version"])
+
+	endpoint = map[string]interface{}{"preshared_keys": []string{"key1"}}
+	keepConnectorIPSecWriteOnly(map[string]interface{}{
+		"preshared_keys":            []interface{}{"key1"},
+		"preshared_keys_wo_version": 0,
+	}, endpoint)
+	assert.Equal(t, []string{"key1"}, endpoint["preshared_keys"])
+}
@@ -205,7 +205,7 @@ func resourceConnectorJuniperSdwanCreate(ctx context.Context, d *schema.Resource
 	api := alkira.NewConnectorJuniperSdwan(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateConnectorJuniperSdwanRequest(d, m)
+	request, err := generateConnectorJuniperSdwanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -318,7 +318,7 @@ func resourceConnectorJuniperSdwanUpdate(ctx context.Context, d *schema.Resource
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorJuniperSdwan(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorJuniperSdwanRequest(d, m)
+	request, err := generateConnectorJuniperSdwanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -404,10 +404,10 @@ func resourceConnectorJuniperSdwanDelete(ctx context.Context, d *schema.Resource
 }
 
 // generateConnectorJuniperSdwanRequest generate request for Juniper SD-WAN connector
-func generateConnectorJuniperSdwanRequest(d *schema.ResourceData, m any) (*alkira.ConnectorJuniperSdwan, error) {
+func generateConnectorJuniperSdwanRequest(ctx context.Context, d *schema.ResourceData, m any) (*alkira.ConnectorJuniperSdwan, error) {
 
 	// Expand juniper instances
-	instances, err := expandJuniperSdwanInstances(m.(*alkira.AlkiraClient), d.Get("instance").([]any))
+	instances, err := expandJuniperSdwanInstances(ctx, m.(*alkira.AlkiraClient), d.Get("instance").([]any))
 
 	if err != nil {
 		return nil, err
@@ -417,7 +417,7 @@ func generateConnectorJuniperSdwanRequest(d *schema.ResourceData, m any) (*alkir
 	connector := &alkira.ConnectorJuniperSdwan{
 		BillingTags:           convertTypeSetToIntList(d.Get("billing_tag_ids").(*schema.Set)),
 		Instances:             instances,
-		JuniperSsrVrfMappings: expandJuniperSdwanVrfMappings(d.Get("juniper_ssr_vrf_mapping").(*schema.Set)),
+		JuniperSsrVrfMappings: expandJuniperSdwanVrfMappings(ctx, d.Get("juniper_ssr_vrf_mapping").(*schema.Set)),
 		Version:               d.Get("juniper_ssr_version").(string),
 		Cxp:                   d.Get("cxp").(string),
 		Group:                 d.Get("group").(string),
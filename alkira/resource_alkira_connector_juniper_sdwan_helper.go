@@ -1,9 +1,10 @@
 package alkira
 
 import (
-	"log"
+	"context"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -70,10 +71,10 @@ func setJuniperInstances(d *schema.ResourceData, connector *alkira.ConnectorJuni
 }
 
 // expandJuniperSdwanVrfMappings expand Juniper SD-WAN VRF Mapping
-func expandJuniperSdwanVrfMappings(in *schema.Set) []alkira.ConnectorJuniperSsrVrfMapping {
+func expandJuniperSdwanVrfMappings(ctx context.Context, in *schema.Set) []alkira.ConnectorJuniperSsrVrfMapping {
 
 	if in.Len() == 0 {
-		log.Printf("[DEBUG] Empty SSR Vrf Mapping")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty juniper_ssr_vrf_mapping")
 		return []alkira.ConnectorJuniperSsrVrfMapping{}
 	}
 
@@ -104,10 +105,10 @@ func expandJuniperSdwanVrfMappings(in *schema.Set) []alkira.ConnectorJuniperSsrV
 }
 
 // expandJuniperSdwanWanInstances expand Juniper SD-WAN Instances
-func expandJuniperSdwanInstances(ac *alkira.AlkiraClient, in []any) ([]alkira.ConnectorJuniperSdwanInstance, error) {
+func expandJuniperSdwanInstances(ctx context.Context, ac *alkira.AlkiraClient, in []any) ([]alkira.ConnectorJuniperSdwanInstance, error) {
 
 	if len(in) == 0 {
-		log.Printf("[DEBUG] Empty instance")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty instance")
 		return []alkira.ConnectorJuniperSdwanInstance{}, nil
 	}
 
@@ -127,7 +128,7 @@ func expandJuniperSdwanInstances(ac *alkira.AlkiraClient, in []any) ([]alkira.Co
 		}
 		if v, ok := t["registration_key_credential_id"].(string); ok {
 			if v == "" {
-				log.Printf("[DEBUG] Creating Juniper SD-WAN instance registration key credential")
+				tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating juniper sdwan instance registration key credential")
 				credentialName := r.HostName + randomNameSuffix()
 
 				credential := alkira.CredentialApiKey{
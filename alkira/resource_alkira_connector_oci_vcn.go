@@ -183,7 +183,7 @@ func resourceConnectorOciVcnCreate(ctx context.Context, d *schema.ResourceData,
 	api := alkira.NewConnectorOciVcn(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateConnectorOciVcnRequest(d, m)
+	request, err := generateConnectorOciVcnRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -275,7 +275,7 @@ func resourceConnectorOciVcnRead(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	// Set vcn_cidr, vcn_subnet, vcn_route_table
-	setConnectorOciVcnRouting(d, connector.VcnRouting)
+	setConnectorOciVcnRouting(ctx, d, connector.VcnRouting)
 
 	// Set provision state
 	if client.Provision && provState != "" {
@@ -292,7 +292,7 @@ func resourceConnectorOciVcnUpdate(ctx context.Context, d *schema.ResourceData,
 	api := alkira.NewConnectorOciVcn(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	connector, err := generateConnectorOciVcnRequest(d, m)
+	connector, err := generateConnectorOciVcnRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -381,7 +381,7 @@ func resourceConnectorOciVcnDelete(ctx context.Context, d *schema.ResourceData,
 }
 
 // generateConnectorOciVcnRequest generate request for connector-oci-vcn
-func generateConnectorOciVcnRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorOciVcn, error) {
+func generateConnectorOciVcnRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorOciVcn, error) {
 
 	//
 	// Segment
@@ -395,7 +395,7 @@ func generateConnectorOciVcnRequest(d *schema.ResourceData, m interface{}) (*alk
 	//
 	// Routing Options
 	//
-	inputPrefixes, err := generateConnectorOciVcnUserInputPrefixes(d.Get("vcn_cidr").([]interface{}), d.Get("vcn_subnet").(*schema.Set))
+	inputPrefixes, err := generateConnectorOciVcnUserInputPrefixes(ctx, d.Get("vcn_cidr").([]interface{}), d.Get("vcn_subnet").(*schema.Set))
 
 	if err != nil {
 		return nil, err
@@ -406,7 +406,7 @@ func generateConnectorOciVcnRequest(d *schema.ResourceData, m interface{}) (*alk
 		Prefixes: inputPrefixes,
 	}
 
-	routeTables := expandConnectorOciVcnRouteTables(d.Get("vcn_route_table").(*schema.Set))
+	routeTables := expandConnectorOciVcnRouteTables(ctx, d.Get("vcn_route_table").(*schema.Set))
 
 	vcnRouting := alkira.ConnectorOciVcnRouting{
 		Export: exportOptions,
@@ -1,52 +1,61 @@
 package alkira
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 // setConnectorOciVcnRouting sets vcn_cidr, vcn_subnet, and vcn_route_table in state
 // by unmarshaling the VcnRouting interface{} returned from the API into typed structs.
-func setConnectorOciVcnRouting(d *schema.ResourceData, vcnRouting interface{}) {
+func setConnectorOciVcnRouting(ctx context.Context, d *schema.ResourceData, vcnRouting interface{}) {
 	if vcnRouting == nil {
 		return
 	}
 
 	routingJSON, err := json.Marshal(vcnRouting)
 	if err != nil {
-		log.Printf("[ERROR] Failed to marshal VcnRouting: %v", err)
+		tflog.SubsystemError(ctx, logSubsystemSchema, "failed to marshal vcn routing", map[string]interface{}{
+			"error": err,
+		})
 		return
 	}
 
 	var routing alkira.ConnectorOciVcnRouting
 	if err := json.Unmarshal(routingJSON, &routing); err != nil {
-		log.Printf("[ERROR] Failed to unmarshal VcnRouting: %v", err)
+		tflog.SubsystemError(ctx, logSubsystemSchema, "failed to unmarshal vcn routing", map[string]interface{}{
+			"error": err,
+		})
 		return
 	}
 
-	setOciVcnExportPrefixes(routing.Export, d)
-	setOciVcnImportRouteTables(routing.Import, d)
+	setOciVcnExportPrefixes(ctx, routing.Export, d)
+	setOciVcnImportRouteTables(ctx, routing.Import, d)
 }
 
 // setOciVcnExportPrefixes sets vcn_cidr or vcn_subnet from export options.
-func setOciVcnExportPrefixes(exportOptions interface{}, d *schema.ResourceData) {
+func setOciVcnExportPrefixes(ctx context.Context, exportOptions interface{}, d *schema.ResourceData) {
 	if exportOptions == nil {
 		return
 	}
 
 	exportJSON, err := json.Marshal(exportOptions)
 	if err != nil {
-		log.Printf("[ERROR] Failed to marshal ExportOptions: %v", err)
+		tflog.SubsystemError(ctx, logSubsystemSchema, "failed to marshal export options", map[string]interface{}{
+			"error": err,
+		})
 		return
 	}
 
 	var export alkira.ConnectorOciVcnExportOptions
 	if err := json.Unmarshal(exportJSON, &export); err != nil {
-		log.Printf("[ERROR] Failed to unmarshal ExportOptions: %v", err)
+		tflog.SubsystemError(ctx, logSubsystemSchema, "failed to unmarshal export options", map[string]interface{}{
+			"error": err,
+		})
 		return
 	}
 
@@ -74,20 +83,24 @@ func setOciVcnExportPrefixes(exportOptions interface{}, d *schema.ResourceData)
 }
 
 // setOciVcnImportRouteTables sets vcn_route_table from import options.
-func setOciVcnImportRouteTables(importOptions interface{}, d *schema.ResourceData) {
+func setOciVcnImportRouteTables(ctx context.Context, importOptions interface{}, d *schema.ResourceData) {
 	if importOptions == nil {
 		return
 	}
 
 	importJSON, err := json.Marshal(importOptions)
 	if err != nil {
-		log.Printf("[ERROR] Failed to marshal ImportOptions: %v", err)
+		tflog.SubsystemError(ctx, logSubsystemSchema, "failed to marshal import options", map[string]interface{}{
+			"error": err,
+		})
 		return
 	}
 
 	var importOpts alkira.ConnectorOciVcnImportOptions
 	if err := json.Unmarshal(importJSON, &importOpts); err != nil {
-		log.Printf("[ERROR] Failed to unmarshal ImportOptions: %v", err)
+		tflog.SubsystemError(ctx, logSubsystemSchema, "failed to unmarshal import options", map[string]interface{}{
+			"error": err,
+		})
 		return
 	}
 
@@ -106,9 +119,9 @@ func setOciVcnImportRouteTables(importOptions interface{}, d *schema.ResourceDat
 }
 
 // expandConnectorOciVcnRouteTables expand OCI-VCN route tables
-func expandConnectorOciVcnRouteTables(in *schema.Set) []alkira.ConnectorOciVcnRouteTables {
+func expandConnectorOciVcnRouteTables(ctx context.Context, in *schema.Set) []alkira.ConnectorOciVcnRouteTables {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty VCN route table input")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty vcn_route_table input")
 		return []alkira.ConnectorOciVcnRouteTables{}
 	}
 
@@ -131,7 +144,7 @@ func expandConnectorOciVcnRouteTables(in *schema.Set) []alkira.ConnectorOciVcnRo
 }
 
 // generateConnectorOciVcnUserInputPrefixes generate UserInputPrefixes used in connector-oci-vcn
-func generateConnectorOciVcnUserInputPrefixes(cidr []interface{}, subnets *schema.Set) ([]alkira.ConnectorOciVcnInputPrefixes, error) {
+func generateConnectorOciVcnUserInputPrefixes(ctx context.Context, cidr []interface{}, subnets *schema.Set) ([]alkira.ConnectorOciVcnInputPrefixes, error) {
 
 	if len(cidr) == 0 && subnets == nil {
 		return nil, fmt.Errorf("ERROR: either `vcn_subnet` or `vcn_cidr` must be specified")
@@ -139,7 +152,9 @@ func generateConnectorOciVcnUserInputPrefixes(cidr []interface{}, subnets *schem
 
 	// Processing "vcn_cidr"
 	if len(cidr) > 0 {
-		log.Printf("[DEBUG] Processing vcn_cidr %v", cidr)
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "processing vcn_cidr", map[string]interface{}{
+			"vcn_cidr": cidr,
+		})
 		cidrList := make([]alkira.ConnectorOciVcnInputPrefixes, len(cidr))
 
 		for i, value := range cidr {
@@ -151,9 +166,9 @@ func generateConnectorOciVcnUserInputPrefixes(cidr []interface{}, subnets *schem
 	}
 
 	// Processing VCN subnets
-	log.Printf("[DEBUG] Processing vcn_subnet")
+	tflog.SubsystemDebug(ctx, logSubsystemSchema, "processing vcn_subnet")
 	if subnets == nil || subnets.Len() == 0 {
-		log.Printf("[DEBUG] Empty vcn_subnet")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty vcn_subnet")
 		return nil, fmt.Errorf("ERROR: Invalid vcn_subnet")
 	}
 
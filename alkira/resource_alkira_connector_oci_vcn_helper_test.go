@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -55,7 +56,7 @@ func ociVcnTestSchema() *schema.Resource {
 
 func TestSetConnectorOciVcnRouting_Nil(t *testing.T) {
 	d := ociVcnTestSchema().TestResourceData()
-	setConnectorOciVcnRouting(d, nil)
+	setConnectorOciVcnRouting(context.Background(), d, nil)
 
 	assert.Empty(t, d.Get("vcn_cidr"))
 	assert.Equal(t, 0, d.Get("vcn_subnet").(*schema.Set).Len())
@@ -65,7 +66,7 @@ func TestSetConnectorOciVcnRouting_Nil(t *testing.T) {
 func TestSetConnectorOciVcnRouting_InvalidType(t *testing.T) {
 	d := ociVcnTestSchema().TestResourceData()
 	// Pass a non-map type — should log error and return without panic
-	setConnectorOciVcnRouting(d, "not-a-map")
+	setConnectorOciVcnRouting(context.Background(), d, "not-a-map")
 
 	assert.Empty(t, d.Get("vcn_cidr"))
 }
@@ -83,7 +84,7 @@ func TestSetConnectorOciVcnRouting_EmptyRouting(t *testing.T) {
 		},
 	}
 
-	setConnectorOciVcnRouting(d, vcnRouting)
+	setConnectorOciVcnRouting(context.Background(), d, vcnRouting)
 
 	assert.Empty(t, d.Get("vcn_cidr"))
 	assert.Equal(t, 0, d.Get("vcn_subnet").(*schema.Set).Len())
@@ -106,7 +107,7 @@ func TestSetConnectorOciVcnRouting_WithCidr(t *testing.T) {
 		},
 	}
 
-	setConnectorOciVcnRouting(d, vcnRouting)
+	setConnectorOciVcnRouting(context.Background(), d, vcnRouting)
 
 	cidr := d.Get("vcn_cidr").([]interface{})
 	assert.Len(t, cidr, 2)
@@ -134,7 +135,7 @@ func TestSetConnectorOciVcnRouting_WithSubnets(t *testing.T) {
 		},
 	}
 
-	setConnectorOciVcnRouting(d, vcnRouting)
+	setConnectorOciVcnRouting(context.Background(), d, vcnRouting)
 
 	assert.Empty(t, d.Get("vcn_cidr"))
 	subnets := d.Get("vcn_subnet").(*schema.Set).List()
@@ -163,7 +164,7 @@ func TestSetConnectorOciVcnRouting_WithRouteTables(t *testing.T) {
 		},
 	}
 
-	setConnectorOciVcnRouting(d, vcnRouting)
+	setConnectorOciVcnRouting(context.Background(), d, vcnRouting)
 
 	tables := d.Get("vcn_route_table").(*schema.Set).List()
 	assert.Len(t, tables, 1)
@@ -194,7 +195,7 @@ func TestSetConnectorOciVcnRouting_CidrAndRouteTables(t *testing.T) {
 		},
 	}
 
-	setConnectorOciVcnRouting(d, vcnRouting)
+	setConnectorOciVcnRouting(context.Background(), d, vcnRouting)
 
 	cidr := d.Get("vcn_cidr").([]interface{})
 	assert.Len(t, cidr, 1)
@@ -262,7 +262,7 @@ func resourceConnectorRemoteAccessRead(ctx context.Context, d *schema.ResourceDa
 		}}
 	}
 
-	err = setConnectorRemoteAccess(connector, d, m)
+	err = setConnectorRemoteAccess(ctx, connector, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -1,10 +1,11 @@
 package alkira
 
 import (
+	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -169,7 +170,7 @@ func generateConnectorRemoteAccessRequest(d *schema.ResourceData, m interface{})
 }
 
 // setAuthorization
-func setAuthorization(d *schema.ResourceData, segmentOptions []alkira.ConnectorRemoteAccessSegmentOptions) {
+func setAuthorization(ctx context.Context, d *schema.ResourceData, segmentOptions []alkira.ConnectorRemoteAccessSegmentOptions) {
 
 	var authorizations []map[string]interface{}
 
@@ -178,7 +179,7 @@ func setAuthorization(d *schema.ResourceData, segmentOptions []alkira.ConnectorR
 		if len(option.UserGroupMappings) != 1 ||
 			len(option.UserGroupMappings[0].CxpToSubnetsMapping) != 1 ||
 			len(option.UserGroupMappings[0].CxpToSubnetsMapping[0].Subnets) == 0 {
-			log.Printf("[ERROR] Invalid SegmentOptions in connector-remote-access")
+			tflog.SubsystemError(ctx, logSubsystemSchema, "invalid segment options in connector-remote-access")
 			continue
 		}
 
@@ -198,12 +199,14 @@ func setAuthorization(d *schema.ResourceData, segmentOptions []alkira.ConnectorR
 }
 
 // setConnectorRemoteAccess
-func setConnectorRemoteAccess(connector *alkira.ConnectorRemoteAccessTemplate, d *schema.ResourceData, m interface{}) error {
+func setConnectorRemoteAccess(ctx context.Context, connector *alkira.ConnectorRemoteAccessTemplate, d *schema.ResourceData, m interface{}) error {
 
 	if len(connector.AuthenticationOptions.SupportedModes) > 1 {
-		log.Printf("[WARN] connector-remote-access %q has %d authentication modes; only the first (%q) will be managed",
-			connector.Name, len(connector.AuthenticationOptions.SupportedModes),
-			connector.AuthenticationOptions.SupportedModes[0])
+		tflog.SubsystemWarn(ctx, logSubsystemSchema, "connector-remote-access has several authentication modes, only the first will be managed", map[string]interface{}{
+			"connector_name":      connector.Name,
+			"authentication_mode": connector.AuthenticationOptions.SupportedModes[0],
+			"mode_count":          len(connector.AuthenticationOptions.SupportedModes),
+		})
 	}
 	if len(connector.AuthenticationOptions.SupportedModes) > 0 {
 		d.Set("authentication_mode", connector.AuthenticationOptions.SupportedModes[0])
@@ -253,7 +256,7 @@ func setConnectorRemoteAccess(connector *alkira.ConnectorRemoteAccessTemplate, d
 	d.Set("segment_ids", segmentIds)
 
 	// Set authorization block
-	setAuthorization(d, connector.SegmentOptions)
+	setAuthorization(ctx, d, connector.SegmentOptions)
 
 	return nil
 }
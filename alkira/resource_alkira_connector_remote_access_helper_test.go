@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"testing"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
@@ -146,7 +147,7 @@ func TestSetConnectorRemoteAccess_SingleMode(t *testing.T) {
 		SegmentOptions: []alkira.ConnectorRemoteAccessSegmentOptions{},
 	}
 
-	err := setConnectorRemoteAccess(connector, d, nil)
+	err := setConnectorRemoteAccess(context.Background(), connector, d, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, "LOCAL", d.Get("authentication_mode").(string))
 }
@@ -173,7 +174,7 @@ func TestSetConnectorRemoteAccess_MultipleModes(t *testing.T) {
 		SegmentOptions: []alkira.ConnectorRemoteAccessSegmentOptions{},
 	}
 
-	err := setConnectorRemoteAccess(connector, d, nil)
+	err := setConnectorRemoteAccess(context.Background(), connector, d, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, "LOCAL", d.Get("authentication_mode").(string))
 }
@@ -200,7 +201,7 @@ func TestSetConnectorRemoteAccess_EmptyModes(t *testing.T) {
 		SegmentOptions: []alkira.ConnectorRemoteAccessSegmentOptions{},
 	}
 
-	err := setConnectorRemoteAccess(connector, d, nil)
+	err := setConnectorRemoteAccess(context.Background(), connector, d, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, "", d.Get("authentication_mode").(string))
 }
@@ -225,7 +226,7 @@ func TestSetConnectorRemoteAccess_EmptyArguments(t *testing.T) {
 		SegmentOptions: []alkira.ConnectorRemoteAccessSegmentOptions{},
 	}
 
-	err := setConnectorRemoteAccess(connector, d, nil)
+	err := setConnectorRemoteAccess(context.Background(), connector, d, nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "empty arguments")
 }
@@ -254,7 +255,7 @@ func TestSetConnectorRemoteAccess_FieldMapping(t *testing.T) {
 		SegmentOptions: []alkira.ConnectorRemoteAccessSegmentOptions{},
 	}
 
-	err := setConnectorRemoteAccess(connector, d, nil)
+	err := setConnectorRemoteAccess(context.Background(), connector, d, nil)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "SAML", d.Get("authentication_mode").(string))
@@ -290,7 +291,7 @@ func TestSetAuthorization_SingleAuth(t *testing.T) {
 		},
 	}
 
-	setAuthorization(d, segOptions)
+	setAuthorization(context.Background(), d, segOptions)
 
 	auths := d.Get("authorization").(*schema.Set).List()
 	assert.Len(t, auths, 1)
@@ -334,7 +335,7 @@ func TestSetAuthorization_MultipleAuths(t *testing.T) {
 		},
 	}
 
-	setAuthorization(d, segOptions)
+	setAuthorization(context.Background(), d, segOptions)
 
 	auths := d.Get("authorization").(*schema.Set).List()
 	assert.Len(t, auths, 2)
@@ -359,7 +360,7 @@ func TestSetAuthorization_InvalidMapping(t *testing.T) {
 		},
 	}
 
-	setAuthorization(d, segOptions)
+	setAuthorization(context.Background(), d, segOptions)
 
 	auths := d.Get("authorization").(*schema.Set).List()
 	assert.Len(t, auths, 0)
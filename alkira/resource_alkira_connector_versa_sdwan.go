@@ -204,7 +204,7 @@ func resourceConnectorVersaSdwanCreate(ctx context.Context, d *schema.ResourceDa
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorVersaSdwan(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorVersaSdwanRequest(d)
+	request, err := generateConnectorVersaSdwanRequest(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -319,7 +319,7 @@ func resourceConnectorVersaSdwanUpdate(ctx context.Context, d *schema.ResourceDa
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorVersaSdwan(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorVersaSdwanRequest(d)
+	request, err := generateConnectorVersaSdwanRequest(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
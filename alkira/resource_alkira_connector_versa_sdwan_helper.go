@@ -1,17 +1,18 @@
 package alkira
 
 import (
-	"log"
+	"context"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 // generateConnectorVersaSdwanRequest generate request for Versa SD-WAN connector
-func generateConnectorVersaSdwanRequest(d *schema.ResourceData) (*alkira.ConnectorVersaSdwan, error) {
+func generateConnectorVersaSdwanRequest(ctx context.Context, d *schema.ResourceData) (*alkira.ConnectorVersaSdwan, error) {
 
 	// Expand Versa SDWAN VOS devices block
-	instances, err := expandVersaSdwanVosDevices(d.Get("versa_vos_device").([]interface{}))
+	instances, err := expandVersaSdwanVosDevices(ctx, d.Get("versa_vos_device").([]interface{}))
 
 	if err != nil {
 		return nil, err
@@ -34,17 +35,17 @@ func generateConnectorVersaSdwanRequest(d *schema.ResourceData) (*alkira.Connect
 		TunnelProtocol:        d.Get("tunnel_protocol").(string),
 		VersaControllerHost:   d.Get("versa_controller_host").(string),
 		Description:           d.Get("description").(string),
-		VersaSdWanVRFMappings: expandVersaSdwanVrfMappings(d.Get("vrf_segment_mapping").(*schema.Set)),
+		VersaSdWanVRFMappings: expandVersaSdwanVrfMappings(ctx, d.Get("vrf_segment_mapping").(*schema.Set)),
 	}
 
 	return connector, nil
 }
 
 // expandVersaSdwanVrfMappings expand Versa SD-WAN VRF segment mapping
-func expandVersaSdwanVrfMappings(in *schema.Set) []alkira.VersaSdwanVrfMapping {
+func expandVersaSdwanVrfMappings(ctx context.Context, in *schema.Set) []alkira.VersaSdwanVrfMapping {
 
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty vrf_segment_mapping")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty vrf_segment_mapping")
 		return []alkira.VersaSdwanVrfMapping{}
 	}
 
@@ -76,10 +77,10 @@ func expandVersaSdwanVrfMappings(in *schema.Set) []alkira.VersaSdwanVrfMapping {
 }
 
 // expandVersaSdwanVosDevices expand Versa SD-WAN VOS devices
-func expandVersaSdwanVosDevices(in []interface{}) ([]alkira.VersaSdwanInstance, error) {
+func expandVersaSdwanVosDevices(ctx context.Context, in []interface{}) ([]alkira.VersaSdwanInstance, error) {
 
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] Empty VOS Devices")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty vos_device")
 		return []alkira.VersaSdwanInstance{}, nil
 	}
 
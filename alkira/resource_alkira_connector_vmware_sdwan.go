@@ -186,7 +186,7 @@ func resourceConnectorVmwareSdwanCreate(ctx context.Context, d *schema.ResourceD
 	api := alkira.NewConnectorVmwareSdwan(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateConnectorVmwareSdwanRequest(d, m)
+	request, err := generateConnectorVmwareSdwanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -297,7 +297,7 @@ func resourceConnectorVmwareSdwanUpdate(ctx context.Context, d *schema.ResourceD
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewConnectorVmwareSdwan(m.(*alkira.AlkiraClient))
 
-	request, err := generateConnectorVmwareSdwanRequest(d, m)
+	request, err := generateConnectorVmwareSdwanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -385,12 +385,12 @@ func resourceConnectorVmwareSdwanDelete(ctx context.Context, d *schema.ResourceD
 }
 
 // generateConnectorVmwareSdwanRequest generate request for VMWARE SD-WAN connector
-func generateConnectorVmwareSdwanRequest(d *schema.ResourceData, m interface{}) (*alkira.ConnectorVmwareSdwan, error) {
+func generateConnectorVmwareSdwanRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ConnectorVmwareSdwan, error) {
 
 	//
 	// Expand virtual_edge block
 	//
-	virtualEdges, err := expandVmwareSdwanVirtualEdges(m.(*alkira.AlkiraClient), d.Get("virtual_edge").([]interface{}))
+	virtualEdges, err := expandVmwareSdwanVirtualEdges(ctx, m.(*alkira.AlkiraClient), d.Get("virtual_edge").([]interface{}))
 
 	if err != nil {
 		return nil, err
@@ -400,7 +400,7 @@ func generateConnectorVmwareSdwanRequest(d *schema.ResourceData, m interface{})
 	connector := &alkira.ConnectorVmwareSdwan{
 		BillingTags:             convertTypeSetToIntList(d.Get("billing_tag_ids").(*schema.Set)),
 		Instances:               virtualEdges,
-		VmWareSdWanVRFMappings:  expandVmwareSdwanVrfMappings(d.Get("target_segment").(*schema.Set)),
+		VmWareSdWanVRFMappings:  expandVmwareSdwanVrfMappings(ctx, d.Get("target_segment").(*schema.Set)),
 		Cxp:                     d.Get("cxp").(string),
 		Group:                   d.Get("group").(string),
 		OrchestratorHostAddress: d.Get("orchestrator_host").(string),
@@ -1,9 +1,10 @@
 package alkira
 
 import (
-	"log"
+	"context"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -71,10 +72,10 @@ func setVirtualEdge(d *schema.ResourceData, connector *alkira.ConnectorVmwareSdw
 }
 
 // expandVmwareSdwanVrfMappings expand VMWARE SD-WAN VRF segment mapping
-func expandVmwareSdwanVrfMappings(in *schema.Set) []alkira.VmwareSdwanVrfMapping {
+func expandVmwareSdwanVrfMappings(ctx context.Context, in *schema.Set) []alkira.VmwareSdwanVrfMapping {
 
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] Empty target_segment")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty target_segment")
 		return []alkira.VmwareSdwanVrfMapping{}
 	}
 
@@ -106,10 +107,10 @@ func expandVmwareSdwanVrfMappings(in *schema.Set) []alkira.VmwareSdwanVrfMapping
 }
 
 // expandVmwareSdwanVedges expand virtual edges
-func expandVmwareSdwanVirtualEdges(ac *alkira.AlkiraClient, in []interface{}) ([]alkira.VmwareSdwanInstance, error) {
+func expandVmwareSdwanVirtualEdges(ctx context.Context, ac *alkira.AlkiraClient, in []interface{}) ([]alkira.VmwareSdwanInstance, error) {
 
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] Empty virtual_edge")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty virtual_edge")
 		return []alkira.VmwareSdwanInstance{}, nil
 	}
 
@@ -129,7 +130,7 @@ func expandVmwareSdwanVirtualEdges(ac *alkira.AlkiraClient, in []interface{}) ([
 		}
 		if v, ok := t["credential_id"].(string); ok {
 			if v == "" {
-				log.Printf("[DEBUG] Creating VMWARE-SDWAN Instance Credential")
+				tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating vmware sdwan instance credential")
 				credentialName := r.HostName + randomNameSuffix()
 
 				credential := alkira.CredentialVmwareSdwanInstance{
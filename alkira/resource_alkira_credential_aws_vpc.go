@@ -4,10 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-cty/cty"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -48,13 +49,31 @@ func resourceAlkiraCredentialAwsVpc() *schema.Resource {
 					nil),
 			},
 			"aws_secret_key": {
-				Description: "AWS secret key.",
-				Type:        schema.TypeString,
-				Optional:    true,
+				Description:   "AWS secret key.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"aws_secret_key_wo"},
 				DefaultFunc: schema.EnvDefaultFunc(
 					"AK_AWS_SECRET_ACCESS_KEY",
 					nil),
 			},
+			"aws_secret_key_wo": {
+				Description: "AWS secret key as a write-only argument, " +
+					"which is never stored in plan or state. Conflicts " +
+					"with `aws_secret_key`. Requires Terraform 1.11 or " +
+					"later.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				WriteOnly:     true,
+				Sensitive:     true,
+				ConflictsWith: []string{"aws_secret_key"},
+			},
+			"aws_secret_key_wo_version": {
+				Description: "Change this value to update the credential " +
+					"with a new `aws_secret_key_wo`.",
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
 			"aws_role_arn": {
 				Description: "AWS Role ARN.",
 				Type:        schema.TypeString,
@@ -83,7 +102,7 @@ func resourceAlkiraCredentialAwsVpc() *schema.Resource {
 func resourceCredentialAwsVpc(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*alkira.AlkiraClient)
 
-	c, err := generateCredentialAwsVpc(d)
+	c, err := generateCredentialAwsVpc(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -106,13 +125,13 @@ func resourceCredentialAwsVpcRead(ctx context.Context, d *schema.ResourceData, m
 func resourceCredentialAwsVpcUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*alkira.AlkiraClient)
 
-	c, err := generateCredentialAwsVpc(d)
+	c, err := generateCredentialAwsVpc(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Printf("[INFO] Updating credential (AWS-VPC) %s", d.Id())
+	tflog.Info(ctx, "updating credential (AWS-VPC)")
 	err = client.UpdateCredential(d.Id(), d.Get("name").(string), alkira.CredentialTypeAwsVpc, c, 0)
 
 	if err != nil {
@@ -126,7 +145,7 @@ func resourceCredentialAwsVpcDelete(ctx context.Context, d *schema.ResourceData,
 	client := meta.(*alkira.AlkiraClient)
 	credentialId := d.Id()
 
-	log.Printf("[INFO] Deleting credential (AWS-VPC %s)\n", credentialId)
+	tflog.Info(ctx, "deleting credential (AWS-VPC)")
 	err := client.DeleteCredential(credentialId, alkira.CredentialTypeAwsVpc)
 
 	if err != nil {
@@ -143,15 +162,23 @@ func resourceCredentialAwsVpcDelete(ctx context.Context, d *schema.ResourceData,
 	return nil
 }
 
-func generateCredentialAwsVpc(d *schema.ResourceData) (interface{}, error) {
+func generateCredentialAwsVpc(ctx context.Context, d *schema.ResourceData) (interface{}, error) {
 	credentialType := d.Get("type").(string)
 	var c interface{}
 
 	switch credentialType {
 	case "ACCESS_KEY":
+		secretKey, err := getWriteOnlyString(d, cty.GetAttrPath("aws_secret_key_wo"))
+		if err != nil {
+			return nil, err
+		}
+		if secretKey == "" {
+			secretKey = d.Get("aws_secret_key").(string)
+		}
+
 		c = alkira.CredentialAwsVpcKey{
 			Ec2AccessKey: d.Get("aws_access_key").(string),
-			Ec2SecretKey: d.Get("aws_secret_key").(string),
+			Ec2SecretKey: secretKey,
 			Type:         d.Get("type").(string),
 		}
 	case "ROLE":
@@ -164,6 +191,8 @@ func generateCredentialAwsVpc(d *schema.ResourceData) (interface{}, error) {
 		return nil, errors.New("ERROR: Invalid AWS-VPC credential type")
 	}
 
-	log.Printf("[INFO] Creating credential (AWS-VPC) with type %s", credentialType)
+	tflog.Debug(ctx, "generated credential (AWS-VPC)", map[string]interface{}{
+		"credential_type": credentialType,
+	})
 	return c, nil
 }
@@ -3,10 +3,10 @@ package alkira
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -89,7 +89,7 @@ func resourceCredentialAzureVnet(ctx context.Context, d *schema.ResourceData, me
 		Environment:    d.Get("environment").(string),
 	}
 
-	log.Printf("[INFO] Creating Credential (AZURE-VNET)")
+	tflog.Info(ctx, "creating credential (AZURE-VNET)")
 	id, err := client.CreateCredential(d.Get("name").(string), alkira.CredentialTypeAzureVnet, c, 0)
 
 	if err != nil {
@@ -115,7 +115,7 @@ func resourceCredentialAzureVnetUpdate(ctx context.Context, d *schema.ResourceDa
 		Environment:    d.Get("environment").(string),
 	}
 
-	log.Printf("[INFO] Updating Credential (AZURE-VNET)")
+	tflog.Info(ctx, "updating credential (AZURE-VNET)")
 	err := client.UpdateCredential(d.Id(), d.Get("name").(string), alkira.CredentialTypeAzureVnet, c, 0)
 
 	if err != nil {
@@ -3,10 +3,10 @@ package alkira
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -102,7 +102,7 @@ func resourceCredentialGcpVpc(ctx context.Context, d *schema.ResourceData, meta
 		Type:              d.Get("type").(string),
 	}
 
-	log.Printf("[INFO] Creating Credential (GCP-VPC)")
+	tflog.Info(ctx, "creating credential (GCP-VPC)")
 	credentialId, err := client.CreateCredential(d.Get("name").(string), "gcpvpc", c, 0)
 
 	if err != nil {
@@ -133,7 +133,7 @@ func resourceCredentialGcpVpcUpdate(ctx context.Context, d *schema.ResourceData,
 		Type:              d.Get("type").(string),
 	}
 
-	log.Printf("[INFO] Updating Credential (GCP-VPC)")
+	tflog.Info(ctx, "updating credential (GCP-VPC)")
 	err := client.UpdateCredential(d.Id(), d.Get("name").(string), "gcpvpc", c, 0)
 
 	if err != nil {
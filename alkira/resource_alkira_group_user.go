@@ -3,10 +3,10 @@ package alkira
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -99,7 +99,7 @@ func resourceGroupUserUpdate(ctx context.Context, d *schema.ResourceData, m inte
 		Description: d.Get("description").(string),
 	}
 
-	log.Printf("[INFO] Updating User Group (%s)", d.Id())
+	tflog.Info(ctx, "updating user group")
 	_, err, valErr, _ := api.Update(d.Id(), group)
 
 	if err != nil {
@@ -0,0 +1,176 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceAlkiraGroupUserMembership() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manage the users of a user group (`alkira_group_user`).\n\n" +
+			"When `authoritative` is `true`, the users are the only " +
+			"members of the group and any other user is removed from it. " +
+			"There must be at most one authoritative membership per group. " +
+			"Otherwise the users are added to the group next to its other " +
+			"members, and several memberships can add users to the same " +
+			"group.\n\n" +
+			"An authoritative membership can be imported with the ID of " +
+			"the group.",
+		CreateContext: resourceGroupUserMembership,
+		ReadContext:   resourceGroupUserMembershipRead,
+		UpdateContext: resourceGroupUserMembershipUpdate,
+		DeleteContext: resourceGroupUserMembershipDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importGroupUserMembership,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Description: "The ID of the user group.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"user_ids": {
+				Description: "The IDs of the users (`alkira_user`) in the group.",
+				Type:        schema.TypeSet,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"authoritative": {
+				Description: "Whether the users are the only members of the " +
+					"group. The default value is `false`.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceGroupUserMembership(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*alkira.AlkiraClient)
+
+	groupId := d.Get("group_id").(string)
+	userIds := convertTypeSetToStringList(d.Get("user_ids").(*schema.Set))
+
+	var remove []string
+
+	if d.Get("authoritative").(bool) {
+		users, err := getAllPortalUsers(client)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		remove = groupMembers(users, groupId)
+	}
+
+	if err := updateGroupMembership(client, groupId, userIds, remove); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// An authoritative membership is identified by its group, while a
+	// group can have several additive ones.
+	if d.Get("authoritative").(bool) {
+		d.SetId(groupId)
+	} else {
+		d.SetId(groupId + ":" + uuid.New().String())
+	}
+
+	return resourceGroupUserMembershipRead(ctx, d, m)
+}
+
+func resourceGroupUserMembershipRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*alkira.AlkiraClient)
+
+	groupId := d.Get("group_id").(string)
+
+	if _, _, err := alkira.NewUserGroup(client).GetById(groupId); err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "FAILED TO GET RESOURCE",
+			Detail:   fmt.Sprintf("%s", err),
+		}}
+	}
+
+	users, err := getAllPortalUsers(client)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	members := groupMembers(users, groupId)
+
+	// Additive memberships only track their own users.
+	if !d.Get("authoritative").(bool) {
+		configured := convertTypeSetToStringList(d.Get("user_ids").(*schema.Set))
+
+		var tracked []string
+		for _, member := range members {
+			if stringInSlice(member, configured) {
+				tracked = append(tracked, member)
+			}
+		}
+		members = tracked
+	}
+
+	d.Set("user_ids", members)
+
+	return nil
+}
+
+func resourceGroupUserMembershipUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*alkira.AlkiraClient)
+
+	old, new := d.GetChange("user_ids")
+	removed := old.(*schema.Set).Difference(new.(*schema.Set))
+
+	tflog.Info(ctx, "updating user group membership")
+	err := updateGroupMembership(client,
+		d.Get("group_id").(string),
+		convertTypeSetToStringList(new.(*schema.Set)),
+		convertTypeSetToStringList(removed))
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceGroupUserMembershipRead(ctx, d, m)
+}
+
+func resourceGroupUserMembershipDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*alkira.AlkiraClient)
+
+	err := updateGroupMembership(client,
+		d.Get("group_id").(string),
+		nil,
+		convertTypeSetToStringList(d.Get("user_ids").(*schema.Set)))
+
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("%w alkira_group_user_membership (group_id=%s)", err, d.Get("group_id")))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// importGroupUserMembership imports the authoritative membership of the
+// group with the given ID.
+func importGroupUserMembership(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	if strings.Contains(d.Id(), ":") {
+		return nil, fmt.Errorf("only authoritative memberships can be imported, " +
+			"by the ID of their group")
+	}
+
+	d.Set("group_id", d.Id())
+	d.Set("authoritative", true)
+
+	return importWithReadValidation(resourceGroupUserMembershipRead)(ctx, d, m)
+}
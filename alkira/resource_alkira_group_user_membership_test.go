@@ -0,0 +1,348 @@
+package alkira
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPortalUsers serves the users and user group "10" of the portal.
+// The users are returned with the attributes in extra, which portalUser
+// doesn't model, and the bodies of the updates are kept by user ID.
+type mockPortalUsers struct {
+	mu      sync.Mutex
+	users   map[string]*portalUser
+	extra   map[string]map[string]interface{}
+	updates []string
+	bodies  map[string]map[string]interface{}
+}
+
+func newMockPortalUsers(users ...portalUser) *mockPortalUsers {
+	m := &mockPortalUsers{
+		users:  make(map[string]*portalUser),
+		extra:  make(map[string]map[string]interface{}),
+		bodies: make(map[string]map[string]interface{}),
+	}
+	for i := range users {
+		m.users[users[i].Id] = &users[i]
+	}
+	return m
+}
+
+// object returns the JSON object of a user as the portal returns it.
+func (m *mockPortalUsers) object(t *testing.T, u *portalUser) map[string]interface{} {
+	data, err := json.Marshal(u)
+	require.NoError(t, err)
+
+	var object map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &object))
+
+	for k, v := range m.extra[u.Id] {
+		object[k] = v
+	}
+
+	return object
+}
+
+func (m *mockPortalUsers) client(t *testing.T) *alkira.AlkiraClient {
+	return createMockAlkiraClient(t, func(w http.ResponseWriter, req *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case req.URL.Path == "/user-groups/10":
+			json.NewEncoder(w).Encode(alkira.UserGroup{Id: "10", Name: "operators"})
+		case req.URL.Path == "/users" && req.Method == http.MethodGet:
+			var users []map[string]interface{}
+			for _, u := range m.users {
+				users = append(users, m.object(t, u))
+			}
+			sort.Slice(users, func(i, j int) bool { return users[i]["id"].(string) < users[j]["id"].(string) })
+			json.NewEncoder(w).Encode(users)
+		case strings.HasPrefix(req.URL.Path, "/users/") && req.Method == http.MethodGet:
+			u, ok := m.users[strings.TrimPrefix(req.URL.Path, "/users/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(m.object(t, u))
+		case strings.HasPrefix(req.URL.Path, "/users/") && req.Method == http.MethodPut:
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			var user portalUser
+			require.NoError(t, json.Unmarshal(data, &user))
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &body))
+			id := strings.TrimPrefix(req.URL.Path, "/users/")
+			user.Id = id
+			m.users[id] = &user
+			m.bodies[id] = body
+			m.updates = append(m.updates, id)
+			json.NewEncoder(w).Encode(user)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func (m *mockPortalUsers) groups(id string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	groups := append([]string{}, m.users[id].UserGroups...)
+	sort.Strings(groups)
+	return groups
+}
+
+func testPortalUsers() []portalUser {
+	return []portalUser{
+		{Id: "1", UserName: "alice", Email: "alice@example.com", UserGroups: []string{"10", "20"}},
+		{Id: "2", UserName: "bob", Email: "bob@example.com", UserGroups: []string{"20"}},
+		{Id: "3", UserName: "carol", Email: "carol@example.com", UserGroups: []string{"10"}},
+		{Id: "4", UserName: "dave", Email: "shared@example.com"},
+		{Id: "5", UserName: "erin", Email: "shared@example.com"},
+	}
+}
+
+func TestUpdateGroupMembership(t *testing.T) {
+	mock := newMockPortalUsers(testPortalUsers()...)
+	client := mock.client(t)
+
+	require.NoError(t, updateGroupMembership(client, "10", []string{"1", "2"}, []string{"3", "4"}))
+
+	assert.Equal(t, []string{"10", "20"}, mock.groups("1"))
+	assert.Equal(t, []string{"10", "20"}, mock.groups("2"))
+	assert.Empty(t, mock.groups("3"))
+	assert.Empty(t, mock.groups("4"))
+
+	// Only the users whose groups changed are updated.
+	assert.ElementsMatch(t, []string{"2", "3"}, mock.updates)
+
+	err := updateGroupMembership(client, "10", []string{"99"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "user 99 does not exist")
+}
+
+func TestPortalUserUpdateKeepsUnmodeledAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		update     func(t *testing.T, client *alkira.AlkiraClient)
+		id         string
+		wantGroups []interface{}
+		wantName   string
+	}{
+		{
+			name: "group membership",
+			update: func(t *testing.T, client *alkira.AlkiraClient) {
+				require.NoError(t, updateGroupMembership(client, "10", []string{"2"}, nil))
+			},
+			id:         "2",
+			wantGroups: []interface{}{"20", "10"},
+			wantName:   "bob",
+		},
+		{
+			name: "user",
+			update: func(t *testing.T, client *alkira.AlkiraClient) {
+				r := resourceAlkiraUser()
+				d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+					"user_name": "robert",
+					"email":     "bob@example.com",
+				})
+				d.SetId("2")
+
+				diags := r.UpdateContext(context.Background(), d, client)
+				require.False(t, diags.HasError(), "%v", diags)
+			},
+			id:         "2",
+			wantGroups: []interface{}{"20"},
+			wantName:   "robert",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockPortalUsers(testPortalUsers()...)
+			mock.extra["2"] = map[string]interface{}{
+				"roles":      []interface{}{"NETWORK_ADMIN"},
+				"mfaEnabled": true,
+				"idpId":      "okta",
+			}
+
+			tt.update(t, mock.client(t))
+
+			body := mock.bodies[tt.id]
+			require.NotNil(t, body)
+			assert.Equal(t, tt.wantGroups, body["userGroups"])
+			assert.Equal(t, tt.wantName, body["userName"])
+			assert.Equal(t, []interface{}{"NETWORK_ADMIN"}, body["roles"])
+			assert.Equal(t, true, body["mfaEnabled"])
+			assert.Equal(t, "okta", body["idpId"])
+		})
+	}
+}
+
+func TestGroupUserMembershipAuthoritative(t *testing.T) {
+	mock := newMockPortalUsers(testPortalUsers()...)
+	client := mock.client(t)
+
+	r := resourceAlkiraGroupUserMembership()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"group_id":      "10",
+		"user_ids":      []interface{}{"2", "3"},
+		"authoritative": true,
+	})
+
+	diags := r.CreateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, "10", d.Id())
+	assert.Equal(t, []string{"20"}, mock.groups("1"))
+	assert.Equal(t, []string{"10", "20"}, mock.groups("2"))
+	assert.Equal(t, []string{"10"}, mock.groups("3"))
+
+	// A user added to the group out of band shows up as drift.
+	mock.users["4"].UserGroups = []string{"10"}
+
+	diags = r.ReadContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.ElementsMatch(t, []string{"2", "3", "4"}, convertTypeSetToStringList(d.Get("user_ids").(*schema.Set)))
+
+	diags = r.DeleteContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	for _, id := range []string{"2", "3", "4"} {
+		assert.NotContains(t, mock.groups(id), "10")
+	}
+	assert.Equal(t, []string{"20"}, mock.groups("2"))
+}
+
+func TestGroupUserMembershipAdditive(t *testing.T) {
+	mock := newMockPortalUsers(testPortalUsers()...)
+	client := mock.client(t)
+
+	r := resourceAlkiraGroupUserMembership()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"group_id": "10",
+		"user_ids": []interface{}{"2"},
+	})
+
+	diags := r.CreateContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.True(t, strings.HasPrefix(d.Id(), "10:"))
+	assert.Equal(t, []string{"2"}, convertTypeSetToStringList(d.Get("user_ids").(*schema.Set)))
+
+	// The other members of the group are kept.
+	assert.Equal(t, []string{"10", "20"}, mock.groups("1"))
+	assert.Equal(t, []string{"10", "20"}, mock.groups("2"))
+	assert.Equal(t, []string{"10"}, mock.groups("3"))
+
+	diags = r.DeleteContext(context.Background(), d, client)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, []string{"20"}, mock.groups("2"))
+	assert.Equal(t, []string{"10"}, mock.groups("3"))
+}
+
+func TestGroupUserMembershipImport(t *testing.T) {
+	mock := newMockPortalUsers(testPortalUsers()...)
+	client := mock.client(t)
+
+	r := resourceAlkiraGroupUserMembership()
+
+	d := r.TestResourceData()
+	d.SetId("10")
+
+	imported, err := r.Importer.StateContext(context.Background(), d, client)
+	require.NoError(t, err)
+	require.Len(t, imported, 1)
+
+	assert.Equal(t, "10", imported[0].Get("group_id"))
+	assert.Equal(t, true, imported[0].Get("authoritative"))
+	assert.ElementsMatch(t, []string{"1", "3"}, convertTypeSetToStringList(imported[0].Get("user_ids").(*schema.Set)))
+
+	d = r.TestResourceData()
+	d.SetId("10:additive")
+
+	_, err = r.Importer.StateContext(context.Background(), d, client)
+	assert.Error(t, err)
+}
+
+func TestBuildUserRequest(t *testing.T) {
+	r := resourceAlkiraUser()
+
+	// NOTE: TestResourceDataRaw has no raw config, so group_ids counts
+	// as not configured and the current groups are kept. The configured
+	// group_ids are only used during plan/apply.
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"user_name":  "alice",
+		"email":      "alice@example.com",
+		"first_name": "Alice",
+		"group_ids":  []interface{}{"20"},
+	})
+
+	user := buildUserRequest(d, []string{"10"})
+	assert.Equal(t, "alice", user.UserName)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, "Alice", user.FirstName)
+	assert.Equal(t, []string{"10"}, user.UserGroups)
+
+	assert.Equal(t, []string{}, buildUserRequest(d, nil).UserGroups)
+}
+
+func TestUserDataSources(t *testing.T) {
+	client := newMockPortalUsers(testPortalUsers()...).client(t)
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantId  string
+		wantErr string
+	}{
+		{name: "by user name", config: map[string]interface{}{"user_name": "bob"}, wantId: "2"},
+		{name: "by email", config: map[string]interface{}{"email": "carol@example.com"}, wantId: "3"},
+		{name: "missing", config: map[string]interface{}{"user_name": "mallory"}, wantErr: `user "mallory" does not exist`},
+		{name: "ambiguous email", config: map[string]interface{}{"email": "shared@example.com"}, wantErr: "2 users have the email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := dataSourceAlkiraUser()
+			d := schema.TestResourceDataRaw(t, r.Schema, tt.config)
+
+			err := r.Read(d, client)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantId, d.Id())
+		})
+	}
+
+	r := dataSourceAlkiraUsers()
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{})
+	require.NoError(t, r.Read(d, client))
+	assert.Equal(t, []interface{}{"1", "2", "3", "4", "5"}, d.Get("ids"))
+
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"group_id": "20"})
+	require.NoError(t, r.Read(d, client))
+	assert.Equal(t, []interface{}{"1", "2"}, d.Get("ids"))
+	assert.Equal(t, "alice", d.Get("users.0.user_name"))
+	assert.Equal(t, []interface{}{"10", "20"}, d.Get("users.0.group_ids"))
+}
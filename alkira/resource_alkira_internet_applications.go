@@ -222,7 +222,7 @@ func resourceInternetApplicationCreate(ctx context.Context, d *schema.ResourceDa
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewInternetApplication(m.(*alkira.AlkiraClient))
 
-	request, err := generateInternetApplicationRequest(d, m)
+	request, err := generateInternetApplicationRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -363,7 +363,7 @@ func resourceInternetApplicationUpdate(ctx context.Context, d *schema.ResourceDa
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewInternetApplication(m.(*alkira.AlkiraClient))
 
-	request, err := generateInternetApplicationRequest(d, m)
+	request, err := generateInternetApplicationRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -450,7 +450,7 @@ func resourceInternetApplicationDelete(ctx context.Context, d *schema.ResourceDa
 	return nil
 }
 
-func generateInternetApplicationRequest(d *schema.ResourceData, m interface{}) (*alkira.InternetApplication, error) {
+func generateInternetApplicationRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.InternetApplication, error) {
 
 	//
 	// Segment
@@ -469,7 +469,7 @@ func generateInternetApplicationRequest(d *schema.ResourceData, m interface{}) (
 	//
 	// Targets
 	//
-	targets := expandInternetApplicationTargets(d.Get("target").(*schema.Set))
+	targets := expandInternetApplicationTargets(ctx, d.Get("target").(*schema.Set))
 
 	// Assemble request
 	request := &alkira.InternetApplication{
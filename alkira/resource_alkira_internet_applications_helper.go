@@ -1,9 +1,10 @@
 package alkira
 
 import (
-	"log"
+	"context"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -31,9 +32,9 @@ import (
 // 	return 0
 // }
 
-func expandInternetApplicationTargets(in *schema.Set) []alkira.InternetApplicationTargets {
+func expandInternetApplicationTargets(ctx context.Context, in *schema.Set) []alkira.InternetApplicationTargets {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] invalid internet application targets")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid internet application targets")
 		return nil
 	}
 
@@ -0,0 +1,205 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceAlkiraIpPool() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manage a pool of IP addresses to allocate tunnel and " +
+			"loopback addressing from.\n\n" +
+			"The pool only lives in the Terraform state, nothing is " +
+			"provisioned. Every name in `allocations` gets a block of " +
+			"`prefix_length` carved from `cidr`. A block is chosen by " +
+			"hashing the name, so it doesn't depend on the order of the " +
+			"names, and once allocated it's kept until the name is removed. " +
+			"Blocks overlapping `reserved_prefixes` or the addressing of " +
+			"existing connectors and IP reservations are never allocated.",
+		CreateContext: resourceIpPoolCreate,
+		ReadContext:   resourceIpPoolRead,
+		UpdateContext: resourceIpPoolUpdate,
+		DeleteContext: resourceIpPoolDelete,
+		CustomizeDiff: resourceIpPoolCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Description: "The name of the pool.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"description": {
+				Description: "The description of the pool.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"cidr": {
+				Description:  "The IPv4 CIDR the blocks are allocated from.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsCIDR,
+			},
+			"prefix_length": {
+				Description: "The prefix length of the allocated blocks, " +
+					"`29`, `30` or `31`. The default value is `30`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ForceNew:     true,
+				ValidateFunc: validation.IntInSlice([]int{29, 30, 31}),
+			},
+			"reserved_prefixes": {
+				Description: "The prefixes of the pool that must not be " +
+					"allocated.",
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.IsCIDR},
+				Optional: true,
+			},
+			"check_existing_connectors": {
+				Description: "Whether to skip blocks overlapping the " +
+					"addressing of existing connectors and IP reservations " +
+					"when allocating. The default value is `true`.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"allocations": {
+				Description: "The names of the allocations. Use stable " +
+					"names, e.g. the `for_each` keys of the connectors " +
+					"using them.",
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			"allocated_prefixes": {
+				Description: "The allocated block of every allocation, " +
+					"keyed by the name of the allocation.",
+				Type:     schema.TypeMap,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"existing_prefixes": {
+				Description: "The addressing of existing connectors and IP " +
+					"reservations overlapping the pool, found the last " +
+					"time blocks were allocated.",
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"available_count": {
+				Description: "The number of blocks still available.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceIpPoolCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// The allocation happens in the plan, see resourceIpPoolCustomizeDiff.
+	d.SetId(d.Get("cidr").(string))
+
+	return nil
+}
+
+func resourceIpPoolRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceIpPoolUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceIpPoolDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// resourceIpPoolCustomizeDiff allocates the blocks at plan time so the
+// connectors using them see the values in the same plan.
+func resourceIpPoolCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && !d.HasChange("allocations") && !d.HasChange("reserved_prefixes") {
+		return nil
+	}
+
+	// The CIDR may be unknown until another resource is created.
+	if !d.NewValueKnown("cidr") || !d.NewValueKnown("allocations") || !d.NewValueKnown("reserved_prefixes") {
+		return d.SetNewComputed("allocated_prefixes")
+	}
+
+	var reserved []netip.Prefix
+	for _, v := range convertTypeSetToStringList(d.Get("reserved_prefixes").(*schema.Set)) {
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return fmt.Errorf("reserved_prefixes: %w", err)
+		}
+		reserved = append(reserved, p)
+	}
+
+	pool, err := newIpPool(d.Get("cidr").(string), d.Get("prefix_length").(int), reserved)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]string)
+	old, _ := d.GetChange("allocated_prefixes")
+	for name, prefix := range old.(map[string]interface{}) {
+		current[name] = prefix.(string)
+	}
+
+	for name, prefix := range current {
+		block, err := netip.ParsePrefix(prefix)
+		if err == nil && pool.isExcluded(block) {
+			return fmt.Errorf("allocation %q (%s) overlaps reserved_prefixes", name, prefix)
+		}
+	}
+
+	names := convertTypeSetToStringList(d.Get("allocations").(*schema.Set))
+
+	// Only look up the existing addressing when new blocks are needed.
+	existing := d.Get("existing_prefixes").([]interface{})
+	if hasNewAllocations(names, current) && d.Get("check_existing_connectors").(bool) {
+		addressing, err := getConnectorAddressing(m.(*alkira.AlkiraClient))
+		if err != nil {
+			return err
+		}
+
+		pool.excluded = append(pool.excluded, addressing...)
+
+		existing = nil
+		for _, p := range pool.overlapping(addressing) {
+			existing = append(existing, p)
+		}
+	}
+
+	allocated, err := pool.allocate(names, current)
+	if err != nil {
+		return err
+	}
+
+	if err := d.SetNew("allocated_prefixes", allocated); err != nil {
+		return err
+	}
+	if err := d.SetNew("existing_prefixes", existing); err != nil {
+		return err
+	}
+
+	return d.SetNew("available_count", pool.available(allocated))
+}
+
+func hasNewAllocations(names []string, current map[string]string) bool {
+	for _, name := range names {
+		if _, ok := current[name]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
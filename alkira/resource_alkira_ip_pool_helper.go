@@ -0,0 +1,346 @@
+package alkira
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+)
+
+// ipPool carves fixed size blocks out of an IPv4 CIDR.
+type ipPool struct {
+	cidr      netip.Prefix
+	blockBits int
+	excluded  []netip.Prefix
+}
+
+func newIpPool(cidr string, prefixLength int, excluded []netip.Prefix) (*ipPool, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	if !prefix.Addr().Is4() {
+		return nil, fmt.Errorf("cidr %s is not an IPv4 CIDR", cidr)
+	}
+
+	if prefix.Bits() > prefixLength {
+		return nil, fmt.Errorf("cidr %s is smaller than a /%d", cidr, prefixLength)
+	}
+
+	return &ipPool{cidr: prefix.Masked(), blockBits: prefixLength, excluded: excluded}, nil
+}
+
+// size returns the number of blocks of the pool.
+func (p *ipPool) size() int {
+	return 1 << (p.blockBits - p.cidr.Bits())
+}
+
+func (p *ipPool) block(slot int) netip.Prefix {
+	base := ipv4ToUint32(p.cidr.Addr())
+	return netip.PrefixFrom(uint32ToIpv4(base+uint32(slot)<<(32-p.blockBits)), p.blockBits)
+}
+
+// allocate returns the block of every name. Names in current keep their
+// block. New names are placed by hashing the name and probing for the
+// next free block, in name order, so the result doesn't depend on the
+// order the names are given in and adding or removing a name never
+// moves another one.
+func (p *ipPool) allocate(names []string, current map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(names))
+	used := make(map[int]bool)
+
+	for _, name := range names {
+		prefix, ok := current[name]
+		if !ok {
+			continue
+		}
+
+		slot, err := p.slot(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("allocation %q: %w", name, err)
+		}
+
+		result[name] = prefix
+		used[slot] = true
+	}
+
+	pending := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := result[name]; !ok {
+			pending = append(pending, name)
+		}
+	}
+	sort.Strings(pending)
+
+	excluded := p.excludedSlots()
+
+	for _, name := range pending {
+		slot, ok := p.probe(name, used, excluded)
+		if !ok {
+			return nil, fmt.Errorf("no free /%d left in %s for allocation %q", p.blockBits, p.cidr, name)
+		}
+
+		result[name] = p.block(slot).String()
+		used[slot] = true
+	}
+
+	return result, nil
+}
+
+// slot returns the position of an allocated block in the pool.
+func (p *ipPool) slot(prefix string) (int, error) {
+	block, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	if block.Bits() != p.blockBits || !p.cidr.Contains(block.Addr()) {
+		return 0, fmt.Errorf("%s is not a /%d of %s", prefix, p.blockBits, p.cidr)
+	}
+
+	return int((ipv4ToUint32(block.Masked().Addr()) - ipv4ToUint32(p.cidr.Addr())) >> (32 - p.blockBits)), nil
+}
+
+// probe returns the first block from the hash of the name on that is
+// neither used nor excluded, wrapping around the end of the pool. Runs
+// of excluded blocks are skipped at once, so large pools with many
+// excluded prefixes stay cheap to plan.
+func (p *ipPool) probe(name string, used map[int]bool, excluded []slotRange) (int, bool) {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+
+	size := p.size()
+	slot := int(h.Sum64() % uint64(size))
+
+	for visited := 0; visited < size; {
+		if r, ok := containingSlotRange(excluded, slot); ok {
+			visited += r.last - slot + 1
+			slot = (r.last + 1) % size
+			continue
+		}
+
+		if !used[slot] {
+			return slot, true
+		}
+
+		visited++
+		slot = (slot + 1) % size
+	}
+
+	return 0, false
+}
+
+// available returns the number of blocks neither allocated nor excluded.
+func (p *ipPool) available(allocated map[string]string) int {
+	excluded := p.excludedSlots()
+
+	count := p.size()
+	for _, r := range excluded {
+		count -= r.last - r.first + 1
+	}
+
+	used := make(map[int]bool)
+	for _, prefix := range allocated {
+		slot, err := p.slot(prefix)
+		if err != nil || used[slot] {
+			continue
+		}
+
+		used[slot] = true
+		if _, ok := containingSlotRange(excluded, slot); !ok {
+			count--
+		}
+	}
+
+	return count
+}
+
+// slotRange is a run of consecutive slots of a pool, both ends included.
+type slotRange struct {
+	first int
+	last  int
+}
+
+// excludedSlots returns the runs of slots of the blocks overlapping the
+// excluded prefixes, sorted and merged.
+func (p *ipPool) excludedSlots() []slotRange {
+	base := ipv4ToUint32(p.cidr.Addr())
+	end := ipv4ToUint32(lastAddr(p.cidr))
+	shift := 32 - p.blockBits
+
+	var ranges []slotRange
+	for _, e := range p.excluded {
+		if !e.Addr().Is4() || !e.Overlaps(p.cidr) {
+			continue
+		}
+
+		e = e.Masked()
+		first := max(ipv4ToUint32(e.Addr()), base)
+		last := min(ipv4ToUint32(lastAddr(e)), end)
+
+		ranges = append(ranges, slotRange{
+			first: int((first - base) >> shift),
+			last:  int((last - base) >> shift),
+		})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].first < ranges[j].first })
+
+	var merged []slotRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.first <= merged[n-1].last+1 {
+			merged[n-1].last = max(merged[n-1].last, r.last)
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// containingSlotRange returns the range of the sorted ranges holding
+// the slot.
+func containingSlotRange(ranges []slotRange, slot int) (slotRange, bool) {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].last >= slot })
+	if i < len(ranges) && ranges[i].first <= slot {
+		return ranges[i], true
+	}
+
+	return slotRange{}, false
+}
+
+func (p *ipPool) isExcluded(block netip.Prefix) bool {
+	for _, e := range p.excluded {
+		if e.Overlaps(block) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// overlapping returns the prefixes overlapping the pool, sorted and
+// without duplicates.
+func (p *ipPool) overlapping(prefixes []netip.Prefix) []string {
+	var out []string
+
+	for _, prefix := range prefixes {
+		if p.cidr.Overlaps(prefix) {
+			out = appendMissing(out, []string{prefix.String()})
+		}
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// getConnectorAddressing returns the tunnel, loopback and underlay
+// addressing of the existing connectors and the overlay IP reservations.
+func getConnectorAddressing(client *alkira.AlkiraClient) ([]netip.Prefix, error) {
+	var values []string
+
+	var ipsecAdv []alkira.ConnectorAdvIPSec
+	if err := getAllForAddressing(alkira.NewConnectorAdvIPSec(client), "IPSec advanced connectors", &ipsecAdv); err != nil {
+		return nil, err
+	}
+	for _, c := range ipsecAdv {
+		for _, g := range c.Gateways {
+			if g == nil {
+				continue
+			}
+			for _, t := range g.Tunnels {
+				if t != nil {
+					values = append(values, t.CustomerEnd.OverlayIp)
+				}
+			}
+		}
+	}
+
+	var awsDx []alkira.ConnectorAwsDirectConnect
+	if err := getAllForAddressing(alkira.NewConnectorAwsDirectConnect(client), "AWS Direct Connect connectors", &awsDx); err != nil {
+		return nil, err
+	}
+	for _, c := range awsDx {
+		values = append(values, c.LoopbackPrefixes...)
+		for _, i := range c.Instances {
+			values = append(values, i.UnderlayPrefix, i.AwsUnderlayIp, i.OnPremUnderlayIp)
+			for _, s := range i.SegmentOptions {
+				values = append(values, s.CustomerLoopbackIp, s.AlkLoopbackIp1, s.AlkLoopbackIp2, s.LoopbackSubnet)
+			}
+		}
+	}
+
+	var gcpInterconnect []alkira.ConnectorGcpInterconnect
+	if err := getAllForAddressing(alkira.NewConnectorGcpInterconnect(client), "GCP Interconnect connectors", &gcpInterconnect); err != nil {
+		return nil, err
+	}
+	for _, c := range gcpInterconnect {
+		values = append(values, c.LoopbackPrefixes...)
+		for _, i := range c.Instances {
+			for _, s := range i.SegmentOptions {
+				for _, g := range s.CustomerGateways {
+					values = append(values, g.LoopbackIp)
+				}
+			}
+		}
+	}
+
+	var prolexic []alkira.ConnectorAkamaiProlexic
+	if err := getAllForAddressing(alkira.NewConnectorAkamaiProlexic(client), "Akamai Prolexic connectors", &prolexic); err != nil {
+		return nil, err
+	}
+	for _, c := range prolexic {
+		for _, o := range c.OverlayConfiguration {
+			for _, t := range o.TunnelIps {
+				values = append(values, t.AlkiraOverlayTunnelIp, t.AkamaiOverlayTunnelIp)
+			}
+		}
+	}
+
+	var reservations []alkira.IPReservation
+	if err := getAllForAddressing(alkira.NewIPReservation(client), "IP reservations", &reservations); err != nil {
+		return nil, err
+	}
+	for _, r := range reservations {
+		if r.Prefix == "" {
+			continue
+		}
+		if r.PrefixLen != 0 && !strings.Contains(r.Prefix, "/") {
+			values = append(values, fmt.Sprintf("%s/%d", r.Prefix, r.PrefixLen))
+		} else {
+			values = append(values, r.Prefix)
+		}
+	}
+
+	var prefixes []netip.Prefix
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		// Addressing that doesn't parse, e.g. a hostname, can't collide
+		// with a pool.
+		if p, err := parsePrefixOrAddr(v); err == nil && p.Addr().Is4() {
+			prefixes = append(prefixes, p.Masked())
+		}
+	}
+
+	return prefixes, nil
+}
+
+func getAllForAddressing[T any](api *alkira.AlkiraAPI[T], kind string, out *[]T) error {
+	data, err := api.GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", kind, err)
+	}
+
+	if err := json.Unmarshal([]byte(data), out); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", kind, err)
+	}
+
+	return nil
+}
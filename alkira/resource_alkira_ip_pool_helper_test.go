@@ -0,0 +1,191 @@
+package alkira
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIpPoolAllocate(t *testing.T) {
+	pool, err := newIpPool("10.100.0.0/24", 30, nil)
+	require.NoError(t, err)
+	require.Equal(t, 64, pool.size())
+
+	first, err := pool.allocate([]string{"branch-1", "branch-2", "branch-3"}, nil)
+	require.NoError(t, err)
+	require.Len(t, first, 3)
+
+	seen := map[string]bool{}
+	for _, prefix := range first {
+		p := netip.MustParsePrefix(prefix)
+		assert.Equal(t, 30, p.Bits())
+		assert.True(t, pool.cidr.Contains(p.Addr()))
+		assert.False(t, seen[prefix], "%s allocated twice", prefix)
+		seen[prefix] = true
+	}
+
+	// The order of the names doesn't matter.
+	reordered, err := pool.allocate([]string{"branch-3", "branch-1", "branch-2"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, first, reordered)
+
+	// Removing and adding names keeps the other blocks.
+	next, err := pool.allocate([]string{"branch-3", "branch-1", "branch-4"}, first)
+	require.NoError(t, err)
+	assert.Equal(t, first["branch-1"], next["branch-1"])
+	assert.Equal(t, first["branch-3"], next["branch-3"])
+	assert.NotContains(t, next, "branch-2")
+	assert.NotEqual(t, next["branch-1"], next["branch-4"])
+	assert.NotEqual(t, next["branch-3"], next["branch-4"])
+}
+
+func TestIpPoolAllocateExcluded(t *testing.T) {
+	excluded := []netip.Prefix{
+		netip.MustParsePrefix("10.100.0.0/30"),
+		netip.MustParsePrefix("10.100.0.5/32"),
+	}
+
+	pool, err := newIpPool("10.100.0.0/29", 31, excluded)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pool.available(nil))
+
+	allocated, err := pool.allocate([]string{"a"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "10.100.0.6/31"}, allocated)
+	assert.Equal(t, 0, pool.available(allocated))
+
+	_, err = pool.allocate([]string{"a", "b"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no free /31 left in 10.100.0.0/29")
+}
+
+func TestIpPoolExcludedSlots(t *testing.T) {
+	excluded := []netip.Prefix{
+		netip.MustParsePrefix("10.100.0.20/30"),
+		netip.MustParsePrefix("10.100.0.16/29"),
+		netip.MustParsePrefix("10.100.0.33/32"),
+		netip.MustParsePrefix("10.100.0.36/30"),
+		netip.MustParsePrefix("10.99.0.0/16"),
+		netip.MustParsePrefix("10.100.0.0/16"),
+	}
+
+	pool, err := newIpPool("10.100.0.0/26", 30, excluded[:5])
+	require.NoError(t, err)
+	assert.Equal(t, []slotRange{{first: 4, last: 5}, {first: 8, last: 9}}, pool.excludedSlots())
+
+	// The whole pool is excluded.
+	pool.excluded = excluded
+	assert.Equal(t, []slotRange{{first: 0, last: 15}}, pool.excludedSlots())
+	assert.Equal(t, 0, pool.available(nil))
+}
+
+func TestIpPoolLargeExcluded(t *testing.T) {
+	var excluded []netip.Prefix
+	for i := 0; i < 200; i++ {
+		excluded = append(excluded, netip.PrefixFrom(netip.AddrFrom4([4]byte{10, byte(i), 0, 0}), 16))
+	}
+	excluded = append(excluded, netip.MustParsePrefix("10.0.0.0/15"), netip.MustParsePrefix("192.168.0.0/16"))
+
+	pool, err := newIpPool("10.0.0.0/8", 31, excluded)
+	require.NoError(t, err)
+	assert.Equal(t, 1<<23-200<<15, pool.available(nil))
+
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	allocated, err := pool.allocate(names, nil)
+	require.NoError(t, err)
+	require.Len(t, allocated, len(names))
+
+	for name, prefix := range allocated {
+		assert.False(t, pool.isExcluded(netip.MustParsePrefix(prefix)), "%s got excluded %s", name, prefix)
+	}
+	assert.Equal(t, 1<<23-200<<15-len(names), pool.available(allocated))
+}
+
+func TestIpPoolAllocateInvalidCurrent(t *testing.T) {
+	pool, err := newIpPool("10.100.0.0/24", 30, nil)
+	require.NoError(t, err)
+
+	_, err = pool.allocate([]string{"a"}, map[string]string{"a": "10.200.0.0/30"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `allocation "a"`)
+}
+
+func TestNewIpPool(t *testing.T) {
+	_, err := newIpPool("10.100.0.0/31", 30, nil)
+	assert.Error(t, err)
+
+	_, err = newIpPool("2001:db8::/64", 30, nil)
+	assert.Error(t, err)
+
+	pool, err := newIpPool("10.100.0.7/29", 30, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.100.0.0/29", pool.cidr.String())
+}
+
+func TestGetConnectorAddressing(t *testing.T) {
+	responses := map[string]string{
+		"adv-ipsec-connectors":        `[{"gateways":[{"tunnels":[{"customerEnd":{"overlayIp":"169.254.10.2"}}]}]}]`,
+		"directconnectconnectors":     `[{"loopbackPrefixes":["172.16.0.0/29"],"instances":[{"underlayPrefix":"169.254.20.0/30"}]}]`,
+		"gcp-interconnect-connectors": `[{"loopbackPrefixes":[],"instances":[{"segmentOptions":[{"customerGateways":[{"loopbackIp":"172.16.1.1"}]}]}]}]`,
+		"akamai-prolexic-connectors":  `[{"overlayConfiguration":[{"tunnelIps":[{"alkiraOverlayTunnelIp":"192.168.100.1","akamaiOverlayTunnelIp":"not-an-ip"}]}]}]`,
+		"ip-reservations":             `[{"prefix":"169.254.30.0","prefixLen":30},{"prefix":""}]`,
+	}
+
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		for suffix, body := range responses {
+			if strings.HasSuffix(r.URL.Path, "/"+suffix) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(body))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	prefixes, err := getConnectorAddressing(client)
+	require.NoError(t, err)
+
+	var got []string
+	for _, p := range prefixes {
+		got = append(got, p.String())
+	}
+
+	assert.ElementsMatch(t, []string{
+		"169.254.10.2/32",
+		"172.16.0.0/29",
+		"169.254.20.0/30",
+		"172.16.1.1/32",
+		"192.168.100.1/32",
+		"169.254.30.0/30",
+	}, got)
+
+	pool, err := newIpPool("169.254.0.0/16", 30, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"169.254.10.2/32", "169.254.20.0/30", "169.254.30.0/30"}, pool.overlapping(prefixes))
+}
+
+func TestIpPoolBlockHosts(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"10.0.0.4/30", []string{"10.0.0.5", "10.0.0.6"}},
+		{"10.0.0.4/31", []string{"10.0.0.4", "10.0.0.5"}},
+		{"10.0.0.8/29", []string{"10.0.0.9", "10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.13", "10.0.0.14"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got, err := ipPoolBlockHosts(netip.MustParsePrefix(tt.prefix))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ipPoolBlockHosts(netip.MustParsePrefix("10.0.0.0/24"))
+	assert.Error(t, err)
+}
@@ -260,7 +260,7 @@ func resourcePolicyNatRule(ctx context.Context, d *schema.ResourceData, m interf
 	api := alkira.NewNatRule(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generatePolicyNatRuleRequest(d)
+	request, err := generatePolicyNatRuleRequest(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -346,7 +346,7 @@ func resourcePolicyNatRuleUpdate(ctx context.Context, d *schema.ResourceData, m
 	api := alkira.NewNatRule(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generatePolicyNatRuleRequest(d)
+	request, err := generatePolicyNatRuleRequest(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -1,17 +1,20 @@
 package alkira
 
 import (
-	"log"
+	"context"
 	"strings"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 // expandPolicyNatRuleMatch expand "match" block for generating request
-func expandPolicyNatRuleMatch(in *schema.Set) *alkira.NatRuleMatch {
+func expandPolicyNatRuleMatch(ctx context.Context, in *schema.Set) *alkira.NatRuleMatch {
 	if in == nil || in.Len() == 0 || in.Len() > 1 {
-		log.Printf("[ERROR] invalid match section (%d)", in.Len())
+		tflog.SubsystemError(ctx, logSubsystemSchema, "invalid match section", map[string]interface{}{
+			"count": in.Len(),
+		})
 		return nil
 	}
 
@@ -48,9 +51,11 @@ func expandPolicyNatRuleMatch(in *schema.Set) *alkira.NatRuleMatch {
 }
 
 // expandPolicyNatRuleAction expand "action" block for generating request
-func expandPolicyNatRuleAction(in *schema.Set) *alkira.NatRuleAction {
+func expandPolicyNatRuleAction(ctx context.Context, in *schema.Set) *alkira.NatRuleAction {
 	if in == nil || in.Len() == 0 || in.Len() > 1 {
-		log.Printf("[ERROR] invalid action section (%d)", in.Len())
+		tflog.SubsystemError(ctx, logSubsystemSchema, "invalid action section", map[string]interface{}{
+			"count": in.Len(),
+		})
 		return nil
 	}
 
@@ -234,10 +239,10 @@ func setNatRuleActionOptions(a alkira.NatRuleAction, d *schema.ResourceData) {
 }
 
 // generatePolicyNatRuleRequest generate request
-func generatePolicyNatRuleRequest(d *schema.ResourceData) (*alkira.NatPolicyRule, error) {
+func generatePolicyNatRuleRequest(ctx context.Context, d *schema.ResourceData) (*alkira.NatPolicyRule, error) {
 
-	match := expandPolicyNatRuleMatch(d.Get("match").(*schema.Set))
-	action := expandPolicyNatRuleAction(d.Get("action").(*schema.Set))
+	match := expandPolicyNatRuleMatch(ctx, d.Get("match").(*schema.Set))
+	action := expandPolicyNatRuleAction(ctx, d.Get("action").(*schema.Set))
 
 	request := &alkira.NatPolicyRule{
 		Name:        d.Get("name").(string),
@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -18,7 +19,7 @@ func TestPolicyNatRuleExpandMatchArrays(t *testing.T) {
 	f := schema.HashResource(r)
 	s := schema.NewSet(f, mArr)
 
-	actual := expandPolicyNatRuleMatch(s)
+	actual := expandPolicyNatRuleMatch(context.Background(), s)
 
 	require.Equal(t, convertTypeListToStringList(expectedStrArr), actual.SourcePrefixes)
 	require.Equal(t, convertTypeListToIntList(expectedIntArr), actual.SourcePrefixListIds)
@@ -40,7 +41,7 @@ func TestPolicyNatRuleExpandNatRuleActionArrays(t *testing.T) {
 	f := schema.HashResource(r)
 	s := schema.NewSet(f, mArr)
 
-	actual := expandPolicyNatRuleAction(s)
+	actual := expandPolicyNatRuleAction(context.Background(), s)
 
 	//Src array validations
 	require.Equal(t,
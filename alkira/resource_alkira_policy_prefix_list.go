@@ -7,6 +7,7 @@ import (
 	"github.com/alkiranet/alkira-client-go/alkira"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 // prefixHash computes a hash for a "prefix" block in a policy prefix list.
@@ -79,7 +80,11 @@ func resourceAlkiraPolicyPrefixList() *schema.Resource {
 				d.SetNew("provision_state", "SUCCESS")
 			}
 
-			return nil
+			if err := diffPrefixesSource(d); err != nil {
+				return err
+			}
+
+			return diffPrefixListAggregation(ctx, d)
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: importWithReadValidation(resourcePolicyPrefixListRead),
@@ -108,7 +113,7 @@ func resourceAlkiraPolicyPrefixList() *schema.Resource {
 				Optional:      true,
 				Deprecated:    "Use the 'prefix' block instead",
 				Elem:          &schema.Schema{Type: schema.TypeString},
-				ConflictsWith: []string{"prefix"},
+				ConflictsWith: []string{"prefix", "prefixes_source"},
 			},
 			"prefix": {
 				Description: "Prefix with description. This new block should " +
@@ -132,6 +137,121 @@ func resourceAlkiraPolicyPrefixList() *schema.Resource {
 					},
 				},
 			},
+			"prefixes_source": {
+				Description: "Prefixes parsed from a document, e.g. a CSV " +
+					"export, an AWS managed prefix list or an IPAM feed " +
+					"read with `file()`. The prefixes are validated, " +
+					"deduplicated and added to the `prefix` blocks, which " +
+					"win when both have a prefix.",
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"prefixes"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"format": {
+							Description: "The format of `content`, either " +
+								"`csv`, `json` or `txt`. `txt` has one prefix " +
+								"per line optionally followed by its " +
+								"description. `csv` has the prefix in the " +
+								"first column and the description in the " +
+								"second one, unless a header names them, " +
+								"e.g. `cidr,description`. `json` is a list " +
+								"of prefixes or of objects with a `cidr`, " +
+								"`prefix` or `ip_prefix` and a `description`, " +
+								"at the top level or in `Entries` or " +
+								"`prefixes`. Addresses are taken as host " +
+								"prefixes.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"csv", "json", "txt"}, false),
+						},
+						"content": {
+							Description: "The document to parse.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"aggregate": {
+							Description: "Whether to drop the prefixes " +
+								"contained in others and merge adjacent " +
+								"prefixes into their supernet. Default " +
+								"value is `false`.",
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"source_prefix": {
+				Description: "The prefixes of `prefixes_source`, so plans " +
+					"show the entries added and removed rather than the " +
+					"whole document.",
+				Type:     schema.TypeSet,
+				Computed: true,
+				Set:      prefixHash,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cidr": {
+							Description: "The network prefix in CIDR notation.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"description": {
+							Description: "Description for the prefix.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"aggregate": {
+				Description: "Whether to summarize the list before sending " +
+					"it, dropping the prefixes and ranges contained in " +
+					"others and merging adjacent ones. `prefix` entries " +
+					"are summarized as addresses, so only enable it for " +
+					"lists matching traffic rather than exact routes. " +
+					"`prefix_range` entries are summarized as the " +
+					"prefixes they match, respecting `ge` and `le`. The " +
+					"configuration is kept as written and `aggregation` " +
+					"reports what was merged. Default value is `false`.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"aggregation": {
+				Description: "The prefixes and ranges sent in place of " +
+					"others when `aggregate` is enabled.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"prefix": {
+							Description: "The prefix sent.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"ge": {
+							Description: "The `ge` of the range sent, `0` for a prefix.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"le": {
+							Description: "The `le` of the range sent, `0` for a prefix.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"absorbed": {
+							Description: "The prefixes and ranges of the " +
+								"configuration it replaces, e.g. " +
+								"`10.1.0.0/24` or `10.2.0.0/16 ge 24 le 28`.",
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
 			"prefix_range": {
 				Description: "A valid prefix range that could be used to " +
 					"define a prefix of type `ROUTE`.",
@@ -179,7 +299,7 @@ func resourcePolicyPrefixList(ctx context.Context, d *schema.ResourceData, m int
 	api := alkira.NewPolicyPrefixList(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generatePolicyPrefixListRequest(d)
+	request, err := generatePolicyPrefixListRequest(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -245,6 +365,15 @@ func resourcePolicyPrefixListRead(ctx context.Context, d *schema.ResourceData, m
 	d.Set("name", list.Name)
 	d.Set("description", list.Description)
 
+	// The state keeps the configuration the aggregate was sent for.
+	if readAggregatedPrefixList(ctx, d, list) {
+		if client.Provision && provState != "" {
+			d.Set("provision_state", provState)
+		}
+
+		return nil
+	}
+
 	// Deprecated: populate "prefixes" when user's config uses the old field.
 	// Remove this block when "prefixes" is removed from schema.
 	usingDeprecatedField := false
@@ -272,8 +401,9 @@ func resourcePolicyPrefixListRead(ctx context.Context, d *schema.ResourceData, m
 			d.Set("prefixes", schema.NewSet(schema.HashString, nil))
 		}
 		d.Set("prefix", schema.NewSet(prefixHash, nil))
+		d.Set("source_prefix", schema.NewSet(prefixHash, nil))
 	} else {
-		setPrefix(d, list.Prefixes, list.PrefixDetails)
+		setPrefixWithSource(d, list.Prefixes, list.PrefixDetails)
 	}
 
 	setPrefixRanges(d, list.PrefixRanges)
@@ -292,7 +422,7 @@ func resourcePolicyPrefixListUpdate(ctx context.Context, d *schema.ResourceData,
 	api := alkira.NewPolicyPrefixList(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generatePolicyPrefixListRequest(d)
+	request, err := generatePolicyPrefixListRequest(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -1,9 +1,13 @@
 package alkira
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -26,10 +30,10 @@ func setPrefixRanges(d *schema.ResourceData, r []alkira.PolicyPrefixListRange) {
 
 // expandPrefixListPrefixRanges expand block "prefix_range" to
 // construct payload
-func expandPrefixListPrefixRanges(in *schema.Set) ([]alkira.PolicyPrefixListRange, error) {
+func expandPrefixListPrefixRanges(ctx context.Context, in *schema.Set) ([]alkira.PolicyPrefixListRange, error) {
 
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] prefix_ranges is empty.")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty prefix_range")
 		return nil, nil
 	}
 
@@ -115,10 +119,69 @@ func setPrefix(d *schema.ResourceData, prefixes []string, details map[string]*al
 	d.Set("prefix", set)
 }
 
+// setPrefixWithSource sets the prefixes read from the API, splitting
+// the ones of the prefix blocks from the ones of prefixes_source.
+func setPrefixWithSource(d *schema.ResourceData, prefixes []string, details map[string]*alkira.PolicyPrefixListDetails) {
+	if source, _ := d.Get("prefixes_source").([]interface{}); len(source) == 0 {
+		setPrefix(d, prefixes, details)
+		d.Set("source_prefix", schema.NewSet(prefixHash, nil))
+		return
+	}
+
+	blocks := prefixBlockCidrs(d.Get("prefix").(*schema.Set))
+
+	var prefix, source []string
+	for _, p := range prefixes {
+		if blocks[p] {
+			prefix = append(prefix, p)
+		} else {
+			source = append(source, p)
+		}
+	}
+
+	setPrefix(d, prefix, details)
+
+	sourcePrefix := schema.NewSet(prefixHash, nil)
+	for _, p := range source {
+		desc := ""
+		if details[p] != nil {
+			desc = details[p].Description
+		}
+		sourcePrefix.Add(map[string]interface{}{
+			"cidr":        p,
+			"description": desc,
+		})
+	}
+
+	d.Set("source_prefix", sourcePrefix)
+}
+
+func prefixesSourceKnown(d *schema.ResourceDiff) bool {
+	return d.NewValueKnown("prefixes_source.0.format") &&
+		d.NewValueKnown("prefixes_source.0.content") &&
+		d.NewValueKnown("prefixes_source.0.aggregate")
+}
+
+// diffPrefixesSource plans source_prefix from prefixes_source, so the
+// plan shows the prefixes added and removed.
+func diffPrefixesSource(d *schema.ResourceDiff) error {
+	if !prefixesSourceKnown(d) || !d.NewValueKnown("prefix") {
+		return d.SetNewComputed("source_prefix")
+	}
+
+	entries, err := expandPrefixesSource(d.Get("prefixes_source").([]interface{}),
+		prefixBlockCidrs(d.Get("prefix").(*schema.Set)))
+	if err != nil {
+		return err
+	}
+
+	return d.SetNew("source_prefix", flattenPrefixEntries(entries))
+}
+
 // generatePolicyPrefixListRequest
-func generatePolicyPrefixListRequest(d *schema.ResourceData) (*alkira.PolicyPrefixList, error) {
+func generatePolicyPrefixListRequest(ctx context.Context, d *schema.ResourceData) (*alkira.PolicyPrefixList, error) {
 
-	prefixRanges, err := expandPrefixListPrefixRanges(d.Get("prefix_range").(*schema.Set))
+	prefixRanges, err := expandPrefixListPrefixRanges(ctx, d.Get("prefix_range").(*schema.Set))
 
 	if err != nil {
 		return nil, err
@@ -144,6 +207,18 @@ func generatePolicyPrefixListRequest(d *schema.ResourceData) (*alkira.PolicyPref
 		prefixes = convertTypeSetToStringList(d.Get("prefixes").(*schema.Set))
 	} else {
 		prefixes, prefixDetailsMap = expandPrefixListPrefixes(d)
+
+		source, _ := d.Get("prefixes_source").([]interface{})
+
+		entries, err := expandPrefixesSource(source, prefixBlockCidrs(d.Get("prefix").(*schema.Set)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			prefixes = append(prefixes, e.cidr.String())
+			prefixDetailsMap[e.cidr.String()] = &alkira.PolicyPrefixListDetails{Description: e.description}
+		}
 	}
 
 	list := &alkira.PolicyPrefixList{
@@ -154,5 +229,216 @@ func generatePolicyPrefixListRequest(d *schema.ResourceData) (*alkira.PolicyPref
 		PrefixRanges:  prefixRanges,
 	}
 
+	if aggregate, _ := d.Get("aggregate").(bool); aggregate {
+		if _, err := aggregatePrefixList(list); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// aggregatePrefixList summarizes the prefixes of a list as addresses
+// and its prefix ranges as the prefixes they match, see summarizeCidrs.
+// Prefixes and ranges kept as is keep their description. It returns
+// the aggregates of both.
+func aggregatePrefixList(list *alkira.PolicyPrefixList) ([]cidrAggregate, error) {
+	var prefixes []cidrRange
+	for _, p := range list.Prefixes {
+		prefix, err := parseNetworkPrefix(p)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate: %w", err)
+		}
+		prefixes = append(prefixes, cidrRange{prefix: prefix})
+	}
+
+	// Ranges of addresses are always valid.
+	prefixAggregates, _ := summarizeCidrs(prefixes, true)
+
+	details := make(map[string]*alkira.PolicyPrefixListDetails, len(prefixAggregates))
+	list.Prefixes = nil
+	for _, a := range prefixAggregates {
+		p := a.prefix.String()
+		list.Prefixes = append(list.Prefixes, p)
+
+		if list.PrefixDetails[p] != nil {
+			details[p] = list.PrefixDetails[p]
+		} else {
+			details[p] = &alkira.PolicyPrefixListDetails{}
+		}
+	}
+	list.PrefixDetails = details
+
+	var ranges []cidrRange
+	originals := make(map[cidrRange]alkira.PolicyPrefixListRange)
+	for _, r := range list.PrefixRanges {
+		prefix, err := parseNetworkPrefix(r.Prefix)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate: %w", err)
+		}
+
+		cr := cidrRange{prefix: prefix, ge: r.Ge, le: r.Le}
+		if _, ok := originals[cr]; !ok {
+			originals[cr] = r
+		}
+		ranges = append(ranges, cr)
+	}
+
+	rangeAggregates, err := summarizeCidrs(ranges, false)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate: %w", err)
+	}
+
+	list.PrefixRanges = nil
+	for _, a := range rangeAggregates {
+		r, ok := originals[a.cidrRange]
+		if !ok {
+			r = alkira.PolicyPrefixListRange{Prefix: a.prefix.String(), Ge: a.ge, Le: a.le}
+		}
+		list.PrefixRanges = append(list.PrefixRanges, r)
+	}
+
+	return append(prefixAggregates, rangeAggregates...), nil
+}
+
+// flattenCidrAggregation returns the aggregates covering other inputs
+// with the inputs they absorbed.
+func flattenCidrAggregation(aggregates []cidrAggregate) []map[string]interface{} {
+	result := []map[string]interface{}{}
+
+	for _, a := range aggregates {
+		absorbed := a.absorbed()
+		if len(absorbed) == 0 {
+			continue
+		}
+
+		inputs := make([]string, len(absorbed))
+		for i, r := range absorbed {
+			inputs[i] = r.String()
+		}
+
+		result = append(result, map[string]interface{}{
+			"prefix":   a.prefix.String(),
+			"ge":       a.ge,
+			"le":       a.le,
+			"absorbed": inputs,
+		})
+	}
+
+	return result
+}
+
+// prefixListOf returns the prefix list given by prefix blocks, the
+// entries of prefixes_source and prefix_range blocks.
+func prefixListOf(ctx context.Context, prefix *schema.Set, source []prefixEntry, prefixRange *schema.Set) (*alkira.PolicyPrefixList, error) {
+	ranges, err := expandPrefixListPrefixRanges(ctx, prefixRange)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &alkira.PolicyPrefixList{
+		PrefixDetails: make(map[string]*alkira.PolicyPrefixListDetails),
+		PrefixRanges:  ranges,
+	}
+
+	if prefix != nil {
+		for _, p := range prefix.List() {
+			m := p.(map[string]interface{})
+			cidr := m["cidr"].(string)
+			description, _ := m["description"].(string)
+
+			list.Prefixes = append(list.Prefixes, cidr)
+			list.PrefixDetails[cidr] = &alkira.PolicyPrefixListDetails{Description: description}
+		}
+	}
+
+	for _, e := range source {
+		list.Prefixes = append(list.Prefixes, e.cidr.String())
+		list.PrefixDetails[e.cidr.String()] = &alkira.PolicyPrefixListDetails{Description: e.description}
+	}
+
 	return list, nil
 }
+
+// diffPrefixListAggregation plans the aggregation report of a prefix
+// list with aggregate enabled.
+func diffPrefixListAggregation(ctx context.Context, d *schema.ResourceDiff) error {
+	if !d.NewValueKnown("aggregate") || !d.NewValueKnown("prefix") || !d.NewValueKnown("prefix_range") ||
+		!prefixesSourceKnown(d) {
+		return d.SetNewComputed("aggregation")
+	}
+
+	if !d.Get("aggregate").(bool) {
+		return d.SetNew("aggregation", []interface{}{})
+	}
+
+	source, err := expandPrefixesSource(d.Get("prefixes_source").([]interface{}),
+		prefixBlockCidrs(d.Get("prefix").(*schema.Set)))
+	if err != nil {
+		return err
+	}
+
+	list, err := prefixListOf(ctx, d.Get("prefix").(*schema.Set), source, d.Get("prefix_range").(*schema.Set))
+	if err != nil {
+		return err
+	}
+
+	aggregates, err := aggregatePrefixList(list)
+	if err != nil {
+		return err
+	}
+
+	return d.SetNew("aggregation", flattenCidrAggregation(aggregates))
+}
+
+// readAggregatedPrefixList sets the aggregation report of a prefix
+// list with aggregate enabled. It returns true when the list holds the
+// aggregate of the prefixes and ranges of the state, which are then
+// kept as is rather than replaced by the aggregate.
+func readAggregatedPrefixList(ctx context.Context, d *schema.ResourceData, list *alkira.PolicyPrefixList) bool {
+	d.Set("aggregation", []interface{}{})
+
+	if aggregate, _ := d.Get("aggregate").(bool); !aggregate {
+		return false
+	}
+
+	var source []prefixEntry
+	if v, ok := d.Get("source_prefix").(*schema.Set); ok {
+		for _, p := range v.List() {
+			m := p.(map[string]interface{})
+			prefix, err := parseNetworkPrefix(m["cidr"].(string))
+			if err != nil {
+				return false
+			}
+			source = append(source, prefixEntry{cidr: prefix, description: m["description"].(string)})
+		}
+	}
+
+	expected, err := prefixListOf(ctx, d.Get("prefix").(*schema.Set), source, d.Get("prefix_range").(*schema.Set))
+	if err != nil {
+		return false
+	}
+
+	aggregates, err := aggregatePrefixList(expected)
+	if err != nil || !samePrefixListEntries(expected, list) {
+		return false
+	}
+
+	d.Set("aggregation", flattenCidrAggregation(aggregates))
+	return true
+}
+
+// samePrefixListEntries returns whether two lists have the same
+// prefixes and prefix ranges, in any order.
+func samePrefixListEntries(a, b *alkira.PolicyPrefixList) bool {
+	entries := func(l *alkira.PolicyPrefixList) string {
+		result := append([]string{}, l.Prefixes...)
+		for _, r := range l.PrefixRanges {
+			result = append(result, fmt.Sprintf("%s ge %d le %d", r.Prefix, r.Ge, r.Le))
+		}
+		sort.Strings(result)
+		return strings.Join(result, ",")
+	}
+
+	return entries(a) == entries(b)
+}
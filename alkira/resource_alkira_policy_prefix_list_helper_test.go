@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"testing"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
@@ -65,7 +66,7 @@ func TestExpandPrefixListPrefixRanges(t *testing.T) {
 			if tt.input != nil {
 				inputSet = schema.NewSet(prefixRangeHash, tt.input)
 			}
-			result, err := expandPrefixListPrefixRanges(inputSet)
+			result, err := expandPrefixListPrefixRanges(context.Background(), inputSet)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -696,7 +697,7 @@ func TestGenerateRequest_PrefixBlockPath(t *testing.T) {
 			map[string]interface{}{"cidr": "192.168.0.0/16", "description": "private"},
 		})
 
-		result, err := generatePolicyPrefixListRequest(d)
+		result, err := generatePolicyPrefixListRequest(context.Background(), d)
 		assert.NoError(t, err)
 		assert.Equal(t, "test-list", result.Name)
 		assert.Equal(t, "test desc", result.Description)
@@ -716,7 +717,7 @@ func TestGenerateRequest_PrefixBlockPath(t *testing.T) {
 		d.Set("name", "empty-list")
 		d.Set("description", "")
 
-		result, err := generatePolicyPrefixListRequest(d)
+		result, err := generatePolicyPrefixListRequest(context.Background(), d)
 		assert.NoError(t, err)
 		assert.Equal(t, "empty-list", result.Name)
 		assert.Nil(t, result.Prefixes)
@@ -739,7 +740,7 @@ func TestGenerateRequest_PrefixBlockPath(t *testing.T) {
 			},
 		})
 
-		result, err := generatePolicyPrefixListRequest(d)
+		result, err := generatePolicyPrefixListRequest(context.Background(), d)
 		assert.NoError(t, err)
 		assert.Len(t, result.Prefixes, 1)
 		assert.Len(t, result.PrefixRanges, 1)
@@ -748,3 +749,60 @@ func TestGenerateRequest_PrefixBlockPath(t *testing.T) {
 		assert.Equal(t, 16, result.PrefixRanges[0].Ge)
 	})
 }
+
+func TestAggregatePrefixList(t *testing.T) {
+	list := &alkira.PolicyPrefixList{
+		Prefixes: []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.1.128/25", "192.168.0.0/16"},
+		PrefixDetails: map[string]*alkira.PolicyPrefixListDetails{
+			"10.0.0.0/24":    {Description: "a"},
+			"192.168.0.0/16": {Description: "lan"},
+		},
+		PrefixRanges: []alkira.PolicyPrefixListRange{
+			{Prefix: "172.16.0.0/12", Ge: 16, Description: "dc"},
+			{Prefix: "172.16.0.0/16", Ge: 20, Le: 24},
+		},
+	}
+
+	aggregates, err := aggregatePrefixList(list)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"10.0.0.0/23", "192.168.0.0/16"}, list.Prefixes)
+	assert.Equal(t, "", list.PrefixDetails["10.0.0.0/23"].Description)
+	assert.Equal(t, "lan", list.PrefixDetails["192.168.0.0/16"].Description)
+	assert.Equal(t, []alkira.PolicyPrefixListRange{
+		{Prefix: "172.16.0.0/12", Ge: 16, Description: "dc"},
+	}, list.PrefixRanges)
+
+	assert.Equal(t, []map[string]interface{}{
+		{"prefix": "10.0.0.0/23", "ge": 0, "le": 0, "absorbed": []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.1.128/25"}},
+		{"prefix": "172.16.0.0/12", "ge": 16, "le": 0, "absorbed": []string{"172.16.0.0/16 ge 20 le 24"}},
+	}, flattenCidrAggregation(aggregates))
+
+	_, err = aggregatePrefixList(&alkira.PolicyPrefixList{Prefixes: []string{"10.0.0.1/24"}})
+	assert.EqualError(t, err, "aggregate: 10.0.0.1/24 has host bits set, did you mean 10.0.0.0/24?")
+}
+
+func TestReadAggregatedPrefixList(t *testing.T) {
+	r := resourceAlkiraPolicyPrefixList()
+
+	d := r.TestResourceData()
+	d.Set("aggregate", true)
+	d.Set("prefix", []interface{}{
+		map[string]interface{}{"cidr": "10.0.0.0/24", "description": "a"},
+		map[string]interface{}{"cidr": "10.0.1.0/24", "description": "b"},
+	})
+
+	request, err := generatePolicyPrefixListRequest(context.Background(), d)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/23"}, request.Prefixes)
+
+	// The aggregate sent keeps the configured prefixes.
+	assert.True(t, readAggregatedPrefixList(context.Background(), d, request))
+	assert.Equal(t, 2, d.Get("prefix").(*schema.Set).Len())
+	assert.Equal(t, "10.0.0.0/23", d.Get("aggregation.0.prefix"))
+
+	// A list changed outside of Terraform is read as is.
+	request.Prefixes = append(request.Prefixes, "10.9.0.0/16")
+	assert.False(t, readAggregatedPrefixList(context.Background(), d, request))
+	assert.Empty(t, d.Get("aggregation"))
+}
@@ -2,8 +2,9 @@ package alkira
 
 import (
 	"context"
-	"log"
+	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -51,14 +52,16 @@ func resourcePolicyPrefixListV0() *schema.Resource {
 // (TypeList for prefix and prefix_range) to V1 (TypeSet). The flat-map
 // state keys change from positional indices (e.g. "prefix.0.cidr") to
 // hash-based keys (e.g. "prefix.<hash>.cidr").
-func resourcePolicyPrefixListStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
-	log.Printf("[DEBUG] Starting PolicyPrefixList state migration from V0 to V1 (TypeList -> TypeSet)")
+func resourcePolicyPrefixListStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	tflog.Debug(ctx, "Starting PolicyPrefixList state migration from V0 to V1 (TypeList -> TypeSet)")
 
 	// Migrate "prefix" from list to set
 	if v, ok := rawState["prefix"]; ok {
 		if prefixList, ok := v.([]interface{}); ok {
 			oldCount := len(prefixList)
-			log.Printf("[DEBUG] Migrating 'prefix' field: %d entries from TypeList to TypeSet", oldCount)
+			tflog.Debug(ctx, "migrating prefix from TypeList to TypeSet", map[string]interface{}{
+				"count": oldCount,
+			})
 
 			set := schema.NewSet(prefixHash, nil)
 			for i, item := range prefixList {
@@ -68,23 +71,34 @@ func resourcePolicyPrefixListStateUpgradeV0(_ context.Context, rawState map[stri
 						"description": m["description"],
 					}
 					set.Add(entry)
-					log.Printf("[DEBUG] Migrated prefix[%d]: cidr=%v, description=%v", i, m["cidr"], m["description"])
+					tflog.Debug(ctx, "migrated prefix entry", map[string]interface{}{
+						"index":       i,
+						"cidr":        m["cidr"],
+						"description": m["description"],
+					})
 				}
 			}
 			rawState["prefix"] = set.List()
-			log.Printf("[DEBUG] 'prefix' migration complete: %d entries migrated to %d set entries", oldCount, set.Len())
+			tflog.Debug(ctx, "migrated prefix", map[string]interface{}{
+				"count":     oldCount,
+				"set_count": set.Len(),
+			})
 		} else {
-			log.Printf("[DEBUG] 'prefix' field exists but is not a TypeList, skipping migration (type=%T)", v)
+			tflog.Debug(ctx, "prefix is not a TypeList, skipping migration", map[string]interface{}{
+				"type": fmt.Sprintf("%T", v),
+			})
 		}
 	} else {
-		log.Printf("[DEBUG] 'prefix' field not present in state, skipping migration")
+		tflog.Debug(ctx, "'prefix' field not present in state, skipping migration")
 	}
 
 	// Migrate "prefix_range" from list to set
 	if v, ok := rawState["prefix_range"]; ok {
 		if rangeList, ok := v.([]interface{}); ok {
 			oldCount := len(rangeList)
-			log.Printf("[DEBUG] Migrating 'prefix_range' field: %d entries from TypeList to TypeSet", oldCount)
+			tflog.Debug(ctx, "migrating prefix_range from TypeList to TypeSet", map[string]interface{}{
+				"count": oldCount,
+			})
 
 			set := schema.NewSet(prefixRangeHash, nil)
 			for i, item := range rangeList {
@@ -104,18 +118,29 @@ func resourcePolicyPrefixListStateUpgradeV0(_ context.Context, rawState map[stri
 						"ge":          ge,
 					}
 					set.Add(entry)
-					log.Printf("[DEBUG] Migrated prefix_range[%d]: prefix=%v, description=%v, le=%d, ge=%d", i, m["prefix"], m["description"], le, ge)
+					tflog.Debug(ctx, "migrated prefix_range entry", map[string]interface{}{
+						"index":       i,
+						"prefix":      m["prefix"],
+						"description": m["description"],
+						"le":          le,
+						"ge":          ge,
+					})
 				}
 			}
 			rawState["prefix_range"] = set.List()
-			log.Printf("[DEBUG] 'prefix_range' migration complete: %d entries migrated to %d set entries", oldCount, set.Len())
+			tflog.Debug(ctx, "migrated prefix_range", map[string]interface{}{
+				"count":     oldCount,
+				"set_count": set.Len(),
+			})
 		} else {
-			log.Printf("[DEBUG] 'prefix_range' field exists but is not a TypeList, skipping migration (type=%T)", v)
+			tflog.Debug(ctx, "prefix_range is not a TypeList, skipping migration", map[string]interface{}{
+				"type": fmt.Sprintf("%T", v),
+			})
 		}
 	} else {
-		log.Printf("[DEBUG] 'prefix_range' field not present in state, skipping migration")
+		tflog.Debug(ctx, "'prefix_range' field not present in state, skipping migration")
 	}
 
-	log.Printf("[INFO] PolicyPrefixList state migration from V0 to V1 completed successfully")
+	tflog.Info(ctx, "PolicyPrefixList state migration from V0 to V1 completed successfully")
 	return rawState, nil
 }
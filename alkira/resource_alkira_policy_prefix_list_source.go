@@ -0,0 +1,355 @@
+package alkira
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// prefixEntry is a prefix of a prefixes_source with its description.
+type prefixEntry struct {
+	cidr        netip.Prefix
+	description string
+}
+
+// prefixSourceCidrKeys and prefixSourceDescriptionKeys are the fields
+// of JSON objects and CSV headers holding the prefix and description,
+// e.g. the Cidr of AWS managed prefix list entries or the ip_prefix of
+// the AWS IP address ranges.
+var (
+	prefixSourceCidrKeys        = []string{"cidr", "prefix", "ip_prefix", "ipv6_prefix", "network"}
+	prefixSourceDescriptionKeys = []string{"description", "comment", "name"}
+)
+
+// parsePrefixesSource parses the content of a prefixes_source in the
+// given format into its entries, in order and with duplicates.
+func parsePrefixesSource(format, content string) ([]prefixEntry, error) {
+	switch format {
+	case "txt":
+		return parsePrefixesTxt(content)
+	case "csv":
+		return parsePrefixesCsv(content)
+	case "json":
+		return parsePrefixesJson(content)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// parsePrefixesTxt parses one prefix per line, optionally followed by
+// its description. Blank lines and lines starting with # are skipped.
+func parsePrefixesTxt(content string) ([]prefixEntry, error) {
+	var entries []prefixEntry
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		prefix, description := line, ""
+		if j := strings.IndexAny(line, " \t"); j >= 0 {
+			prefix, description = line[:j], strings.TrimSpace(line[j:])
+		}
+
+		cidr, err := parseNetworkPrefix(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		entries = append(entries, prefixEntry{cidr: cidr, description: description})
+	}
+
+	return entries, nil
+}
+
+// parsePrefixesCsv parses the rows of a CSV. The prefix is the first
+// column and the description the second one, unless a header names
+// the columns.
+func parsePrefixesCsv(content string) ([]prefixEntry, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	cidrColumn, descriptionColumn := 0, 1
+
+	var entries []prefixEntry
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if row == 1 {
+			if _, err := parseNetworkPrefix(record[0]); err != nil {
+				cidrColumn, descriptionColumn = -1, -1
+				for i, column := range record {
+					column = strings.ToLower(strings.TrimSpace(column))
+					if cidrColumn < 0 && stringInSlice(column, prefixSourceCidrKeys) {
+						cidrColumn = i
+					}
+					if descriptionColumn < 0 && stringInSlice(column, prefixSourceDescriptionKeys) {
+						descriptionColumn = i
+					}
+				}
+
+				if cidrColumn < 0 {
+					return nil, fmt.Errorf("row 1: no column named one of %s", strings.Join(prefixSourceCidrKeys, ", "))
+				}
+
+				continue
+			}
+		}
+
+		if cidrColumn >= len(record) {
+			return nil, fmt.Errorf("row %d: missing prefix", row)
+		}
+
+		cidr, err := parseNetworkPrefix(record[cidrColumn])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		entry := prefixEntry{cidr: cidr}
+		if descriptionColumn >= 0 && descriptionColumn < len(record) {
+			entry.description = strings.TrimSpace(record[descriptionColumn])
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// parsePrefixesJson parses a list of prefixes or of objects holding
+// them, either at the top level or in the Entries, prefixes and
+// ipv6_prefixes of the AWS managed prefix list and IP address ranges
+// documents.
+func parsePrefixesJson(content string) ([]prefixEntry, error) {
+	var document interface{}
+	if err := json.Unmarshal([]byte(content), &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var lists [][]interface{}
+	switch v := document.(type) {
+	case []interface{}:
+		lists = append(lists, v)
+	case map[string]interface{}:
+		for key, value := range v {
+			switch strings.ToLower(key) {
+			case "entries", "prefixes", "ipv6_prefixes":
+				if list, ok := value.([]interface{}); ok {
+					lists = append(lists, list)
+				}
+			}
+		}
+	}
+
+	if len(lists) == 0 {
+		return nil, fmt.Errorf("expected a list of prefixes or an object with Entries, prefixes or ipv6_prefixes")
+	}
+
+	var entries []prefixEntry
+	for _, list := range lists {
+		for i, item := range list {
+			entry, err := prefixEntryOf(item)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func prefixEntryOf(item interface{}) (prefixEntry, error) {
+	if s, ok := item.(string); ok {
+		cidr, err := parseNetworkPrefix(s)
+		return prefixEntry{cidr: cidr}, err
+	}
+
+	object, ok := item.(map[string]interface{})
+	if !ok {
+		return prefixEntry{}, fmt.Errorf("expected a prefix or an object")
+	}
+
+	fields := make(map[string]string, len(object))
+	for key, value := range object {
+		if s, ok := value.(string); ok {
+			fields[strings.ToLower(key)] = s
+		}
+	}
+
+	var entry prefixEntry
+
+	for _, key := range prefixSourceDescriptionKeys {
+		if v, ok := fields[key]; ok {
+			entry.description = v
+			break
+		}
+	}
+
+	for _, key := range prefixSourceCidrKeys {
+		if v, ok := fields[key]; ok {
+			cidr, err := parseNetworkPrefix(v)
+			entry.cidr = cidr
+			return entry, err
+		}
+	}
+
+	return entry, fmt.Errorf("no field named one of %s", strings.Join(prefixSourceCidrKeys, ", "))
+}
+
+// parseNetworkPrefix parses a prefix in CIDR notation, or an address as
+// a host prefix. Prefixes with host bits set are rejected.
+func parseNetworkPrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid prefix %q", s)
+	}
+
+	if prefix.Masked() != prefix {
+		return netip.Prefix{}, fmt.Errorf("%s has host bits set, did you mean %s?", prefix, prefix.Masked())
+	}
+
+	return prefix, nil
+}
+
+// dedupPrefixEntries removes the repeated prefixes, keeping the first
+// description given, and sorts them by address.
+func dedupPrefixEntries(entries []prefixEntry) []prefixEntry {
+	seen := make(map[netip.Prefix]int)
+	var result []prefixEntry
+
+	for _, e := range entries {
+		if i, ok := seen[e.cidr]; ok {
+			if result[i].description == "" {
+				result[i].description = e.description
+			}
+			continue
+		}
+
+		seen[e.cidr] = len(result)
+		result = append(result, e)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return prefixLess(result[i].cidr, result[j].cidr) })
+
+	return result
+}
+
+// prefixLess orders IPv4 before IPv6 prefixes, then by address and
+// then from the shortest to the longest prefix.
+func prefixLess(a, b netip.Prefix) bool {
+	if a.Addr().Is4() != b.Addr().Is4() {
+		return a.Addr().Is4()
+	}
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c < 0
+	}
+	return a.Bits() < b.Bits()
+}
+
+// aggregatePrefixEntries removes the prefixes contained in others and
+// merges adjacent prefixes into their common supernet. Prefixes kept
+// as is keep their description.
+func aggregatePrefixEntries(entries []prefixEntry) []prefixEntry {
+	descriptions := make(map[netip.Prefix]string, len(entries))
+	ranges := make([]cidrRange, len(entries))
+
+	for i, e := range entries {
+		if _, ok := descriptions[e.cidr]; !ok {
+			descriptions[e.cidr] = e.description
+		}
+		ranges[i] = cidrRange{prefix: e.cidr}
+	}
+
+	// Ranges of addresses are always valid.
+	aggregates, _ := summarizeCidrs(ranges, true)
+
+	result := make([]prefixEntry, len(aggregates))
+	for i, a := range aggregates {
+		result[i] = prefixEntry{cidr: a.prefix, description: descriptions[a.prefix]}
+	}
+
+	return result
+}
+
+// expandPrefixesSource returns the entries of the prefixes_source
+// block, parsed, deduplicated and aggregated when asked for. The
+// prefixes of the prefix blocks, given as exclude, are left out.
+func expandPrefixesSource(in []interface{}, exclude map[string]bool) ([]prefixEntry, error) {
+	if len(in) == 0 || in[0] == nil {
+		return nil, nil
+	}
+
+	source := in[0].(map[string]interface{})
+
+	entries, err := parsePrefixesSource(source["format"].(string), source["content"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("prefixes_source: %w", err)
+	}
+
+	entries = dedupPrefixEntries(entries)
+	if aggregate, _ := source["aggregate"].(bool); aggregate {
+		entries = aggregatePrefixEntries(entries)
+	}
+
+	var result []prefixEntry
+	for _, e := range entries {
+		if !exclude[e.cidr.String()] {
+			result = append(result, e)
+		}
+	}
+
+	return result, nil
+}
+
+// prefixBlockCidrs returns the prefixes of the prefix blocks.
+func prefixBlockCidrs(prefix *schema.Set) map[string]bool {
+	cidrs := make(map[string]bool)
+
+	if prefix == nil {
+		return cidrs
+	}
+
+	for _, p := range prefix.List() {
+		if cidr, ok := p.(map[string]interface{})["cidr"].(string); ok {
+			cidrs[cidr] = true
+		}
+	}
+
+	return cidrs
+}
+
+// flattenPrefixEntries returns the entries as the set of source_prefix.
+func flattenPrefixEntries(entries []prefixEntry) *schema.Set {
+	set := schema.NewSet(prefixHash, nil)
+
+	for _, e := range entries {
+		set.Add(map[string]interface{}{
+			"cidr":        e.cidr.String(),
+			"description": e.description,
+		})
+	}
+
+	return set
+}
@@ -0,0 +1,212 @@
+package alkira
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prefixEntryStrings(entries []prefixEntry) []string {
+	var result []string
+	for _, e := range entries {
+		s := e.cidr.String()
+		if e.description != "" {
+			s += " " + e.description
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func TestParsePrefixesSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "txt",
+			format:  "txt",
+			content: "# exported\n10.0.0.0/8 corporate network\n\n192.168.1.1\t gateway\n2001:db8::/32\n",
+			want:    []string{"10.0.0.0/8 corporate network", "192.168.1.1/32 gateway", "2001:db8::/32"},
+		},
+		{
+			name:    "txt host bits",
+			format:  "txt",
+			content: "10.0.0.0/8\n10.1.2.3/16\n",
+			wantErr: "line 2: 10.1.2.3/16 has host bits set, did you mean 10.1.0.0/16?",
+		},
+		{
+			name:    "csv without header",
+			format:  "csv",
+			content: "10.0.0.0/8,corporate\n172.16.0.0/12\n",
+			want:    []string{"10.0.0.0/8 corporate", "172.16.0.0/12"},
+		},
+		{
+			name:    "csv with header",
+			format:  "csv",
+			content: "site,Description,CIDR\nhq,\"head office, floor 2\",10.1.0.0/16\n",
+			want:    []string{"10.1.0.0/16 head office, floor 2"},
+		},
+		{
+			name:    "csv without prefix column",
+			format:  "csv",
+			content: "site,description\nhq,head office\n",
+			wantErr: "row 1: no column named one of cidr, prefix, ip_prefix, ipv6_prefix, network",
+		},
+		{
+			name:    "csv invalid prefix",
+			format:  "csv",
+			content: "cidr\n10.0.0.0/8\nbogus\n",
+			wantErr: `row 3: invalid prefix "bogus"`,
+		},
+		{
+			name:    "json list",
+			format:  "json",
+			content: `["10.0.0.0/8", {"prefix": "10.2.0.0/16", "description": "lab"}]`,
+			want:    []string{"10.0.0.0/8", "10.2.0.0/16 lab"},
+		},
+		{
+			name:    "json AWS managed prefix list",
+			format:  "json",
+			content: `{"Entries": [{"Cidr": "3.5.140.0/22", "Description": "s3"}]}`,
+			want:    []string{"3.5.140.0/22 s3"},
+		},
+		{
+			name:    "json AWS IP address ranges",
+			format:  "json",
+			content: `{"syncToken": "1", "prefixes": [{"ip_prefix": "3.2.34.0/26", "region": "af-south-1"}]}`,
+			want:    []string{"3.2.34.0/26"},
+		},
+		{
+			name:    "json without prefixes",
+			format:  "json",
+			content: `{"items": []}`,
+			wantErr: "expected a list of prefixes or an object with Entries, prefixes or ipv6_prefixes",
+		},
+		{
+			name:    "json object without prefix",
+			format:  "json",
+			content: `[{"name": "x"}]`,
+			wantErr: "entry 1: no field named one of cidr, prefix, ip_prefix, ipv6_prefix, network",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parsePrefixesSource(tt.format, tt.content)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, prefixEntryStrings(entries))
+		})
+	}
+}
+
+func TestAggregatePrefixEntries(t *testing.T) {
+	entries := func(cidrs ...string) []prefixEntry {
+		var result []prefixEntry
+		for _, c := range cidrs {
+			result = append(result, prefixEntry{cidr: netip.MustParsePrefix(c), description: "d " + c})
+		}
+		return dedupPrefixEntries(result)
+	}
+
+	tests := []struct {
+		name  string
+		input []prefixEntry
+		want  []string
+	}{
+		{
+			name:  "adjacent",
+			input: entries("10.0.1.0/24", "10.0.0.0/24"),
+			want:  []string{"10.0.0.0/23"},
+		},
+		{
+			name:  "cascading",
+			input: entries("10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/23"),
+			want:  []string{"10.0.0.0/22"},
+		},
+		{
+			name:  "contained",
+			input: entries("10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"),
+			want:  []string{"10.0.0.0/8 d 10.0.0.0/8"},
+		},
+		{
+			name:  "not siblings",
+			input: entries("10.0.1.0/24", "10.0.2.0/24"),
+			want:  []string{"10.0.1.0/24 d 10.0.1.0/24", "10.0.2.0/24 d 10.0.2.0/24"},
+		},
+		{
+			name:  "families apart",
+			input: entries("2001:db8::/33", "2001:db8:8000::/33", "0.0.0.0/1"),
+			want:  []string{"0.0.0.0/1 d 0.0.0.0/1", "2001:db8::/32"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, prefixEntryStrings(aggregatePrefixEntries(tt.input)))
+		})
+	}
+}
+
+func TestExpandPrefixesSource(t *testing.T) {
+	source := []interface{}{map[string]interface{}{
+		"format":    "txt",
+		"content":   "10.0.1.0/24 b\n10.0.0.0/24 a\n10.0.0.0/24 again\n192.168.0.0/16 lan\n",
+		"aggregate": false,
+	}}
+
+	entries, err := expandPrefixesSource(source, map[string]bool{"192.168.0.0/16": true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/24 a", "10.0.1.0/24 b"}, prefixEntryStrings(entries))
+
+	source[0].(map[string]interface{})["aggregate"] = true
+
+	entries, err = expandPrefixesSource(source, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/23", "192.168.0.0/16 lan"}, prefixEntryStrings(entries))
+
+	_, err = expandPrefixesSource([]interface{}{map[string]interface{}{
+		"format":  "json",
+		"content": "[",
+	}}, nil)
+	assert.ErrorContains(t, err, "prefixes_source: invalid JSON")
+}
+
+func TestPrefixesSourceRequestAndRead(t *testing.T) {
+	r := resourceAlkiraPolicyPrefixList()
+
+	d := r.TestResourceData()
+	d.Set("name", "feed")
+	d.Set("prefix", []interface{}{
+		map[string]interface{}{"cidr": "10.0.0.0/24", "description": "pinned"},
+	})
+	d.Set("prefixes_source", []interface{}{map[string]interface{}{
+		"format":  "csv",
+		"content": "cidr,description\n10.0.0.0/24,feed\n10.9.0.0/16,branch\n",
+	}})
+
+	request, err := generatePolicyPrefixListRequest(context.Background(), d)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"10.0.0.0/24", "10.9.0.0/16"}, request.Prefixes)
+	assert.Equal(t, "pinned", request.PrefixDetails["10.0.0.0/24"].Description)
+	assert.Equal(t, "branch", request.PrefixDetails["10.9.0.0/16"].Description)
+
+	setPrefixWithSource(d, request.Prefixes, request.PrefixDetails)
+
+	assert.Equal(t, 1, d.Get("prefix").(*schema.Set).Len())
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"cidr": "10.9.0.0/16", "description": "branch"},
+	}, d.Get("source_prefix").(*schema.Set).List())
+}
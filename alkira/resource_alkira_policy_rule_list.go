@@ -73,7 +73,7 @@ func resourcePolicyRuleList(ctx context.Context, d *schema.ResourceData, m inter
 	api := alkira.NewPolicyRuleList(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generatePolicyRuleListRequest(d)
+	request, err := generatePolicyRuleListRequest(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -155,7 +155,7 @@ func resourcePolicyRuleListUpdate(ctx context.Context, d *schema.ResourceData, m
 	api := alkira.NewPolicyRuleList(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generatePolicyRuleListRequest(d)
+	request, err := generatePolicyRuleListRequest(ctx, d)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -240,9 +240,9 @@ func resourcePolicyRuleListDelete(ctx context.Context, d *schema.ResourceData, m
 	return nil
 }
 
-func generatePolicyRuleListRequest(d *schema.ResourceData) (*alkira.PolicyRuleList, error) {
+func generatePolicyRuleListRequest(ctx context.Context, d *schema.ResourceData) (*alkira.PolicyRuleList, error) {
 
-	rules := expandPolicyRuleListRules(d.Get("rules").(*schema.Set))
+	rules := expandPolicyRuleListRules(ctx, d.Get("rules").(*schema.Set))
 
 	request := &alkira.PolicyRuleList{
 		Name:        d.Get("name").(string),
@@ -1,15 +1,16 @@
 package alkira
 
 import (
-	"log"
+	"context"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func expandPolicyRuleListRules(in *schema.Set) []alkira.PolicyRuleListRule {
+func expandPolicyRuleListRules(ctx context.Context, in *schema.Set) []alkira.PolicyRuleListRule {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] invalid policy rule")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid policy rule")
 		return nil
 	}
 
@@ -1,9 +1,10 @@
 package alkira
 
 import (
-	"log"
+	"context"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -34,10 +35,10 @@ func getSegmentIdByName(name string, m interface{}) (string, error) {
 }
 
 // convertSegmentIdsToSegmentNames
-func convertSegmentIdsToSegmentNames(in *schema.Set, m interface{}) ([]string, error) {
+func convertSegmentIdsToSegmentNames(ctx context.Context, in *schema.Set, m interface{}) ([]string, error) {
 
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] empty SegmentIds to convert to SegmentNames")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "empty segment_ids to convert to segment names")
 		return nil, nil
 	}
 
@@ -47,7 +48,9 @@ func convertSegmentIdsToSegmentNames(in *schema.Set, m interface{}) ([]string, e
 		segmentName, err := getSegmentNameById(id.(string), m)
 
 		if err != nil {
-			log.Printf("[DEBUG] failed to get segment name by ID %s.", id)
+			tflog.SubsystemDebug(ctx, logSubsystemSchema, "failed to get segment name by id", map[string]interface{}{
+				"segment_id": id,
+			})
 			return nil, err
 		}
 
@@ -58,14 +61,16 @@ func convertSegmentIdsToSegmentNames(in *schema.Set, m interface{}) ([]string, e
 }
 
 // convertSegmentNamesToSegmentIds
-func convertSegmentNamesToSegmentIds(names []string, m interface{}) ([]string, error) {
+func convertSegmentNamesToSegmentIds(ctx context.Context, names []string, m interface{}) ([]string, error) {
 	api := alkira.NewSegment(m.(*alkira.AlkiraClient))
 
 	var segmentIds []string
 	for _, name := range names {
 		seg, _, err := api.GetByName(name)
 		if err != nil {
-			log.Printf("[DEBUG] failed to get segment. %s does not exist: ", name)
+			tflog.SubsystemDebug(ctx, logSubsystemSchema, "failed to get segment id by name", map[string]interface{}{
+				"segment_name": name,
+			})
 			return nil, err
 		}
 
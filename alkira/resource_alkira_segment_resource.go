@@ -89,7 +89,7 @@ func resourceSegmentResource(ctx context.Context, d *schema.ResourceData, m inte
 	api := alkira.NewSegmentResource(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateSegmentResourceRequest(d, m)
+	request, err := generateSegmentResourceRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -199,7 +199,7 @@ func resourceSegmentResourceUpdate(ctx context.Context, d *schema.ResourceData,
 	api := alkira.NewSegmentResource(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateSegmentResourceRequest(d, m)
+	request, err := generateSegmentResourceRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -286,7 +286,7 @@ func resourceSegmentResourceDelete(ctx context.Context, d *schema.ResourceData,
 	return nil
 }
 
-func generateSegmentResourceRequest(d *schema.ResourceData, m interface{}) (*alkira.SegmentResource, error) {
+func generateSegmentResourceRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.SegmentResource, error) {
 	//
 	// Segment
 	//
@@ -299,7 +299,7 @@ func generateSegmentResourceRequest(d *schema.ResourceData, m interface{}) (*alk
 	//
 	// Group Prefix
 	//
-	groupPrefixes := expandSegmentResourceGroupPrefix(d.Get("group_prefix").(*schema.Set))
+	groupPrefixes := expandSegmentResourceGroupPrefix(ctx, d.Get("group_prefix").(*schema.Set))
 
 	// Assemble request
 	resource := alkira.SegmentResource{
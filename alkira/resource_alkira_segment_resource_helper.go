@@ -1,15 +1,16 @@
 package alkira
 
 import (
-	"log"
+	"context"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func expandSegmentResourceGroupPrefix(in *schema.Set) []alkira.SegmentResourceGroupPrefix {
+func expandSegmentResourceGroupPrefix(ctx context.Context, in *schema.Set) []alkira.SegmentResourceGroupPrefix {
 	if in == nil || in.Len() == 0 {
-		log.Printf("[DEBUG] invalid input for segment resource group prefix.")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid input for segment resource group_prefix")
 		return nil
 	}
 
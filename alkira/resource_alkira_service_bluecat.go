@@ -271,7 +271,7 @@ func resourceBluecat(ctx context.Context, d *schema.ResourceData, m interface{})
 	api := alkira.NewServiceBluecat(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateBluecatRequest(d, m)
+	request, err := generateBluecatRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -337,7 +337,7 @@ func resourceBluecatRead(ctx context.Context, d *schema.ResourceData, m interfac
 	}
 
 	// Convert segment names to segment IDs
-	segmentIds, err := convertSegmentNamesToSegmentIds(bluecat.Segments, m)
+	segmentIds, err := convertSegmentNamesToSegmentIds(ctx, bluecat.Segments, m)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -360,7 +360,7 @@ func resourceBluecatUpdate(ctx context.Context, d *schema.ResourceData, m interf
 	api := alkira.NewServiceBluecat(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateBluecatRequest(d, m)
+	request, err := generateBluecatRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -441,11 +441,12 @@ func resourceBluecatDelete(ctx context.Context, d *schema.ResourceData, m interf
 	return nil
 }
 
-func generateBluecatRequest(d *schema.ResourceData, m interface{}) (*alkira.ServiceBluecat, error) {
+func generateBluecatRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ServiceBluecat, error) {
 	// Parse Instances - use GetChange so we can pass old state for hostname-based
 	// id lookup. This prevents positional list shifts from sending wrong ids to the API.
 	oldInstanceListRaw, newInstanceListRaw := d.GetChange("instance")
 	instances, err := expandBluecatInstances(
+		ctx,
 		newInstanceListRaw.(*schema.Set).List(),
 		oldInstanceListRaw.(*schema.Set).List(),
 		m,
@@ -467,7 +468,7 @@ func generateBluecatRequest(d *schema.ResourceData, m interface{}) (*alkira.Serv
 	}
 
 	// Convert segment IDs to segment names
-	segmentNames, err := convertSegmentIdsToSegmentNames(d.Get("segment_ids").(*schema.Set), m)
+	segmentNames, err := convertSegmentIdsToSegmentNames(ctx, d.Get("segment_ids").(*schema.Set), m)
 	if err != nil {
 		return nil, err
 	}
@@ -1,10 +1,11 @@
 package alkira
 
 import (
+	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -30,7 +31,7 @@ var bluecatInstanceHash = typeSetHash(func(m map[string]interface{}) string {
 	return fmt.Sprintf("%s-%s", hostname, instanceType)
 })
 
-func expandBluecatInstances(in []interface{}, oldInstances []interface{}, m interface{}) ([]alkira.BluecatInstance, error) {
+func expandBluecatInstances(ctx context.Context, in []interface{}, oldInstances []interface{}, m interface{}) ([]alkira.BluecatInstance, error) {
 	if in == nil || len(in) == 0 {
 		return nil, fmt.Errorf("[ERROR]: Bluecat instances cannot be nil or empty")
 	}
@@ -43,14 +44,18 @@ func expandBluecatInstances(in []interface{}, oldInstances []interface{}, m inte
 	for _, old := range oldInstances {
 		cfg, ok := old.(map[string]interface{})
 		if !ok {
-			log.Printf("[WARN] Bluecat: skipping malformed instance entry in old state: %v", old)
+			tflog.SubsystemWarn(ctx, logSubsystemSchema, "skipping malformed bluecat instance in old state", map[string]interface{}{
+				"instance": old,
+			})
 			continue
 		}
 		hostname := getHostnameFromInstance(cfg)
 		id, hasId := cfg["id"].(int)
 		if hostname == "" {
 			if hasId && id != 0 {
-				log.Printf("[WARN] Bluecat: existing instance with id=%d has no hostname; it cannot be matched by hostname during reorder and will be treated as new", id)
+				tflog.SubsystemWarn(ctx, logSubsystemSchema, "existing bluecat instance has no hostname, it cannot be matched by hostname during reorder and will be treated as new", map[string]interface{}{
+					"instance_id": id,
+				})
 			}
 			continue
 		}
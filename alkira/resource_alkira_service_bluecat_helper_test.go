@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"testing"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
@@ -248,7 +249,7 @@ func TestExpandBluecatInstances_HostnameIdLookup(t *testing.T) {
 		},
 	}
 
-	result, err := expandBluecatInstances(newInstances, oldInstances, mockClient)
+	result, err := expandBluecatInstances(context.Background(), newInstances, oldInstances, mockClient)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 6)
@@ -291,7 +292,7 @@ func TestExpandBluecatInstances_EmptyHostname(t *testing.T) {
 		},
 	}
 
-	result, err := expandBluecatInstances(newInstances, oldInstances, mockClient)
+	result, err := expandBluecatInstances(context.Background(), newInstances, oldInstances, mockClient)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
@@ -353,7 +354,7 @@ func TestExpandBluecatInstances_RemoveFromMiddle(t *testing.T) {
 		},
 	}
 
-	result, err := expandBluecatInstances(newInstances, oldInstances, mockClient)
+	result, err := expandBluecatInstances(context.Background(), newInstances, oldInstances, mockClient)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -418,7 +419,7 @@ func TestExpandBluecatInstances_MixedCreateUpdateDelete(t *testing.T) {
 		},
 	}
 
-	result, err := expandBluecatInstances(newInstances, oldInstances, mockClient)
+	result, err := expandBluecatInstances(context.Background(), newInstances, oldInstances, mockClient)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 3)
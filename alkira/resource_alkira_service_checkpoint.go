@@ -274,6 +274,7 @@ func resourceAlkiraCheckpoint() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{
 					"IPSEC", "GRE"}, false),
 			},
+			"update_strategy": updateStrategySchema(),
 			"version": {
 				Description: "The version of the Checkpoint Firewall. Please " +
 					"check all supported versions from Alkira Portal.",
@@ -290,14 +291,14 @@ func resourceCheckpoint(ctx context.Context, d *schema.ResourceData, m interface
 	api := alkira.NewServiceCheckpoint(m.(*alkira.AlkiraClient))
 
 	// Create checkpoint service credentail
-	credentialId, err := createCheckpointCredential(d, client)
+	credentialId, err := createCheckpointCredential(ctx, d, client)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	d.Set("credential_id", credentialId)
 
 	// Construct request
-	request, err := generateCheckpointRequest(d, m)
+	request, err := generateCheckpointRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -389,7 +390,7 @@ func resourceCheckpointRead(ctx context.Context, d *schema.ResourceData, m inter
 	d.Set("name", checkpoint.Name)
 	d.Set("pdp_ips", checkpoint.PdpIps)
 	d.Set("size", checkpoint.Size)
-	d.Set("segment_options", deflateSegmentOptions(checkpoint.SegmentOptions))
+	d.Set("segment_options", deflateSegmentOptions(ctx, checkpoint.SegmentOptions))
 	d.Set("tunnel_protocol", checkpoint.TunnelProtocol)
 	d.Set("version", checkpoint.Version)
 
@@ -407,20 +408,20 @@ func resourceCheckpointUpdate(ctx context.Context, d *schema.ResourceData, m int
 	api := alkira.NewServiceCheckpoint(m.(*alkira.AlkiraClient))
 
 	// Update checkpoint service credential
-	err := updateCheckpointCredential(d, client)
+	err := updateCheckpointCredential(ctx, d, client)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	// Construct request
-	request, err := generateCheckpointRequest(d, m)
+	request, err := generateCheckpointRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	// Send update request
-	provState, err, valErr, provErr := api.Update(d.Id(), request)
+	provState, err, valErr, provErr := updateServiceInstances(ctx, d, api, request)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -1,16 +1,17 @@
 package alkira
 
 import (
+	"context"
 	"errors"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 // createCheckpointCredential create checkpoint service credential
-func createCheckpointCredential(d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
-	log.Printf("[INFO] Creating Checkpoint service credential")
+func createCheckpointCredential(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
+	tflog.Info(ctx, "creating Checkpoint service credential")
 
 	credentialName := d.Get("name").(string) + "-" + randomNameSuffix()
 	credential := alkira.CredentialCheckPointFwService{AdminPassword: d.Get("password").(string)}
@@ -19,13 +20,11 @@ func createCheckpointCredential(d *schema.ResourceData, c *alkira.AlkiraClient)
 }
 
 // updateCheckpointCredential update checkpoint service credential
-func updateCheckpointCredential(d *schema.ResourceData, c *alkira.AlkiraClient) error {
-	log.Printf("[INFO] Updating Checkpoint service credential")
-
+func updateCheckpointCredential(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) error {
 	if d.HasChanges("password") {
-		log.Printf("[INFO] Checkpoint service credential has changed")
+		tflog.Info(ctx, "updating Checkpoint service credential")
 
-		credentialId, err := createCheckpointCredential(d, c)
+		credentialId, err := createCheckpointCredential(ctx, d, c)
 		if err != nil {
 			return err
 		}
@@ -35,12 +34,12 @@ func updateCheckpointCredential(d *schema.ResourceData, c *alkira.AlkiraClient)
 	return nil
 }
 
-func expandCheckpointManagementServer(name string, in []interface{}, m interface{}) (*alkira.CheckpointManagementServer, error) {
+func expandCheckpointManagementServer(ctx context.Context, name string, in []interface{}, m interface{}) (*alkira.CheckpointManagementServer, error) {
 
 	client := m.(*alkira.AlkiraClient)
 
 	if in == nil || len(in) > 1 {
-		log.Printf("[DEBUG] Invalid Checkpoint Firewall Management Server input")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid checkpoint management_server input")
 		return nil, errors.New("ERROR: Invalid checkpoint firewall management server input")
 	}
 
@@ -107,7 +106,7 @@ func expandCheckpointManagementServer(name string, in []interface{}, m interface
 	return mg, nil
 }
 
-func expandCheckpointInstances(in []interface{}, m interface{}) ([]alkira.CheckpointInstance, error) {
+func expandCheckpointInstances(ctx context.Context, in []interface{}, m interface{}) ([]alkira.CheckpointInstance, error) {
 
 	if in == nil || len(in) == 0 {
 		return nil, errors.New("ERROR: Invalid checkpoint firewall instance input")
@@ -136,7 +135,7 @@ func expandCheckpointInstances(in []interface{}, m interface{}) ([]alkira.Checkp
 				credentialName := r.Name + "-" + randomNameSuffix()
 				c := &alkira.CredentialCheckPointFwServiceInstance{SicKey: sicKey}
 
-				log.Printf("[INFO] Creating Credential CheckpointInstance.")
+				tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating checkpoint instance credential")
 				credentialId, err := client.CreateCredential(
 					credentialName,
 					alkira.CredentialTypeChkpFwInstance,
@@ -271,10 +270,10 @@ func setCheckpointInstances(d *schema.ResourceData, c []alkira.CheckpointInstanc
 }
 
 // generateCheckpointRequest
-func generateCheckpointRequest(d *schema.ResourceData, m interface{}) (*alkira.ServiceCheckpoint, error) {
+func generateCheckpointRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ServiceCheckpoint, error) {
 
 	// Management Server block
-	managementServer, err := expandCheckpointManagementServer(d.Get("name").(string), d.Get("management_server").([]interface{}), m)
+	managementServer, err := expandCheckpointManagementServer(ctx, d.Get("name").(string), d.Get("management_server").([]interface{}), m)
 
 	if err != nil {
 		return nil, err
@@ -283,7 +282,7 @@ func generateCheckpointRequest(d *schema.ResourceData, m interface{}) (*alkira.S
 	//
 	// Instances block
 	//
-	instances, err := expandCheckpointInstances(d.Get("instance").([]interface{}), m)
+	instances, err := expandCheckpointInstances(ctx, d.Get("instance").([]interface{}), m)
 
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -25,13 +26,13 @@ func TestCheckpointSegmentOptionsNil(t *testing.T) {
 
 func TestCheckpointInstanceInvalid(t *testing.T) {
 	//test nil Set
-	c, err := expandCheckpointInstances(nil, nil)
+	c, err := expandCheckpointInstances(context.Background(), nil, nil)
 	require.Nil(t, c)
 	require.Error(t, err)
 
 	//test empty Set
 	s := newSetFromCheckpointResource(nil)
-	c, err = expandCheckpointInstances(s.List(), nil)
+	c, err = expandCheckpointInstances(context.Background(), s.List(), nil)
 	require.Nil(t, c)
 	require.Error(t, err)
 }
@@ -74,7 +75,7 @@ func TestCheckpointDeflateSegmentOptionsValid(t *testing.T) {
 	segmentOptions := make(map[string]alkira.OuterZoneToGroups)
 	segmentOptions[expectedSegment.Name] = z
 
-	m := deflateSegmentOptions(segmentOptions)
+	m := deflateSegmentOptions(context.Background(), segmentOptions)
 	require.Len(t, m, 1)
 	require.Equal(t, m[0]["groups"], expectedGroups)
 	require.Equal(t, m[0]["zone_name"], expectedZoneName)
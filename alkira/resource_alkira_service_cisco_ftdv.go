@@ -214,6 +214,7 @@ func resourceAlkiraServiceCiscoFTDv() *schema.Resource {
 					},
 				},
 			},
+			"update_strategy": updateStrategySchema(),
 			"segment_options": {
 				Type:        schema.TypeSet,
 				Optional:    true,
@@ -250,7 +251,7 @@ func resourceServiceCiscoFTDvCreate(ctx context.Context, d *schema.ResourceData,
 	api := alkira.NewServiceCiscoFTDv(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateServiceCiscoFTDvRequest(d, m)
+	request, err := generateServiceCiscoFTDvRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -317,7 +318,7 @@ func resourceServiceCiscoFTDvRead(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	// Convert segment names to segment IDs
-	segmentIds, err := convertSegmentNamesToSegmentIds(service.Segments, m)
+	segmentIds, err := convertSegmentNamesToSegmentIds(ctx, service.Segments, m)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -333,7 +334,7 @@ func resourceServiceCiscoFTDvRead(ctx context.Context, d *schema.ResourceData, m
 	d.Set("min_instance_count", service.MinInstanceCount)
 	d.Set("name", service.Name)
 	d.Set("segment_ids", segmentIds)
-	d.Set("segment_options", deflateSegmentOptions(service.SegmentOptions))
+	d.Set("segment_options", deflateSegmentOptions(ctx, service.SegmentOptions))
 	d.Set("size", service.Size)
 	d.Set("tunnel_protocol", service.TunnelProtocol)
 	d.Set("description", service.Description)
@@ -353,14 +354,14 @@ func resourceServiceCiscoFTDvUpdate(ctx context.Context, d *schema.ResourceData,
 	api := alkira.NewServiceCiscoFTDv(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateServiceCiscoFTDvRequest(d, m)
+	request, err := generateServiceCiscoFTDvRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("ERROR : UpdateServiceCiscoFTDv: failed to marshal: %w", err))
 	}
 
 	// Send update request
-	provState, err, valErr, provErr := api.Update(d.Id(), request)
+	provState, err, valErr, provErr := updateServiceInstances(ctx, d, api, request)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -442,10 +443,10 @@ func resourceServiceCiscoFTDvDelete(ctx context.Context, d *schema.ResourceData,
 }
 
 // generateServiceCiscoFTDvRequest generate a request
-func generateServiceCiscoFTDvRequest(d *schema.ResourceData, m interface{}) (*alkira.ServiceCiscoFTDv, error) {
+func generateServiceCiscoFTDvRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ServiceCiscoFTDv, error) {
 
 	// Segments
-	segmentNames, err := convertSegmentIdsToSegmentNames(d.Get("segment_ids").(*schema.Set), m)
+	segmentNames, err := convertSegmentIdsToSegmentNames(ctx, d.Get("segment_ids").(*schema.Set), m)
 
 	if err != nil {
 		return nil, err
@@ -464,7 +465,7 @@ func generateServiceCiscoFTDvRequest(d *schema.ResourceData, m interface{}) (*al
 	// credential_id and ip_allow_list is on top level of the service,
 	// but those fields should be part of the management_center.
 	//
-	credentialId, ipAllowList, managementServer, err := expandCiscoFtdvManagementServer(d.Get("firepower_management_center").([]interface{}), m)
+	credentialId, ipAllowList, managementServer, err := expandCiscoFtdvManagementServer(ctx, d.Get("firepower_management_center").([]interface{}), m)
 
 	if err != nil {
 		return nil, err
@@ -473,7 +474,7 @@ func generateServiceCiscoFTDvRequest(d *schema.ResourceData, m interface{}) (*al
 	//
 	// Instances
 	//
-	instances, err := expandCiscoFTDvInstances(d.Get("instance").([]interface{}), m)
+	instances, err := expandCiscoFTDvInstances(ctx, d.Get("instance").([]interface{}), m)
 
 	if err != nil {
 		return nil, err
@@ -1,19 +1,20 @@
 package alkira
 
 import (
+	"context"
 	"errors"
-	"log"
 	"strconv"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func expandCiscoFTDvInstances(in []interface{}, m interface{}) ([]alkira.CiscoFTDvInstance, error) {
+func expandCiscoFTDvInstances(ctx context.Context, in []interface{}, m interface{}) ([]alkira.CiscoFTDvInstance, error) {
 	client := m.(*alkira.AlkiraClient)
 
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] invalid Cisco FTDv instance input")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid cisco ftdv instance input")
 		return nil, errors.New("ERROR: Invalid Cisco FTDv instance input")
 	}
 
@@ -52,7 +53,7 @@ func expandCiscoFTDvInstances(in []interface{}, m interface{}) ([]alkira.CiscoFT
 					FmcRegistrationKey: fmcRegistrationKey,
 					FtvdNatId:          ftdvNatId,
 				}
-				log.Printf("[INFO] Creating Credential Cisco FTDv Instance.")
+				tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating cisco ftdv instance credential")
 				credentialId, err := client.CreateCredential(
 					credentialName,
 					alkira.CredentialTypeCiscoFtdvInstance,
@@ -82,7 +83,7 @@ func expandCiscoFTDvInstances(in []interface{}, m interface{}) ([]alkira.CiscoFT
 	return instances, nil
 }
 
-func expandCiscoFtdvManagementServer(in []interface{}, m interface{}) (string, []string, alkira.CiscoFTDvManagementServer, error) {
+func expandCiscoFtdvManagementServer(ctx context.Context, in []interface{}, m interface{}) (string, []string, alkira.CiscoFTDvManagementServer, error) {
 	client := m.(*alkira.AlkiraClient)
 
 	var credentialId string
@@ -90,7 +91,7 @@ func expandCiscoFtdvManagementServer(in []interface{}, m interface{}) (string, [
 	var managementServer = alkira.CiscoFTDvManagementServer{}
 
 	if in == nil || len(in) != 1 {
-		log.Printf("[DEBUG] Invalid Cisco FTDv Management Server input.")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid cisco ftdv management_server input")
 		return credentialId, ipAllowList, managementServer, errors.New("ERROR: Invalid Cisco FTDv Management Server input")
 	}
 
@@ -111,7 +112,7 @@ func expandCiscoFtdvManagementServer(in []interface{}, m interface{}) (string, [
 				credentialName := "cisco-fdtv-" + randomNameSuffix()
 				c := alkira.CredentialCiscoFtdv{Username: username, Password: password}
 
-				log.Printf("[INFO] Creating Cisco FTDv Firewall Service Credentials")
+				tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating cisco ftdv management server credential")
 				cId, err := client.CreateCredential(credentialName, alkira.CredentialTypeCiscoFtdv, c, 0)
 
 				if err != nil {
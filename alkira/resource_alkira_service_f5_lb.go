@@ -98,6 +98,7 @@ func resourceAlkiraF5LoadBalancer() *schema.Resource {
 				Type:        schema.TypeInt,
 				Optional:    true,
 			},
+			"update_strategy": updateStrategySchema(),
 			"segment_options": {
 				Type:     schema.TypeSet,
 				Required: true,
@@ -319,7 +320,7 @@ func resourceF5LoadBalancerCreate(ctx context.Context, d *schema.ResourceData, m
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewServiceF5Lb(client)
 
-	request, err := generateRequestF5Lb(d, m)
+	request, err := generateRequestF5Lb(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -426,13 +427,13 @@ func resourceF5LoadBalancerUpdate(ctx context.Context, d *schema.ResourceData, m
 	client := m.(*alkira.AlkiraClient)
 	api := alkira.NewServiceF5Lb(m.(*alkira.AlkiraClient))
 
-	request, err := generateRequestF5Lb(d, m)
+	request, err := generateRequestF5Lb(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	provState, err, valErr, provErr := api.Update(d.Id(), request)
+	provState, err, valErr, provErr := updateServiceInstances(ctx, d, api, request)
 
 	if err != nil {
 		return diag.FromErr(err)
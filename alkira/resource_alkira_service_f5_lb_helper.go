@@ -1,13 +1,14 @@
 package alkira
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -19,12 +20,12 @@ var f5LBInstanceMetadataHash = typeSetHash(func(m map[string]interface{}) string
 })
 
 // expandF5Instances converts the input data to a slice of F5Instances structs.
-func expandF5Instances(in []interface{}, m interface{}) ([]alkira.F5Instance, error) {
+func expandF5Instances(ctx context.Context, in []interface{}, m interface{}) ([]alkira.F5Instance, error) {
 
 	client := m.(*alkira.AlkiraClient)
 
 	if in == nil || len(in) == 0 {
-		log.Printf("[ERROR] Invalid F5 Load Balancer instance input.")
+		tflog.SubsystemError(ctx, logSubsystemSchema, "invalid f5 load balancer instance input")
 		return nil, errors.New("ERROR: Invalid F5 load balancer instance input")
 	}
 
@@ -57,7 +58,9 @@ func expandF5Instances(in []interface{}, m interface{}) ([]alkira.F5Instance, er
 							RegistrationKey: tfInstance["f5_registration_key"].(string),
 						}
 
-						log.Printf("[INFO] Creating F5 Load Balancer Instance Registration Credential %s", credentialName)
+						tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating f5 load balancer instance registration credential", map[string]interface{}{
+							"credential_name": credentialName,
+						})
 						credentialId, err := client.CreateCredential(
 							credentialName,
 							alkira.CredentialTypeF5InstanceRegistration,
@@ -99,7 +102,9 @@ func expandF5Instances(in []interface{}, m interface{}) ([]alkira.F5Instance, er
 					Password: tfInstance["f5_password"].(string),
 				}
 
-				log.Printf("[INFO] Creating F5 Load Balancer Instance Credential %s", credentialName)
+				tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating f5 load balancer instance credential", map[string]interface{}{
+					"credential_name": credentialName,
+				})
 				credentialId, err := client.CreateCredential(
 					credentialName,
 					alkira.CredentialTypeF5Instance,
@@ -276,18 +281,18 @@ func flattenF5LBInstanceMetadata(m *alkira.F5LBServiceInstanceMetadata) []map[st
 }
 
 // generateRequestF5Lb generates the request payload for creating an F5 Load Balancer service.
-func generateRequestF5Lb(d *schema.ResourceData, m interface{}) (*alkira.ServiceF5Lb, error) {
+func generateRequestF5Lb(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ServiceF5Lb, error) {
 
 	billingTagIds := convertTypeSetToIntList(d.Get("billing_tag_ids").(*schema.Set))
 
-	instances, err := expandF5Instances(
+	instances, err := expandF5Instances(ctx,
 		d.Get("instance").([]interface{}), m)
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert segment IDs to segment names
-	segmentNames, err := convertSegmentIdsToSegmentNames(d.Get("segment_ids").(*schema.Set), m)
+	segmentNames, err := convertSegmentIdsToSegmentNames(ctx, d.Get("segment_ids").(*schema.Set), m)
 	if err != nil {
 		return nil, err
 	}
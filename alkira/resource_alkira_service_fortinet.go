@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
 
@@ -28,7 +29,7 @@ func resourceAlkiraServiceFortinet() *schema.Resource {
 				d.SetNew("provision_state", "SUCCESS")
 			}
 
-			return nil
+			return customizeDiffFortinetLicenses(d)
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: importWithReadValidation(resourceFortinetRead),
@@ -142,6 +143,50 @@ func resourceAlkiraServiceFortinet() *schema.Resource {
 					},
 				},
 			},
+			"licenses": {
+				Description: "The licenses of the instances, in the order " +
+					"of `instances`. A change of the content of a license, " +
+					"including the content of the file at " +
+					"`license_key_file_path`, creates a new instance " +
+					"credential. Unreadable, malformed, expired or " +
+					"duplicate licenses fail the plan.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "The name of the instance.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"sha256": {
+							Description: "The SHA-256 hash of the license.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"serial_number": {
+							Description: "The serial number of the " +
+								"FortiGate VM, from the license or else " +
+								"the name of the license file.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"expiry_date": {
+							Description: "The expiry date of the license " +
+								"in the format `YYYY-MM-DD`, when the " +
+								"license has one.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"days_remaining": {
+							Description: "The number of days until " +
+								"`expiry_date`.",
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
 			"license_type": {
 				Description: "Fortinet license type, either `BRING_YOUR_OWN`" +
 					"or `PAY_AS_YOU_GO`.",
@@ -248,6 +293,7 @@ func resourceAlkiraServiceFortinet() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{
 					"IPSEC", "GRE"}, false),
 			},
+			"update_strategy": updateStrategySchema(),
 			"version": {
 				Description: "The version of the Fortinet Firewall. Please " +
 					"check Alkira Portal for all supported versions.",
@@ -264,18 +310,26 @@ func resourceFortinetCreate(ctx context.Context, d *schema.ResourceData, m inter
 	api := alkira.NewServiceFortinet(m.(*alkira.AlkiraClient))
 
 	// Create fortinet service credentials
-	credentialId, err := createFortinetCredential(d, client)
+	credentialId, err := createFortinetCredential(ctx, d, client)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	d.Set("credential_id", credentialId)
 
 	// Construct request
-	request, err := generateFortinetRequest(d, m)
+	request, err := generateFortinetRequest(ctx, d, m)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Track the licenses the instance credentials were created with
+	licenses, err := expandFortinetLicenses(d.Get("instances").([]interface{}), time.Now())
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	d.Set("licenses", licenses)
 
 	// Send create request
 	response, provState, err, valErr, provErr := api.Create(request)
@@ -343,7 +397,7 @@ func resourceFortinetRead(ctx context.Context, d *schema.ResourceData, m interfa
 	d.Set("max_instance_count", f.MaxInstanceCount)
 	d.Set("min_instance_count", f.MinInstanceCount)
 	d.Set("name", f.Name)
-	d.Set("segment_options", deflateSegmentOptions(f.SegmentOptions))
+	d.Set("segment_options", deflateSegmentOptions(ctx, f.SegmentOptions))
 	d.Set("size", f.Size)
 	d.Set("tunnel_protocol", f.TunnelProtocol)
 	d.Set("version", f.Version)
@@ -374,7 +428,17 @@ func resourceFortinetRead(ctx context.Context, d *schema.ResourceData, m interfa
 	d.Set("segment_ids", segments)
 
 	// Set instances
-	setInstance(d, f)
+	setInstance(ctx, d, f)
+
+	// Track the licenses of services created before they were tracked
+	if licenses := d.Get("licenses").([]interface{}); len(licenses) == 0 {
+		licenses, err := expandFortinetLicenses(d.Get("instances").([]interface{}), time.Now())
+		if err == nil {
+			d.Set("licenses", licenses)
+		}
+	} else {
+		d.Set("licenses", refreshFortinetLicenseDays(licenses))
+	}
 
 	// Set provision state
 	if client.Provision && provState != "" {
@@ -390,25 +454,36 @@ func resourceFortinetUpdate(ctx context.Context, d *schema.ResourceData, m inter
 	api := alkira.NewServiceFortinet(m.(*alkira.AlkiraClient))
 
 	// Update fortinet service credential
-	err := updateFortinetCredential(d, client)
+	err := updateFortinetCredential(ctx, d, client)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	// Construct request
-	request, err := generateFortinetRequest(d, m)
+	request, err := generateFortinetRequest(ctx, d, m)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Track the licenses the instance credentials were created with
+	licenses, err := expandFortinetLicenses(d.Get("instances").([]interface{}), time.Now())
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	// UPDATE
-	provState, err, valErr, provErr := api.Update(d.Id(), request)
+	provState, err, valErr, provErr := updateServiceInstances(ctx, d, api, request)
 
 	if err != nil {
+		// Keep the previous state, the licenses in use didn't change
+		d.Partial(true)
 		return diag.FromErr(err)
 	}
 
+	d.Set("licenses", licenses)
+
 	if client.Validate && valErr != nil {
 		var diags diag.Diagnostics
 		readDiags := resourceFortinetRead(ctx, d, m)
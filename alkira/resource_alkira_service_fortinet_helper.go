@@ -1,17 +1,16 @@
 package alkira
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"log"
-	"os"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 // setInstance set instance block values
-func setInstance(d *schema.ResourceData, service *alkira.ServiceFortinet) {
+func setInstance(ctx context.Context, d *schema.ResourceData, service *alkira.ServiceFortinet) {
 	var instances []map[string]interface{}
 
 	//
@@ -30,7 +29,10 @@ func setInstance(d *schema.ResourceData, service *alkira.ServiceFortinet) {
 			if instanceConfig["id"].(int) == info.Id ||
 				instanceConfig["name"].(string) == info.Name {
 
-				log.Printf("[DEBUG] Found instance [%v|%v]", info.Id, info.Name)
+				tflog.SubsystemDebug(ctx, logSubsystemSchema, "found fortinet instance", map[string]interface{}{
+					"instance_id":   info.Id,
+					"instance_name": info.Name,
+				})
 				instance := map[string]interface{}{
 					"id":                    info.Id,
 					"credential_id":         info.CredentialId,
@@ -83,11 +85,11 @@ func setInstance(d *schema.ResourceData, service *alkira.ServiceFortinet) {
 }
 
 // expandFortinetInstances expand instance blocks to construct the request payload
-func expandFortinetInstances(licenseType string, in []interface{}, m interface{}) ([]alkira.FortinetInstance, error) {
+func expandFortinetInstances(ctx context.Context, licenseType string, in []interface{}, m interface{}) ([]alkira.FortinetInstance, error) {
 	client := m.(*alkira.AlkiraClient)
 
 	if in == nil || len(in) == 0 {
-		log.Printf("[DEBUG] invalid Fortinet instance input")
+		tflog.SubsystemDebug(ctx, logSubsystemSchema, "invalid fortinet instance input")
 		return nil, errors.New("ERROR: Invalid fortinet instance input")
 	}
 
@@ -117,7 +119,7 @@ func expandFortinetInstances(licenseType string, in []interface{}, m interface{}
 		}
 		if v, ok := instanceCfg["credential_id"].(string); ok {
 			if v == "" {
-				credentialId, err := createFortinetInstanceCredential(client, r.Name, licenseType, licenseKeyLiteral, licenseKeyPath)
+				credentialId, err := createFortinetInstanceCredential(ctx, client, r.Name, licenseType, licenseKeyLiteral, licenseKeyPath)
 				if err != nil {
 					return nil, err
 				}
@@ -157,9 +159,9 @@ func expandFortinetInstances(licenseType string, in []interface{}, m interface{}
 // }
 
 // createFortinetCredential
-func createFortinetCredential(d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
+func createFortinetCredential(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
 
-	log.Printf("[INFO] Creating Fortinet Credential")
+	tflog.Info(ctx, "creating Fortinet credential")
 
 	credentialName := d.Get("name").(string) + "_" + randomNameSuffix()
 	d.Set("credential_name", credentialName)
@@ -173,9 +175,9 @@ func createFortinetCredential(d *schema.ResourceData, c *alkira.AlkiraClient) (s
 }
 
 // updateFortinetCredential update credential when username or password has changes
-func updateFortinetCredential(d *schema.ResourceData, c *alkira.AlkiraClient) error {
+func updateFortinetCredential(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) error {
 	if d.HasChanges("username", "password") {
-		log.Printf("[INFO] Fortinet credential has changed")
+		tflog.Info(ctx, "updating Fortinet credential")
 
 		if d.Get("credential_id") == nil {
 			return errors.New("credential_id is empty when updating fortinet credential")
@@ -207,9 +209,9 @@ func updateFortinetCredential(d *schema.ResourceData, c *alkira.AlkiraClient) er
 // }
 
 // createFortinetInstanceCredential
-func createFortinetInstanceCredential(c *alkira.AlkiraClient, name string, licenseType string, licenseKey string, licenseKeyPath string) (string, error) {
+func createFortinetInstanceCredential(ctx context.Context, c *alkira.AlkiraClient, name string, licenseType string, licenseKey string, licenseKeyPath string) (string, error) {
 
-	log.Printf("[INFO] Creating Fortinet Instance Credential")
+	tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating fortinet instance credential")
 
 	// When license type is "PAY_AS_YOU_GO", license key is optional
 	if licenseKey == "" && licenseKeyPath == "" {
@@ -222,20 +224,9 @@ func createFortinetInstanceCredential(c *alkira.AlkiraClient, name string, licen
 
 	// If the license_key is provided directly in the config, use it,
 	// otherwise, try to read it from the given license key file
-	if licenseKey == "" {
-		if _, err := os.Stat(licenseKeyPath); errors.Is(err, os.ErrNotExist) {
-			return "", fmt.Errorf("file not found at %s: %w", licenseKeyPath, err)
-		}
-
-		b, err := os.ReadFile(licenseKeyPath)
-		if err != nil {
-			return "", err
-		}
-		licenseKey = string(b)
-
-		if licenseKey == "" {
-			return "", errors.New("'license_key' of 'service_fortinet_instance' is invalid")
-		}
+	licenseKey, err := readFortinetLicense(licenseKey, licenseKeyPath)
+	if err != nil {
+		return "", err
 	}
 
 	id, err := c.CreateCredential(
@@ -254,7 +245,7 @@ func createFortinetInstanceCredential(c *alkira.AlkiraClient, name string, licen
 	return id, nil
 }
 
-func generateFortinetRequest(d *schema.ResourceData, m interface{}) (*alkira.ServiceFortinet, error) {
+func generateFortinetRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ServiceFortinet, error) {
 
 	billingTagIds := convertTypeSetToIntList(d.Get("billing_tag_ids").(*schema.Set))
 
@@ -268,9 +259,23 @@ func generateFortinetRequest(d *schema.ResourceData, m interface{}) (*alkira.Ser
 		Segment:   mgmtSegName,
 	}
 
+	// A new credential is created for every instance whose license
+	// changed.
+	in := d.Get("instances").([]interface{})
+	changed := changedFortinetLicenses(d)
+
+	for _, instance := range in {
+		instanceCfg := instance.(map[string]interface{})
+
+		if changed[instanceCfg["name"].(string)] {
+			instanceCfg["credential_id"] = ""
+		}
+	}
+
 	instances, err := expandFortinetInstances(
+		ctx,
 		d.Get("license_type").(string),
-		d.Get("instances").([]interface{}),
+		in,
 		m,
 	)
 	if err != nil {
@@ -278,7 +283,7 @@ func generateFortinetRequest(d *schema.ResourceData, m interface{}) (*alkira.Ser
 	}
 
 	// Convert segment IDs to segment names
-	segmentNames, err := convertSegmentIdsToSegmentNames(d.Get("segment_ids").(*schema.Set), m)
+	segmentNames, err := convertSegmentIdsToSegmentNames(ctx, d.Get("segment_ids").(*schema.Set), m)
 
 	if err != nil {
 		return nil, err
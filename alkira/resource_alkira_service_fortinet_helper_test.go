@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"testing"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
@@ -26,7 +27,7 @@ func TestFortinetExpandGenerateFortinetInstances(t *testing.T) {
 	mArr := []interface{}{m, m1, m2}
 
 	client := &alkira.AlkiraClient{}
-	actual, err := expandFortinetInstances("", mArr, client)
+	actual, err := expandFortinetInstances(context.Background(), "", mArr, client)
 	require.NoError(t, err)
 	require.Equal(t, len(actual), len(mArr))
 
@@ -0,0 +1,288 @@
+package alkira
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	fortinetLicenseBegin = "-----BEGIN FGT VM LICENSE-----"
+	fortinetLicenseEnd   = "-----END FGT VM LICENSE-----"
+)
+
+var (
+	// FortiCare names the license files of FortiGate VMs after the
+	// serial number of the VM, e.g. FGVM04TM21000123.lic.
+	fortinetSerialNumber = regexp.MustCompile(`^FGVM[A-Z0-9]{4,}$`)
+
+	fortinetExpiryLayouts = []string{"2006-01-02", "2006/01/02", "Jan 2 2006", "January 2, 2006"}
+)
+
+// fortinetLicense is what the provider knows about the license of an
+// instance.
+type fortinetLicense struct {
+	Hash         string
+	SerialNumber string
+	Expiry       time.Time
+}
+
+// readFortinetLicense returns the license of an instance, the literal
+// license_key having precedence over license_key_file_path, or "" when
+// neither is set.
+func readFortinetLicense(licenseKey, licenseKeyPath string) (string, error) {
+	if licenseKey != "" || licenseKeyPath == "" {
+		return licenseKey, nil
+	}
+
+	if _, err := os.Stat(licenseKeyPath); errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("file not found at %s: %w", licenseKeyPath, err)
+	}
+
+	b, err := os.ReadFile(licenseKeyPath)
+	if err != nil {
+		return "", err
+	}
+
+	if len(b) == 0 {
+		return "", fmt.Errorf("license key file %s is empty", licenseKeyPath)
+	}
+
+	return string(b), nil
+}
+
+// parseFortinetLicense parses the license of an instance.
+//
+// A FortiGate VM license file holds a base64 encoded block between
+// BEGIN and END FGT VM LICENSE lines, which is checked to be complete.
+// The serial number and expiry date are taken from "Key: value" lines
+// outside of the block when present, the serial number otherwise from
+// the name of the file. Any other license, e.g. a FortiFlex token, is
+// only hashed.
+func parseFortinetLicense(content, path string) (*fortinetLicense, error) {
+	content = strings.TrimSpace(content)
+	sum := sha256.Sum256([]byte(content))
+
+	license := &fortinetLicense{Hash: hex.EncodeToString(sum[:])}
+
+	outside := content
+
+	if begin := strings.Index(content, fortinetLicenseBegin); begin >= 0 {
+		end := strings.Index(content, fortinetLicenseEnd)
+		if end < begin {
+			return nil, fmt.Errorf("malformed license, missing %s", fortinetLicenseEnd)
+		}
+
+		body := strings.Join(strings.Fields(content[begin+len(fortinetLicenseBegin):end]), "")
+		if body == "" {
+			return nil, fmt.Errorf("malformed license, the license block is empty")
+		}
+
+		if _, err := base64.StdEncoding.DecodeString(body); err != nil {
+			return nil, fmt.Errorf("malformed license, the license block is not base64 encoded: %w", err)
+		}
+
+		outside = content[:begin] + "\n" + content[end+len(fortinetLicenseEnd):]
+	}
+
+	for _, line := range strings.Split(outside, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "sn", "serial", "serial number", "serial_number":
+			license.SerialNumber = value
+		case "expires", "expiry", "expiry date", "expiration date", "expiration":
+			expiry, err := parseFortinetExpiry(value)
+			if err != nil {
+				return nil, err
+			}
+			license.Expiry = expiry
+		}
+	}
+
+	if license.SerialNumber == "" && path != "" {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if fortinetSerialNumber.MatchString(name) {
+			license.SerialNumber = name
+		}
+	}
+
+	return license, nil
+}
+
+func parseFortinetExpiry(value string) (time.Time, error) {
+	for _, layout := range fortinetExpiryLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("malformed license, unknown expiry date %q", value)
+}
+
+// expandFortinetLicenses returns the licenses attribute for the given
+// instance blocks, one element per instance. It fails on an unreadable,
+// malformed or expired license or a license used by more than one
+// instance.
+func expandFortinetLicenses(instances []interface{}, now time.Time) ([]interface{}, error) {
+	licenses := make([]interface{}, len(instances))
+	used := make(map[string]string)
+
+	for i, instance := range instances {
+		cfg := instance.(map[string]interface{})
+
+		name, _ := cfg["name"].(string)
+		licenseKey, _ := cfg["license_key"].(string)
+		licenseKeyPath, _ := cfg["license_key_file_path"].(string)
+
+		element := map[string]interface{}{
+			"name":           name,
+			"sha256":         "",
+			"serial_number":  "",
+			"expiry_date":    "",
+			"days_remaining": 0,
+		}
+		licenses[i] = element
+
+		content, err := readFortinetLicense(licenseKey, licenseKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %w", name, err)
+		}
+
+		if content == "" {
+			continue
+		}
+
+		path := licenseKeyPath
+		if licenseKey != "" {
+			path = ""
+		}
+
+		license, err := parseFortinetLicense(content, path)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %w", name, err)
+		}
+
+		if other, ok := used[license.Hash]; ok {
+			return nil, fmt.Errorf("instances %q and %q use the same license", other, name)
+		}
+		used[license.Hash] = name
+
+		if !license.Expiry.IsZero() && license.Expiry.Before(now) {
+			return nil, fmt.Errorf("instance %q: license expired on %s",
+				name, license.Expiry.Format(expiryDateLayout))
+		}
+
+		element["sha256"] = license.Hash
+		element["serial_number"] = license.SerialNumber
+		if !license.Expiry.IsZero() {
+			element["expiry_date"] = license.Expiry.Format(expiryDateLayout)
+			element["days_remaining"], _ = daysRemaining(element["expiry_date"].(string), now)
+		}
+	}
+
+	return licenses, nil
+}
+
+// customizeDiffFortinetLicenses plans the licenses of the instances, so
+// that changing the content of a license file behind the same path
+// produces a diff.
+func customizeDiffFortinetLicenses(d *schema.ResourceDiff) error {
+	instances := d.Get("instances").([]interface{})
+
+	for i := range instances {
+		for _, key := range []string{"license_key", "license_key_file_path"} {
+			if !d.NewValueKnown(fmt.Sprintf("instances.%d.%s", i, key)) {
+				return d.SetNewComputed("licenses")
+			}
+		}
+	}
+
+	licenses, err := expandFortinetLicenses(instances, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !fortinetLicensesEqual(d.Get("licenses").([]interface{}), licenses) {
+		return d.SetNew("licenses", licenses)
+	}
+
+	return nil
+}
+
+// refreshFortinetLicenseDays updates the days remaining of the tracked
+// licenses.
+func refreshFortinetLicenseDays(licenses []interface{}) []interface{} {
+	for _, l := range licenses {
+		if license, ok := l.(map[string]interface{}); ok {
+			license["days_remaining"] = daysRemainingOrNil(license["expiry_date"].(string))
+		}
+	}
+
+	return licenses
+}
+
+// fortinetLicensesEqual compares licenses, ignoring the days remaining
+// which change every day.
+func fortinetLicensesEqual(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		x, _ := a[i].(map[string]interface{})
+		y, _ := b[i].(map[string]interface{})
+
+		for _, key := range []string{"name", "sha256", "serial_number", "expiry_date"} {
+			if fmt.Sprint(x[key]) != fmt.Sprint(y[key]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// changedFortinetLicenses returns the names of the instances whose
+// license changed since the last apply. Instances without a license
+// hash in the state haven't been tracked yet and never changed.
+func changedFortinetLicenses(d *schema.ResourceData) map[string]bool {
+	changed := make(map[string]bool)
+
+	old, new := d.GetChange("licenses")
+
+	hashes := make(map[string]string)
+	for _, l := range old.([]interface{}) {
+		if license, ok := l.(map[string]interface{}); ok {
+			hashes[license["name"].(string)] = license["sha256"].(string)
+		}
+	}
+
+	for _, l := range new.([]interface{}) {
+		license, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name := license["name"].(string)
+		if hash := hashes[name]; hash != "" && hash != license["sha256"].(string) {
+			changed[name] = true
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,380 @@
+package alkira
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFortinetLicense = "-----BEGIN FGT VM LICENSE-----\n" +
+	"QAAAAHRlc3QgbGljZW5zZSBvZiBhIEZvcnRpR2F0ZSBWTQ==\n" +
+	"-----END FGT VM LICENSE-----\n"
+
+func TestParseFortinetLicense(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		path       string
+		wantSerial string
+		wantExpiry string
+		wantErr    string
+	}{
+		{
+			name:       "serial number from file name",
+			content:    testFortinetLicense,
+			path:       "/licenses/FGVM04TM21000123.lic",
+			wantSerial: "FGVM04TM21000123",
+		},
+		{
+			name:    "other file name",
+			content: testFortinetLicense,
+			path:    "/licenses/branch.lic",
+		},
+		{
+			name: "serial number and expiry from header",
+			content: "Serial Number: FGVM08TM22000456\n" +
+				"Expiry Date: 2030-06-30\n" + testFortinetLicense,
+			path:       "/licenses/FGVM04TM21000123.lic",
+			wantSerial: "FGVM08TM22000456",
+			wantExpiry: "2030-06-30",
+		},
+		{
+			name:    "token",
+			content: "FLEX-TOKEN-0123456789",
+		},
+		{
+			name:    "missing end",
+			content: "-----BEGIN FGT VM LICENSE-----\nQAAAAHRlc3Q=\n",
+			wantErr: "missing -----END FGT VM LICENSE-----",
+		},
+		{
+			name:    "empty block",
+			content: "-----BEGIN FGT VM LICENSE-----\n-----END FGT VM LICENSE-----",
+			wantErr: "block is empty",
+		},
+		{
+			name:    "not base64",
+			content: "-----BEGIN FGT VM LICENSE-----\nnot base64!\n-----END FGT VM LICENSE-----",
+			wantErr: "not base64 encoded",
+		},
+		{
+			name:    "unknown expiry date",
+			content: "Expires: someday\n" + testFortinetLicense,
+			wantErr: "unknown expiry date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			license, err := parseFortinetLicense(tt.content, tt.path)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, license.Hash, 64)
+			assert.Equal(t, tt.wantSerial, license.SerialNumber)
+
+			if tt.wantExpiry == "" {
+				assert.True(t, license.Expiry.IsZero())
+			} else {
+				assert.Equal(t, tt.wantExpiry, license.Expiry.Format("2006-01-02"))
+			}
+		})
+	}
+
+	// The literal and the file() of the same license hash the same.
+	a, err := parseFortinetLicense(testFortinetLicense, "")
+	require.NoError(t, err)
+	b, err := parseFortinetLicense("  "+testFortinetLicense+"\n\n", "")
+	require.NoError(t, err)
+	assert.Equal(t, a.Hash, b.Hash)
+}
+
+func TestExpandFortinetLicenses(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	first := write("FGVM04TM21000123.lic", testFortinetLicense)
+	second := write("FGVM04TM21000124.lic", "Expires: 2027-01-01\n"+
+		"-----BEGIN FGT VM LICENSE-----\nc2Vjb25k\n-----END FGT VM LICENSE-----")
+	expired := write("expired.lic", "Expires: 2025-12-31\n"+testFortinetLicense)
+	empty := write("empty.lic", "")
+
+	instance := func(name, path, key string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":                  name,
+			"license_key_file_path": path,
+			"license_key":           key,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		instances []interface{}
+		want      []interface{}
+		wantErr   string
+	}{
+		{
+			name: "files",
+			instances: []interface{}{
+				instance("fw1", first, ""),
+				instance("fw2", second, ""),
+			},
+			want: []interface{}{
+				map[string]interface{}{
+					"name":           "fw1",
+					"sha256":         "-",
+					"serial_number":  "FGVM04TM21000123",
+					"expiry_date":    "",
+					"days_remaining": 0,
+				},
+				map[string]interface{}{
+					"name":           "fw2",
+					"sha256":         "-",
+					"serial_number":  "FGVM04TM21000124",
+					"expiry_date":    "2027-01-01",
+					"days_remaining": 365,
+				},
+			},
+		},
+		{
+			name: "literal has precedence and pay as you go has none",
+			instances: []interface{}{
+				instance("fw1", filepath.Join(dir, "missing.lic"), "FLEX-TOKEN"),
+				instance("fw2", "", ""),
+			},
+			want: []interface{}{
+				map[string]interface{}{
+					"name":           "fw1",
+					"sha256":         "-",
+					"serial_number":  "",
+					"expiry_date":    "",
+					"days_remaining": 0,
+				},
+				map[string]interface{}{
+					"name":           "fw2",
+					"sha256":         "",
+					"serial_number":  "",
+					"expiry_date":    "",
+					"days_remaining": 0,
+				},
+			},
+		},
+		{
+			name:      "missing file",
+			instances: []interface{}{instance("fw1", filepath.Join(dir, "missing.lic"), "")},
+			wantErr:   `instance "fw1": file not found`,
+		},
+		{
+			name:      "empty file",
+			instances: []interface{}{instance("fw1", empty, "")},
+			wantErr:   "is empty",
+		},
+		{
+			name:      "expired",
+			instances: []interface{}{instance("fw1", expired, "")},
+			wantErr:   `instance "fw1": license expired on 2025-12-31`,
+		},
+		{
+			name: "duplicate",
+			instances: []interface{}{
+				instance("fw1", first, ""),
+				instance("fw2", "", testFortinetLicense),
+			},
+			wantErr: `instances "fw1" and "fw2" use the same license`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			licenses, err := expandFortinetLicenses(tt.instances, now)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, licenses, len(tt.want))
+
+			for i, l := range licenses {
+				license := l.(map[string]interface{})
+				want := tt.want[i].(map[string]interface{})
+
+				if want["sha256"] == "-" {
+					assert.Len(t, license["sha256"], 64)
+					want["sha256"] = license["sha256"]
+				}
+				assert.Equal(t, want, license)
+			}
+		})
+	}
+}
+
+func TestFortinetLicenseContentDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "FGVM04TM21000123.lic")
+	require.NoError(t, os.WriteFile(path, []byte(testFortinetLicense), 0600))
+
+	r := resourceAlkiraServiceFortinet()
+	client := &alkira.AlkiraClient{}
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"cxp":                          "US-WEST",
+		"license_type":                 "BRING_YOUR_OWN",
+		"management_server_segment_id": "1",
+		"max_instance_count":           1,
+		"name":                         "fortinet",
+		"segment_ids":                  []interface{}{"1"},
+		"size":                         "SMALL",
+		"version":                      "7.2.0",
+		"instances": []interface{}{
+			map[string]interface{}{
+				"name":                  "fw1",
+				"license_key_file_path": path,
+			},
+		},
+	})
+
+	diff, err := r.Diff(context.Background(), nil, config, client)
+	require.NoError(t, err)
+
+	hash := diff.Attributes["licenses.0.sha256"].New
+	assert.Len(t, hash, 64)
+	assert.Equal(t, "FGVM04TM21000123", diff.Attributes["licenses.0.serial_number"].New)
+
+	state := &terraform.InstanceState{
+		ID: "1",
+		Attributes: map[string]string{
+			"id":                                "1",
+			"auto_scale":                        "OFF",
+			"cxp":                               "US-WEST",
+			"license_type":                      "BRING_YOUR_OWN",
+			"license_scheme":                    "TERM_BASED",
+			"management_server_segment_id":      "1",
+			"max_instance_count":                "1",
+			"min_instance_count":                "0",
+			"name":                              "fortinet",
+			"segment_ids.#":                     "1",
+			"segment_ids.0":                     "1",
+			"size":                              "SMALL",
+			"tunnel_protocol":                   "IPSEC",
+			"version":                           "7.2.0",
+			"instances.#":                       "1",
+			"instances.0.name":                  "fw1",
+			"instances.0.license_key_file_path": path,
+			"instances.0.license_key":           "",
+			"instances.0.serial_number":         "",
+			"instances.0.credential_id":         "c1",
+			"instances.0.id":                    "1",
+			"licenses.#":                        "1",
+			"licenses.0.name":                   "fw1",
+			"licenses.0.sha256":                 hash,
+			"licenses.0.serial_number":          "FGVM04TM21000123",
+			"licenses.0.expiry_date":            "",
+		},
+	}
+
+	// Same content, no diff.
+	diff, err = r.Diff(context.Background(), state, config, client)
+	require.NoError(t, err)
+	assert.True(t, diff == nil || diff.Empty(), "%v", diff)
+
+	// New content behind the same path.
+	require.NoError(t, os.WriteFile(path, []byte("Expires: 2999-01-01\n"+testFortinetLicense), 0600))
+
+	diff, err = r.Diff(context.Background(), state, config, client)
+	require.NoError(t, err)
+	require.NotNil(t, diff)
+	assert.NotEqual(t, hash, diff.Attributes["licenses.0.sha256"].New)
+	assert.Equal(t, "2999-01-01", diff.Attributes["licenses.0.expiry_date"].New)
+
+	// An expired license fails the plan.
+	require.NoError(t, os.WriteFile(path, []byte("Expires: 2001-01-01\n"+testFortinetLicense), 0600))
+
+	_, err = r.Diff(context.Background(), state, config, client)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "license expired on 2001-01-01")
+}
+
+func TestFortinetUpdateLicensesAfterUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		updateStatus int
+		wantErr      bool
+	}{
+		{name: "update succeeds", updateStatus: http.StatusOK},
+		{name: "update fails", updateStatus: http.StatusBadRequest, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/tenantnetworks/0/segments/1":
+					_, _ = w.Write([]byte(`{"id":1,"name":"seg1"}`))
+				case r.Method == http.MethodPost && r.URL.Path == "/api/credentials/"+string(alkira.CredentialTypeFortinetInstance):
+					_, _ = w.Write([]byte(`{"id":"c2"}`))
+				case r.Method == http.MethodPut && r.URL.Path == "/tenantnetworks/0/ftnt-fw-services/1":
+					w.WriteHeader(tt.updateStatus)
+					_, _ = w.Write([]byte(`{}`))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			})
+
+			r := resourceAlkiraServiceFortinet()
+			d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+				"cxp":                          "US-WEST",
+				"license_type":                 "BRING_YOUR_OWN",
+				"management_server_segment_id": "1",
+				"max_instance_count":           1,
+				"name":                         "fortinet",
+				"segment_ids":                  []interface{}{"1"},
+				"size":                         "SMALL",
+				"version":                      "7.2.0",
+				"instances": []interface{}{
+					map[string]interface{}{
+						"name":        "fw1",
+						"license_key": testFortinetLicense,
+					},
+				},
+			})
+			d.SetId("1")
+
+			diags := r.UpdateContext(context.Background(), d, client)
+
+			if tt.wantErr {
+				require.True(t, diags.HasError())
+				assert.Empty(t, d.Get("licenses"))
+				assert.NotContains(t, d.State().Attributes, "licenses.#")
+				return
+			}
+
+			require.False(t, diags.HasError(), "%v", diags)
+			require.Len(t, d.Get("licenses"), 1)
+			assert.Equal(t, "fw1", d.Get("licenses.0.name"))
+			assert.Len(t, d.Get("licenses.0.sha256"), 64)
+		})
+	}
+}
@@ -263,7 +263,7 @@ func resourceInfoblox(ctx context.Context, d *schema.ResourceData, m interface{}
 	api := alkira.NewServiceInfoblox(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateInfobloxRequest(d, m)
+	request, err := generateInfobloxRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -328,10 +328,10 @@ func resourceInfobloxRead(ctx context.Context, d *schema.ResourceData, m interfa
 		}}
 	}
 
-	setAllInfobloxResourceFields(d, infoblox)
+	setAllInfobloxResourceFields(ctx, d, infoblox)
 
 	// Convert segment names from API to segment IDs for state
-	segmentIds, err := convertSegmentNamesToSegmentIds(infoblox.Segments, m)
+	segmentIds, err := convertSegmentNamesToSegmentIds(ctx, infoblox.Segments, m)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -352,7 +352,7 @@ func resourceInfobloxUpdate(ctx context.Context, d *schema.ResourceData, m inter
 	api := alkira.NewServiceInfoblox(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateInfobloxRequest(d, m)
+	request, err := generateInfobloxRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -439,7 +439,7 @@ func resourceInfobloxDelete(ctx context.Context, d *schema.ResourceData, m inter
 	return nil
 }
 
-func generateInfobloxRequest(d *schema.ResourceData, m interface{}) (*alkira.ServiceInfoblox, error) {
+func generateInfobloxRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ServiceInfoblox, error) {
 	client := m.(*alkira.AlkiraClient)
 
 	//Create Infoblox Service Credential
@@ -484,7 +484,7 @@ func generateInfobloxRequest(d *schema.ResourceData, m interface{}) (*alkira.Ser
 	}
 
 	//segmentIdsToSegmentNames
-	segmentNames, err := convertSegmentIdsToSegmentNames(d.Get("segment_ids").(*schema.Set), m)
+	segmentNames, err := convertSegmentIdsToSegmentNames(ctx, d.Get("segment_ids").(*schema.Set), m)
 
 	if err != nil {
 		return nil, err
@@ -1,12 +1,13 @@
 package alkira
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -84,12 +85,15 @@ func expandInfobloxInstances(in []interface{}, m interface{}) ([]alkira.Infoblox
 	return instances, nil
 }
 
-func deflateInfobloxInstances(c []alkira.InfobloxInstance) []map[string]interface{} {
+func deflateInfobloxInstances(ctx context.Context, c []alkira.InfobloxInstance) []map[string]interface{} {
 	var m []map[string]interface{}
 	for _, v := range c {
 		id, err := v.Id.Int64()
 		if err != nil {
-			log.Printf("[WARN] failed to convert infoblox instance id %q to int: %s", v.Id, err)
+			tflog.SubsystemWarn(ctx, logSubsystemSchema, "failed to convert infoblox instance id to int", map[string]interface{}{
+				"instance_id": v.Id.String(),
+				"error":       err,
+			})
 		}
 		j := map[string]interface{}{
 			"anycast_enabled": v.AnyCastEnabled,
@@ -207,7 +211,7 @@ func deflateInfobloxAnycast(ia alkira.InfobloxAnycast) []map[string]interface{}
 	return []map[string]interface{}{m}
 }
 
-func setAllInfobloxResourceFields(d *schema.ResourceData, in *alkira.ServiceInfoblox) {
+func setAllInfobloxResourceFields(ctx context.Context, d *schema.ResourceData, in *alkira.ServiceInfoblox) {
 	if in == nil {
 		return
 	}
@@ -218,7 +222,7 @@ func setAllInfobloxResourceFields(d *schema.ResourceData, in *alkira.ServiceInfo
 	d.Set("description", in.Description)
 	d.Set("global_cidr_list_id", in.GlobalCidrListId)
 	d.Set("grid_master", deflateInfobloxGridMaster(in.GridMaster))
-	d.Set("instance", deflateInfobloxInstances(in.Instances))
+	d.Set("instance", deflateInfobloxInstances(ctx, in.Instances))
 	d.Set("license_type", in.LicenseType)
 	d.Set("service_group_name", in.ServiceGroupName)
 	d.Set("allow_list_id", in.AllowListId)
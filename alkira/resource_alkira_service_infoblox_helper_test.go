@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -97,7 +98,7 @@ func TestDeflateInfobloxInstances(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := deflateInfobloxInstances(tt.input)
+			result := deflateInfobloxInstances(context.Background(), tt.input)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -263,7 +263,7 @@ func TestAlkiraServiceInfoblox_setServiceInfobloxFields(t *testing.T) {
 		},
 	}
 
-	setAllInfobloxResourceFields(d, serviceInfoblox)
+	setAllInfobloxResourceFields(context.Background(), d, serviceInfoblox)
 
 	// Verify name is set in state (the fix for AK-67145)
 	assert.Equal(t, "test-infoblox-service", d.Get("name").(string))
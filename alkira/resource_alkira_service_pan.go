@@ -3,10 +3,10 @@ package alkira
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -30,6 +30,22 @@ func resourceAlkiraServicePan() *schema.Resource {
 				d.SetNew("provision_state", "SUCCESS")
 			}
 
+			for _, key := range []string{"master_key", "registration_pin"} {
+				if !d.HasChange(key + "_expiry") {
+					continue
+				}
+
+				if days := daysRemainingOrNil(d.Get(key + "_expiry").(string)); days != nil {
+					d.SetNew(key+"_days_remaining", days)
+				} else {
+					d.SetNewComputed(key + "_days_remaining")
+				}
+			}
+
+			if d.Id() != "" && d.HasChanges(panRegistrationCredentialKeys...) {
+				d.SetNewComputed("pan_registration_credential_id")
+			}
+
 			cxpName := d.Get("cxp").(string)
 			minCount := d.Get("min_instance_count").(int)
 			maxCount := d.Get("max_instance_count").(int)
@@ -38,7 +54,10 @@ func resourceAlkiraServicePan() *schema.Resource {
 			cxp, _, err := cxpAPI.GetByName(cxpName)
 
 			if err != nil {
-				log.Printf("[WARNING] Unable to get CXP information for validation: %v", err)
+				tflog.Warn(ctx, "unable to get CXP information for validation", map[string]interface{}{
+					"cxp":   cxpName,
+					"error": err.Error(),
+				})
 				return nil
 			}
 
@@ -202,8 +221,16 @@ func resourceAlkiraServicePan() *schema.Resource {
 						"auth_expiry": {
 							Description: "PAN Auth Expiry. The date should be in " +
 								"format of `YYYY-MM-DD`, e.g. `2000-01-01`.",
-							Type:     schema.TypeString,
-							Optional: true,
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateExpiryDate,
+						},
+						"auth_days_remaining": {
+							Description: "The number of days until " +
+								"`auth_expiry`, negative once it has " +
+								"passed. Not set without `auth_expiry`.",
+							Type:     schema.TypeInt,
+							Computed: true,
 						},
 						"credential_id": {
 							Description: "ID of PAN instance credential.",
@@ -318,8 +345,16 @@ func resourceAlkiraServicePan() *schema.Resource {
 			"master_key_expiry": {
 				Description: "PAN Master Key Expiry. The date should be in " +
 					"format of `YYYY-MM-DD`, e.g. `2000-01-01`.",
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateExpiryDate,
+			},
+			"master_key_days_remaining": {
+				Description: "The number of days until `master_key_expiry`, " +
+					"negative once it has passed. Not set without " +
+					"`master_key_expiry`.",
+				Type:     schema.TypeInt,
+				Computed: true,
 			},
 			"max_instance_count": {
 				Description: "Max number of Panorama instances for auto scale. " +
@@ -343,15 +378,42 @@ func resourceAlkiraServicePan() *schema.Resource {
 				Required:    true,
 			},
 			"registration_pin_value": {
-				Description: "PAN Registration PIN Value.",
-				Type:        schema.TypeString,
-				Required:    true,
+				Description: "PAN Registration PIN Value. Either " +
+					"`registration_pin_value` or " +
+					"`registration_pin_value_wo` must be set.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"registration_pin_value", "registration_pin_value_wo"},
+			},
+			"registration_pin_value_wo": {
+				Description: "PAN Registration PIN Value as a write-only " +
+					"argument, which is never stored in plan or state. " +
+					"Requires Terraform 1.11 or later.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				WriteOnly:    true,
+				Sensitive:    true,
+				ExactlyOneOf: []string{"registration_pin_value", "registration_pin_value_wo"},
+			},
+			"registration_pin_value_wo_version": {
+				Description: "Change this value to update the service " +
+					"with a new `registration_pin_value_wo`.",
+				Type:     schema.TypeInt,
+				Optional: true,
 			},
 			"registration_pin_expiry": {
 				Description: "PAN Registration PIN Expiry. The date " +
 					"should be in format of `YYYY-MM-DD`, e.g. `2000-01-01`.",
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateExpiryDate,
+			},
+			"registration_pin_days_remaining": {
+				Description: "The number of days until " +
+					"`registration_pin_expiry`, negative once it has " +
+					"passed. Not set without `registration_pin_expiry`.",
+				Type:     schema.TypeInt,
+				Computed: true,
 			},
 			"name": {
 				Description: "Name of the PAN service.",
@@ -392,6 +454,7 @@ func resourceAlkiraServicePan() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{
 					"VM-300", "VM-500", "VM-700", "VM-SIM"}, false),
 			},
+			"update_strategy": updateStrategySchema(),
 			"version": {
 				Description: "The version of the PAN firewall. Please check " +
 					"Alkira Portal for all supported versions.",
@@ -436,13 +499,13 @@ func resourceServicePanCreate(ctx context.Context, d *schema.ResourceData, m int
 	api := alkira.NewServicePan(client)
 
 	// Create credentials
-	err := createCredentials(d, client)
+	err := createCredentials(ctx, d, client)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	// Construct request
-	request, err := generateServicePanRequest(d, m)
+	request, err := generateServicePanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -511,8 +574,8 @@ func resourceServicePanRead(ctx context.Context, d *schema.ResourceData, m inter
 	d.Set("bundle", pan.Bundle)
 	d.Set("cxp", pan.CXP)
 	d.Set("global_protect_enabled", pan.GlobalProtectEnabled)
-	d.Set("global_protect_segment_options", flattenGlobalProtectSegmentOptions(pan.GlobalProtectSegmentOptions, m))
-	d.Set("instance", setPanInstances(d, pan.Instances, m))
+	d.Set("global_protect_segment_options", flattenGlobalProtectSegmentOptions(ctx, pan.GlobalProtectSegmentOptions, m))
+	d.Set("instance", setPanInstances(ctx, d, pan.Instances, m))
 	d.Set("license_type", pan.LicenseType)
 	d.Set("license_sub_type", pan.SubLicenseType)
 	d.Set("management_segment_id", pan.ManagementSegmentId)
@@ -523,7 +586,7 @@ func resourceServicePanRead(ctx context.Context, d *schema.ResourceData, m inter
 	d.Set("panorama_enabled", pan.PanoramaEnabled)
 	d.Set("panorama_ip_addresses", pan.PanoramaIpAddresses)
 	d.Set("segment_ids", pan.SegmentIds)
-	d.Set("segment_options", deflateSegmentOptions(pan.SegmentOptions))
+	d.Set("segment_options", deflateSegmentOptions(ctx, pan.SegmentOptions))
 	d.Set("size", pan.Size)
 	d.Set("tunnel_protocol", pan.TunnelProtocol)
 	d.Set("type", pan.Type)
@@ -541,10 +604,16 @@ func resourceServicePanRead(ctx context.Context, d *schema.ResourceData, m inter
 		if cred, err := client.GetCredentialById(pan.CredentialId); err == nil {
 			d.Set("pan_credential_name", cred.Name)
 		} else {
-			log.Printf("[WARNING] Failed to get PAN credential name: %v", err)
+			tflog.Warn(ctx, "failed to get PAN credential name", map[string]interface{}{
+				"credential_id": pan.CredentialId,
+				"error":         err.Error(),
+			})
 		}
 	}
 
+	d.Set("master_key_days_remaining", daysRemainingOrNil(d.Get("master_key_expiry").(string)))
+	d.Set("registration_pin_days_remaining", daysRemainingOrNil(d.Get("registration_pin_expiry").(string)))
+
 	if pan.PanoramaDeviceGroup != nil {
 		d.Set("panorama_device_group", pan.PanoramaDeviceGroup)
 	}
@@ -568,20 +637,20 @@ func resourceServicePanUpdate(ctx context.Context, d *schema.ResourceData, m int
 	api := alkira.NewServicePan(client)
 
 	// Update all credentials
-	err := updateCredentials(d, client)
+	err := updateCredentials(ctx, d, client)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	// Construct request
-	request, err := generateServicePanRequest(d, m)
+	request, err := generateServicePanRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	// UPDATE
-	provState, err, valErr, provErr := api.Update(d.Id(), request)
+	provState, err, valErr, provErr := updateServiceInstances(ctx, d, api, request)
 
 	if err != nil {
 		return diag.FromErr(err)
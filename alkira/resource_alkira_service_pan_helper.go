@@ -1,10 +1,13 @@
 package alkira
 
 import (
+	"context"
 	"errors"
-	"log"
+	"fmt"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -16,8 +19,8 @@ import (
 // }
 
 // Helper functions for PAN credentials
-func createPanCredential(d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
-	log.Printf("[INFO] Creating PAN Credential")
+func createPanCredential(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
+	tflog.Info(ctx, "creating PAN credential")
 
 	credentialName := d.Get("name").(string) + randomNameSuffix()
 	credential := alkira.CredentialPan{
@@ -30,11 +33,9 @@ func createPanCredential(d *schema.ResourceData, c *alkira.AlkiraClient) (string
 	return c.CreateCredential(credentialName, alkira.CredentialTypePan, credential, 0)
 }
 
-func updatePanCredential(d *schema.ResourceData, c *alkira.AlkiraClient) error {
-	log.Printf("[INFO] Updating PAN Credential")
-
+func updatePanCredential(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) error {
 	if d.HasChanges("pan_username", "pan_password", "pan_license_key") {
-		log.Printf("[INFO] PAN credential has changed")
+		tflog.Info(ctx, "updating PAN credential")
 
 		if d.Get("pan_credential_id") == nil {
 			return errors.New("pan_credential_id is empty when updating PAN credential")
@@ -64,33 +65,65 @@ func updatePanCredential(d *schema.ResourceData, c *alkira.AlkiraClient) error {
 // }
 
 // Helper functions for PAN Registration Credentials
-func createPanRegistrationCredential(d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
+func createPanRegistrationCredential(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
 	var credentialExpiry int64
 	var expiryValue string
 
 	if v, ok := d.GetOk("registration_pin_expiry"); ok {
 		expiryValue = v.(string)
-		log.Printf("[INFO] Creating PAN Registration Credential with expiry %v", expiryValue)
 		var err error
 		credentialExpiry, err = convertInputTimeToEpoch(expiryValue)
 		if err != nil {
-			log.Printf("[ERROR] failed to parse 'registration_pin_expiry', %v", err)
-			return "", err
+			return "", fmt.Errorf("failed to parse 'registration_pin_expiry': %w", err)
 		}
 	} else {
-		log.Printf("[INFO] Creating PAN Registration Credential with no expiry")
 		credentialExpiry = 0
 	}
 
+	tflog.Info(ctx, "creating PAN registration credential", map[string]interface{}{
+		"registration_pin_expiry": expiryValue,
+	})
+
+	pinValue, err := getWriteOnlyString(d, cty.GetAttrPath("registration_pin_value_wo"))
+	if err != nil {
+		return "", err
+	}
+	if pinValue == "" {
+		pinValue = d.Get("registration_pin_value").(string)
+	}
+
 	credentialName := d.Get("name").(string) + randomNameSuffix()
 	credential := alkira.CredentialPanRegistration{
 		RegistrationPinId:    d.Get("registration_pin_id").(string),
-		RegistrationPinValue: d.Get("registration_pin_value").(string),
+		RegistrationPinValue: pinValue,
 	}
 
 	return c.CreateCredential(credentialName, alkira.CredentialTypePanRegistration, credential, credentialExpiry)
 }
 
+// panRegistrationCredentialKeys are the attributes of the PAN
+// registration credential, which is created anew when any of them
+// changes.
+var panRegistrationCredentialKeys = []string{
+	"registration_pin_id",
+	"registration_pin_value",
+	"registration_pin_value_wo_version",
+}
+
+func updatePanRegistrationCredential(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) error {
+	if !d.HasChanges(panRegistrationCredentialKeys...) {
+		return nil
+	}
+
+	credentialId, err := createPanRegistrationCredential(ctx, d, c)
+	if err != nil {
+		return err
+	}
+	d.Set("pan_registration_credential_id", credentialId)
+
+	return nil
+}
+
 // UNUSED: Commented out to suppress linter warnings
 // func deletePanRegistrationCredential(id string, c *alkira.AlkiraClient) error {
 // 	log.Printf("[INFO] Deleting PAN Registration Credential")
@@ -98,14 +131,16 @@ func createPanRegistrationCredential(d *schema.ResourceData, c *alkira.AlkiraCli
 // }
 
 // Helper function for PAN Master Key Credential
-func createPanMasterKeyCredential(d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
-	log.Printf("[INFO] Creating PAN Master Key Credential %v", d.Get("master_key_expiry").(string))
-
+func createPanMasterKeyCredential(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) (string, error) {
 	if !d.Get("master_key_enabled").(bool) {
-		log.Printf("[INFO] PAN master key is not enabled, skip creating credential")
+		tflog.Debug(ctx, "PAN master key is not enabled, skip creating credential")
 		return "", nil
 	}
 
+	tflog.Info(ctx, "creating PAN master key credential", map[string]interface{}{
+		"master_key_expiry": d.Get("master_key_expiry").(string),
+	})
+
 	credentialName := d.Get("name").(string) + randomNameSuffix()
 	credential := alkira.CredentialPanMasterKey{
 		MasterKey: d.Get("master_key").(string),
@@ -114,12 +149,11 @@ func createPanMasterKeyCredential(d *schema.ResourceData, c *alkira.AlkiraClient
 	credentialExpiry, err := convertInputTimeToEpoch(d.Get("master_key_expiry").(string))
 
 	if err != nil {
-		log.Printf("[ERROR] failed to parse 'master_key_expiry', %v", err)
-		return "", err
+		return "", fmt.Errorf("failed to parse 'master_key_expiry': %w", err)
 	}
 
 	if credentialExpiry == 0 {
-		log.Printf("[ERROR] argument 'master_key_expiry' is required when master key was enabled.")
+		tflog.Error(ctx, "argument 'master_key_expiry' is required when master key was enabled")
 		return "", err
 	}
 
@@ -137,10 +171,10 @@ func createPanMasterKeyCredential(d *schema.ResourceData, c *alkira.AlkiraClient
 // - PAN Credential
 // - PAN Registration Credential
 // - PAN Master Key Credential
-func createCredentials(d *schema.ResourceData, c *alkira.AlkiraClient) error {
+func createCredentials(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) error {
 
 	// Create PAN credentail
-	panCredentialId, err := createPanCredential(d, c)
+	panCredentialId, err := createPanCredential(ctx, d, c)
 	if err != nil {
 		return err
 	}
@@ -148,14 +182,14 @@ func createCredentials(d *schema.ResourceData, c *alkira.AlkiraClient) error {
 	d.Set("pan_credential_id", panCredentialId)
 
 	// Create PAN Registration Credential
-	panRegistrationCredentialId, err := createPanRegistrationCredential(d, c)
+	panRegistrationCredentialId, err := createPanRegistrationCredential(ctx, d, c)
 	if err != nil {
 		return err
 	}
 	d.Set("pan_registration_credential_id", panRegistrationCredentialId)
 
 	// Create PAN Master Key Credential
-	panMasterKeyCredentialId, err := createPanMasterKeyCredential(d, c)
+	panMasterKeyCredentialId, err := createPanMasterKeyCredential(ctx, d, c)
 	if err != nil {
 		return err
 	}
@@ -164,10 +198,16 @@ func createCredentials(d *schema.ResourceData, c *alkira.AlkiraClient) error {
 	return nil
 }
 
-func updateCredentials(d *schema.ResourceData, c *alkira.AlkiraClient) error {
+func updateCredentials(ctx context.Context, d *schema.ResourceData, c *alkira.AlkiraClient) error {
 
 	// Update PAN credentail
-	err := updatePanCredential(d, c)
+	err := updatePanCredential(ctx, d, c)
+	if err != nil {
+		return err
+	}
+
+	// Update PAN Registration Credential
+	err = updatePanRegistrationCredential(ctx, d, c)
 	if err != nil {
 		return err
 	}
@@ -238,7 +278,7 @@ func expandGlobalProtectSegmentOptionsInstance(in *schema.Set, m interface{}) (m
 	return sgmtOptions, nil
 }
 
-func flattenGlobalProtectSegmentOptions(in map[string]*alkira.GlobalProtectSegmentName, m interface{}) []map[string]interface{} {
+func flattenGlobalProtectSegmentOptions(ctx context.Context, in map[string]*alkira.GlobalProtectSegmentName, m interface{}) []map[string]interface{} {
 
 	if len(in) == 0 {
 		return nil
@@ -249,7 +289,10 @@ func flattenGlobalProtectSegmentOptions(in map[string]*alkira.GlobalProtectSegme
 	for segmentName, option := range in {
 		segmentId, err := getSegmentIdByName(segmentName, m)
 		if err != nil {
-			log.Printf("[WARNING] Failed to get segment ID for segment name %s: %v", segmentName, err)
+			tflog.SubsystemWarn(ctx, logSubsystemSchema, "failed to get segment id for segment name", map[string]interface{}{
+				"segment_name": segmentName,
+				"error":        err,
+			})
 			continue
 		}
 
@@ -265,7 +308,7 @@ func flattenGlobalProtectSegmentOptions(in map[string]*alkira.GlobalProtectSegme
 	return options
 }
 
-func flattenGlobalProtectSegmentOptionsInstance(in map[string]*alkira.GlobalProtectSegmentNameInstance, m interface{}) []map[string]interface{} {
+func flattenGlobalProtectSegmentOptionsInstance(ctx context.Context, in map[string]*alkira.GlobalProtectSegmentNameInstance, m interface{}) []map[string]interface{} {
 
 	if len(in) == 0 {
 		return nil
@@ -276,7 +319,10 @@ func flattenGlobalProtectSegmentOptionsInstance(in map[string]*alkira.GlobalProt
 	for segmentName, option := range in {
 		segmentId, err := getSegmentIdByName(segmentName, m)
 		if err != nil {
-			log.Printf("[WARNING] Failed to get segment ID for segment name %s: %v", segmentName, err)
+			tflog.SubsystemWarn(ctx, logSubsystemSchema, "failed to get segment id for segment name", map[string]interface{}{
+				"segment_name": segmentName,
+				"error":        err,
+			})
 			continue
 		}
 
@@ -343,7 +389,7 @@ func flattenGlobalProtectSegmentOptionsInstance(in map[string]*alkira.GlobalProt
 // }
 
 // expand "instance" block from config to generate request payload
-func expandPanInstances(in []interface{}, m interface{}) ([]alkira.ServicePanInstance, error) {
+func expandPanInstances(ctx context.Context, in []interface{}, m interface{}) ([]alkira.ServicePanInstance, error) {
 	client := m.(*alkira.AlkiraClient)
 
 	if len(in) == 0 {
@@ -377,7 +423,9 @@ func expandPanInstances(in []interface{}, m interface{}) ([]alkira.ServicePanIns
 				authExpiry, err = convertInputTimeToEpoch(v)
 
 				if err != nil {
-					log.Printf("[ERROR] failed to parse 'auth_expiry', %v", err)
+					tflog.SubsystemError(ctx, logSubsystemSchema, "failed to parse auth_expiry", map[string]interface{}{
+						"error": err,
+					})
 					return nil, err
 				}
 			}
@@ -390,7 +438,9 @@ func expandPanInstances(in []interface{}, m interface{}) ([]alkira.ServicePanIns
 					AuthKey:  authKey,
 				}
 
-				log.Printf("[INFO] Creating PAN Instance Credential %s", credentialName)
+				tflog.SubsystemInfo(ctx, logSubsystemCredential, "creating pan instance credential", map[string]interface{}{
+					"credential_name": credentialName,
+				})
 				credentialId, err := client.CreateCredential(
 					credentialName,
 					alkira.CredentialTypePanInstance,
@@ -425,7 +475,7 @@ func expandPanInstances(in []interface{}, m interface{}) ([]alkira.ServicePanIns
 }
 
 // generate request payload
-func generateServicePanRequest(d *schema.ResourceData, m interface{}) (*alkira.ServicePan, error) {
+func generateServicePanRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ServicePan, error) {
 
 	panoramaDeviceGroup := d.Get("panorama_device_group").(string)
 	panoramaIpAddresses := convertTypeListToStringList(d.Get("panorama_ip_addresses").([]interface{}))
@@ -434,7 +484,7 @@ func generateServicePanRequest(d *schema.ResourceData, m interface{}) (*alkira.S
 	//
 	// Construct instances
 	//
-	instances, err := expandPanInstances(d.Get("instance").([]interface{}), m)
+	instances, err := expandPanInstances(ctx, d.Get("instance").([]interface{}), m)
 
 	if err != nil {
 		return nil, err
@@ -492,7 +542,7 @@ func generateServicePanRequest(d *schema.ResourceData, m interface{}) (*alkira.S
 }
 
 // Set "instance" blocks from API response
-func setPanInstances(d *schema.ResourceData, c []alkira.ServicePanInstance, m interface{}) []map[string]interface{} {
+func setPanInstances(ctx context.Context, d *schema.ResourceData, c []alkira.ServicePanInstance, m interface{}) []map[string]interface{} {
 	var instances []map[string]interface{}
 
 	for _, ins := range c {
@@ -517,9 +567,10 @@ func setPanInstances(d *schema.ResourceData, c []alkira.ServicePanInstance, m in
 			"auth_key":                       authKey,
 			"auth_code":                      authCode,
 			"auth_expiry":                    authExpiry,
+			"auth_days_remaining":            daysRemainingOrNil(authExpiry),
 			"credential_id":                  ins.CredentialId,
 			"enable_traffic":                 ins.TrafficEnabled,
-			"global_protect_segment_options": flattenGlobalProtectSegmentOptionsInstance(ins.GlobalProtectSegmentOptions, m),
+			"global_protect_segment_options": flattenGlobalProtectSegmentOptionsInstance(ctx, ins.GlobalProtectSegmentOptions, m),
 		}
 		instances = append(instances, instance)
 	}
@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -140,7 +141,7 @@ func TestAlkiraServicePanExpandPanInstances(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	result, err := expandPanInstances(instances, mockClient)
+	result, err := expandPanInstances(context.Background(), instances, mockClient)
 	require.NoError(t, err, "expandPanInstances should not return error")
 	require.Len(t, result, 2, "Should return 2 instances")
 
@@ -174,7 +175,7 @@ func TestAlkiraServicePanSetPanInstances(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	result := setPanInstances(d, instances, mockClient)
+	result := setPanInstances(context.Background(), d, instances, mockClient)
 	require.Len(t, result, 2, "Should return 2 instances")
 
 	// Check first instance
@@ -204,11 +205,39 @@ func TestAlkiraServicePanCreatePanCredential(t *testing.T) {
 	d.Set("pan_password", "test-password")
 	d.Set("pan_license_key", "test-license-key")
 
-	credentialId, err := createPanCredential(d, client)
+	credentialId, err := createPanCredential(context.Background(), d, client)
 	require.NoError(t, err, "createPanCredential should not return error")
 	assert.NotEmpty(t, credentialId, "Credential ID should not be empty")
 }
 
+func TestAlkiraServicePanUpdatePanRegistrationCredential(t *testing.T) {
+	requests := 0
+	client := createMockAlkiraClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": "credential-456"}`))
+	})
+
+	r := resourceAlkiraServicePan()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name": "test-pan-service",
+	})
+
+	require.NoError(t, updatePanRegistrationCredential(context.Background(), d, client))
+	assert.Equal(t, 0, requests, "Unchanged registration PIN should not create a credential")
+
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":                              "test-pan-service",
+		"registration_pin_id":               "pin-id",
+		"registration_pin_value_wo_version": 2,
+	})
+
+	require.NoError(t, updatePanRegistrationCredential(context.Background(), d, client))
+	assert.Equal(t, 1, requests, "Changed registration PIN should create a credential")
+	assert.Equal(t, "credential-456", d.Get("pan_registration_credential_id"))
+}
+
 func TestAlkiraServicePanFlattenGlobalProtectSegmentOptions(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -279,7 +308,7 @@ func TestAlkiraServicePanFlattenGlobalProtectSegmentOptions(t *testing.T) {
 				w.Write(jsonBytes)
 			})
 
-			result := flattenGlobalProtectSegmentOptions(tt.input, mockClient)
+			result := flattenGlobalProtectSegmentOptions(context.Background(), tt.input, mockClient)
 
 			if tt.expected == 0 {
 				assert.Nil(t, result, "Expected nil result for empty/nil input")
@@ -321,7 +350,7 @@ func TestAlkiraServicePanFlattenGlobalProtectSegmentOptionsValues(t *testing.T)
 		w.Write(jsonBytes)
 	})
 
-	result := flattenGlobalProtectSegmentOptions(input, mockClient)
+	result := flattenGlobalProtectSegmentOptions(context.Background(), input, mockClient)
 
 	require.Len(t, result, 1, "Should return 1 option")
 	assert.Equal(t, "999", result[0]["segment_id"], "segment_id should be 999")
@@ -397,7 +426,7 @@ func TestAlkiraServicePanFlattenGlobalProtectSegmentOptionsInstance(t *testing.T
 				w.Write(jsonBytes)
 			})
 
-			result := flattenGlobalProtectSegmentOptionsInstance(tt.input, mockClient)
+			result := flattenGlobalProtectSegmentOptionsInstance(context.Background(), tt.input, mockClient)
 
 			if tt.expected == 0 {
 				assert.Nil(t, result, "Expected nil result for empty/nil input")
@@ -439,7 +468,7 @@ func TestAlkiraServicePanFlattenGlobalProtectSegmentOptionsInstanceValues(t *tes
 		w.Write(jsonBytes)
 	})
 
-	result := flattenGlobalProtectSegmentOptionsInstance(input, mockClient)
+	result := flattenGlobalProtectSegmentOptionsInstance(context.Background(), input, mockClient)
 
 	require.Len(t, result, 1, "Should return 1 option")
 	assert.Equal(t, "888", result[0]["segment_id"], "segment_id should be 888")
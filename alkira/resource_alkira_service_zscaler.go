@@ -219,7 +219,7 @@ func resourceZscaler(ctx context.Context, d *schema.ResourceData, m interface{})
 	api := alkira.NewServiceZscaler(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateZscalerRequest(d, m)
+	request, err := generateZscalerRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -284,7 +284,7 @@ func resourceZscalerRead(ctx context.Context, d *schema.ResourceData, m interfac
 		}}
 	}
 
-	segmentIds, err := convertSegmentNamesToSegmentIds(z.Segments, m)
+	segmentIds, err := convertSegmentNamesToSegmentIds(ctx, z.Segments, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -321,7 +321,7 @@ func resourceZscalerUpdate(ctx context.Context, d *schema.ResourceData, m interf
 	api := alkira.NewServiceZscaler(m.(*alkira.AlkiraClient))
 
 	// Construct request
-	request, err := generateZscalerRequest(d, m)
+	request, err := generateZscalerRequest(ctx, d, m)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -409,7 +409,7 @@ func resourceZscalerDelete(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 // generateZscalerRequest generate service-zscaler request
-func generateZscalerRequest(d *schema.ResourceData, m interface{}) (*alkira.ServiceZscaler, error) {
+func generateZscalerRequest(ctx context.Context, d *schema.ResourceData, m interface{}) (*alkira.ServiceZscaler, error) {
 
 	cfgs, err := expandZscalerIpsecConfigurations(d.Get("ipsec_configuration").(*schema.Set))
 
@@ -417,7 +417,7 @@ func generateZscalerRequest(d *schema.ResourceData, m interface{}) (*alkira.Serv
 		return nil, err
 	}
 
-	segmentNames, err := convertSegmentIdsToSegmentNames(d.Get("segment_ids").(*schema.Set), m)
+	segmentNames, err := convertSegmentIdsToSegmentNames(ctx, d.Get("segment_ids").(*schema.Set), m)
 
 	if err != nil {
 		return nil, err
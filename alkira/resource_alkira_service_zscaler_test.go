@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -41,7 +42,7 @@ func TestGenerateRequesetServiceZscaler(t *testing.T) {
 	d.Set("tunnel_protocol", z.TunnelType)
 
 	client := serveZscaler(t, z)
-	actual, err := generateZscalerRequest(d, client)
+	actual, err := generateZscalerRequest(context.Background(), d, client)
 	require.NoError(t, err)
 	require.Equal(t, expectedIpSecConfig, *actual.IpsecConfiguration)
 }
@@ -0,0 +1,223 @@
+package alkira
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceAlkiraUser() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manage users of the portal.\n\n" +
+			"The user groups of a user can either be managed with " +
+			"`group_ids` or with `alkira_group_user_membership`, but " +
+			"not both for the same group.",
+		CreateContext: resourceUser,
+		ReadContext:   resourceUserRead,
+		UpdateContext: resourceUserUpdate,
+		DeleteContext: resourceUserDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importWithReadValidation(resourceUserRead),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"user_name": {
+				Description: "The user name to log into the portal with. " +
+					"Changing it replaces the user.",
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"email": {
+				Description: "The email address of the user.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"first_name": {
+				Description: "The first name of the user.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"last_name": {
+				Description: "The last name of the user.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"group_ids": {
+				Description: "The IDs of the user groups (`alkira_group_user`) " +
+					"of the user. When not set, the groups of the user are " +
+					"left untouched.",
+				Type:     schema.TypeSet,
+				Optional: true,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceUser(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := newPortalUser(m.(*alkira.AlkiraClient))
+
+	user := buildUserRequest(d, nil)
+
+	resource, _, err, valErr, _ := api.Create(user)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(resource.Id)
+
+	// Handle validation error
+	client := m.(*alkira.AlkiraClient)
+	if client.Validate && valErr != nil {
+		var diags diag.Diagnostics
+		readDiags := resourceUserRead(ctx, d, m)
+		if readDiags.HasError() {
+			diags = append(diags, readDiags...)
+		}
+
+		// Add the validation error
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "VALIDATION (CREATE) FAILED",
+			Detail:   fmt.Sprintf("%s", valErr),
+		})
+
+		return diags
+	}
+
+	return resourceUserRead(ctx, d, m)
+}
+
+func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := newPortalUser(m.(*alkira.AlkiraClient))
+
+	user, _, err := api.GetById(d.Id())
+
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "FAILED TO GET RESOURCE",
+			Detail:   fmt.Sprintf("%s", err),
+		}}
+	}
+
+	groups := append([]string{}, user.UserGroups...)
+	sort.Strings(groups)
+
+	d.Set("user_name", user.UserName)
+	d.Set("email", user.Email)
+	d.Set("first_name", user.FirstName)
+	d.Set("last_name", user.LastName)
+	d.Set("group_ids", groups)
+
+	return nil
+}
+
+func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := newPortalUserObject(m.(*alkira.AlkiraClient))
+
+	// The user is updated from its current object, which keeps the
+	// attributes the resource doesn't manage. Its groups may be managed
+	// by memberships, which must be kept when group_ids isn't set.
+	current, _, err := api.GetById(d.Id())
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	currentUser, err := decodePortalUser(*current)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	mergePortalUser(*current, buildUserRequest(d, currentUser.UserGroups))
+
+	tflog.Info(ctx, "updating user")
+	_, err, valErr, _ := api.Update(d.Id(), current)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Handle validation error
+	client := m.(*alkira.AlkiraClient)
+	if client.Validate && valErr != nil {
+		var diags diag.Diagnostics
+		readDiags := resourceUserRead(ctx, d, m)
+		if readDiags.HasError() {
+			diags = append(diags, readDiags...)
+		}
+
+		// Add the validation error
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "VALIDATION (UPDATE) FAILED",
+			Detail:   fmt.Sprintf("%s", valErr),
+		})
+
+		return diags
+	}
+
+	return resourceUserRead(ctx, d, m)
+}
+
+func resourceUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := newPortalUser(m.(*alkira.AlkiraClient))
+
+	_, err, valErr, _ := api.Delete(d.Id())
+
+	if err != nil {
+		// Terraform may not print "with <resource address>" for destroys of objects
+		// that are no longer in configuration, so include identifying context here.
+		name, _ := d.GetOk("user_name")
+		if nameStr, ok := name.(string); ok && nameStr != "" {
+			return diag.FromErr(fmt.Errorf("%w alkira_user (user_name=%q id=%s)", err, nameStr, d.Id()))
+		}
+		return diag.FromErr(fmt.Errorf("%w alkira_user (id=%s)", err, d.Id()))
+	}
+
+	// Handle validation error
+	client := m.(*alkira.AlkiraClient)
+	if client.Validate && valErr != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "VALIDATION (DELETE) FAILED",
+			Detail:   fmt.Sprintf("%s", valErr),
+		}}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// buildUserRequest builds the user from the resource. The groups of
+// the user are the configured group_ids, or the given current groups
+// when group_ids isn't configured.
+func buildUserRequest(d *schema.ResourceData, currentGroups []string) *portalUser {
+	groups := currentGroups
+
+	if !d.GetRawConfig().IsNull() && !d.GetRawConfig().GetAttr("group_ids").IsNull() {
+		groups = convertTypeSetToStringList(d.Get("group_ids").(*schema.Set))
+	}
+
+	if groups == nil {
+		groups = []string{}
+	}
+
+	return &portalUser{
+		UserName:   d.Get("user_name").(string),
+		Email:      d.Get("email").(string),
+		FirstName:  d.Get("first_name").(string),
+		LastName:   d.Get("last_name").(string),
+		UserGroups: groups,
+	}
+}
@@ -0,0 +1,202 @@
+package alkira
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+)
+
+// portalUser is a user of the portal. The groups of a user are the IDs
+// of the user groups (alkira_group_user) the user is a member of.
+type portalUser struct {
+	Id         string   `json:"id,omitempty"`
+	UserName   string   `json:"userName"`
+	Email      string   `json:"email"`
+	FirstName  string   `json:"firstName"`
+	LastName   string   `json:"lastName"`
+	UserGroups []string `json:"userGroups"`
+}
+
+// newPortalUser returns the API of the portal users, which the client
+// doesn't provide.
+func newPortalUser(ac *alkira.AlkiraClient) *alkira.AlkiraAPI[portalUser] {
+	return &alkira.AlkiraAPI[portalUser]{
+		Client: ac,
+		Uri:    fmt.Sprintf("%s/users", ac.URI),
+	}
+}
+
+// newPortalUserObject returns the API of the portal users as raw JSON
+// objects. Users are updated through it, so that the attributes of a
+// user that portalUser doesn't model are put back unchanged.
+func newPortalUserObject(ac *alkira.AlkiraClient) *alkira.AlkiraAPI[map[string]interface{}] {
+	return &alkira.AlkiraAPI[map[string]interface{}]{
+		Client: ac,
+		Uri:    fmt.Sprintf("%s/users", ac.URI),
+	}
+}
+
+// getAllPortalUsers returns all users of the portal.
+func getAllPortalUsers(client *alkira.AlkiraClient) ([]portalUser, error) {
+	users, _, err := getAllPortalUserObjects(client)
+	return users, err
+}
+
+// getAllPortalUserObjects returns all users of the portal, both decoded
+// and as the raw JSON objects of the same index.
+func getAllPortalUserObjects(client *alkira.AlkiraClient) ([]portalUser, []map[string]interface{}, error) {
+	data, err := newPortalUser(client).GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var users []portalUser
+	if err := json.Unmarshal([]byte(data), &users); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal users: %w", err)
+	}
+
+	var objects []map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &objects); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal users: %w", err)
+	}
+
+	return users, objects, nil
+}
+
+// decodePortalUser decodes the raw JSON object of a user.
+func decodePortalUser(object map[string]interface{}) (portalUser, error) {
+	var user portalUser
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return user, err
+	}
+
+	if err := json.Unmarshal(data, &user); err != nil {
+		return user, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+
+	return user, nil
+}
+
+// mergePortalUser sets the attributes modeled by portalUser in the raw
+// JSON object of a user, leaving its other attributes untouched.
+func mergePortalUser(object map[string]interface{}, user *portalUser) {
+	object["userName"] = user.UserName
+	object["email"] = user.Email
+	object["firstName"] = user.FirstName
+	object["lastName"] = user.LastName
+	object["userGroups"] = user.UserGroups
+}
+
+func (u *portalUser) inGroup(groupId string) bool {
+	return stringInSlice(groupId, u.UserGroups)
+}
+
+// setGroup adds the user to or removes the user from a group and
+// reports whether the groups of the user changed.
+func (u *portalUser) setGroup(groupId string, member bool) bool {
+	if u.inGroup(groupId) == member {
+		return false
+	}
+
+	if member {
+		u.UserGroups = append(u.UserGroups, groupId)
+		return true
+	}
+
+	groups := make([]string, 0, len(u.UserGroups))
+	for _, g := range u.UserGroups {
+		if g != groupId {
+			groups = append(groups, g)
+		}
+	}
+	u.UserGroups = groups
+
+	return true
+}
+
+// groupMembers returns the sorted IDs of the users in a group.
+func groupMembers(users []portalUser, groupId string) []string {
+	var members []string
+
+	for _, u := range users {
+		if u.inGroup(groupId) {
+			members = append(members, u.Id)
+		}
+	}
+
+	sort.Strings(members)
+	return members
+}
+
+// updateGroupMembership adds the users in add to a group and removes
+// the users in remove from it, leaving the other groups of the users
+// untouched. Only the users whose groups change are updated, and only
+// their userGroups is changed.
+func updateGroupMembership(client *alkira.AlkiraClient, groupId string, add, remove []string) error {
+	users, objects, err := getAllPortalUserObjects(client)
+	if err != nil {
+		return err
+	}
+
+	byId := make(map[string]*portalUser, len(users))
+	for i := range users {
+		byId[users[i].Id] = &users[i]
+	}
+
+	for _, id := range add {
+		if _, ok := byId[id]; !ok {
+			return fmt.Errorf("user %s does not exist", id)
+		}
+	}
+
+	changed := make(map[string]bool)
+
+	for _, id := range remove {
+		if u, ok := byId[id]; ok && u.setGroup(groupId, false) {
+			changed[id] = true
+		}
+	}
+
+	for _, id := range add {
+		if byId[id].setGroup(groupId, true) {
+			changed[id] = true
+		}
+	}
+
+	api := newPortalUserObject(client)
+
+	for i, u := range users {
+		if !changed[u.Id] {
+			continue
+		}
+
+		object := objects[i]
+		object["userGroups"] = u.UserGroups
+
+		if _, err, _, _ := api.Update(u.Id, &object); err != nil {
+			return fmt.Errorf("failed to update the groups of user %s: %w", u.UserName, err)
+		}
+	}
+
+	return nil
+}
+
+// flattenPortalUser returns the attributes of a user in the data
+// sources.
+func flattenPortalUser(u portalUser) map[string]interface{} {
+	groups := append([]string{}, u.UserGroups...)
+	sort.Strings(groups)
+
+	return map[string]interface{}{
+		"id":         u.Id,
+		"user_name":  u.UserName,
+		"email":      u.Email,
+		"first_name": u.FirstName,
+		"last_name":  u.LastName,
+		"group_ids":  groups,
+	}
+}
@@ -0,0 +1,478 @@
+package alkira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	updateStrategyAllAtOnce = "all_at_once"
+	updateStrategyRolling   = "rolling"
+)
+
+// rollingUpdateHealthInterval is the interval between two checks of the
+// health of an updated instance.
+var rollingUpdateHealthInterval = 15 * time.Second
+
+// updateStrategySchema is the update_strategy block of the services
+// with instances.
+func updateStrategySchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "How changes of the instances are applied. By default " +
+			"the whole service, all instances included, is updated at once.",
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Description: "The update strategy, either `all_at_once` " +
+						"or `rolling`. A `rolling` update applies the changed " +
+						"instances one batch at a time and halts on the " +
+						"first batch that fails. The default value is " +
+						"`all_at_once`.",
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  updateStrategyAllAtOnce,
+					ValidateFunc: validation.StringInSlice([]string{
+						updateStrategyAllAtOnce,
+						updateStrategyRolling,
+					}, false),
+				},
+				"max_unavailable": {
+					Description: "The number of instances updated in one " +
+						"batch of a `rolling` update. The default value is `1`.",
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+				"health_check": {
+					Description: "Whether to wait for the updated instances " +
+						"of a batch to be healthy before updating the next " +
+						"batch. The default value is `true`.",
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+				"health_check_timeout": {
+					Description: "The number of seconds to wait for an " +
+						"updated instance to be healthy. The default value " +
+						"is `1800`.",
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1800,
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+			},
+		},
+	}
+}
+
+type updateStrategy struct {
+	Type               string
+	MaxUnavailable     int
+	HealthCheck        bool
+	HealthCheckTimeout time.Duration
+}
+
+func expandUpdateStrategy(in []interface{}) updateStrategy {
+	strategy := updateStrategy{
+		Type:               updateStrategyAllAtOnce,
+		MaxUnavailable:     1,
+		HealthCheck:        true,
+		HealthCheckTimeout: 1800 * time.Second,
+	}
+
+	if len(in) == 0 || in[0] == nil {
+		return strategy
+	}
+
+	cfg := in[0].(map[string]interface{})
+
+	if v, ok := cfg["type"].(string); ok && v != "" {
+		strategy.Type = v
+	}
+	if v, ok := cfg["max_unavailable"].(int); ok && v > 0 {
+		strategy.MaxUnavailable = v
+	}
+	if v, ok := cfg["health_check"].(bool); ok {
+		strategy.HealthCheck = v
+	}
+	if v, ok := cfg["health_check_timeout"].(int); ok && v > 0 {
+		strategy.HealthCheckTimeout = time.Duration(v) * time.Second
+	}
+
+	return strategy
+}
+
+// updateServiceInstances updates a service with instances according to
+// its update_strategy and returns what api.Update returns.
+//
+// A rolling update sends one update per batch of changed instances.
+// The update of a batch carries the new configuration of the service
+// and of the instances rolled so far, while the other instances keep
+// their current configuration and new instances are left out. The last
+// update is the request itself, which also removes the instances no
+// longer configured. The update halts on the first batch that fails
+// validation or provisioning or whose instances don't become healthy.
+func updateServiceInstances[T any](ctx context.Context, d *schema.ResourceData, api *alkira.AlkiraAPI[T], request *T) (string, error, error, error) {
+	strategy := expandUpdateStrategy(d.Get("update_strategy").([]interface{}))
+
+	if strategy.Type != updateStrategyRolling {
+		return api.Update(d.Id(), request)
+	}
+
+	current, _, err := api.GetById(d.Id())
+	if err != nil {
+		return "", fmt.Errorf("rolling update: failed to get the current service: %w", err), nil, nil
+	}
+
+	desiredObject, err := serviceObject(request)
+	if err != nil {
+		return "", err, nil, nil
+	}
+
+	currentObject, err := serviceObject(current)
+	if err != nil {
+		return "", err, nil, nil
+	}
+
+	batches := rollingUpdateBatches(desiredObject, currentObject, strategy.MaxUnavailable)
+
+	tflog.Info(ctx, "rolling update", map[string]interface{}{
+		"id":      d.Id(),
+		"batches": len(batches),
+	})
+
+	raw := &alkira.AlkiraAPI[map[string]interface{}]{
+		Client:    api.Client,
+		Uri:       api.Uri,
+		Provision: api.Provision,
+	}
+
+	rolled := make(map[int]bool)
+	var updated []string
+
+	for i, batch := range batches {
+		var names []string
+		for _, index := range batch {
+			rolled[index] = true
+			names = append(names, instanceName(instancesOf(desiredObject)[index]))
+		}
+
+		tflog.Info(ctx, "rolling update: updating instances", map[string]interface{}{
+			"id":        d.Id(),
+			"batch":     i + 1,
+			"instances": names,
+		})
+
+		var state string
+		var err, valErr, provErr error
+
+		if i == len(batches)-1 {
+			state, err, valErr, provErr = api.Update(d.Id(), request)
+		} else {
+			step := rollingUpdateStep(desiredObject, currentObject, rolled)
+			state, err, valErr, provErr = raw.Update(d.Id(), &step)
+		}
+
+		if err == nil && valErr == nil && state != "FAILED" && strategy.HealthCheck {
+			err = waitForServiceInstances(ctx, api, d.Id(), names, strategy.HealthCheckTimeout)
+		}
+
+		if err != nil || valErr != nil || state == "FAILED" {
+			if err != nil {
+				err = rollingUpdateHalted(err, updated, names, batches, desiredObject, rolled)
+			}
+			return state, err, valErr, provErr
+		}
+
+		updated = append(updated, names...)
+
+		if i == len(batches)-1 {
+			return state, nil, nil, provErr
+		}
+	}
+
+	// Nothing to roll, e.g. only the service itself changed.
+	return api.Update(d.Id(), request)
+}
+
+func rollingUpdateHalted(err error, updated, failed []string, batches [][]int, desired map[string]interface{}, rolled map[int]bool) error {
+	var pending []string
+	for _, batch := range batches {
+		for _, index := range batch {
+			if !rolled[index] {
+				pending = append(pending, instanceName(instancesOf(desired)[index]))
+			}
+		}
+	}
+
+	return fmt.Errorf("rolling update halted on instances [%s], updated [%s], not updated [%s]: %w",
+		strings.Join(failed, ", "), strings.Join(updated, ", "), strings.Join(pending, ", "), err)
+}
+
+// serviceObject returns the JSON object of a service.
+func serviceObject(service interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(service)
+	if err != nil {
+		return nil, fmt.Errorf("rolling update: failed to marshal the service: %w", err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("rolling update: failed to unmarshal the service: %w", err)
+	}
+
+	return object, nil
+}
+
+func instancesOf(object map[string]interface{}) []map[string]interface{} {
+	list, _ := object["instances"].([]interface{})
+
+	instances := make([]map[string]interface{}, 0, len(list))
+	for _, i := range list {
+		if instance, ok := i.(map[string]interface{}); ok {
+			instances = append(instances, instance)
+		}
+	}
+
+	return instances
+}
+
+func instanceName(instance map[string]interface{}) string {
+	name, _ := instance["name"].(string)
+	return name
+}
+
+func instanceId(instance map[string]interface{}) string {
+	id := fmt.Sprint(instance["id"])
+	if instance["id"] == nil || id == "0" {
+		return ""
+	}
+	return id
+}
+
+// matchInstance returns the current instance a desired instance is the
+// new configuration of, by ID or else by name.
+func matchInstance(desired map[string]interface{}, current []map[string]interface{}) map[string]interface{} {
+	if id := instanceId(desired); id != "" {
+		for _, c := range current {
+			if instanceId(c) == id {
+				return c
+			}
+		}
+	}
+
+	for _, c := range current {
+		if instanceName(c) == instanceName(desired) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// instanceChanged reports whether a desired instance differs from its
+// current configuration, ignoring what only the API returns.
+func instanceChanged(desired, current map[string]interface{}) bool {
+	if current == nil {
+		return true
+	}
+
+	for key, value := range desired {
+		if key == "id" {
+			continue
+		}
+		if !reflect.DeepEqual(value, current[key]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rollingUpdateBatches returns the indexes of the changed desired
+// instances in batches of at most size instances.
+func rollingUpdateBatches(desired, current map[string]interface{}, size int) [][]int {
+	currentInstances := instancesOf(current)
+
+	var batches [][]int
+	var batch []int
+
+	for i, instance := range instancesOf(desired) {
+		if !instanceChanged(instance, matchInstance(instance, currentInstances)) {
+			continue
+		}
+
+		batch = append(batch, i)
+		if len(batch) == size {
+			batches = append(batches, batch)
+			batch = nil
+		}
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// rollingUpdateStep returns the service with the new configuration of
+// the rolled instances only.
+func rollingUpdateStep(desired, current map[string]interface{}, rolled map[int]bool) map[string]interface{} {
+	step := make(map[string]interface{}, len(desired))
+	for key, value := range desired {
+		step[key] = value
+	}
+
+	currentInstances := instancesOf(current)
+	matched := make(map[string]bool)
+
+	var instances []interface{}
+
+	for i, instance := range instancesOf(desired) {
+		c := matchInstance(instance, currentInstances)
+		if c != nil {
+			matched[instanceId(c)+"/"+instanceName(c)] = true
+		}
+
+		switch {
+		case rolled[i] || !instanceChanged(instance, c):
+			instances = append(instances, instance)
+		case c != nil:
+			instances = append(instances, currentInstance(instance, c))
+		}
+	}
+
+	// Removed instances are kept until the last update.
+	for _, c := range currentInstances {
+		if !matched[instanceId(c)+"/"+instanceName(c)] {
+			instances = append(instances, c)
+		}
+	}
+
+	step["instances"] = instances
+	return step
+}
+
+// currentInstance returns the current configuration of an instance in
+// the shape of the request.
+func currentInstance(desired, current map[string]interface{}) map[string]interface{} {
+	instance := make(map[string]interface{}, len(desired))
+
+	for key := range desired {
+		if value, ok := current[key]; ok {
+			instance[key] = value
+		}
+	}
+
+	if id, ok := current["id"]; ok {
+		instance["id"] = id
+	}
+
+	return instance
+}
+
+// waitForServiceInstances waits for the instances with the given names
+// to be healthy.
+func waitForServiceInstances[T any](ctx context.Context, api *alkira.AlkiraAPI[T], serviceId string, names []string, timeout time.Duration) error {
+	// New instances only get an ID once created.
+	service, _, err := api.GetById(serviceId)
+	if err != nil {
+		return fmt.Errorf("failed to get the service: %w", err)
+	}
+
+	object, err := serviceObject(service)
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string]string)
+	for _, instance := range instancesOf(object) {
+		ids[instanceName(instance)] = instanceId(instance)
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	for _, name := range names {
+		id := ids[name]
+		if id == "" {
+			return fmt.Errorf("instance %q not found after the update", name)
+		}
+
+		for {
+			data, err := api.Client.GetHealthOfServiceInstance(serviceId, id)
+
+			status := ""
+			if err == nil {
+				status, err = serviceHealthStatus(data)
+				if err != nil {
+					return fmt.Errorf("instance %q: %w", name, err)
+				}
+				if status == serviceHealthUp {
+					break
+				}
+			}
+
+			if time.Now().After(deadline) {
+				if err != nil {
+					return fmt.Errorf("instance %q is not healthy after %s: %w", name, timeout, err)
+				}
+				return fmt.Errorf("instance %q is not healthy after %s, its health is %q", name, timeout, status)
+			}
+
+			tflog.Debug(ctx, "rolling update: waiting for instance", map[string]interface{}{
+				"instance": name,
+				"health":   status,
+			})
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(rollingUpdateHealthInterval):
+			}
+		}
+	}
+
+	return nil
+}
+
+// serviceInstanceHealth is the response of GetHealthOfServiceInstance.
+type serviceInstanceHealth struct {
+	Status string `json:"status"`
+}
+
+// serviceHealthUp is the status of a healthy instance.
+const serviceHealthUp = "UP"
+
+// serviceHealthStatus returns the upper case status of a health
+// response. A response without a status is an error rather than an
+// unhealthy instance, so that the update doesn't wait for the whole
+// timeout on a response it can't read.
+func serviceHealthStatus(data string) (string, error) {
+	var health serviceInstanceHealth
+	if err := json.Unmarshal([]byte(data), &health); err != nil {
+		return "", fmt.Errorf("failed to parse the health response %q: %w", data, err)
+	}
+
+	if health.Status == "" {
+		return "", fmt.Errorf("the health response %q has no status", data)
+	}
+
+	return strings.ToUpper(health.Status), nil
+}
@@ -0,0 +1,261 @@
+package alkira
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alkiranet/alkira-client-go/alkira"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockFortinetService serves the Fortinet service "1" and the health of
+// its instances, testdata/service_health/instance_up.json unless health
+// has another response for the instance ID.
+type mockFortinetService struct {
+	mu      sync.Mutex
+	service alkira.ServiceFortinet
+	updates [][]string
+	health  map[string]string
+}
+
+func testServiceHealth(t *testing.T, name string) string {
+	data, err := os.ReadFile(filepath.Join("testdata", "service_health", name+".json"))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func (m *mockFortinetService) client(t *testing.T) *alkira.AlkiraClient {
+	return createMockAlkiraClient(t, func(w http.ResponseWriter, req *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case req.URL.Path == "/tenantnetworks/0/ftnt-fw-services/1" && req.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(m.service)
+		case req.URL.Path == "/tenantnetworks/0/ftnt-fw-services/1" && req.Method == http.MethodPut:
+			body, _ := io.ReadAll(req.Body)
+
+			var service alkira.ServiceFortinet
+			require.NoError(t, json.Unmarshal(body, &service))
+
+			var names []string
+			for i := range service.Instances {
+				if service.Instances[i].Id == 0 {
+					service.Instances[i].Id = 100 + i
+				}
+				names = append(names, service.Instances[i].Name+"="+service.Instances[i].HostName)
+			}
+
+			service.Id = "1"
+			m.service = service
+			m.updates = append(m.updates, names)
+			w.Write([]byte("{}"))
+		case strings.HasPrefix(req.URL.Path, "/tenantnetworks/0/health/service/1/instance/"):
+			id := strings.TrimPrefix(req.URL.Path, "/tenantnetworks/0/health/service/1/instance/")
+			health, ok := m.health[id]
+			if !ok {
+				health = testServiceHealth(t, "instance_up")
+			}
+			w.Write([]byte(health))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func testFortinetService() alkira.ServiceFortinet {
+	return alkira.ServiceFortinet{
+		Id:   "1",
+		Name: "fortinet",
+		Instances: []alkira.FortinetInstance{
+			{Id: 11, Name: "fw1", HostName: "old"},
+			{Id: 12, Name: "fw2", HostName: "old"},
+			{Id: 13, Name: "fw3", HostName: "old"},
+			{Id: 15, Name: "fw5", HostName: "old"},
+		},
+	}
+}
+
+func testFortinetRequest() *alkira.ServiceFortinet {
+	return &alkira.ServiceFortinet{
+		Name: "fortinet",
+		Instances: []alkira.FortinetInstance{
+			{Id: 11, Name: "fw1", HostName: "new"},
+			{Id: 12, Name: "fw2", HostName: "old"},
+			{Id: 13, Name: "fw3", HostName: "new"},
+			{Name: "fw4", HostName: "new"},
+		},
+	}
+}
+
+func testRollingUpdateData(t *testing.T, strategy map[string]interface{}) *schema.ResourceData {
+	r := resourceAlkiraServiceFortinet()
+
+	config := map[string]interface{}{}
+	if strategy != nil {
+		config["update_strategy"] = []interface{}{strategy}
+	}
+
+	d := schema.TestResourceDataRaw(t, r.Schema, config)
+	d.SetId("1")
+
+	return d
+}
+
+func TestRollingUpdateBatches(t *testing.T) {
+	desired, err := serviceObject(testFortinetRequest())
+	require.NoError(t, err)
+
+	service := testFortinetService()
+	current, err := serviceObject(&service)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]int{{0}, {2}, {3}}, rollingUpdateBatches(desired, current, 1))
+	assert.Equal(t, [][]int{{0, 2}, {3}}, rollingUpdateBatches(desired, current, 2))
+	assert.Equal(t, [][]int{{0, 2, 3}}, rollingUpdateBatches(desired, current, 5))
+
+	// Unchanged instances are left out.
+	assert.Empty(t, rollingUpdateBatches(current, current, 1))
+}
+
+func TestUpdateServiceInstancesAllAtOnce(t *testing.T) {
+	mock := &mockFortinetService{service: testFortinetService()}
+	client := mock.client(t)
+
+	d := testRollingUpdateData(t, nil)
+
+	_, err, _, _ := updateServiceInstances(context.Background(), d, alkira.NewServiceFortinet(client), testFortinetRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"fw1=new", "fw2=old", "fw3=new", "fw4=new"},
+	}, mock.updates)
+}
+
+func TestUpdateServiceInstancesRolling(t *testing.T) {
+	mock := &mockFortinetService{service: testFortinetService()}
+	client := mock.client(t)
+
+	d := testRollingUpdateData(t, map[string]interface{}{
+		"type":            "rolling",
+		"max_unavailable": 1,
+		"health_check":    true,
+	})
+
+	_, err, _, _ := updateServiceInstances(context.Background(), d, alkira.NewServiceFortinet(client), testFortinetRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"fw1=new", "fw2=old", "fw3=old", "fw5=old"},
+		{"fw1=new", "fw2=old", "fw3=new", "fw5=old"},
+		{"fw1=new", "fw2=old", "fw3=new", "fw4=new"},
+	}, mock.updates)
+}
+
+func TestUpdateServiceInstancesRollingHalts(t *testing.T) {
+	interval := rollingUpdateHealthInterval
+	rollingUpdateHealthInterval = 10 * time.Millisecond
+	t.Cleanup(func() { rollingUpdateHealthInterval = interval })
+
+	mock := &mockFortinetService{
+		service: testFortinetService(),
+		health:  map[string]string{"13": testServiceHealth(t, "instance_down")},
+	}
+	client := mock.client(t)
+
+	d := testRollingUpdateData(t, map[string]interface{}{
+		"type":                 "rolling",
+		"max_unavailable":      1,
+		"health_check":         true,
+		"health_check_timeout": 1,
+	})
+
+	_, err, _, _ := updateServiceInstances(context.Background(), d, alkira.NewServiceFortinet(client), testFortinetRequest())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rolling update halted on instances [fw3], updated [fw1], not updated [fw4]")
+	assert.Contains(t, err.Error(), `instance "fw3" is not healthy after 1s, its health is "DOWN"`)
+
+	// The instances after the failed batch are left alone.
+	assert.Len(t, mock.updates, 2)
+
+	// Without health checks the update doesn't wait for the instances.
+	mock = &mockFortinetService{
+		service: testFortinetService(),
+		health:  map[string]string{"13": testServiceHealth(t, "instance_down")},
+	}
+	client = mock.client(t)
+
+	d = testRollingUpdateData(t, map[string]interface{}{
+		"type":         "rolling",
+		"health_check": false,
+	})
+
+	_, err, _, _ = updateServiceInstances(context.Background(), d, alkira.NewServiceFortinet(client), testFortinetRequest())
+	require.NoError(t, err)
+	assert.Len(t, mock.updates, 3)
+}
+
+func TestUpdateServiceInstancesRollingUnreadableHealth(t *testing.T) {
+	// An unreadable health response fails the update at once instead of
+	// waiting for the timeout.
+	mock := &mockFortinetService{
+		service: testFortinetService(),
+		health:  map[string]string{"11": `{"health": "UP"}`},
+	}
+	client := mock.client(t)
+
+	d := testRollingUpdateData(t, map[string]interface{}{
+		"type":            "rolling",
+		"max_unavailable": 1,
+		"health_check":    true,
+	})
+
+	start := time.Now()
+	_, err, _, _ := updateServiceInstances(context.Background(), d, alkira.NewServiceFortinet(client), testFortinetRequest())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `instance "fw1": the health response`)
+	assert.Less(t, time.Since(start), rollingUpdateHealthInterval)
+	assert.Len(t, mock.updates, 1)
+}
+
+func TestServiceHealthStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{name: "up", data: testServiceHealth(t, "instance_up"), want: "UP"},
+		{name: "down", data: testServiceHealth(t, "instance_down"), want: "DOWN"},
+		{name: "lower case", data: `{"status": "up"}`, want: "UP"},
+		{name: "no status", data: `{"health": "DOWN"}`, wantErr: "has no status"},
+		{name: "not an object", data: `["UP"]`, wantErr: "failed to parse"},
+		{name: "not json", data: `not json`, wantErr: "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := serviceHealthStatus(tt.data)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
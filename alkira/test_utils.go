@@ -1,6 +1,7 @@
 package alkira
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/alkiranet/alkira-client-go/alkira"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -29,6 +31,29 @@ func createMockAlkiraClient(t *testing.T, handler http.HandlerFunc) *alkira.Alki
 	}
 }
 
+// testResourceDataWithRawConfig returns the resource data of a
+// configuration along with its raw configuration, which
+// schema.TestResourceDataRaw leaves null.
+func testResourceDataWithRawConfig(t *testing.T, r *schema.Resource, config map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+
+	d := schema.TestResourceDataRaw(t, r.Schema, config)
+	d.SetId("test")
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("failed to marshal the configuration: %s", err)
+	}
+
+	state := d.State()
+	state.RawConfig, err = ctyjson.Unmarshal(data, r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatalf("failed to build the raw configuration: %s", err)
+	}
+
+	return r.Data(state)
+}
+
 // UNUSED: Commented out to suppress linter warnings
 // // Common HTTP handler for mock responses
 // func createMockHandler(responseData interface{}, statusCode int) http.HandlerFunc {
@@ -0,0 +1,12 @@
+data "alkira_byoip_message" "aws" {
+  cloud_provider = "AWS"
+  prefix         = "198.51.100.0/24"
+  account_id     = "012345678901"
+  expiry_date    = "2030-12-31"
+}
+
+# Sign the message with the private key of the BYOIP certificate, e.g.
+# with the command of data.alkira_byoip_message.aws.openssl_command.
+output "byoip_message" {
+  value = data.alkira_byoip_message.aws.message
+}
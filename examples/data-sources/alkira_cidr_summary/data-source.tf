@@ -0,0 +1,14 @@
+data "alkira_cidr_summary" "segments" {
+  cidrs = concat(alkira_segment.prod.cidrs, alkira_segment.dev.cidrs)
+}
+
+resource "alkira_policy_prefix_list" "segments" {
+  name = "segments"
+
+  dynamic "prefix" {
+    for_each = data.alkira_cidr_summary.segments.summary
+    content {
+      cidr = prefix.value
+    }
+  }
+}
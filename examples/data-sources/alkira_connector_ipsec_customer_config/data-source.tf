@@ -0,0 +1,20 @@
+data "alkira_connector_ipsec_customer_config" "branch" {
+  connector_ipsec_id = alkira_connector_ipsec.branch.id
+  vendor             = "cisco_iosxe"
+  endpoint_name      = "branch-1"
+  wan_interface      = "GigabitEthernet1"
+  alkira_asn         = "65001"
+
+  tunnel {
+    endpoint_name       = "branch-1"
+    tunnel_no           = 1
+    alkira_public_ip    = "192.0.2.10"
+    alkira_overlay_ip   = "169.254.0.1/30"
+    customer_overlay_ip = "169.254.0.2/30"
+  }
+}
+
+resource "local_sensitive_file" "branch" {
+  content  = data.alkira_connector_ipsec_customer_config.branch.config
+  filename = "${path.module}/branch-1.cfg"
+}
@@ -1,3 +1,8 @@
 data "alkira_credential" "cred1" {
   name = "credential-name"
 }
+
+data "alkira_credential" "pan" {
+  name_regex = "^pan-registration-"
+  type       = "pan-registration"
+}
@@ -0,0 +1,11 @@
+data "alkira_credentials" "aws" {
+  type = "awsvpc"
+}
+
+# The AWS credentials no connector uses.
+output "unused_aws_credentials" {
+  value = setsubtract(
+    data.alkira_credentials.aws.ids,
+    [for c in alkira_connector_aws_vpc.all : c.credential_id]
+  )
+}
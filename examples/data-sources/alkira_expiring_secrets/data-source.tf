@@ -0,0 +1,7 @@
+data "alkira_expiring_secrets" "soon" {
+  within_days = 60
+}
+
+output "expiring_secrets" {
+  value = data.alkira_expiring_secrets.soon.secrets
+}
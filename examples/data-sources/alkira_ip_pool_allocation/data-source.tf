@@ -0,0 +1,13 @@
+data "alkira_ip_pool_allocation" "tunnel" {
+  for_each = alkira_ip_pool.tunnels.allocated_prefixes
+
+  prefix              = each.value
+  first_ip_assignment = "CXP"
+}
+
+output "tunnel_ips" {
+  value = {
+    for name, a in data.alkira_ip_pool_allocation.tunnel :
+    name => "${a.cxp_ip} <-> ${a.customer_ip}"
+  }
+}
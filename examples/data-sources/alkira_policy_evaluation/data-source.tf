@@ -0,0 +1,28 @@
+data "alkira_policy_evaluation" "web" {
+  segment_id   = alkira_segment.prod.id
+  src_group_id = alkira_group.branches.id
+  dst_group_id = alkira_group.datacenter.id
+  src_ip       = "172.16.0.10"
+  dst_ip       = "10.1.2.3"
+  protocol     = "tcp"
+  dst_port     = 443
+}
+
+check "web_goes_through_firewall" {
+  assert {
+    condition     = data.alkira_policy_evaluation.web.action == "ALLOW" && contains(data.alkira_policy_evaluation.web.service_ids, tonumber(alkira_service_pan.firewall.id))
+    error_message = "Web traffic to the datacenter must be steered to the firewall: ${join("; ", data.alkira_policy_evaluation.web.trace)}"
+  }
+}
+
+# Evaluate the planned policy, rule list and rules before they are applied.
+data "alkira_policy_evaluation" "planned" {
+  policy     = [alkira_policy.web]
+  rule_list  = [alkira_policy_rule_list.web]
+  rule       = [alkira_policy_rule.allow_web, alkira_policy_rule.drop_all]
+  segment_id = alkira_segment.prod.id
+  src_ip     = "172.16.0.10"
+  dst_ip     = "10.1.2.3"
+  protocol   = "tcp"
+  dst_port   = 443
+}
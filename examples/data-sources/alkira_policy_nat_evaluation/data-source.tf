@@ -0,0 +1,27 @@
+data "alkira_policy_nat_evaluation" "web" {
+  policy_nat_id = alkira_policy_nat.ingress.id
+  segment_id    = alkira_segment.corp.id
+  src_ip        = "198.51.100.20"
+  dst_ip        = "203.0.113.10"
+  protocol      = "tcp"
+  src_port      = 40000
+  dst_port      = 8443
+}
+
+check "web_port_forward" {
+  assert {
+    condition = (
+      data.alkira_policy_nat_evaluation.web.translated_dst_ip == "172.16.0.10" &&
+      data.alkira_policy_nat_evaluation.web.translated_dst_port == 443
+    )
+    error_message = "Port 8443 must be forwarded to 172.16.0.10:443: ${join("; ", data.alkira_policy_nat_evaluation.web.trace)}"
+  }
+}
+
+# Evaluate the planned rules before they are applied.
+data "alkira_policy_nat_evaluation" "hide" {
+  rule     = [alkira_policy_nat_rule.hide]
+  src_ip   = "192.168.1.10"
+  dst_ip   = "203.0.113.10"
+  protocol = "tcp"
+}
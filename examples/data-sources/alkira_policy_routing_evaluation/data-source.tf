@@ -0,0 +1,13 @@
+data "alkira_policy_routing_evaluation" "leak" {
+  rule               = alkira_policy_routing.export.rule
+  prefix             = "192.0.2.0/24"
+  as_path            = "65001 65100 65200"
+  connector_group_id = alkira_group.branches.id
+}
+
+check "no_route_leak" {
+  assert {
+    condition     = !data.alkira_policy_routing_evaluation.leak.allowed
+    error_message = "Routes learned through AS 65100 must not be exported: ${join("; ", data.alkira_policy_routing_evaluation.leak.trace)}"
+  }
+}
@@ -0,0 +1,12 @@
+data "alkira_unreferenced_objects" "tenant" {}
+
+output "orphaned_prefix_lists" {
+  value = [for o in data.alkira_unreferenced_objects.tenant.prefix_lists : o.name]
+}
+
+# Adopt the orphaned billing tags to delete them with Terraform.
+import {
+  for_each = { for o in data.alkira_unreferenced_objects.tenant.billing_tags : o.id => o }
+  to       = alkira_billing_tag.orphaned[each.key]
+  id       = each.key
+}
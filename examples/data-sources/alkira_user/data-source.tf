@@ -0,0 +1,3 @@
+data "alkira_user" "jane" {
+  email = "jane.doe@example.com"
+}
@@ -0,0 +1,3 @@
+data "alkira_users" "operators" {
+  group_id = alkira_group_user.operators.id
+}
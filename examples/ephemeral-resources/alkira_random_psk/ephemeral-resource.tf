@@ -0,0 +1,26 @@
+ephemeral "alkira_random_psk" "tunnel" {
+  length = 40
+}
+
+resource "alkira_connector_ipsec_adv" "branch" {
+  name                = "branch"
+  cxp                 = "US-WEST"
+  segment_id          = alkira_segment.prod.id
+  size                = "SMALL"
+  vpn_mode            = "ROUTE_BASED"
+  tunnels_per_gateway = 1
+
+  gateway {
+    name                = "gw1"
+    customer_gateway_ip = "8.8.8.8"
+
+    tunnel {
+      customer_end_overlay_ip_reservation_id = alkira_ip_reservation.customer.id
+      cxp_end_overlay_ip_reservation_id      = alkira_ip_reservation.cxp_overlay.id
+      cxp_end_public_ip_reservation_id       = alkira_ip_reservation.cxp_public.id
+
+      preshared_key_wo         = ephemeral.alkira_random_psk.tunnel.value
+      preshared_key_wo_version = 1
+    }
+  }
+}
@@ -0,0 +1,13 @@
+ephemeral "alkira_session_token" "portal" {}
+
+resource "terraform_data" "report" {
+  provisioner "local-exec" {
+    command = "curl -sf -H \"$HEADER\" \"$API_URL/tenantnetworks/$TENANT/segments\""
+
+    environment = {
+      HEADER  = "${ephemeral.alkira_session_token.portal.header_name}: ${ephemeral.alkira_session_token.portal.header_value}"
+      API_URL = ephemeral.alkira_session_token.portal.api_url
+      TENANT  = ephemeral.alkira_session_token.portal.tenant_network_id
+    }
+  }
+}
@@ -0,0 +1,8 @@
+variable "gateway_ip" {
+  type = string
+
+  validation {
+    condition     = provider::alkira::cidr_contains("172.16.0.0/12", var.gateway_ip)
+    error_message = "The gateway address must be within 172.16.0.0/12."
+  }
+}
@@ -0,0 +1,8 @@
+variable "branch_cidr" {
+  type = string
+
+  validation {
+    condition     = !provider::alkira::cidr_overlaps(var.branch_cidr, "10.0.0.0/16")
+    error_message = "The branch CIDR must not overlap the segment CIDR."
+  }
+}
@@ -0,0 +1,12 @@
+locals {
+  # { "US-WEST" = "10.0.0.0/17", "US-EAST" = "10.0.128.0/17" }
+  cxp_cidrs = provider::alkira::cidr_split_per_cxp("10.0.0.0/16", ["US-WEST", "US-EAST"])
+}
+
+resource "alkira_segment" "prod" {
+  for_each = local.cxp_cidrs
+
+  name  = "prod-${lower(each.key)}"
+  asn   = "65513"
+  cidrs = [each.value]
+}
@@ -0,0 +1,15 @@
+variable "communities" {
+  type = list(string)
+
+  validation {
+    condition = alltrue([
+      for c in var.communities : provider::alkira::community_valid(c, "standard")
+    ])
+    error_message = "Communities must be in the format AA:NN."
+  }
+}
+
+resource "alkira_list_community" "example" {
+  name   = "example"
+  values = var.communities
+}
@@ -0,0 +1,12 @@
+locals {
+  # The third /30 of the tunnel CIDR, 169.254.100.8/30.
+  tunnel = provider::alkira::tunnel_pair("169.254.100.0/24", 2)
+}
+
+output "cxp_ip" {
+  value = local.tunnel.cxp_ip # 169.254.100.9
+}
+
+output "customer_ip" {
+  value = local.tunnel.customer_ip # 169.254.100.10
+}
@@ -1,8 +1,16 @@
-resource "alkira_byoip" "test" {
-  prefix      = "172.16.1.2"
-  cxp         = "US-WEST"
-  description = "simple test"
-  message     = "1|aws|0123456789AB|198.51.100.0/24|20211231|SHA256|RSAPSS"
-  signature   = "signature from AWS BYOIP"
-  public_key  = "public key from AWS BYOIP"
+data "alkira_byoip_message" "test" {
+  cloud_provider = "AWS"
+  prefix         = "198.51.100.0/24"
+  account_id     = "012345678901"
+  expiry_date    = "2030-12-31"
+}
+
+resource "alkira_byoip_prefix" "test" {
+  prefix         = "198.51.100.0/24"
+  cxp            = "US-WEST"
+  cloud_provider = "AWS"
+  description    = "simple test"
+  message        = data.alkira_byoip_message.test.message
+  signature      = file("signature.txt")
+  public_key     = file("certificate.pem")
 }
@@ -0,0 +1 @@
+terraform import alkira_group_user_membership.example GROUP_ID
@@ -0,0 +1,17 @@
+resource "alkira_group_user" "operators" {
+  name        = "operators"
+  description = "Network operators"
+}
+
+# The listed users are the only members of the group.
+resource "alkira_group_user_membership" "operators" {
+  group_id      = alkira_group_user.operators.id
+  user_ids      = [alkira_user.jane.id]
+  authoritative = true
+}
+
+# Add a user to a group, keeping its other members.
+resource "alkira_group_user_membership" "oncall" {
+  group_id = data.alkira_group_user.oncall.id
+  user_ids = [alkira_user.jane.id]
+}
@@ -0,0 +1,11 @@
+resource "alkira_ip_pool" "tunnels" {
+  name          = "ipsec-tunnels"
+  cidr          = "169.254.100.0/24"
+  prefix_length = 30
+
+  # Keep the first /28 for manual addressing.
+  reserved_prefixes = ["169.254.100.0/28"]
+
+  # One /30 per branch, stable when branches are added or removed.
+  allocations = keys(var.branches)
+}
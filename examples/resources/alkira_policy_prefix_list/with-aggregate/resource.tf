@@ -0,0 +1,12 @@
+resource "alkira_policy_prefix_list" "branches" {
+  name      = "branches"
+  aggregate = true
+
+  # Sent as 10.10.0.0/23.
+  prefix {
+    cidr = "10.10.0.0/24"
+  }
+  prefix {
+    cidr = "10.10.1.0/24"
+  }
+}
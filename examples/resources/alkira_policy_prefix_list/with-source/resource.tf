@@ -0,0 +1,11 @@
+resource "alkira_policy_prefix_list" "s3" {
+  name        = "aws-s3"
+  description = "Prefixes of the AWS managed prefix list of S3"
+
+  # aws ec2 get-managed-prefix-list-entries --prefix-list-id pl-63a5400a
+  prefixes_source {
+    format    = "json"
+    content   = file("${path.module}/s3-prefixes.json")
+    aggregate = true
+  }
+}
@@ -0,0 +1 @@
+terraform import alkira_user.example USER_ID
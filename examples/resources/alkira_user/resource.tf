@@ -0,0 +1,6 @@
+resource "alkira_user" "jane" {
+  user_name  = "jane.doe"
+  email      = "jane.doe@example.com"
+  first_name = "Jane"
+  last_name  = "Doe"
+}
@@ -1,12 +1,30 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+
 	"github.com/alkiranet/terraform-provider-alkira/alkira"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := alkira.RunExport(context.Background(), os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "export: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	providerServer, err := alkira.NewProviderServer(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: alkira.Provider,
+		GRPCProviderFunc: providerServer,
 	})
 }